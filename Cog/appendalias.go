@@ -0,0 +1,155 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// appendAliasRule reports append results stored in a different variable
+// than the slice appended to, while both stay in use. When the source has
+// spare capacity, append writes into its backing array, so the two slices
+// share elements:
+//
+//	b := append(a[:i], a[i+1:]...) // shifts a's own elements: a changes too
+//	c := append(a, x)              // c and a may share storage
+//	a = append(a, y)               // may overwrite c[len(a)-1]
+//
+// To keep false positives low, the rule only fires when the next use of
+// both the source and the result after the append is a read; reassigning
+// either one first ends the aliasing. A full slice expression a[i:j:k]
+// caps the capacity, forces a copy, and is never reported.
+var appendAliasRule = &Rule{
+	ID:       "CogAppendAlias",
+	Doc:      "report append results that alias a source slice still in use",
+	Run:      runAppendAlias,
+	Category: CategoryTypes,
+}
+
+func runAppendAlias(p *Pass) {
+	for c := range p.Inspector.Root().Preorder((*ast.AssignStmt)(nil), (*ast.ValueSpec)(nil)) {
+		var lhs, rhs ast.Expr
+		switch n := c.Node().(type) {
+		case *ast.AssignStmt:
+			if len(n.Lhs) == 1 && len(n.Rhs) == 1 && (n.Tok == token.ASSIGN || n.Tok == token.DEFINE) {
+				lhs, rhs = n.Lhs[0], n.Rhs[0]
+			}
+		case *ast.ValueSpec:
+			if len(n.Names) == 1 && len(n.Values) == 1 {
+				lhs, rhs = n.Names[0], n.Values[0]
+			}
+		}
+		call, ok := ast.Unparen(rhs).(*ast.CallExpr)
+		if !ok || !isBuiltin(p, call.Fun, "append") || len(call.Args) < 2 {
+			continue
+		}
+		dst, ok := lhs.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		result := identVar(p, dst)
+		source, slice := appendSource(p, call.Args[0])
+		if result == nil || source == nil || result == source {
+			continue
+		}
+
+		_, body := enclosingFunc(c)
+		if body == nil || !readNext(p, c, body, source) || !readNext(p, c, body, result) {
+			continue
+		}
+		if slice && call.Ellipsis.IsValid() && slicesVar(p, call.Args[1], source) {
+			text, ok := sourceText(p, call)
+			if !ok {
+				text = types.ExprString(call)
+			}
+			p.Report(call, text+" shifts elements "+
+				"inside "+source.Name()+"'s backing array, so "+source.Name()+" is modified too and "+result.Name()+
+				" aliases it; use slices.Delete on "+source.Name()+", or build "+result.Name()+" from a copy")
+			continue
+		}
+		p.Report(call, result.Name()+" = append("+source.Name()+", ...) may share "+source.Name()+"'s backing array, "+
+			"so a later append to either can overwrite the other's elements; append to slices.Clone("+source.Name()+
+			") or assign the result back to "+source.Name())
+	}
+}
+
+// appendSource returns the variable whose backing array the first append
+// argument e exposes — x for x or x[i:j] — and whether e is a slice
+// expression. Full slice expressions x[i:j:k] limit the capacity and yield
+// nil.
+func appendSource(p *Pass, e ast.Expr) (*types.Var, bool) {
+	switch e := ast.Unparen(e).(type) {
+	case *ast.Ident:
+		return identVar(p, e), false
+	case *ast.SliceExpr:
+		if e.Slice3 {
+			return nil, false
+		}
+		if id, ok := ast.Unparen(e.X).(*ast.Ident); ok {
+			return identVar(p, id), true
+		}
+	}
+	return nil, false
+}
+
+// slicesVar reports whether e is a slice expression of v.
+func slicesVar(p *Pass, e ast.Expr, v *types.Var) bool {
+	s, ok := ast.Unparen(e).(*ast.SliceExpr)
+	if !ok {
+		return false
+	}
+	id, ok := ast.Unparen(s.X).(*ast.Ident)
+	return ok && identVar(p, id) == v
+}
+
+// identVar returns the variable id declares or refers to, or nil.
+func identVar(p *Pass, id *ast.Ident) *types.Var {
+	v, _ := p.TypesInfo.ObjectOf(id).(*types.Var) // FALLBACK: nil for non-variables
+	return v
+}
+
+// isBuiltin reports whether fun names the builtin function name.
+func isBuiltin(p *Pass, fun ast.Expr, name string) bool {
+	id, ok := ast.Unparen(fun).(*ast.Ident)
+	if !ok {
+		return false
+	}
+	b, ok := p.TypesInfo.Uses[id].(*types.Builtin)
+	return ok && b.Name() == name
+}
+
+// readNext reports whether the first reference to v in body after the
+// statement at c reads it, rather than assigning it a new value.
+func readNext(p *Pass, c inspector.Cursor, body *ast.BlockStmt, v *types.Var) bool {
+	after := c.Node().End()
+	bc, ok := p.Inspector.Root().FindNode(body)
+	if !ok {
+		return false
+	}
+	for ic := range bc.Preorder((*ast.Ident)(nil)) {
+		id, ok := ic.Node().(*ast.Ident)
+		if !ok || id.Pos() < after || p.TypesInfo.Uses[id] != v {
+			continue
+		}
+		assign, ok := ic.Parent().Node().(*ast.AssignStmt)
+		return !ok || assign.Tok != token.ASSIGN || !isLHS(assign, id) || appendsTo(p, assign, v)
+	}
+	return false
+}
+
+// appendsTo reports whether assign's right-hand side reads v, as in
+// `v = append(v, x)`, which keeps using v's backing array.
+func appendsTo(p *Pass, assign *ast.AssignStmt, v *types.Var) bool {
+	found := false
+	for _, rhs := range assign.Rhs {
+		ast.Inspect(rhs, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok && p.TypesInfo.Uses[id] == v {
+				found = true
+			}
+			return !found
+		})
+	}
+	return found
+}
@@ -0,0 +1,137 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// appendCapRule reports a slice made with a length that is then only
+// appended to.
+//
+//	names := make([]string, len(users)) // meant make([]string, 0, len(users))
+//	for _, u := range users {
+//		names = append(names, u.Name) // after len(users) empty strings
+//	}
+//
+// make([]T, n) holds n zero values, and append adds after them, so the
+// result starts with n zero values the author never meant to keep. The rule
+// fires on `s := make([]T, n)` (or var s = ...) with a non-zero n when s is
+// appended to and never indexed, sliced, copied into or handed to other
+// code before the appends, which could fill its elements. The fix turns the
+// length into a capacity: make([]T, 0, n).
+var appendCapRule = &Rule{
+	ID:       "CogAppendCap",
+	Doc:      "report slices made with a length and then only appended to",
+	Run:      runAppendCap,
+	Category: CategoryTypes,
+	Fixable:  true,
+}
+
+func runAppendCap(p *Pass) {
+	for c := range p.Inspector.Root().Preorder((*ast.CallExpr)(nil)) {
+		call, ok := c.Node().(*ast.CallExpr)
+		if !ok || len(call.Args) != 2 || !isBuiltin(p, call.Fun, "make") {
+			continue
+		}
+		if _, ok := p.TypesInfo.TypeOf(call).Underlying().(*types.Slice); !ok {
+			continue
+		}
+		if n := p.TypesInfo.Types[call.Args[1]].Value; n != nil && n.String() == "0" {
+			continue
+		}
+		v := madeVar(p, c)
+		_, body := enclosingFunc(c)
+		if v == nil || body == nil || !onlyAppended(p, body, v) {
+			continue
+		}
+		n := types.ExprString(call.Args[1])
+		p.Report(call, v.Name()+" is made with length "+n+" but only appended to, so it starts with "+n+
+			" zero values; use make("+types.ExprString(call.Args[0])+", 0, "+n+")",
+			analysis.SuggestedFix{
+				Message:   "Make the length a capacity",
+				TextEdits: []analysis.TextEdit{{Pos: call.Args[1].Pos(), End: call.Args[1].Pos(), NewText: []byte("0, ")}},
+			})
+	}
+}
+
+// madeVar returns the local variable that the make call at c initializes
+// with := or var, or nil.
+func madeVar(p *Pass, c inspector.Cursor) *types.Var {
+	switch n := c.Parent().Node().(type) {
+	case *ast.AssignStmt:
+		if n.Tok == token.DEFINE && len(n.Lhs) == 1 && len(n.Rhs) == 1 {
+			return localVar(p, n.Lhs[0])
+		}
+	case *ast.ValueSpec:
+		if len(n.Names) == 1 && len(n.Values) == 1 {
+			return localVar(p, n.Names[0])
+		}
+	}
+	return nil
+}
+
+// onlyAppended reports whether v is appended to in body, and every use of
+// v before its last `v = append(v, ...)` is such an append, a len or cap
+// call, or a comparison.
+func onlyAppended(p *Pass, body *ast.BlockStmt, v *types.Var) bool {
+	bc, ok := p.Inspector.Root().FindNode(body)
+	if !ok {
+		return false
+	}
+	uses := make([]inspector.Cursor, 0)
+	lastAppend := token.NoPos
+	for ic := range bc.Preorder((*ast.Ident)(nil)) {
+		id, ok := ic.Node().(*ast.Ident)
+		if !ok || p.TypesInfo.Uses[id] != v {
+			continue
+		}
+		uses = append(uses, ic)
+		if call, ok := ic.Parent().Node().(*ast.CallExpr); ok && isBuiltin(p, call.Fun, "append") &&
+			call.Args[0] == id && selfAppend(p, ic.Parent(), v) {
+			lastAppend = id.Pos()
+		}
+	}
+	if lastAppend == token.NoPos {
+		return false
+	}
+	for _, ic := range uses {
+		id, ok := ic.Node().(*ast.Ident)
+		if !ok || id.Pos() > lastAppend {
+			continue
+		}
+		switch parent := ic.Parent().Node().(type) {
+		case *ast.AssignStmt:
+			if !isLHS(parent, id) || !isSelfAppendAssign(p, parent, v) {
+				return false // aliased, or reassigned to something else
+			}
+		case *ast.CallExpr:
+			switch {
+			case isBuiltin(p, parent.Fun, "len"), isBuiltin(p, parent.Fun, "cap"):
+			case isBuiltin(p, parent.Fun, "append") && parent.Args[0] == id && selfAppend(p, ic.Parent(), v):
+			default:
+				return false // copied into, or handed to code that may fill it
+			}
+		case *ast.BinaryExpr:
+		default:
+			return false // indexed, sliced, ranged over, ...
+		}
+	}
+	return true
+}
+
+// isSelfAppendAssign reports whether assign is `v = append(v, ...)`.
+func isSelfAppendAssign(p *Pass, assign *ast.AssignStmt, v *types.Var) bool {
+	if assign.Tok != token.ASSIGN || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+		return false
+	}
+	call, ok := ast.Unparen(assign.Rhs[0]).(*ast.CallExpr)
+	if !ok || !isBuiltin(p, call.Fun, "append") || len(call.Args) == 0 {
+		return false
+	}
+	id, ok := call.Args[0].(*ast.Ident)
+	return ok && p.TypesInfo.Uses[id] == v
+}
@@ -0,0 +1,11 @@
+package cog
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAppendCap(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), ruleAnalyzer(appendCapRule), "appendcap")
+}
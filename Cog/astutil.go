@@ -0,0 +1,72 @@
+package cog
+
+import (
+	"go/ast"
+
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// enclosingFunc returns the signature and body of the innermost function
+// declaration or literal containing c, or nils at package level.
+func enclosingFunc(c inspector.Cursor) (*ast.FuncType, *ast.BlockStmt) {
+	for fc := range c.Enclosing((*ast.FuncDecl)(nil), (*ast.FuncLit)(nil)) {
+		switch fn := fc.Node().(type) {
+		case *ast.FuncDecl:
+			return fn.Type, fn.Body
+		case *ast.FuncLit:
+			return fn.Type, fn.Body
+		}
+	}
+	return nil, nil
+}
+
+// enclosingDecl returns the top-level function declaration containing c,
+// or nil at package level.
+func enclosingDecl(c inspector.Cursor) *ast.FuncDecl {
+	for fc := range c.Enclosing((*ast.FuncDecl)(nil)) {
+		if decl, ok := fc.Node().(*ast.FuncDecl); ok {
+			return decl
+		}
+	}
+	return nil
+}
+
+// enclosingFile returns the file containing c.
+func enclosingFile(c inspector.Cursor) *ast.File {
+	for fc := range c.Enclosing((*ast.File)(nil)) {
+		if f, ok := fc.Node().(*ast.File); ok {
+			return f
+		}
+	}
+	return nil
+}
+
+// enclosingFuncLit returns the innermost function literal containing c, or
+// nil when c is directly inside a declared function.
+func enclosingFuncLit(c inspector.Cursor) *ast.FuncLit {
+	for fc := range c.Enclosing((*ast.FuncDecl)(nil), (*ast.FuncLit)(nil)) {
+		lit, _ := fc.Node().(*ast.FuncLit) // FALLBACK: nil for a FuncDecl
+		return lit
+	}
+	return nil
+}
+
+// followingStmts returns the statements after the one at c in its
+// statement list, or nil when c is not in a statement list.
+func followingStmts(c inspector.Cursor) []ast.Stmt {
+	var list []ast.Stmt
+	switch n := c.Parent().Node().(type) {
+	case *ast.BlockStmt:
+		list = n.List
+	case *ast.CaseClause:
+		list = n.Body
+	case *ast.CommClause:
+		list = n.Body
+	}
+	for i, stmt := range list {
+		if stmt == c.Node() {
+			return list[i+1:]
+		}
+	}
+	return nil
+}
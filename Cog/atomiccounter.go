@@ -0,0 +1,142 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// atomicCounterRule reports an integer counter that several goroutines
+// update without synchronization.
+//
+//	var processed int
+//	for _, job := range jobs {
+//		wg.Add(1)
+//		go func() {
+//			defer wg.Done()
+//			run(job)
+//			processed++ // every goroutine increments processed
+//		}()
+//	}
+//
+// n++ and n += k read, add and write back; two goroutines doing it at
+// once lose updates, and the race detector reports it. The rule fires on
+// ++, -- or an assignment operator such as += applied to an integer
+// variable of the function inside a `go` statement with a function literal,
+// when that `go` statement runs in a loop declared outside the variable's
+// scope or another `go` statement also uses the variable, so that more than
+// one goroutine provably shares it. It does not fire when the function
+// locks a sync.Mutex or sync.RWMutex anywhere, or when the variable's
+// address is taken, for instance for atomic.AddInt64(&n, 1).
+var atomicCounterRule = &Rule{
+	ID:       "CogAtomicCounter",
+	Doc:      "report integer counters updated by several goroutines without sync/atomic or a mutex",
+	Run:      runAtomicCounter,
+	Category: CategoryConcurrency,
+}
+
+// A counterUpdate is one ++, -- or op= on a counter inside a goroutine.
+type counterUpdate struct {
+	stmt ast.Stmt
+	g    inspector.Cursor // the go statement running it
+}
+
+func runAtomicCounter(p *Pass) {
+	for c := range p.Inspector.Root().Preorder((*ast.FuncDecl)(nil)) {
+		decl, ok := c.Node().(*ast.FuncDecl)
+		if !ok || decl.Body == nil || locksMutex(p, c) {
+			continue
+		}
+		updates := make(map[*types.Var][]counterUpdate)
+		starters := make(map[*types.Var]map[*ast.GoStmt]bool)
+		addressed := make(map[*types.Var]bool)
+		order := make([]*types.Var, 0)
+		for ic := range c.Preorder((*ast.Ident)(nil)) {
+			id, ok := ic.Node().(*ast.Ident)
+			if !ok {
+				continue
+			}
+			v, ok := p.TypesInfo.Uses[id].(*types.Var)
+			if !ok || v.Pos() < decl.Pos() || v.Pos() >= decl.End() || !isIntegerVar(v) {
+				continue
+			}
+			if u, ok := ic.Parent().Node().(*ast.UnaryExpr); ok && u.Op == token.AND {
+				addressed[v] = true
+			}
+			g := startingGo(ic, v)
+			if g == nil {
+				continue
+			}
+			if starters[v] == nil {
+				starters[v] = make(map[*ast.GoStmt]bool)
+				order = append(order, v)
+			}
+			starters[v][g] = true
+			gc, ok := c.FindNode(g)
+			if stmt := counterStmt(ic); stmt != nil && ok {
+				updates[v] = append(updates[v], counterUpdate{stmt: stmt, g: gc})
+			}
+		}
+		for _, v := range order {
+			if addressed[v] {
+				continue
+			}
+			for _, u := range updates[v] {
+				why := "by every goroutine the loop starts"
+				if !inLoopWithin(u.g, v) {
+					if len(starters[v]) < 2 {
+						continue
+					}
+					why = "by " + strconv.Itoa(len(starters[v])) + " goroutines"
+				}
+				p.Report(u.stmt, v.Name()+" is updated by the goroutine started on line "+
+					strconv.Itoa(p.Fset.Position(u.g.Node().Pos()).Line)+" and shared "+why+", with no mutex "+
+					"or atomic; concurrent updates race and lose counts, so make it an atomic.Int64 and call "+
+					v.Name()+".Add, or use atomic.AddInt64")
+			}
+		}
+	}
+}
+
+// isIntegerVar reports whether v has an integer type.
+func isIntegerVar(v *types.Var) bool {
+	b, ok := v.Type().Underlying().(*types.Basic)
+	return ok && b.Info()&types.IsInteger != 0
+}
+
+// counterStmt returns the statement when the identifier at c is the
+// operand of ++ or -- or the left side of an assignment operator such as
+// +=, or nil.
+func counterStmt(c inspector.Cursor) ast.Stmt {
+	switch s := c.Parent().Node().(type) {
+	case *ast.IncDecStmt:
+		if s.X == c.Node() {
+			return s
+		}
+	case *ast.AssignStmt:
+		if s.Tok != token.ASSIGN && s.Tok != token.DEFINE && len(s.Lhs) == 1 && s.Lhs[0] == c.Node() {
+			return s
+		}
+	}
+	return nil
+}
+
+// inLoopWithin reports whether the go statement at g runs in a loop of its
+// function that lies within the scope of v, so that each iteration starts
+// another goroutine sharing v.
+func inLoopWithin(g inspector.Cursor, v *types.Var) bool {
+	for lc := range g.Enclosing((*ast.ForStmt)(nil), (*ast.RangeStmt)(nil), (*ast.FuncLit)(nil), (*ast.FuncDecl)(nil)) {
+		switch loop := lc.Node().(type) {
+		case *ast.ForStmt, *ast.RangeStmt:
+			if v.Pos() < loop.Pos() || v.Pos() >= loop.End() {
+				return true
+			}
+		default:
+			return false
+		}
+	}
+	return false
+}
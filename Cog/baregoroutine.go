@@ -0,0 +1,103 @@
+package cog
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+)
+
+// bareGoroutineRule reports a go statement that nothing can wait for or
+// stop.
+//
+//	func (s *Server) Handle(req Request) {
+//		go s.audit(req) // no WaitGroup, channel or context: leaks on shutdown
+//	}
+//
+// A goroutine started without a sync.WaitGroup, an errgroup.Group, a
+// channel or a context.Context cannot be waited for on shutdown or
+// cancelled, so it leaks or is cut off mid-work. The rule looks at the
+// values the go statement mentions: the function literal's body, the
+// receiver and the arguments. It is satisfied when one of them has one of
+// those types, or is a struct, or a pointer to one, with a field of one of
+// them. _test.go files are not checked.
+//
+// The rule is a heuristic, and short programs may start a goroutine and
+// forget it on purpose, so it is opt-in (-baregoroutine.enable).
+var bareGoroutineRule = &Rule{
+	ID:       "CogBareGoroutine",
+	Doc:      "report go statements with no WaitGroup, errgroup, channel or context governing them",
+	Run:      runBareGoroutine,
+	Category: CategoryConcurrency,
+
+	Severity: SeverityWarning,
+}
+
+// bareGoroutineEnable turns the rule on.
+var bareGoroutineEnable bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&bareGoroutineEnable, "baregoroutine.enable", false,
+		"report go statements with no WaitGroup, errgroup, channel or context governing them (heuristic)")
+}
+
+func runBareGoroutine(p *Pass) {
+	if !bareGoroutineEnable {
+		return
+	}
+	for c := range p.Inspector.Root().Preorder((*ast.GoStmt)(nil)) {
+		stmt, ok := c.Node().(*ast.GoStmt)
+		if !ok || strings.HasSuffix(p.Fset.Position(stmt.Pos()).Filename, "_test.go") {
+			continue
+		}
+		if governed(p, stmt.Call) {
+			continue
+		}
+		p.Report(stmt, "goroutine is started with no WaitGroup, errgroup, channel or context to wait for "+
+			"or stop it, so it leaks or is cut off on shutdown; track it with a sync.WaitGroup or pass it a context")
+	}
+}
+
+// governed reports whether an identifier in call has a type that can
+// govern a goroutine's lifetime.
+func governed(p *Pass, call *ast.CallExpr) bool {
+	found := false
+	ast.Inspect(call, func(n ast.Node) bool {
+		if found {
+			return false
+		}
+		if id, ok := n.(*ast.Ident); ok {
+			if obj := p.TypesInfo.ObjectOf(id); obj != nil {
+				if _, isVar := obj.(*types.Var); isVar && lifetimeType(obj.Type(), true) {
+					found = true
+				}
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// lifetimeType reports whether t is a channel, a context.Context, a
+// sync.WaitGroup or an errgroup.Group, or a pointer to one. With fields
+// set, a struct with a field of such a type counts too.
+func lifetimeType(t types.Type, fields bool) bool {
+	if ptr, ok := types.Unalias(t).(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	if _, ok := t.Underlying().(*types.Chan); ok {
+		return true
+	}
+	if isContextType(t) || isNamed(t, "sync", "WaitGroup") || isNamed(t, "golang.org/x/sync/errgroup", "Group") {
+		return true
+	}
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok || !fields {
+		return false
+	}
+	for i := range st.NumFields() {
+		if lifetimeType(st.Field(i).Type(), false) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,14 @@
+package cog
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestBareGoroutine(t *testing.T) {
+	saved := bareGoroutineEnable
+	t.Cleanup(func() { bareGoroutineEnable = saved })
+	bareGoroutineEnable = true
+	analysistest.Run(t, analysistest.TestData(), ruleAnalyzer(bareGoroutineRule), "baregoroutine")
+}
@@ -0,0 +1,88 @@
+package cog
+
+import (
+	"go/ast"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// bareReturnRule reports Mistake 3: a bare `return` in a function with
+// named results, whose returned values can only be found by tracing every
+// assignment above it.
+//
+//	func Calculate(x int) (result int, err error) {
+//		if x < 0 {
+//			err = fmt.Errorf("negative input")
+//			return // what does this return?
+//		}
+//
+// Every bare return is reported and can be expanded to list the named
+// results. Functions spanning at most -barereturn.maxlines lines are exempt,
+// since there the names document the results and the body is in view.
+var bareReturnRule = &Rule{
+	ID:       "CogBareReturn",
+	Doc:      "report bare returns in functions with named results",
+	Run:      runBareReturn,
+	Category: CategoryStyle,
+	Fixable:  true,
+}
+
+// bareReturnMaxLines exempts functions spanning at most that many lines.
+var bareReturnMaxLines int
+
+func init() {
+	Analyzer.Flags.IntVar(&bareReturnMaxLines, "barereturn.maxlines", 0,
+		"do not report functions spanning at most this many lines (0 reports every function)")
+}
+
+func runBareReturn(p *Pass) {
+	for c := range p.Inspector.Root().Preorder((*ast.ReturnStmt)(nil)) {
+		ret, ok := c.Node().(*ast.ReturnStmt)
+		if !ok || len(ret.Results) > 0 {
+			continue
+		}
+		ft, body := enclosingFunc(c)
+		if ft == nil || ft.Results == nil || len(ft.Results.List) == 0 || len(ft.Results.List[0].Names) == 0 {
+			continue
+		}
+		if bareReturnMaxLines > 0 &&
+			p.Fset.Position(body.Rbrace).Line-p.Fset.Position(ft.Pos()).Line+1 <= bareReturnMaxLines {
+			continue
+		}
+
+		names := make([]string, 0, len(ft.Results.List))
+		for _, field := range ft.Results.List {
+			for _, name := range field.Names {
+				names = append(names, name.Name)
+			}
+		}
+		list := strings.Join(names, ", ")
+		if hasBlankResult(ft) {
+			p.Report(ret, "bare return in a function with named results hides what is returned; list the results explicitly")
+			continue
+		}
+		msg := "bare return in a function with named results hides what is returned; write `return " + list + "`"
+		p.Report(ret, msg, analysis.SuggestedFix{
+			Message: "Return the named results explicitly",
+			TextEdits: []analysis.TextEdit{{
+				Pos:     ret.End(),
+				End:     ret.End(),
+				NewText: []byte(" " + list),
+			}},
+		})
+	}
+}
+
+// hasBlankResult reports whether a result of ft is named _, which a return
+// statement cannot list.
+func hasBlankResult(ft *ast.FuncType) bool {
+	for _, field := range ft.Results.List {
+		for _, name := range field.Names {
+			if name.Name == "_" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,18 @@
+package cog
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestBareReturn(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), ruleAnalyzer(bareReturnRule), "barereturn")
+}
+
+func TestBareReturnMaxLines(t *testing.T) {
+	saved := bareReturnMaxLines
+	t.Cleanup(func() { bareReturnMaxLines = saved })
+	bareReturnMaxLines = 4
+	analysistest.Run(t, analysistest.TestData(), ruleAnalyzer(bareReturnRule), "barereturnmaxlines")
+}
@@ -0,0 +1,184 @@
+package cog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// A baseline records the findings a project has accepted, so that adopting
+// Cog on existing code only fails on new ones. WriteBaseline creates it and
+// -baseline applies it:
+//
+//	{
+//	  "version": 1,
+//	  "findings": [
+//	    {
+//	      "rule": "CogTypedNil",
+//	      "file": "examples/before.go",
+//	      "fingerprint": "9c1e64a0b3d2f871"
+//	    }
+//	  ]
+//	}
+//
+// A finding is baselined when an entry has its rule, file and fingerprint.
+// Files are slash-separated paths relative to the module root. Entries with
+// the same key are counted: two identical findings in a file need two
+// entries, and a third is reported.
+type baseline struct {
+	Version  int             `json:"version"`
+	Findings []baselineEntry `json:"findings"`
+}
+
+// baselineEntry is one accepted finding. It is also the key findings are
+// matched by.
+type baselineEntry struct {
+	Rule        string `json:"rule"`
+	File        string `json:"file"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// baselineVersion is the version of the baseline format WriteBaseline
+// writes and -baseline reads.
+const baselineVersion = 1
+
+// WriteBaseline writes findings to w as a baseline file for -baseline,
+// sorted so that regenerating it only shows real changes in a diff.
+func WriteBaseline(w io.Writer, findings []Finding) error {
+	b := baseline{Version: baselineVersion, Findings: make([]baselineEntry, 0, len(findings))}
+	for _, f := range findings {
+		b.Findings = append(b.Findings, baselineEntry{
+			Rule:        f.Rule,
+			File:        baselineFile(f.Pos.Filename),
+			Fingerprint: f.Fingerprint,
+		})
+	}
+	slices.SortFunc(b.Findings, func(x, y baselineEntry) int {
+		return strings.Compare(x.File+"\x00"+x.Rule+"\x00"+x.Fingerprint, y.File+"\x00"+y.Rule+"\x00"+y.Fingerprint)
+	})
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(b); err != nil {
+		return fmt.Errorf("cog: baseline: %w", err)
+	}
+	return nil
+}
+
+// baselineFile returns filename relative to its module root, or as given
+// outside a module.
+func baselineFile(filename string) string {
+	if root, ok := moduleRoot(filepath.Dir(filename)); ok {
+		if abs, err := filepath.Abs(filename); err == nil {
+			if rel, err := filepath.Rel(root, abs); err == nil {
+				return filepath.ToSlash(rel)
+			}
+		}
+	}
+	return filepath.ToSlash(filename)
+}
+
+// digits matches the numbers dropped from messages before fingerprinting.
+var digits = regexp.MustCompile(`[0-9]+`)
+
+// fingerprint identifies a finding without its line number, so that edits
+// elsewhere in the file leave it unchanged. It hashes the message with
+// every number removed (messages cite lines, such as "on the path to line
+// 42") and the Go tokens of the source line the finding starts on. Moving
+// the line, re-indenting it, commenting it or renumbering the file keeps
+// the fingerprint; editing the code on the line or changing the message
+// does not.
+func fingerprint(p *Pass, rng analysis.Range, msg string) string {
+	text := digits.ReplaceAllString(msg, "") + "\x00"
+	pos := p.Fset.Position(rng.Pos())
+	if src, err := p.ReadFile(pos.Filename); err == nil {
+		if line, ok := sourceLine(src, pos.Offset, pos.Column); ok {
+			text += lineTokens(line)
+		}
+	}
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:8])
+}
+
+// lineTokens returns the tokens of a line of Go source separated by single
+// spaces, without comments.
+func lineTokens(line []byte) string {
+	fset := token.NewFileSet()
+	var s scanner.Scanner
+	s.Init(fset.AddFile("", -1, len(line)), line, nil, 0)
+	toks := make([]string, 0)
+	for {
+		_, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.SEMICOLON && lit == "\n" {
+			continue // inserted at the end of the line
+		}
+		if lit == "" {
+			lit = tok.String()
+		}
+		toks = append(toks, lit)
+	}
+	return strings.Join(toks, " ")
+}
+
+// baselinePath is the -baseline flag: a baseline file whose findings are
+// not reported.
+var baselinePath string
+
+func init() {
+	Analyzer.Flags.StringVar(&baselinePath, "baseline", "",
+		"baseline file (written by cog.WriteBaseline) listing findings not to report")
+}
+
+// readBaseline returns how many times each entry occurs in the baseline
+// file at path, or an empty map when path is "". It is read anew by every
+// Run and every package the analyzer driver analyzes, so that a rewritten
+// baseline takes effect on the next run.
+func readBaseline(path string) (map[baselineEntry]int, error) {
+	counts := make(map[baselineEntry]int)
+	if path == "" {
+		return counts, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cog: baseline: %w", err)
+	}
+	var b baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("cog: baseline %s: %w", path, err)
+	}
+	if b.Version != baselineVersion {
+		return nil, fmt.Errorf("cog: baseline %s: unsupported version %d", path, b.Version)
+	}
+	for _, e := range b.Findings {
+		counts[e]++
+	}
+	return counts, nil
+}
+
+// baselined reports whether a finding of the current rule with fingerprint
+// fp at filename is in the baseline, using up one matching entry.
+func (p *Pass) baselined(filename, fp string) bool {
+	if len(p.baseline) == 0 {
+		return false
+	}
+	key := baselineEntry{Rule: p.rule.ID, File: baselineFile(filename), Fingerprint: fp}
+	if p.baselineUsed[key] >= p.baseline[key] {
+		return false
+	}
+	p.baselineUsed[key]++
+	return true
+}
@@ -0,0 +1,109 @@
+package cog
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteBaseline(t *testing.T) {
+	findings := testFindings()
+	for i := range findings {
+		findings[i].Fingerprint = []string{"c3", "a1", "b2"}[i]
+	}
+	var out bytes.Buffer
+	if err := WriteBaseline(&out, findings); err != nil {
+		t.Fatalf("WriteBaseline: %v", err)
+	}
+	checkGolden(t, "baseline.golden", out.Bytes())
+}
+
+// baselineSrc is a file with one CogErrorWrap finding per function in
+// funcs, each the same, after prefix.
+func baselineSrc(prefix string, funcs ...string) string {
+	src := "package a\n\nimport \"os\"\n" + prefix
+	for _, name := range funcs {
+		src += "\nfunc " + name + "() error {\n\terr := os.Remove(\"scratch\")\n\treturn err\n}\n"
+	}
+	return src
+}
+
+// TestBaseline writes a baseline of a module's findings and checks that
+// it hides them after the file is edited around them, that it counts
+// identical findings, and that each Run reads the baseline anew.
+func TestBaseline(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module baselinetest\n\ngo 1.26\n",
+		"a/a.go": baselineSrc("", "Remove"),
+	})
+	t.Chdir(dir)
+	savedPath, savedCache := baselinePath, cacheDir
+	t.Cleanup(func() { baselinePath, cacheDir = savedPath, savedCache })
+	baselinePath, cacheDir = "", t.TempDir()
+	cfg := Config{Rules: map[string]Severity{}}
+	for _, r := range Rules {
+		if r.ID != errorWrapRule.ID {
+			cfg.Rules[r.ID] = SeverityOff
+		}
+	}
+	run := func() []Finding {
+		t.Helper()
+		findings, err := Run(t.Context(), []string{"./..."}, cfg)
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+		return findings
+	}
+	writeFile := func(name, src string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), []byte(src), 0o644); err != nil {
+			t.Fatalf("writing %s: %v", name, err)
+		}
+	}
+
+	before := run()
+	if len(before) != 1 {
+		t.Fatalf("Run found %d findings, want 1: %v", len(before), before)
+	}
+	var b bytes.Buffer
+	if err := WriteBaseline(&b, before); err != nil {
+		t.Fatalf("WriteBaseline: %v", err)
+	}
+	writeFile("baseline.json", b.String())
+	writeFile("empty.json", `{"version": 1, "findings": []}`)
+
+	// Lines added above the finding move it but keep its fingerprint.
+	writeFile("a/a.go", baselineSrc("\n// Remove deletes the scratch file.\n//\n// It is shifted down.\n", "Remove"))
+	after := run()
+	if len(after) != 1 {
+		t.Fatalf("Run after the edit found %d findings, want 1: %v", len(after), after)
+	}
+	if after[0].Pos.Line == before[0].Pos.Line {
+		t.Fatalf("the edit left the finding on line %d", after[0].Pos.Line)
+	}
+	if after[0].Fingerprint != before[0].Fingerprint {
+		t.Errorf("fingerprint changed from %s to %s when the finding moved", before[0].Fingerprint, after[0].Fingerprint)
+	}
+
+	baselinePath = "baseline.json"
+	if got := run(); len(got) != 0 {
+		t.Errorf("Run with the baseline found %v, want nothing", got)
+	}
+
+	// A second identical finding needs a second entry.
+	writeFile("a/a.go", baselineSrc("", "Remove", "RemoveAgain"))
+	if got := run(); len(got) != 1 || got[0].Fingerprint != before[0].Fingerprint {
+		t.Errorf("Run with the baseline and two identical findings found %v, want the second one", got)
+	}
+
+	// Another baseline takes effect on the next Run, cache or not.
+	baselinePath = "empty.json"
+	if got := run(); len(got) != 2 {
+		t.Errorf("Run with an empty baseline found %d findings, want 2: %v", len(got), got)
+	}
+	writeFile("empty.json", b.String())
+	if got := run(); len(got) != 1 {
+		t.Errorf("Run after rewriting the baseline found %d findings, want 1: %v", len(got), got)
+	}
+}
@@ -0,0 +1,248 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/cfg"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// bodyCloseRule reports *http.Response values whose Body is not closed on
+// every path out of the function.
+//
+//	resp, err := http.Get(url)
+//	if err != nil {
+//		return err // fine: resp is nil here
+//	}
+//	data, err := io.ReadAll(resp.Body)
+//	return err // leak: resp.Body is never closed
+//
+// `defer resp.Body.Close()`, a direct Close, or handing resp (or its Body)
+// to other code — returning it, storing it, passing it to a function —
+// satisfies the rule. Branches on which the call's error is non-nil or resp
+// is nil are skipped, since there is no body to close.
+var bodyCloseRule = &Rule{
+	ID:       "CogBodyClose",
+	Doc:      "report HTTP response bodies that are not closed on every path",
+	Run:      runBodyClose,
+	Category: CategoryPerformance,
+}
+
+func runBodyClose(p *Pass) {
+	cfgs := make(map[*ast.BlockStmt]*cfg.CFG)
+	for c := range p.Inspector.Root().Preorder((*ast.AssignStmt)(nil), (*ast.ExprStmt)(nil)) {
+		acq, ok := acquisition(p, c, isHTTPResponse)
+		if !ok {
+			continue
+		}
+		what := types.ExprString(acq.call.Fun)
+		if acq.resource == nil {
+			p.Report(acq.call, "response from "+what+" is discarded, so its body is never closed")
+			continue
+		}
+
+		_, body := enclosingFunc(c)
+		if body == nil {
+			continue
+		}
+		g, ok := cfgs[body]
+		if !ok {
+			g = funcCFG(p, body)
+			cfgs[body] = g
+		}
+		exit, leaked := findLeak(p, g, body, acq.stmt, leakCheck{
+			released: func(n ast.Node) bool { return releasesBody(p, n, acq.resource) },
+			unheld:   acq.unheld(p),
+		})
+		if !leaked {
+			continue
+		}
+		name := acq.resource.Name()
+		p.Report(acq.call, "body of response from "+what+" is not closed on the path to line "+
+			strconv.Itoa(p.Fset.Position(exit).Line)+"; add `defer "+name+".Body.Close()` after checking the error")
+	}
+}
+
+// isHTTPResponse reports whether t is *net/http.Response.
+func isHTTPResponse(t types.Type) bool {
+	return isNamedPointer(t, "net/http", "Response")
+}
+
+// isNamedPointer reports whether t is a pointer to the named type pkg.name.
+func isNamedPointer(t types.Type, pkg, name string) bool {
+	ptr, ok := types.Unalias(t).(*types.Pointer)
+	if !ok {
+		return false
+	}
+	return isNamed(ptr.Elem(), pkg, name)
+}
+
+// isNamed reports whether t is the named type pkg.name.
+func isNamed(t types.Type, pkg, name string) bool {
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == pkg && obj.Name() == name
+}
+
+// acquired describes a statement that obtains a resource from a call.
+type acquired struct {
+	stmt     ast.Stmt
+	call     *ast.CallExpr
+	resource *types.Var // nil when the result is discarded
+	err      *types.Var // the error returned alongside, if bound
+}
+
+// acquisition recognizes `x, err := f()` (or a bare `f()`) at c where one of
+// f's results satisfies match.
+func acquisition(p *Pass, c inspector.Cursor, match func(types.Type) bool) (acquired, bool) {
+	var lhs []ast.Expr
+	var rhs ast.Expr
+	switch n := c.Node().(type) {
+	case *ast.AssignStmt:
+		if len(n.Rhs) != 1 || (n.Tok != token.DEFINE && n.Tok != token.ASSIGN) {
+			return acquired{}, false
+		}
+		lhs, rhs = n.Lhs, n.Rhs[0]
+	case *ast.ExprStmt:
+		rhs = n.X
+	default:
+		return acquired{}, false
+	}
+	call, ok := ast.Unparen(rhs).(*ast.CallExpr)
+	if !ok {
+		return acquired{}, false
+	}
+	results := resultTypes(p.TypesInfo, call)
+	stmt, _ := c.Node().(ast.Stmt) // FALLBACK: both cases above are statements
+	acq := acquired{stmt: stmt, call: call}
+	found := false
+	for i, t := range results {
+		if match(t) && !found {
+			found = true
+			if i < len(lhs) {
+				acq.resource = localVar(p, lhs[i])
+			}
+		}
+		if isErrorType(t) && i < len(lhs) {
+			acq.err = localVar(p, lhs[i])
+		}
+	}
+	if !found || (len(lhs) > 0 && len(lhs) != len(results)) {
+		return acquired{}, false
+	}
+	if acq.resource == nil && len(lhs) > 0 {
+		// Assigned to something other than a local variable, such as a
+		// struct field: ownership moved elsewhere.
+		for i, t := range results {
+			if match(t) && !isBlank(lhs[i]) {
+				return acquired{}, false
+			}
+		}
+	}
+	return acq, true
+}
+
+// unheld returns a leakCheck.unheld that skips the branch on which the
+// acquiring call failed or the resource is nil.
+func (a acquired) unheld(p *Pass) func(ast.Expr) int {
+	return func(cond ast.Expr) int {
+		if i := nilCheckBranch(p, cond, a.err, true); i >= 0 {
+			return i
+		}
+		return nilCheckBranch(p, cond, a.resource, false)
+	}
+}
+
+// localVar returns the local variable e names, or nil.
+func localVar(p *Pass, e ast.Expr) *types.Var {
+	id, ok := e.(*ast.Ident)
+	if !ok || id.Name == "_" {
+		return nil
+	}
+	v, ok := p.TypesInfo.ObjectOf(id).(*types.Var)
+	if !ok || v.Parent() == p.Pkg.Scope() {
+		return nil
+	}
+	return v
+}
+
+// releasesBody reports whether n closes resp.Body or hands resp off.
+func releasesBody(p *Pass, n ast.Node, resp *types.Var) bool {
+	cur, ok := p.Inspector.Root().FindNode(n)
+	if !ok {
+		return false
+	}
+	for ic := range cur.Preorder((*ast.Ident)(nil)) {
+		id, ok := ic.Node().(*ast.Ident)
+		if !ok || p.TypesInfo.Uses[id] != resp {
+			continue
+		}
+		parent := ic.Parent()
+		switch pn := parent.Node().(type) {
+		case *ast.SelectorExpr:
+			if pn.Sel.Name == "Body" && releasesValue(p, parent) {
+				return true
+			}
+		case *ast.BinaryExpr:
+			// resp == nil and friends neither close nor escape.
+		case *ast.AssignStmt:
+			if !isLHS(pn, id) {
+				return true
+			}
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// releasesValue reports whether the expression at c — a resource or a
+// field holding one — is closed or handed off: `x.Close()`,
+// `closeQuietly(x)`, returned, stored, or assigned to another variable.
+func releasesValue(p *Pass, c inspector.Cursor) bool {
+	parent := c.Parent()
+	switch pn := parent.Node().(type) {
+	case *ast.SelectorExpr:
+		call, ok := parent.Parent().Node().(*ast.CallExpr)
+		return ok && call.Fun == pn && pn.Sel.Name == "Close"
+	case *ast.CallExpr:
+		if pn.Fun == c.Node() {
+			return false
+		}
+		return closesArgs(p.TypesInfo, pn)
+	case *ast.ReturnStmt, *ast.CompositeLit, *ast.KeyValueExpr, *ast.UnaryExpr:
+		return true
+	case *ast.AssignStmt:
+		return !isLHS(pn, c.Node())
+	}
+	return false
+}
+
+// closesArgs reports whether the function called mentions close in its
+// name, like closeBody or mustClose, and so presumably closes its arguments.
+// Only the function's own name counts, not its package or receiver.
+func closesArgs(info *types.Info, call *ast.CallExpr) bool {
+	name := types.ExprString(call.Fun)
+	if obj := typeutil.Callee(info, call); obj != nil {
+		name = obj.Name()
+	}
+	return strings.Contains(strings.ToLower(name), "close")
+}
+
+// isLHS reports whether n is one of the assignment's left-hand operands.
+func isLHS(assign *ast.AssignStmt, n ast.Node) bool {
+	for _, l := range assign.Lhs {
+		if l == n {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,230 @@
+package cog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// A result cache lets Run skip packages whose findings cannot have changed.
+// It is a directory, set by -cache-dir, holding one file per analyzed
+// package:
+//
+//	<cache-dir>/<key[:2]>/<key>.json
+//
+// where key is the hex SHA-256 of everything the findings depend on:
+//
+//   - the cache format version and the Cog build (its module version, or
+//     the hash of the running executable for development builds);
+//   - the ID and severity of every rule, in order;
+//   - the value of every flag, which covers config settings, and the
+//     content of the -baseline file;
+//   - the package path and the names and contents of its Go files, and the
+//     same, recursively, for every package it imports.
+//
+// Each file holds a cacheEntry:
+//
+//	{"version": 1, "findings": [ ...Finding... ]}
+//
+// A change to any input gives a new key, so entries are never updated in
+// place; stale ones are simply not read again, and the directory can be
+// deleted at any time.
+type cacheEntry struct {
+	Version  int       `json:"version"`
+	Findings []Finding `json:"findings"`
+}
+
+// cacheVersion is the version of the cache entry format and key
+// derivation. Changing either requires bumping it.
+const cacheVersion = 1
+
+// cacheDir is the -cache-dir flag.
+var cacheDir string
+
+func init() {
+	Analyzer.Flags.StringVar(&cacheDir, "cache-dir", "",
+		"directory caching the findings of unchanged packages between cog.Run calls; empty disables the cache")
+}
+
+// uncachedFlags are the flags that do not affect findings.
+var uncachedFlags = []string{"cache-dir", "j"}
+
+// A resultCache reads and writes the cache entries of one Run.
+type resultCache struct {
+	dir  string
+	base []byte // the part of every key shared by all packages
+
+	digests map[*packages.Package][]byte
+}
+
+// newResultCache returns the cache in dir for runs at the severities of
+// cfg with the current flags.
+func newResultCache(dir string, cfg *Config) (*resultCache, error) {
+	build, err := cogBuild()
+	if err != nil {
+		return nil, fmt.Errorf("identifying the Cog build: %w", err)
+	}
+	var b strings.Builder
+	writeField(&b, "cog cache", strconv.Itoa(cacheVersion), build)
+	for _, rule := range Rules {
+		writeField(&b, "rule", rule.ID, string(cfg.Severity(rule.ID)))
+	}
+	Analyzer.Flags.VisitAll(func(f *flag.Flag) {
+		if !slices.Contains(uncachedFlags, f.Name) {
+			writeField(&b, "flag", f.Name, f.Value.String())
+		}
+	})
+	if baselinePath != "" {
+		data, err := os.ReadFile(baselinePath)
+		if err != nil {
+			return nil, fmt.Errorf("hashing the baseline: %w", err)
+		}
+		sum := sha256.Sum256(data)
+		writeField(&b, "baseline", string(sum[:]))
+	}
+	base := sha256.Sum256([]byte(b.String()))
+	return &resultCache{dir: dir, base: base[:], digests: make(map[*packages.Package][]byte)}, nil
+}
+
+// get returns the cached findings of pkg. A missing or corrupt entry is a
+// miss.
+func (c *resultCache) get(pkg *packages.Package) ([]Finding, bool, error) {
+	path, err := c.path(pkg)
+	if err != nil {
+		return nil, false, fmt.Errorf("entry key: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("reading the entry: %w", err)
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Version != cacheVersion {
+		return nil, false, nil // a torn or foreign file: analyze again and overwrite it
+	}
+	return entry.Findings, true, nil
+}
+
+// put stores the findings of pkg. The entry is written to a temporary file
+// and renamed into place, so that concurrent runs never read a partial
+// entry.
+func (c *resultCache) put(pkg *packages.Package, findings []Finding) error {
+	path, err := c.path(pkg)
+	if err != nil {
+		return fmt.Errorf("storing %s: entry key: %w", pkg.PkgPath, err)
+	}
+	data, err := json.Marshal(cacheEntry{Version: cacheVersion, Findings: findings})
+	if err != nil {
+		return fmt.Errorf("storing %s: %w", pkg.PkgPath, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("storing %s: %w", pkg.PkgPath, err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "tmp-*")
+	if err != nil {
+		return fmt.Errorf("storing %s: %w", pkg.PkgPath, err)
+	}
+	_, werr := tmp.Write(data)
+	cerr := tmp.Close()
+	if err := errors.Join(werr, cerr); err != nil {
+		return fmt.Errorf("storing %s: %w", pkg.PkgPath, errors.Join(err, os.Remove(tmp.Name())))
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("storing %s: %w", pkg.PkgPath, errors.Join(err, os.Remove(tmp.Name())))
+	}
+	return nil
+}
+
+// path returns the file of the entry for pkg.
+func (c *resultCache) path(pkg *packages.Package) (string, error) {
+	digest, err := c.digest(pkg)
+	if err != nil {
+		return "", fmt.Errorf("hashing sources: %w", err)
+	}
+	var b strings.Builder
+	writeField(&b, string(c.base), string(digest))
+	sum := sha256.Sum256([]byte(b.String()))
+	key := hex.EncodeToString(sum[:])
+	return filepath.Join(c.dir, key[:2], key+".json"), nil
+}
+
+// digest returns the hash of the sources of pkg and, through their
+// digests, of the packages it imports, computing each once.
+func (c *resultCache) digest(pkg *packages.Package) ([]byte, error) {
+	if d, ok := c.digests[pkg]; ok {
+		return d, nil
+	}
+	var b strings.Builder
+	writeField(&b, "package", pkg.ID, pkg.PkgPath)
+	for _, name := range pkg.CompiledGoFiles {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", pkg.PkgPath, err)
+		}
+		sum := sha256.Sum256(data)
+		writeField(&b, "file", name, string(sum[:]))
+	}
+	paths := make([]string, 0, len(pkg.Imports))
+	for path := range pkg.Imports {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	for _, path := range paths {
+		d, err := c.digest(pkg.Imports[path])
+		if err != nil {
+			return nil, fmt.Errorf("%s imports %w", pkg.PkgPath, err)
+		}
+		writeField(&b, "import", path, string(d))
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	c.digests[pkg] = sum[:]
+	return sum[:], nil
+}
+
+// writeField appends fields to the key material in b, each preceded by its
+// length so that distinct field lists never hash alike.
+func writeField(b *strings.Builder, fields ...string) {
+	for _, f := range fields {
+		b.WriteString(strconv.Itoa(len(f)) + ":" + f)
+	}
+}
+
+// cogBuild identifies the Cog code of this process, loaded once.
+var cogBuild = sync.OnceValues(func() (string, error) {
+	path := reflect.TypeFor[Finding]().PkgPath()
+	if info, ok := debug.ReadBuildInfo(); ok {
+		mods := append([]*debug.Module{&info.Main}, info.Deps...)
+		for _, m := range mods {
+			if m.Path == path && m.Replace == nil && m.Version != "" && m.Version != "(devel)" {
+				return m.Version + " " + m.Sum, nil
+			}
+		}
+	}
+	// A development build: only the binary itself tells versions apart.
+	exe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("locating the executable: %w", err)
+	}
+	data, err := os.ReadFile(exe)
+	if err != nil {
+		return "", fmt.Errorf("hashing the executable: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+})
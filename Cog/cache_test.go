@@ -0,0 +1,117 @@
+package cog
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// cachedMessage marks the findings markCacheEntries rewrites, so that a
+// run reading them back shows which packages came from the cache.
+const cachedMessage = "read from the cache"
+
+// markCacheEntries sets the message of every finding in the cache entries
+// under dir to cachedMessage.
+func markCacheEntries(t *testing.T, dir string) {
+	t.Helper()
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".json") {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var entry cacheEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return err
+		}
+		for i := range entry.Findings {
+			entry.Findings[i].Message = cachedMessage
+		}
+		if data, err = json.Marshal(entry); err != nil {
+			return err
+		}
+		return os.WriteFile(path, data, 0o644)
+	})
+	if err != nil {
+		t.Fatalf("marking the cache entries: %v", err)
+	}
+}
+
+// cachedPackages returns the directories, sorted, of the findings read
+// from marked cache entries.
+func cachedPackages(findings []Finding) []string {
+	pkgs := make([]string, 0)
+	for _, f := range findings {
+		pkg := filepath.Base(filepath.Dir(f.Pos.Filename))
+		if f.Message == cachedMessage && !slices.Contains(pkgs, pkg) {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	slices.Sort(pkgs)
+	return pkgs
+}
+
+func TestRunCacheInvalidation(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module cachetest\n\ngo 1.26\n",
+		"a/a.go": "package a\n\nimport \"cachetest/b\"\n\nfunc Load() error {\n\terr := b.Load()\n\treturn err\n}\n",
+		"b/b.go": "package b\n\nimport \"os\"\n\nfunc Load() error {\n\terr := os.Remove(\"scratch\")\n\treturn err\n}\n",
+	})
+	t.Chdir(dir)
+	defer func(dir string) { cacheDir = dir }(cacheDir)
+	cacheDir = t.TempDir()
+
+	run := func(cfg Config) []string {
+		t.Helper()
+		findings, err := Run(t.Context(), []string{"./..."}, cfg)
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+		if len(findings) == 0 {
+			t.Fatal("Run found nothing")
+		}
+		return cachedPackages(findings)
+	}
+	check := func(step string, got []string, want ...string) {
+		t.Helper()
+		if !slices.Equal(got, want) {
+			t.Errorf("%s: packages read from the cache = %q, want %q", step, got, want)
+		}
+	}
+
+	check("first run", run(Config{}))
+	markCacheEntries(t, cacheDir)
+	check("unchanged", run(Config{}), "a", "b")
+
+	// A changed dependency invalidates its importers too.
+	f, err := os.OpenFile(filepath.Join(dir, "b", "b.go"), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString("\n// Changed.\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	check("dependency changed", run(Config{}))
+	markCacheEntries(t, cacheDir)
+	check("dependency unchanged since", run(Config{}), "a", "b")
+
+	check("rule severity changed", run(Config{Rules: map[string]Severity{"CogBareReturn": SeverityOff}}))
+
+	defer func(v regexpFlag) { errorWrapExempt = v }(errorWrapExempt)
+	check("setting changed", run(Config{Settings: map[string]any{"errorwrap.exempt": "^Save$"}}))
+	errorWrapExempt = regexpFlag{}
+	check("setting restored", run(Config{}), "a", "b")
+
+	defer func(build func() (string, error)) { cogBuild = build }(cogBuild)
+	cogBuild = func() (string, error) { return "v0.0.0-next", nil }
+	check("Cog version changed", run(Config{}))
+}
@@ -0,0 +1,112 @@
+package cog
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// A Category groups related rules in the catalog.
+type Category string
+
+// The categories of the built-in rules.
+const (
+	CategoryConcurrency Category = "concurrency"
+	CategoryErrors      Category = "errors"
+	CategoryTypes       Category = "types"
+	CategoryPerformance Category = "performance"
+	CategorySecurity    Category = "security"
+	CategoryStyle       Category = "style"
+)
+
+var (
+	// onlyCategories restricts a run to the rules of these categories.
+	onlyCategories categoryFlag
+
+	// excludeCategories skips the rules of these categories.
+	excludeCategories categoryFlag
+)
+
+func init() {
+	Analyzer.Flags.Var(&onlyCategories, "only",
+		"comma-separated rule categories to run, skipping the others, e.g. concurrency,security")
+	Analyzer.Flags.Var(&excludeCategories, "exclude",
+		"comma-separated rule categories to skip, e.g. style")
+}
+
+// categorySelected reports whether -only and -exclude let the rules of
+// category c run.
+func categorySelected(c Category) bool {
+	if len(onlyCategories) > 0 && !slices.Contains(onlyCategories, c) {
+		return false
+	}
+	return !slices.Contains(excludeCategories, c)
+}
+
+// RuleInfo describes a rule for tools that list or document rules, such as
+// dashboards. Its JSON form is what `cog rules --json` prints.
+type RuleInfo struct {
+	ID              string   `json:"id"`
+	Name            string   `json:"name"`
+	Summary         string   `json:"summary"`
+	Category        Category `json:"category"`
+	DefaultSeverity Severity `json:"defaultSeverity"`
+	FixAvailable    bool     `json:"fixAvailable"`
+	DocURL          string   `json:"docURL"`
+}
+
+// Catalog describes every rule in Rules, in order, followed by the
+// CogUnusedIgnore pseudo-rule. It is built from the registered rules on
+// each call, so rules added with Register are included.
+func Catalog() []RuleInfo {
+	all := append(slices.Clone(Rules), unusedIgnoreRule)
+	infos := make([]RuleInfo, 0, len(all))
+	for _, r := range all {
+		infos = append(infos, RuleInfo{
+			ID:              r.ID,
+			Name:            ruleName(r.ID),
+			Summary:         r.Doc,
+			Category:        r.Category,
+			DefaultSeverity: r.defaultSeverity(),
+			FixAvailable:    r.Fixable,
+			DocURL:          Analyzer.URL + "#the-analyzer",
+		})
+	}
+	return infos
+}
+
+// ruleName spells a rule ID as words: "CogNilSliceJSON" becomes
+// "Nil slice JSON".
+func ruleName(id string) string {
+	words := identWords(strings.TrimPrefix(id, "Cog"))
+	for i, word := range words {
+		if strings.ToUpper(word) != word {
+			words[i] = strings.ToLower(word)
+		}
+	}
+	name := strings.Join(words, " ")
+	if name == "" {
+		return id
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// identWords splits an identifier into its words at underscores and case
+// changes: "newJSONToken_v2" gives "new", "JSON", "Token", "v2". Runs of
+// capitals are kept as one word.
+func identWords(ident string) []string {
+	words := make([]string, 0, 4)
+	for _, part := range strings.Split(ident, "_") {
+		rs := []rune(part)
+		start := 0
+		for i := 1; i <= len(rs); i++ {
+			boundary := i == len(rs) ||
+				unicode.IsUpper(rs[i]) && (unicode.IsLower(rs[i-1]) || i+1 < len(rs) && unicode.IsLower(rs[i+1]))
+			if boundary {
+				words = append(words, string(rs[start:i]))
+				start = i
+			}
+		}
+	}
+	return words
+}
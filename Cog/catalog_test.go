@@ -0,0 +1,153 @@
+package cog
+
+import (
+	"slices"
+	"testing"
+)
+
+var categories = []Category{
+	CategoryConcurrency, CategoryErrors, CategoryTypes, CategoryPerformance, CategorySecurity, CategoryStyle,
+}
+
+func TestCatalog(t *testing.T) {
+	infos := Catalog()
+	if len(infos) != len(Rules)+1 {
+		t.Fatalf("Catalog has %d rules, want the %d registered and CogUnusedIgnore", len(infos), len(Rules))
+	}
+	seen := make(map[string]bool, len(infos))
+	for i, info := range infos {
+		switch {
+		case info.ID == "" || info.Name == "" || info.Summary == "" || info.DocURL == "":
+			t.Errorf("rule %d has empty metadata: %+v", i, info)
+		case seen[info.ID]:
+			t.Errorf("rule %s is listed twice", info.ID)
+		case !slices.Contains(categories, info.Category):
+			t.Errorf("rule %s has category %q, want one of %v", info.ID, info.Category, categories)
+		case !slices.Contains([]Severity{SeverityError, SeverityWarning, SeverityInfo, SeverityOff}, info.DefaultSeverity):
+			t.Errorf("rule %s has default severity %q", info.ID, info.DefaultSeverity)
+		}
+		seen[info.ID] = true
+		if i < len(Rules) && (info.ID != Rules[i].ID || info.FixAvailable != Rules[i].Fixable) {
+			t.Errorf("Catalog()[%d] = %+v, want rule %s", i, info, Rules[i].ID)
+		}
+	}
+	if last := infos[len(infos)-1]; last.ID != unusedIgnoreRule.ID {
+		t.Errorf("last rule is %s, want %s", last.ID, unusedIgnoreRule.ID)
+	}
+}
+
+func TestCatalogRegistered(t *testing.T) {
+	defer func(rules []*Rule) { Rules = rules }(slices.Clone(Rules))
+	rule := &Rule{ID: "CogTestOnly", Doc: "a rule registered by a test", Run: func(*Pass) {}, Category: CategoryStyle}
+	if err := Register(rule); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	i := slices.IndexFunc(Catalog(), func(info RuleInfo) bool { return info.ID == rule.ID })
+	if i < 0 {
+		t.Fatal("Catalog does not list a registered rule")
+	}
+	want := RuleInfo{
+		ID:              "CogTestOnly",
+		Name:            "Test only",
+		Summary:         "a rule registered by a test",
+		Category:        CategoryStyle,
+		DefaultSeverity: SeverityError,
+		DocURL:          Analyzer.URL + "#the-analyzer",
+	}
+	if got := Catalog()[i]; got != want {
+		t.Errorf("Catalog lists %+v, want %+v", got, want)
+	}
+}
+
+func TestRuleName(t *testing.T) {
+	for id, want := range map[string]string{
+		"CogNilSliceJSON":   "Nil slice JSON",
+		"CogTypedNil":       "Typed nil",
+		"CogHTTPNoTimeouts": "HTTP no timeouts",
+		"CogJSONMapAny":     "JSON map any",
+		"Cog":               "Cog",
+	} {
+		if got := ruleName(id); got != want {
+			t.Errorf("ruleName(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
+
+// selectedRules returns the IDs of the rules -only and -exclude let run.
+func selectedRules() []string {
+	ids := make([]string, 0, len(Rules))
+	for _, r := range Rules {
+		if categorySelected(r.Category) {
+			ids = append(ids, r.ID)
+		}
+	}
+	return ids
+}
+
+// rulesIn returns the IDs of the rules whose category satisfies in.
+func rulesIn(in func(Category) bool) []string {
+	ids := make([]string, 0, len(Rules))
+	for _, r := range Rules {
+		if in(r.Category) {
+			ids = append(ids, r.ID)
+		}
+	}
+	return ids
+}
+
+func TestCategoryFilter(t *testing.T) {
+	defer func(only, exclude categoryFlag) {
+		onlyCategories, excludeCategories = only, exclude
+	}(onlyCategories, excludeCategories)
+	tests := []struct {
+		only, exclude string
+		want          func(Category) bool
+	}{
+		{"", "", func(Category) bool { return true }},
+		{"concurrency,security", "", func(c Category) bool { return c == CategoryConcurrency || c == CategorySecurity }},
+		{"", "style", func(c Category) bool { return c != CategoryStyle }},
+		{" errors , style", "style", func(c Category) bool { return c == CategoryErrors }},
+	}
+	for _, tt := range tests {
+		if err := Analyzer.Flags.Set("only", tt.only); err != nil {
+			t.Fatalf("-only=%q: %v", tt.only, err)
+		}
+		if err := Analyzer.Flags.Set("exclude", tt.exclude); err != nil {
+			t.Fatalf("-exclude=%q: %v", tt.exclude, err)
+		}
+		got, want := selectedRules(), rulesIn(tt.want)
+		if len(want) == 0 || !slices.Equal(got, want) {
+			t.Errorf("-only=%q -exclude=%q selects %v, want %v", tt.only, tt.exclude, got, want)
+		}
+	}
+	if err := Analyzer.Flags.Set("only", "concurrency,nosuchcategory"); err == nil {
+		t.Error("-only with an unknown category succeeded, want an error")
+	}
+}
+
+func TestCategoryFilterRun(t *testing.T) {
+	defer func(only categoryFlag) { onlyCategories = only }(onlyCategories)
+	for _, tt := range []struct {
+		only string
+		want []string
+	}{
+		{"errors", []string{"CogIgnoredError"}},
+		{"style", []string{"CogBareReturn"}},
+		{"concurrency", []string{}},
+	} {
+		findings, err := Run(t.Context(), []string{"./testdata/run"}, Config{
+			Rules:    map[string]Severity{"CogBareReturn": SeverityWarning},
+			Settings: map[string]any{"only": tt.only},
+		})
+		if err != nil {
+			t.Fatalf("Run with -only=%s: %v", tt.only, err)
+		}
+		got := make([]string, 0, len(findings))
+		for _, f := range findings {
+			got = append(got, f.Rule)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Run with -only=%s found %v, want %v", tt.only, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/cfg"
+)
+
+// channelCloseRule reports a channel closed by a goroutine that only
+// receives from it, and a channel that may be closed twice.
+//
+//	jobs := make(chan Job)
+//	go func() {
+//		for job := range jobs {
+//			run(job)
+//		}
+//		close(jobs) // the receiver closes: a later send panics
+//	}()
+//
+// Only the sender closes a channel: a send on a closed channel panics, and
+// so does a second close, while receivers learn that a channel is closed
+// from the receive itself. The rule follows channel variables declared in
+// a function. A close is reported when it runs in a `go` function literal
+// that receives from the channel and never sends on it, while the function
+// or another goroutine sends. A second close is reported when the control
+// flow of one function can reach it after another close of the same
+// channel, deferred or not, or reach the same close again through a loop.
+// To stay conservative, channels that are reassigned or passed to other
+// code are not followed, and closes in different goroutines are never
+// paired.
+var channelCloseRule = &Rule{
+	ID:       "CogChannelClose",
+	Doc:      "report channels closed by a receiver or closed twice",
+	Run:      runChannelClose,
+	Category: CategoryConcurrency,
+}
+
+// A chanOpKind is what a use of a channel variable does.
+type chanOpKind int
+
+const (
+	chanRecv  chanOpKind = iota // a receive, a range or a receive case
+	chanSend                    // a send statement or case
+	chanClose                   // close(ch), deferred or not
+	chanQuery                   // len(ch) or cap(ch)
+)
+
+// A chanOp is one use of a channel variable.
+type chanOp struct {
+	c    inspector.Cursor // the identifier
+	kind chanOpKind
+	g    *ast.GoStmt // the goroutine making it; nil for the declaring function
+}
+
+func runChannelClose(p *Pass) {
+	for c := range p.Inspector.Root().Preorder((*ast.FuncDecl)(nil)) {
+		decl, ok := c.Node().(*ast.FuncDecl)
+		if !ok || decl.Body == nil {
+			continue
+		}
+		ops, order := chanOps(p, c)
+		for _, ch := range order {
+			if !receiverClose(p, ch, ops[ch]) {
+				doubleClose(p, ch, ops[ch])
+			}
+		}
+	}
+}
+
+// chanOps returns the uses of the channel variables declared in the
+// function at c, in order of first use, leaving out channels that are
+// reassigned or escape.
+func chanOps(p *Pass, c inspector.Cursor) (map[*types.Var][]chanOp, []*types.Var) {
+	ops := make(map[*types.Var][]chanOp)
+	escaped := make(map[*types.Var]bool)
+	order := make([]*types.Var, 0)
+	for ic := range c.Preorder((*ast.Ident)(nil)) {
+		id, ok := ic.Node().(*ast.Ident)
+		if !ok {
+			continue
+		}
+		v, ok := p.TypesInfo.Uses[id].(*types.Var)
+		if !ok || v.Pos() < c.Node().Pos() || v.Pos() >= c.Node().End() {
+			continue // not declared in this function
+		}
+		if _, ok := v.Type().Underlying().(*types.Chan); !ok || escaped[v] {
+			continue
+		}
+		kind, ok := chanUse(p, ic)
+		if !ok {
+			escaped[v] = true
+			continue
+		}
+		if _, seen := ops[v]; !seen {
+			order = append(order, v)
+		}
+		ops[v] = append(ops[v], chanOp{c: ic, kind: kind, g: startingGo(ic, v)})
+	}
+	kept := make([]*types.Var, 0, len(order))
+	for _, v := range order {
+		if !escaped[v] {
+			kept = append(kept, v)
+		}
+	}
+	return ops, kept
+}
+
+// chanUse classifies the use of a channel at c. It returns false for any
+// other use, through which the channel may escape or change.
+func chanUse(p *Pass, c inspector.Cursor) (chanOpKind, bool) {
+	id := c.Node()
+	switch pn := c.Parent().Node().(type) {
+	case *ast.SendStmt:
+		return chanSend, pn.Chan == id
+	case *ast.UnaryExpr:
+		return chanRecv, pn.Op == token.ARROW
+	case *ast.RangeStmt:
+		return chanRecv, pn.X == id
+	case *ast.CallExpr:
+		switch {
+		case len(pn.Args) != 1:
+		case isBuiltin(p, pn.Fun, "close"):
+			return chanClose, true
+		case isBuiltin(p, pn.Fun, "len"), isBuiltin(p, pn.Fun, "cap"):
+			return chanQuery, true
+		}
+	}
+	return 0, false
+}
+
+// receiverClose reports, and returns true for, closes of ch in a goroutine
+// that receives from ch but never sends on it, while other code sends.
+func receiverClose(p *Pass, ch *types.Var, ops []chanOp) bool {
+	reported := false
+	for _, cl := range ops {
+		if cl.kind != chanClose || cl.g == nil {
+			continue
+		}
+		recv := false
+		var send *chanOp
+		for i, op := range ops {
+			switch {
+			case op.g == cl.g && op.kind == chanRecv:
+				recv = true
+			case op.g != cl.g && op.kind == chanSend && send == nil:
+				send = &ops[i]
+			}
+		}
+		if !recv || send == nil || sendsIn(ops, cl.g) {
+			continue
+		}
+		name := ch.Name()
+		p.Report(cl.c.Parent().Node(), "close("+name+") runs in a goroutine that only receives from "+name+
+			", while "+name+" is sent on at line "+strconv.Itoa(p.Fset.Position(send.c.Node().Pos()).Line)+
+			"; only the sender should close a channel, since a send on a closed channel panics, so close "+
+			name+" where the sends end and let receivers stop when it is drained")
+		reported = true
+	}
+	return reported
+}
+
+// sendsIn reports whether ops include a send by the goroutine g.
+func sendsIn(ops []chanOp, g *ast.GoStmt) bool {
+	for _, op := range ops {
+		if op.g == g && op.kind == chanSend {
+			return true
+		}
+	}
+	return false
+}
+
+// doubleClose reports a close of ch that the control flow of its function
+// can reach after another close of ch, or after itself.
+func doubleClose(p *Pass, ch *types.Var, ops []chanOp) {
+	closes := make([]chanOp, 0, 2)
+	for _, op := range ops {
+		if op.kind == chanClose && closeStmt(op.c) != nil {
+			closes = append(closes, op)
+		}
+	}
+	graphs := make(map[*ast.BlockStmt]*cfg.CFG)
+	for _, second := range closes {
+		for _, first := range closes {
+			_, body := enclosingFunc(first.c)
+			if _, b2 := enclosingFunc(second.c); body == nil || body != b2 {
+				continue
+			}
+			g, ok := graphs[body]
+			if !ok {
+				g = funcCFG(p, body)
+				graphs[body] = g
+			}
+			if !stmtReaches(g, closeStmt(first.c), closeStmt(second.c)) {
+				continue
+			}
+			name := ch.Name()
+			where := "the close on line " + strconv.Itoa(p.Fset.Position(first.c.Node().Pos()).Line)
+			if first.c == second.c {
+				where = "itself, through a loop"
+			}
+			p.Report(closeStmt(second.c), "channel "+name+" may be closed twice: this close can run after "+
+				where+", and closing a closed channel panics; close "+name+" exactly once, in the goroutine "+
+				"that sends on it, or guard the close with a sync.Once")
+			return
+		}
+	}
+}
+
+// closeStmt returns the expression or defer statement of the close call
+// whose argument is at c, or nil when the call is part of an expression.
+func closeStmt(c inspector.Cursor) ast.Stmt {
+	switch s := c.Parent().Parent().Node().(type) {
+	case *ast.ExprStmt:
+		return s
+	case *ast.DeferStmt:
+		return s
+	}
+	return nil
+}
+
+// stmtReaches reports whether a live path of g leads from the statement
+// from to the statement to; from reaches itself only through a loop.
+func stmtReaches(g *cfg.CFG, from, to ast.Stmt) bool {
+	start, index := stmtBlock(g, from)
+	if start == nil || !start.Live {
+		return false
+	}
+	if from != to {
+		for _, n := range start.Nodes[index+1:] {
+			if n == to {
+				return true
+			}
+		}
+	}
+	seen := make(map[*cfg.Block]bool)
+	queue := append([]*cfg.Block(nil), start.Succs...)
+	for len(queue) > 0 {
+		b := queue[0]
+		queue = queue[1:]
+		if seen[b] {
+			continue
+		}
+		seen[b] = true
+		for _, n := range b.Nodes {
+			if n == to {
+				return true
+			}
+		}
+		queue = append(queue, b.Succs...)
+	}
+	return false
+}
+
+// stmtBlock returns the block of g holding s and its index there.
+func stmtBlock(g *cfg.CFG, s ast.Stmt) (*cfg.Block, int) {
+	for _, b := range g.Blocks {
+		for i, n := range b.Nodes {
+			if n == s {
+				return b, i
+			}
+		}
+	}
+	return nil, 0
+}
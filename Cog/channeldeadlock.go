@@ -0,0 +1,175 @@
+package cog
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// channelDeadlockRule reports a send on an unbuffered channel that no
+// other goroutine can receive from yet, which blocks the sender forever.
+//
+//	results := make(chan int)
+//	results <- compute() // blocks: nothing receives concurrently
+//	go func() { fmt.Println(<-results) }()
+//
+// An unbuffered send completes only when another goroutine receives. The
+// rule tracks a channel made in a function until it first escapes: passed
+// to a function or goroutine, captured by a closure, stored or returned. A
+// send reached before that point cannot have a receiver. To stay
+// conservative, sends inside select statements and in loops entered after
+// the make are skipped, as are functions using goto.
+var channelDeadlockRule = &Rule{
+	ID:       "CogChannelDeadlock",
+	Doc:      "report sends on unbuffered channels that no other goroutine can receive from",
+	Run:      runChannelDeadlock,
+	Category: CategoryConcurrency,
+}
+
+func runChannelDeadlock(p *Pass) {
+	for c := range p.Inspector.Root().Preorder((*ast.AssignStmt)(nil), (*ast.ValueSpec)(nil)) {
+		ch, made := unbufferedMake(p, c.Node())
+		if ch == nil {
+			continue
+		}
+		_, body := enclosingFunc(c)
+		if body == nil || hasGoto(body) {
+			continue
+		}
+		send, ok := blockingSend(p, c, body, ch, made)
+		if !ok {
+			continue
+		}
+		name := ch.Name()
+		p.Report(send, "send on unbuffered channel "+name+" blocks forever: no other goroutine can receive "+
+			"from "+name+" yet, since it has not been passed to one; start the receiving goroutine before "+
+			"sending, or give the channel a buffer")
+	}
+}
+
+// unbufferedMake returns the variable assigned `make(chan T)` (or
+// `make(chan T, 0)`) by n, and the make call's position.
+func unbufferedMake(p *Pass, n ast.Node) (*types.Var, token.Pos) {
+	var lhs, rhs []ast.Expr
+	switch n := n.(type) {
+	case *ast.AssignStmt:
+		lhs, rhs = n.Lhs, n.Rhs
+	case *ast.ValueSpec:
+		for _, name := range n.Names {
+			lhs = append(lhs, name)
+		}
+		rhs = n.Values
+	}
+	if len(lhs) != 1 || len(rhs) != 1 {
+		return nil, token.NoPos
+	}
+	call, ok := ast.Unparen(rhs[0]).(*ast.CallExpr)
+	if !ok || !isBuiltin(p, call.Fun, "make") || len(call.Args) == 0 || len(call.Args) > 2 {
+		return nil, token.NoPos
+	}
+	if _, ok := p.TypesInfo.TypeOf(call.Args[0]).Underlying().(*types.Chan); !ok {
+		return nil, token.NoPos
+	}
+	if len(call.Args) == 2 {
+		size := p.TypesInfo.Types[call.Args[1]].Value
+		if size == nil || constant.Sign(size) != 0 {
+			return nil, token.NoPos
+		}
+	}
+	id, ok := lhs[0].(*ast.Ident)
+	if !ok {
+		return nil, token.NoPos
+	}
+	v, ok := p.TypesInfo.ObjectOf(id).(*types.Var)
+	if !ok || v.Parent() == p.Pkg.Scope() {
+		return nil, token.NoPos
+	}
+	return v, call.Pos()
+}
+
+// blockingSend returns the first send on ch in body that precedes every
+// escape of ch, reached without entering a loop that began after made.
+func blockingSend(p *Pass, c inspector.Cursor, body *ast.BlockStmt, ch *types.Var, made token.Pos) (*ast.SendStmt, bool) {
+	cur, ok := p.Inspector.Root().FindNode(body)
+	if !ok {
+		return nil, false
+	}
+	lit := enclosingFuncLit(c)
+	var first *ast.SendStmt
+	for ic := range cur.Preorder((*ast.Ident)(nil)) {
+		id, ok := ic.Node().(*ast.Ident)
+		if !ok || p.TypesInfo.Uses[id] != ch {
+			continue
+		}
+		if id.Pos() < made {
+			return nil, false // a reuse of the variable
+		}
+		if enclosingFuncLit(ic) != lit {
+			return first, first != nil // captured by a closure
+		}
+		parent := ic.Parent()
+		switch pn := parent.Node().(type) {
+		case *ast.SendStmt:
+			if pn.Chan != id {
+				return first, first != nil
+			}
+			if first == nil && !inSelect(parent) && !inLoopAfter(parent, made) {
+				first = pn
+			}
+		case *ast.UnaryExpr:
+			if pn.Op != token.ARROW {
+				return first, first != nil
+			}
+		case *ast.CallExpr:
+			if !isBuiltin(p, pn.Fun, "close") && !isBuiltin(p, pn.Fun, "len") && !isBuiltin(p, pn.Fun, "cap") {
+				return first, first != nil
+			}
+		case *ast.RangeStmt:
+			if pn.X != id {
+				return first, first != nil
+			}
+		case *ast.BinaryExpr:
+		default:
+			return first, first != nil
+		}
+	}
+	return first, first != nil
+}
+
+// inSelect reports whether the send at c is a communication of a select
+// case.
+func inSelect(c inspector.Cursor) bool {
+	clause, ok := c.Parent().Node().(*ast.CommClause)
+	return ok && clause.Comm == c.Node()
+}
+
+// inLoopAfter reports whether c lies in a for or range statement of its
+// function that begins after pos.
+func inLoopAfter(c inspector.Cursor, pos token.Pos) bool {
+	for lc := range c.Enclosing((*ast.ForStmt)(nil), (*ast.RangeStmt)(nil), (*ast.FuncLit)(nil), (*ast.FuncDecl)(nil)) {
+		switch lc.Node().(type) {
+		case *ast.ForStmt, *ast.RangeStmt:
+			if lc.Node().Pos() > pos {
+				return true
+			}
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// hasGoto reports whether body contains a goto statement.
+func hasGoto(body *ast.BlockStmt) bool {
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		if br, ok := n.(*ast.BranchStmt); ok && br.Tok == token.GOTO {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
@@ -0,0 +1,205 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// channelRecvOkRule reports a receive in an endless loop that never checks
+// whether the channel is closed.
+//
+//	for {
+//		job := <-jobs // after close(jobs): a zero Job on every iteration
+//		run(job)
+//	}
+//
+// A receive from a closed channel returns the zero value at once, so once
+// the channel is closed such a loop spins forever on zero values. The rule
+// fires on `v := <-ch` or `v = <-ch` whose innermost loop is a `for {}`
+// without a condition, outside select, when ch can be closed: the package
+// closes the same variable or field, or ch is a receive-only parameter,
+// which producers close when done. A receive whose value is tested by an
+// `if` in the loop that breaks or returns is taken to check for a sentinel
+// and is not reported, and neither is one whose value is discarded, by a
+// bare `<-ch` statement or `_ = <-ch`, nor one from a chan struct{}: both
+// wait for a signal rather than take values. When the receive is the
+// loop's first statement, the fix turns the loop into `for v := range ch`.
+var channelRecvOkRule = &Rule{
+	ID:       "CogChannelRecvOk",
+	Doc:      "report receives in endless loops that never check whether the channel is closed",
+	Run:      runChannelRecvOk,
+	Category: CategoryConcurrency,
+	Fixable:  true,
+}
+
+func runChannelRecvOk(p *Pass) {
+	closed := closedChannels(p)
+	for c := range p.Inspector.Root().Preorder((*ast.UnaryExpr)(nil)) {
+		recv, ok := c.Node().(*ast.UnaryExpr)
+		if !ok || recv.Op != token.ARROW {
+			continue
+		}
+		stmt, v := recvStmt(p, c)
+		if stmt == nil || !closable(p, c, recv.X, closed) {
+			continue
+		}
+		if isBlank(stmt.Lhs[0]) || isSignalChan(p.TypesInfo.TypeOf(recv.X)) {
+			continue // waiting for a signal, not taking a value
+		}
+		loop := endlessLoop(c)
+		if loop == nil || v != nil && sentinelChecked(p, loop, v) {
+			continue
+		}
+		ch := types.ExprString(recv.X)
+		fixes := make([]analysis.SuggestedFix, 0, 1)
+		if loop.Body.List[0] == stmt {
+			head := "for " + types.ExprString(stmt.Lhs[0]) + " " + stmt.Tok.String() + " range " + ch + " {"
+			fixes = append(fixes, analysis.SuggestedFix{
+				Message:   "Range over " + ch,
+				TextEdits: []analysis.TextEdit{{Pos: loop.Pos(), End: stmt.End(), NewText: []byte(head)}},
+			})
+		}
+		p.Report(recv, "receive from "+ch+" in an endless loop does not check whether "+ch+" is closed; once it "+
+			"is, every receive returns the zero value and the loop spins; use for range "+ch+
+			", or v, ok := <-"+ch+" and stop when !ok", fixes...)
+	}
+}
+
+// closedChannels returns the variables and fields the package closes.
+func closedChannels(p *Pass) map[types.Object]bool {
+	closed := make(map[types.Object]bool)
+	for n := range p.Inspector.PreorderSeq((*ast.CallExpr)(nil)) {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) != 1 || !isBuiltin(p, call.Fun, "close") {
+			continue
+		}
+		if obj := chanObject(p, call.Args[0]); obj != nil {
+			closed[obj] = true
+		}
+	}
+	return closed
+}
+
+// chanObject returns the variable or field e names, or nil.
+func chanObject(p *Pass, e ast.Expr) types.Object {
+	switch e := ast.Unparen(e).(type) {
+	case *ast.Ident:
+		return p.TypesInfo.Uses[e]
+	case *ast.SelectorExpr:
+		return p.TypesInfo.Uses[e.Sel]
+	}
+	return nil
+}
+
+// closable reports whether the channel ch, received from at c, may be
+// closed: it is closed in the package, or is a receive-only parameter of
+// the enclosing function.
+func closable(p *Pass, c inspector.Cursor, ch ast.Expr, closed map[types.Object]bool) bool {
+	obj := chanObject(p, ch)
+	if obj == nil {
+		return false
+	}
+	if closed[obj] {
+		return true
+	}
+	t, ok := obj.Type().Underlying().(*types.Chan)
+	if !ok || t.Dir() != types.RecvOnly {
+		return false
+	}
+	sig := enclosingSignature(p, c)
+	if sig == nil {
+		return false
+	}
+	for param := range sig.Params().Variables() {
+		if param == obj {
+			return true
+		}
+	}
+	return false
+}
+
+// recvStmt returns the assignment when the receive at c is its only
+// right-hand side, without an ok value, and the variable it assigns, if
+// any.
+func recvStmt(p *Pass, c inspector.Cursor) (*ast.AssignStmt, *types.Var) {
+	s, ok := c.Parent().Node().(*ast.AssignStmt)
+	if !ok || len(s.Lhs) != 1 || len(s.Rhs) != 1 || s.Rhs[0] != c.Node() {
+		return nil, nil
+	}
+	if _, ok := c.Parent().Parent().Node().(*ast.CommClause); ok {
+		return nil, nil // a select case
+	}
+	id, ok := s.Lhs[0].(*ast.Ident)
+	if !ok {
+		return s, nil
+	}
+	v, _ := p.TypesInfo.ObjectOf(id).(*types.Var) // FALLBACK: nil for _
+	return s, v
+}
+
+// isSignalChan reports whether t is a channel of struct{}, whose receives
+// carry no value.
+func isSignalChan(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+	ch, ok := t.Underlying().(*types.Chan)
+	if !ok {
+		return false
+	}
+	elem, ok := ch.Elem().Underlying().(*types.Struct)
+	return ok && elem.NumFields() == 0
+}
+
+// endlessLoop returns the innermost loop enclosing c, within its function,
+// when it is a for statement without a condition, or nil.
+func endlessLoop(c inspector.Cursor) *ast.ForStmt {
+	for lc := range c.Enclosing((*ast.ForStmt)(nil), (*ast.RangeStmt)(nil), (*ast.SelectStmt)(nil),
+		(*ast.FuncLit)(nil), (*ast.FuncDecl)(nil)) {
+		loop, ok := lc.Node().(*ast.ForStmt)
+		if !ok || loop.Cond != nil {
+			return nil
+		}
+		return loop
+	}
+	return nil
+}
+
+// sentinelChecked reports whether an if statement in loop tests v and
+// leaves the loop with a break or return.
+func sentinelChecked(p *Pass, loop *ast.ForStmt, v *types.Var) bool {
+	found := false
+	ast.Inspect(loop.Body, func(n ast.Node) bool {
+		ifStmt, ok := n.(*ast.IfStmt)
+		if !ok || found {
+			return !found
+		}
+		mentions := false
+		ast.Inspect(ifStmt.Cond, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok && p.TypesInfo.Uses[id] == v {
+				mentions = true
+			}
+			return !mentions
+		})
+		if !mentions {
+			return true
+		}
+		ast.Inspect(ifStmt.Body, func(n ast.Node) bool {
+			switch s := n.(type) {
+			case *ast.ReturnStmt:
+				found = true
+			case *ast.BranchStmt:
+				found = found || s.Tok == token.BREAK
+			case *ast.FuncLit:
+				return false
+			}
+			return !found
+		})
+		return !found
+	})
+	return found
+}
@@ -0,0 +1,257 @@
+// Command cog runs the Cog analyzer.
+//
+// Use it standalone:
+//
+//	cog ./...
+//
+// or as a vet tool:
+//
+//	go vet -vettool=$(which cog) ./...
+//
+// With -format, cog prints the findings through a cog.Reporter instead:
+// text (grouped by file, with the source line), json, sarif, or github
+// workflow commands that annotate a pull request. Inside GitHub Actions
+// (GITHUB_ACTIONS=true) the github format is the default:
+//
+//	cog -format=sarif ./... > cog.sarif
+//
+// In this mode -max-findings caps the findings written, and -fail-on sets
+// the least severe finding that fails the run. Only written findings
+// count: the exit status is that of the most severe written finding at or
+// above -fail-on, 3 for an error, 4 for a warning and 5 for an info, or 0
+// when there is none. cog exits with status 1 when it cannot analyze the
+// packages and 2 when a subcommand fails:
+//
+//	cog -format=text -fail-on=error -max-findings=50 ./...
+//
+// -fix-dry-run, also a report mode flag, prints the suggested fixes as a
+// unified diff that git apply accepts, instead of the findings, and
+// changes no file:
+//
+//	cog -fix-dry-run ./... > fixes.patch
+//
+// The rules subcommand lists the rules instead, as a table or, with -json,
+// as a JSON array of cog.RuleInfo:
+//
+//	cog rules --json
+//
+// The explain subcommand prints the documentation of one rule, with the
+// buggy and fixed code from the examples package when they show it:
+//
+//	cog explain CogTypedNil
+//
+// The stats subcommand counts the findings per rule and per severity, with
+// the files scanned and the time taken, as a table or, with -json, as JSON:
+//
+//	cog stats ./...
+//
+// The watch subcommand analyzes the packages again each time a .go file or
+// the config file changes, printing the findings of every run until
+// interrupted:
+//
+//	cog watch ./...
+//
+// and the init subcommand writes a .cog.yaml listing every rule at its
+// default severity, refusing to replace an existing config file without
+// -force:
+//
+//	cog init
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	cog "github.com/PCfVW/Amphigraphic-Strict/Cog"
+)
+
+func main() {
+	if status, ok := subcommand(os.Args[1:], os.Stdout, os.Stderr); ok {
+		if status != 0 {
+			os.Exit(status)
+		}
+		return
+	}
+	if format, ok := reportFormat(os.Args[1:]); ok {
+		status, err := report(os.Args[1:], format, os.Stdout, os.Stderr)
+		if err != nil {
+			//cog:ignore-next-line CogIgnoredError stderr is the last place to report to
+			fmt.Fprintln(os.Stderr, "cog:", err)
+			os.Exit(1)
+		}
+		if status != 0 {
+			os.Exit(status)
+		}
+		return
+	}
+	singlechecker.Main(cog.Analyzer)
+}
+
+// subcommands are the commands run instead of the analyzer when named by
+// the first argument.
+var subcommands = map[string]func(args []string, w io.Writer) error{
+	"explain": explain,
+	"init":    initConfig,
+	"rules":   rules,
+	"stats":   stats,
+	"watch":   watch,
+}
+
+// subcommand runs the subcommand that args name, if any, writing its
+// output to w and its error to errw. It returns the exit status, 2 when
+// the subcommand fails, and whether args name a subcommand.
+func subcommand(args []string, w, errw io.Writer) (int, bool) {
+	if len(args) == 0 {
+		return 0, false
+	}
+	cmd, ok := subcommands[args[0]]
+	if !ok {
+		return 0, false
+	}
+	if err := cmd(args[1:], w); err != nil {
+		//cog:ignore-next-line CogIgnoredError stderr is the last place to report to
+		fmt.Fprintln(errw, "cog "+args[0]+":", err)
+		return 2, true
+	}
+	return 0, true
+}
+
+// initConfig runs the init subcommand with args: it writes a .cog.yaml
+// listing every rule to the current directory, which should be the module
+// root, reporting the file written to w.
+func initConfig(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("cog init", flag.ContinueOnError)
+	force := fs.Bool("force", false, "overwrite an existing config file")
+	err := fs.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %q", fs.Args())
+	}
+	const name = ".cog.yaml"
+	if _, err := os.Stat(".cog.toml"); err == nil {
+		return fmt.Errorf(".cog.toml already exists, and a module cannot have both it and %s", name)
+	}
+	if _, err := os.Stat(name); err == nil && !*force {
+		return fmt.Errorf("%s already exists; use -force to replace it", name)
+	}
+	if err := os.WriteFile(name, cog.ScaffoldConfig(), 0o644); err != nil {
+		return fmt.Errorf("writing the config: %w", err)
+	}
+	if _, err := fmt.Fprintln(w, "wrote", name); err != nil {
+		return fmt.Errorf("reporting: %w", err)
+	}
+	return nil
+}
+
+// explain runs the explain subcommand with args, writing the documentation
+// of the rule they name to w.
+func explain(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("cog explain", flag.ContinueOnError)
+	err := fs.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: cog explain <ruleID>")
+	}
+	ex, err := cog.Explain(fs.Arg(0))
+	if errors.Is(err, cog.ErrUnknownRule) {
+		return fmt.Errorf("unknown rule %q; run cog rules to list them", fs.Arg(0))
+	}
+	if err != nil {
+		return fmt.Errorf("explaining %s: %w", fs.Arg(0), err)
+	}
+	fix := "no fix"
+	if ex.FixAvailable {
+		fix = "fix available"
+	}
+	var b strings.Builder
+	b.WriteString(ex.ID + ": " + ex.Name + "\n")
+	b.WriteString("Category " + string(ex.Category) + ", default severity " + string(ex.DefaultSeverity) + ", " + fix + "\n\n")
+	b.WriteString(ex.Rationale)
+	if ex.Before != "" {
+		b.WriteString("\nBefore (examples/before.go):\n\n" + indent(ex.Before))
+	}
+	if ex.After != "" {
+		b.WriteString("\nAfter (examples/after.go):\n\n" + indent(ex.After))
+	}
+	b.WriteString("\nSee " + ex.DocURL + "\n")
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("writing: %w", err)
+	}
+	return nil
+}
+
+// indent prefixes every non-empty line of text with a tab.
+func indent(text string) string {
+	lines := strings.SplitAfter(text, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			lines[i] = "\t" + line
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+// rules runs the rules subcommand with args, writing the list to w.
+func rules(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("cog rules", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the rules as a JSON array")
+	err := fs.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %q", fs.Args())
+	}
+	return printRules(w, cog.Catalog(), *asJSON)
+}
+
+// printRules writes infos to w as indented JSON or as a table.
+func printRules(w io.Writer, infos []cog.RuleInfo, asJSON bool) error {
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(infos); err != nil {
+			return fmt.Errorf("encoding: %w", err)
+		}
+		return nil
+	}
+	var b strings.Builder
+	b.WriteString("ID\tCATEGORY\tSEVERITY\tFIX\tSUMMARY\n")
+	for _, info := range infos {
+		fix := "-"
+		if info.FixAvailable {
+			fix = "yes"
+		}
+		b.WriteString(info.ID + "\t" + string(info.Category) + "\t" + string(info.DefaultSeverity) + "\t" +
+			fix + "\t" + info.Summary + "\n")
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	if _, err := io.WriteString(tw, b.String()); err != nil {
+		return fmt.Errorf("writing: %w", err)
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("writing: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	cog "github.com/PCfVW/Amphigraphic-Strict/Cog"
+)
+
+func TestRulesJSON(t *testing.T) {
+	var out bytes.Buffer
+	if err := rules([]string{"-json"}, &out); err != nil {
+		t.Fatalf("rules -json: %v", err)
+	}
+	var got []cog.RuleInfo
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("rules -json wrote invalid JSON: %v\n%s", err, out.Bytes())
+	}
+	if want := cog.Catalog(); !reflect.DeepEqual(got, want) {
+		t.Errorf("rules -json = %+v, want the catalog %+v", got, want)
+	}
+}
+
+func TestInitConfig(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module initconfig\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	var out bytes.Buffer
+	if err := initConfig(nil, &out); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	cfg, err := cog.LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("loading the config init wrote: %v", err)
+	}
+	if want := cog.DefaultConfig(); !reflect.DeepEqual(cfg.Rules, want.Rules) {
+		t.Errorf("init wrote rules %v, want the defaults %v", cfg.Rules, want.Rules)
+	}
+
+	if err := os.WriteFile(".cog.yaml", []byte("rules: {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := initConfig(nil, &out); err == nil {
+		t.Error("init over an existing .cog.yaml succeeded, want an error")
+	}
+	if err := initConfig([]string{"-force"}, &out); err != nil {
+		t.Fatalf("init -force: %v", err)
+	}
+	if data, err := os.ReadFile(".cog.yaml"); err != nil || !bytes.Equal(data, cog.ScaffoldConfig()) {
+		t.Errorf("init -force left %q, %v, want the scaffold", data, err)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	var out, errw bytes.Buffer
+	if status, ok := subcommand([]string{"explain", "cogtypednil"}, &out, &errw); !ok || status != 0 {
+		t.Fatalf("explain = %d, %v, want 0, true; stderr:\n%s", status, ok, errw.String())
+	}
+	for _, want := range []string{
+		"CogTypedNil: ",
+		"\nCogTypedNil reports",
+		"\nBefore (examples/before.go):\n\n\t// --- MISTAKE 4:",
+		"\nAfter (examples/after.go):\n\n\t// --- FIX 4:",
+		"\nSee https://",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("explain output lacks %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestExplainUnknownRule(t *testing.T) {
+	var out, errw bytes.Buffer
+	status, ok := subcommand([]string{"explain", "CogNoSuchRule"}, &out, &errw)
+	if !ok || status != 2 {
+		t.Errorf("explain of an unknown rule = %d, %v, want 2, true", status, ok)
+	}
+	const want = "cog explain: unknown rule \"CogNoSuchRule\"; run cog rules to list them\n"
+	if errw.String() != want {
+		t.Errorf("explain of an unknown rule wrote %q, want %q", errw.String(), want)
+	}
+}
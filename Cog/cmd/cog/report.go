@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	cog "github.com/PCfVW/Amphigraphic-Strict/Cog"
+)
+
+// driverFlags are the flags only the go/analysis driver understands; with
+// one of them, cog runs the driver even inside GitHub Actions.
+var driverFlags = []string{"V", "flags", "fix", "diff", "json", "c"}
+
+// reportFlags are the flags only report mode understands; with one of
+// them, cog runs in report mode even without -format.
+var reportFlags = []string{"max-findings", "fail-on", "fix-dry-run"}
+
+// reportFormat returns the output format args select with -format, or
+// "github" when GITHUB_ACTIONS is true, and whether cog should run in
+// report mode: through cog.Run and a cog.Reporter instead of the
+// go/analysis driver. A report-only flag without -format selects the text
+// format. It never selects report mode for a go vet invocation.
+func reportFormat(args []string) (string, bool) {
+	driver, reportOnly := false, false
+	for i, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			driver = driver || filepath.Ext(arg) == ".cfg" // go vet passes a .cfg file
+			continue
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		switch {
+		case name == "format" && hasValue:
+			return value, true
+		case name == "format" && i+1 < len(args):
+			return args[i+1], true
+		case name == "format":
+			return "", true // report rejects the missing value
+		case slices.Contains(driverFlags, name):
+			driver = true
+		case slices.Contains(reportFlags, name):
+			reportOnly = true
+		}
+	}
+	switch {
+	case driver:
+		return "", false
+	case os.Getenv("GITHUB_ACTIONS") == "true":
+		return "github", true
+	case reportOnly:
+		return "text", true
+	}
+	return "", false
+}
+
+// report runs the analyzer in report mode over the packages named by args,
+// writing the findings to w in format unless args set -format. With
+// -max-findings it writes only the first findings, and notes how many it
+// left out on errw. It returns the exit status of the written findings:
+// that of the most severe one at least as severe as -fail-on, or 0 when
+// there is none. With -fix-dry-run it writes the suggested fixes of the
+// findings as a unified diff instead, changing no file.
+func report(args []string, format string, w, errw io.Writer) (int, error) {
+	fs := flag.NewFlagSet("cog", flag.ContinueOnError)
+	fs.StringVar(&format, "format", format, "output format: "+strings.Join(cog.Formats(), ", "))
+	maxFindings := fs.Int("max-findings", 0, "write at most this many findings; 0 writes them all")
+	failOn := fs.String("fail-on", string(cog.SeverityInfo),
+		"least severe finding that makes cog exit with a non-zero status: error, warning or info")
+	dryRun := fs.Bool("fix-dry-run", false, "print the suggested fixes as a unified diff instead of applying or listing them")
+	cog.Analyzer.Flags.VisitAll(func(f *flag.Flag) { fs.Var(f.Value, f.Name, f.Usage) })
+	err := fs.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("parsing flags: %w", err)
+	}
+	if fs.NArg() == 0 {
+		return 0, errors.New("no packages to analyze; pass patterns such as ./...")
+	}
+	if *maxFindings < 0 {
+		return 0, fmt.Errorf("invalid -max-findings %d: want 0 or more", *maxFindings)
+	}
+	threshold, ok := severityRank[cog.Severity(*failOn)]
+	if !ok {
+		return 0, fmt.Errorf("invalid -fail-on %q: want error, warning or info", *failOn)
+	}
+	write, err := cog.NewReporter(format)
+	if err != nil {
+		return 0, fmt.Errorf("selecting the reporter: %w", err)
+	}
+
+	cfg, err := loadConfig(fs)
+	if err != nil {
+		return 0, fmt.Errorf("loading the config: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	findings, err := cog.Run(ctx, fs.Args(), *cfg)
+	if err != nil {
+		return 0, fmt.Errorf("analyzing: %w", err)
+	}
+	shown := findings
+	if *maxFindings > 0 && len(findings) > *maxFindings {
+		shown = findings[:*maxFindings]
+	}
+	var worst cog.Severity
+	for _, f := range shown {
+		if rank := severityRank[f.Severity]; rank >= threshold && rank > severityRank[worst] {
+			worst = f.Severity
+		}
+	}
+	if *dryRun {
+		write = cog.WriteFixDiff
+	}
+	if err := write(w, shown); err != nil {
+		return 0, fmt.Errorf("writing the findings: %w", err)
+	}
+	if left := len(findings) - len(shown); left > 0 {
+		_, err := fmt.Fprintf(errw, "cog: %d more findings not shown (-max-findings=%d)\n", left, *maxFindings)
+		if err != nil {
+			return 0, fmt.Errorf("writing the summary: %w", err)
+		}
+	}
+	return exitStatus[worst], nil
+}
+
+// severityRank orders the severities a finding can have, the least severe
+// first.
+var severityRank = map[cog.Severity]int{
+	cog.SeverityInfo:    1,
+	cog.SeverityWarning: 2,
+	cog.SeverityError:   3,
+}
+
+// exitStatus maps the severity of the most severe failing finding to the
+// exit status of the run. A run without one exits with 0.
+var exitStatus = map[cog.Severity]int{
+	cog.SeverityError:   3,
+	cog.SeverityWarning: 4,
+	cog.SeverityInfo:    5,
+}
+
+// loadConfig reads the config file that the -config flag of the parsed fs
+// names, or the one at the module root, dropping the settings of flags set
+// on the command line, which win.
+func loadConfig(fs *flag.FlagSet) (*cog.Config, error) {
+	var cfg *cog.Config
+	var err error
+	if path := fs.Lookup("config").Value.String(); path != "" {
+		cfg, err = cog.ReadConfig(path)
+	} else {
+		cfg, err = cog.LoadConfig(".")
+	}
+	if err != nil {
+		return nil, err //cog:ignore CogErrorWrap the caller wraps it
+	}
+	fs.Visit(func(f *flag.Flag) { delete(cfg.Settings, f.Name) })
+	return cfg, nil
+}
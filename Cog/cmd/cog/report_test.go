@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// reportArgs run report mode on testdata/report, whose findings are an
+// info, a warning and an error, in that order.
+var reportArgs = []string{"-config", "testdata/report/cog.yaml"}
+
+func TestReportExitStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"all findings", nil, 3},
+		{"fail on warning", []string{"-fail-on", "warning"}, 3},
+		{"first finding", []string{"-max-findings", "1"}, 5},
+		{"first two findings", []string{"-max-findings", "2"}, 4},
+		{"info cut off", []string{"-max-findings", "2", "-fail-on", "warning"}, 4},
+		{"only info written", []string{"-max-findings", "1", "-fail-on", "warning"}, 0},
+		{"only errors fail", []string{"-max-findings", "2", "-fail-on", "error"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append(append(append([]string(nil), reportArgs...), tt.args...), "./testdata/report")
+			var out, errw bytes.Buffer
+			got, err := report(args, "text", &out, &errw)
+			if err != nil {
+				t.Fatalf("report(%q): %v", args, err)
+			}
+			if got != tt.want {
+				t.Errorf("report(%q) = %d, want %d\n%s", args, got, tt.want, out.String())
+			}
+		})
+	}
+}
+
+func TestReportMaxFindings(t *testing.T) {
+	args := append(append([]string(nil), reportArgs...), "-max-findings", "1", "./testdata/report")
+	var out, errw bytes.Buffer
+	if _, err := report(args, "text", &out, &errw); err != nil {
+		t.Fatalf("report(%q): %v", args, err)
+	}
+	if !strings.Contains(out.String(), "(1 finding)") || strings.Contains(out.String(), "CogIgnoredError") {
+		t.Errorf("report(%q) wrote more than the first finding:\n%s", args, out.String())
+	}
+	const want = "cog: 2 more findings not shown (-max-findings=1)\n"
+	if errw.String() != want {
+		t.Errorf("report(%q) summary = %q, want %q", args, errw.String(), want)
+	}
+}
+
+func TestReportInvalidFlags(t *testing.T) {
+	for _, flag := range [][]string{{"-max-findings", "-1"}, {"-fail-on", "fatal"}} {
+		args := append(append([]string(nil), flag...), "./testdata/report")
+		var out, errw bytes.Buffer
+		if status, err := report(args, "text", &out, &errw); err == nil {
+			t.Errorf("report(%q) = %d, nil; want an error", args, status)
+		}
+	}
+}
+
+func TestReportFormat(t *testing.T) {
+	tests := []struct {
+		args          []string
+		githubActions string
+		want          string
+		wantReport    bool
+	}{
+		{[]string{"./..."}, "", "", false},
+		{[]string{"-format", "json", "./..."}, "", "json", true},
+		{[]string{"--format=sarif", "./..."}, "", "sarif", true},
+		{[]string{"-max-findings", "5", "./..."}, "", "text", true},
+		{[]string{"./..."}, "true", "github", true},
+		{[]string{"-format", "text", "./..."}, "true", "text", true},
+		{[]string{"-fix", "./..."}, "true", "", false},
+		{[]string{"vet.cfg"}, "true", "", false},
+	}
+	for _, tt := range tests {
+		t.Setenv("GITHUB_ACTIONS", tt.githubActions)
+		got, gotReport := reportFormat(tt.args)
+		if got != tt.want || gotReport != tt.wantReport {
+			t.Errorf("reportFormat(%q) with GITHUB_ACTIONS=%q = %q, %v, want %q, %v",
+				tt.args, tt.githubActions, got, gotReport, tt.want, tt.wantReport)
+		}
+	}
+}
+
+func TestReportFormats(t *testing.T) {
+	for _, format := range []string{"text", "json", "sarif", "github"} {
+		args := append(append([]string(nil), reportArgs...), "-format", format, "./testdata/report")
+		var out, errw bytes.Buffer
+		if _, err := report(args, "text", &out, &errw); err != nil {
+			t.Fatalf("report(%q): %v", args, err)
+		}
+		if !strings.Contains(out.String(), "CogIgnoredError") {
+			t.Errorf("report(%q) wrote no CogIgnoredError finding:\n%s", args, out.String())
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	cog "github.com/PCfVW/Amphigraphic-Strict/Cog"
+)
+
+// A findingStats is the summary the stats subcommand prints.
+type findingStats struct {
+	Total      int          `json:"total"`
+	Packages   int          `json:"packages"`
+	Files      int          `json:"files"`
+	DurationMs int64        `json:"durationMs"`
+	ByRule     []ruleCount  `json:"byRule"`
+	BySeverity []levelCount `json:"bySeverity"`
+}
+
+// A ruleCount is the number of findings of one rule.
+type ruleCount struct {
+	Rule     string       `json:"rule"`
+	Severity cog.Severity `json:"severity"`
+	Count    int          `json:"count"`
+}
+
+// A levelCount is the number of findings of one severity.
+type levelCount struct {
+	Severity cog.Severity `json:"severity"`
+	Count    int          `json:"count"`
+}
+
+// stats runs the stats subcommand with args: it analyzes the packages they
+// name as report mode does and writes to w the number of findings per rule,
+// most frequent first, and per severity, with the files scanned and the time
+// taken, as a table or, with -json, as a JSON object. Findings do not make
+// it fail.
+func stats(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("cog stats", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the summary as a JSON object")
+	cog.Analyzer.Flags.VisitAll(func(f *flag.Flag) { fs.Var(f.Value, f.Name, f.Usage) })
+	err := fs.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+	if fs.NArg() == 0 {
+		return errors.New("no packages to analyze; pass patterns such as ./...")
+	}
+	cfg, err := loadConfig(fs)
+	if err != nil {
+		return fmt.Errorf("loading the config: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	findings, run, err := cog.RunStats(ctx, fs.Args(), *cfg)
+	if err != nil {
+		return fmt.Errorf("analyzing: %w", err)
+	}
+	summary := summarize(findings, run)
+	if *asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(summary); err != nil {
+			return fmt.Errorf("encoding: %w", err)
+		}
+		return nil
+	}
+	return printStats(w, summary)
+}
+
+// summarize counts findings by rule and by severity.
+func summarize(findings []cog.Finding, run cog.Stats) findingStats {
+	s := findingStats{
+		Total:      len(findings),
+		Packages:   run.Packages,
+		Files:      run.Files,
+		DurationMs: run.Duration.Milliseconds(),
+		ByRule:     make([]ruleCount, 0),
+		BySeverity: make([]levelCount, 0, 3),
+	}
+	for _, f := range findings {
+		i := slices.IndexFunc(s.ByRule, func(rc ruleCount) bool { return rc.Rule == f.Rule && rc.Severity == f.Severity })
+		if i < 0 {
+			s.ByRule = append(s.ByRule, ruleCount{Rule: f.Rule, Severity: f.Severity})
+			i = len(s.ByRule) - 1
+		}
+		s.ByRule[i].Count++
+	}
+	slices.SortFunc(s.ByRule, func(a, b ruleCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Rule, b.Rule))
+	})
+	for _, sev := range []cog.Severity{cog.SeverityError, cog.SeverityWarning, cog.SeverityInfo} {
+		n := 0
+		for _, f := range findings {
+			if f.Severity == sev {
+				n++
+			}
+		}
+		s.BySeverity = append(s.BySeverity, levelCount{Severity: sev, Count: n})
+	}
+	return s
+}
+
+// printStats writes s to w as two tables and a closing line.
+func printStats(w io.Writer, s findingStats) error {
+	var b strings.Builder
+	b.WriteString("RULE\tSEVERITY\tFINDINGS\n")
+	for _, rc := range s.ByRule {
+		b.WriteString(rc.Rule + "\t" + string(rc.Severity) + "\t" + strconv.Itoa(rc.Count) + "\n")
+	}
+	b.WriteString("\nSEVERITY\tFINDINGS\n")
+	for _, lc := range s.BySeverity {
+		b.WriteString(string(lc.Severity) + "\t" + strconv.Itoa(lc.Count) + "\n")
+	}
+	b.WriteString("\n" + strconv.Itoa(s.Total) + " findings in " + strconv.Itoa(s.Files) + " files of " +
+		strconv.Itoa(s.Packages) + " packages, in " + strconv.FormatInt(s.DurationMs, 10) + "ms\n")
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	if _, err := io.WriteString(tw, b.String()); err != nil {
+		return fmt.Errorf("writing: %w", err)
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("writing: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	cog "github.com/PCfVW/Amphigraphic-Strict/Cog"
+)
+
+// statsWant is the summary of testdata/stats: three ignored errors and a
+// bare return in two files.
+var statsWant = findingStats{
+	Total:    4,
+	Packages: 1,
+	Files:    2,
+	ByRule: []ruleCount{
+		{Rule: "CogIgnoredError", Severity: cog.SeverityError, Count: 3},
+		{Rule: "CogBareReturn", Severity: cog.SeverityError, Count: 1},
+	},
+	BySeverity: []levelCount{
+		{Severity: cog.SeverityError, Count: 4},
+		{Severity: cog.SeverityWarning, Count: 0},
+		{Severity: cog.SeverityInfo, Count: 0},
+	},
+}
+
+// statsArgs are the stats arguments for testdata/stats after flags. They
+// clear -config, which the analyzer flags keep across calls, so that the
+// default severities apply whichever test set it last.
+func statsArgs(flags ...string) []string {
+	return append(append([]string{"-config="}, flags...), "./testdata/stats")
+}
+
+func TestStatsJSON(t *testing.T) {
+	var out bytes.Buffer
+	if err := stats(statsArgs("-json"), &out); err != nil {
+		t.Fatalf("stats -json: %v", err)
+	}
+	var got findingStats
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("stats -json wrote invalid JSON: %v\n%s", err, out.Bytes())
+	}
+	got.DurationMs = 0
+	if !reflect.DeepEqual(got, statsWant) {
+		t.Errorf("stats -json = %+v, want %+v", got, statsWant)
+	}
+}
+
+func TestStatsTable(t *testing.T) {
+	var out bytes.Buffer
+	if err := stats(statsArgs(), &out); err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+	for _, want := range []string{
+		"CogIgnoredError  error     3\n",
+		"CogBareReturn    error     1\n",
+		"error     4\nwarning   0\ninfo      0\n",
+		"4 findings in 2 files of 1 packages, in ",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("stats output lacks %q:\n%s", want, out.String())
+		}
+	}
+}
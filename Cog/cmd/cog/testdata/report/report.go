@@ -0,0 +1,24 @@
+// Package report has one finding of each severity, in the order info,
+// warning, error, under the rule severities of cog.yaml.
+package report
+
+import (
+	"os"
+)
+
+func split(s string) (head, tail string) {
+	head, tail = s[:1], s[1:]
+	return
+}
+
+func keys(m map[string]int) []string {
+	ks := make([]string, 0, len(m))
+	for k := range m {
+		ks = append(ks, k)
+	}
+	return ks
+}
+
+func cleanup() {
+	os.Remove("scratch")
+}
@@ -0,0 +1,13 @@
+package stats
+
+import "os"
+
+func clean() {
+	os.Remove("a")
+	os.Remove("b")
+}
+
+func split(s string) (head, tail string) {
+	head, tail = s[:1], s[1:]
+	return
+}
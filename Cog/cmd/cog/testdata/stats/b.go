@@ -0,0 +1,7 @@
+package stats
+
+import "os"
+
+func enter() {
+	os.Chdir("dir")
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	cog "github.com/PCfVW/Amphigraphic-Strict/Cog"
+)
+
+// watch runs the watch subcommand with args: it analyzes the packages they
+// name, then again each time a .go file under the current directory
+// changes, writing the findings of every run to w, until interrupted.
+//
+// Saves in quick succession are analyzed once, -debounce after the last.
+// A change to the config file, the one -config names or a .cog.yaml or
+// .cog.toml in the tree, reloads it before the next run; an invalid config
+// is reported instead of the findings until it is fixed. Every run goes
+// through the -cache-dir result cache, defaulting to a directory under
+// os.UserCacheDir, so only the changed packages and the packages importing
+// them are analyzed again. An error loading the packages, as while a file
+// is half edited, is reported and the watch goes on.
+func watch(args []string, w io.Writer) (err error) {
+	flags := flag.NewFlagSet("cog watch", flag.ContinueOnError)
+	format := flags.String("format", "text", "output format: "+strings.Join(cog.Formats(), ", "))
+	debounce := flags.Duration("debounce", 200*time.Millisecond, "how long to wait after a change for more before analyzing")
+	cog.Analyzer.Flags.VisitAll(func(f *flag.Flag) { flags.Var(f.Value, f.Name, f.Usage) })
+	err = flags.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+	if flags.NArg() == 0 {
+		return errors.New("no packages to analyze; pass patterns such as ./...")
+	}
+	write, err := cog.NewReporter(*format)
+	if err != nil {
+		return fmt.Errorf("selecting the reporter: %w", err)
+	}
+	cfg, err := loadConfig(flags)
+	if err != nil {
+		return fmt.Errorf("loading the config: %w", err)
+	}
+	if _, ok := cfg.Settings["cache-dir"]; !ok && flags.Lookup("cache-dir").Value.String() == "" {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return fmt.Errorf("locating the cache: %w; set -cache-dir", err)
+		}
+		if err := flags.Set("cache-dir", filepath.Join(dir, "cog")); err != nil {
+			return fmt.Errorf("setting the cache: %w", err)
+		}
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("starting the watcher: %w", err)
+	}
+	defer func() {
+		if cerr := watcher.Close(); cerr != nil {
+			err = errors.Join(err, fmt.Errorf("stopping the watcher: %w", cerr))
+		}
+	}()
+	if err := watchTree(watcher, "."); err != nil {
+		return fmt.Errorf("watching: %w", err)
+	}
+
+	configFile := flags.Lookup("config").Value.String()
+	if configFile != "" {
+		// The config may lie outside the tree; watch its directory too.
+		if err := watcher.Add(filepath.Dir(configFile)); err != nil {
+			return fmt.Errorf("watching the config: %w", err)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	analyze := func(changed []string, reload bool) error {
+		start := time.Now()
+		var b strings.Builder
+		b.WriteString("--- " + start.Format(time.TimeOnly))
+		if len(changed) > 0 {
+			b.WriteString(" " + strings.Join(changed, ", ") + " changed")
+		}
+		if reload {
+			reloaded, err := loadConfig(flags)
+			if err != nil {
+				b.WriteString(": loading the config: " + err.Error() + "\n")
+				if _, err := io.WriteString(w, b.String()); err != nil {
+					return fmt.Errorf("writing: %w", err)
+				}
+				return nil
+			}
+			cfg = reloaded
+		}
+		findings, runErr := cog.Run(ctx, flags.Args(), *cfg)
+		if ctx.Err() != nil {
+			return nil // interrupted; the loop returns
+		}
+		if runErr != nil {
+			b.WriteString(": " + runErr.Error() + "\n")
+		} else {
+			b.WriteString(": " + strconv.Itoa(len(findings)) + " findings in " + time.Since(start).Round(time.Millisecond).String() + "\n")
+		}
+		if _, err := io.WriteString(w, b.String()); err != nil {
+			return fmt.Errorf("writing: %w", err)
+		}
+		if runErr != nil || len(findings) == 0 {
+			return nil
+		}
+		if err := write(w, findings); err != nil {
+			return fmt.Errorf("writing the findings: %w", err)
+		}
+		return nil
+	}
+	if err := analyze(nil, false); err != nil {
+		return err //cog:ignore CogErrorWrap analyze says what failed
+	}
+	loop := watchLoop{
+		events:   watcher.Events,
+		errors:   watcher.Errors,
+		debounce: *debounce,
+		addTree:  func(dir string) error { return watchTree(watcher, dir) },
+		isConfig: func(name string) bool { return isConfigFile(name, configFile) },
+		analyze:  analyze,
+	}
+	return loop.run(ctx)
+}
+
+// A watchLoop analyzes again, through analyze, once the file events it
+// receives settle for debounce.
+type watchLoop struct {
+	events   <-chan fsnotify.Event
+	errors   <-chan error
+	debounce time.Duration
+
+	// addTree watches a directory created under the watched tree.
+	addTree func(dir string) error
+
+	// isConfig reports whether the file name is the config file.
+	isConfig func(name string) bool
+
+	// analyze runs the analysis after the files changed changed,
+	// reloading the config first when it is one of them.
+	analyze func(changed []string, reload bool) error
+}
+
+// run collects events and analyzes after each burst of changes to .go
+// files or the config file, until ctx is done or the events end.
+func (l *watchLoop) run(ctx context.Context) error {
+	timer := time.NewTimer(l.debounce)
+	timer.Stop()
+	changed := make(map[string]bool)
+	reload := false
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-l.events:
+			if !ok {
+				return nil
+			}
+			if ev.Has(fsnotify.Create) {
+				if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
+					if err := l.addTree(ev.Name); err != nil {
+						return fmt.Errorf("watching: %w", err)
+					}
+				}
+			}
+			config := l.isConfig(ev.Name)
+			if (filepath.Ext(ev.Name) == ".go" || config) && !ev.Has(fsnotify.Chmod) {
+				changed[filepath.Clean(ev.Name)] = true
+				reload = reload || config
+				timer.Reset(l.debounce)
+			}
+		case err, ok := <-l.errors:
+			if !ok {
+				return nil
+			}
+			return fmt.Errorf("watching: %w", err)
+		case <-timer.C:
+			names := slices.Sorted(maps.Keys(changed))
+			clear(changed)
+			if err := l.analyze(names, reload); err != nil {
+				return err //cog:ignore CogErrorWrap analyze says what failed
+			}
+			reload = false
+		}
+	}
+}
+
+// isConfigFile reports whether the file name is the config file: the one
+// -config names when it is set, otherwise any .cog.yaml or .cog.toml.
+func isConfigFile(name, configFile string) bool {
+	if configFile != "" {
+		return sameFile(name, configFile)
+	}
+	base := filepath.Base(name)
+	return base == ".cog.yaml" || base == ".cog.toml"
+}
+
+// sameFile reports whether the paths a and b name the same file.
+func sameFile(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
+
+// watchTree adds root and the directories below it to watcher, skipping
+// those the go command ignores: testdata, vendor, and names starting with
+// . or _.
+func watchTree(watcher *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err //cog:ignore CogErrorWrap WalkDir names the path
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if name := d.Name(); path != root && (name == "testdata" || name == "vendor" ||
+			strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+			return filepath.SkipDir
+		}
+		if err := watcher.Add(path); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		return nil
+	})
+}
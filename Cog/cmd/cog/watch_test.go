@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// A watchRun is one call of the analyze function of a watchLoop.
+type watchRun struct {
+	changed []string
+	reload  bool
+}
+
+// TestWatchLoop drives a watchLoop with fake file events and checks that
+// each burst of changes is analyzed once, after the debounce, and that a
+// change to the config reloads it.
+func TestWatchLoop(t *testing.T) {
+	events := make(chan fsnotify.Event)
+	runs := make(chan watchRun)
+	loop := watchLoop{
+		events:   events,
+		errors:   make(chan error),
+		debounce: 100 * time.Millisecond,
+		addTree:  func(string) error { return nil },
+		isConfig: func(name string) bool { return isConfigFile(name, "") },
+		analyze: func(changed []string, reload bool) error {
+			runs <- watchRun{changed, reload}
+			return nil
+		},
+	}
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+	go func() { done <- loop.run(ctx) }()
+
+	next := func(step string, want watchRun) {
+		t.Helper()
+		select {
+		case got := <-runs:
+			if !slices.Equal(got.changed, want.changed) || got.reload != want.reload {
+				t.Errorf("%s: analyze(%q, %v), want analyze(%q, %v)", step, got.changed, got.reload, want.changed, want.reload)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s: no run", step)
+		}
+	}
+
+	// A burst of saves, with events that do not count, is analyzed once.
+	events <- fsnotify.Event{Name: "b.go", Op: fsnotify.Write}
+	events <- fsnotify.Event{Name: "notes.txt", Op: fsnotify.Write}
+	events <- fsnotify.Event{Name: "c.go", Op: fsnotify.Chmod}
+	events <- fsnotify.Event{Name: "./a.go", Op: fsnotify.Write}
+	events <- fsnotify.Event{Name: "b.go", Op: fsnotify.Write}
+	next("saves", watchRun{changed: []string{"a.go", "b.go"}})
+
+	// Editing the config reloads it, then the next run does not.
+	events <- fsnotify.Event{Name: ".cog.yaml", Op: fsnotify.Write}
+	next("config", watchRun{changed: []string{".cog.yaml"}, reload: true})
+	events <- fsnotify.Event{Name: "a.go", Op: fsnotify.Write}
+	next("after the config", watchRun{changed: []string{"a.go"}})
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("run: %v", err)
+	}
+}
+
+func TestIsConfigFile(t *testing.T) {
+	for _, tt := range []struct {
+		name, configFile string
+		want             bool
+	}{
+		{".cog.yaml", "", true},
+		{"sub/.cog.toml", "", true},
+		{"cog.yaml", "", false},
+		{"ci/lint.yaml", "ci/lint.yaml", true},
+		{"ci/../ci/lint.yaml", "ci/lint.yaml", true},
+		{".cog.yaml", "ci/lint.yaml", false},
+	} {
+		if got := isConfigFile(tt.name, tt.configFile); got != tt.want {
+			t.Errorf("isConfigFile(%q, %q) = %v, want %v", tt.name, tt.configFile, got, tt.want)
+		}
+	}
+}
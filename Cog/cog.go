@@ -0,0 +1,381 @@
+// Package cog enforces the Cog rules (strict Go for AI-assisted development)
+// as a go/analysis Analyzer.
+//
+// Every rule from the Cog README that can be checked mechanically lives here
+// as a Rule. Analyzer runs all registered rules over a package and reports one
+// diagnostic per violation, prefixed with the rule ID:
+//
+//	before.go:54:9: CogTypedNil: returning typed nil *MyError as error
+//
+// A .cog.yaml or .cog.toml file at the module root sets rule severities,
+// turns rules off and supplies flag values; see Config.
+//
+// Analyzer plugs into any go/analysis driver: `go vet -vettool`, golangci-lint,
+// gopls, or the bundled cmd/cog command.
+package cog
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// errInspectorMissing is returned when the driver did not run the inspect
+// pass that Analyzer requires; it indicates a broken driver, not bad input.
+var errInspectorMissing = errors.New("cog: inspect analyzer result missing")
+
+// errSSAMissing is the buildssa counterpart of errInspectorMissing.
+var errSSAMissing = errors.New("cog: buildssa analyzer result missing")
+
+// Analyzer runs every rule in Rules and reports Cog violations.
+//
+// Its result is the []Finding reported for the package, so other analyzers
+// (or programmatic drivers) can consume the findings without re-parsing
+// diagnostic messages.
+var Analyzer = &analysis.Analyzer{
+	Name:       "cog",
+	Doc:        "enforce Cog (strict Go) rules\n\nCog reports Go patterns that compile cleanly but that AI assistants\nfrequently get wrong. Each diagnostic is prefixed with its rule ID.",
+	URL:        "https://github.com/PCfVW/Amphigraphic-Strict/tree/main/Cog",
+	Requires:   []*analysis.Analyzer{inspect.Analyzer, buildssa.Analyzer},
+	Run:        run,
+	ResultType: reflect.TypeFor[[]Finding](),
+}
+
+// A Rule is a single Cog check.
+type Rule struct {
+	// ID is the stable identifier used in diagnostics and configuration,
+	// e.g. "CogTypedNil".
+	ID string
+
+	// Doc is a one-line summary of what the rule reports.
+	Doc string
+
+	// Run inspects the package and reports violations through the Pass.
+	Run func(p *Pass)
+
+	// Category groups the rule with related rules in the catalog.
+	Category Category
+
+	// Fixable reports whether the rule's diagnostics can carry suggested
+	// fixes.
+	Fixable bool
+
+	// Severity is the rule's severity when no Config overrides it; empty
+	// means SeverityError.
+	Severity Severity
+}
+
+// defaultSeverity returns r.Severity, or SeverityError when unset.
+func (r *Rule) defaultSeverity() Severity {
+	if r.Severity == "" {
+		return SeverityError
+	}
+	return r.Severity
+}
+
+// Rules lists the rules Analyzer runs, in the order they were registered:
+// the built-in rules first, then those added with Register.
+var Rules []*Rule
+
+// builtinRules are the rules shipped with Cog, registered in this order.
+var builtinRules = []*Rule{
+	typedNilRule,
+	ignoredErrorRule,
+	errorWrapRule,
+	nilSliceJSONRule,
+	loopCaptureRule,
+	errShadowRule,
+	bodyCloseRule,
+	resourceCloseRule,
+	contextFirstRule,
+	errorsIsRule,
+	typeErasureRule,
+	bareReturnRule,
+	deferInLoopRule,
+	timerLeakRule,
+	copyLockRule,
+	appendAliasRule,
+	nilMapWriteRule,
+	uncheckedAssertRule,
+	contextCancelRule,
+	stringConcatLoopRule,
+	channelDeadlockRule,
+	waitGroupAddRule,
+	floatEqualityRule,
+	unkeyedStructRule,
+	libraryPanicRule,
+	osExitRule,
+	mapRangeOrderRule,
+	deferCloseErrorRule,
+	intDivFloatRule,
+	rowsErrRule,
+	timeJSONFormatRule,
+	deferCaptureRule,
+	mapRaceRule,
+	channelCloseRule,
+	weakRandomRule,
+	indexBoundsRule,
+	selectContextRule,
+	errorStringRule,
+	bareGoroutineRule,
+	jsonMapAnyRule,
+	sliceMutationRule,
+	recoverSwallowRule,
+	httpNoTimeoutsRule,
+	regexpMustRule,
+	largeValueCopyRule,
+	waitGroupDoneRule,
+	sqlInjectionRule,
+	printfRule,
+	timeSleepSyncRule,
+	deferNilReceiverRule,
+	appendCapRule,
+	interfaceAssertRule,
+	atomicCounterRule,
+	structCompareRule,
+	pointerToLoopVarRule,
+	missingReturnRule,
+	missingDocRule,
+	panicStringRule,
+	channelRecvOkRule,
+	jsonTrailingRule,
+}
+
+func init() {
+	for _, rule := range builtinRules {
+		MustRegister(rule)
+	}
+}
+
+// Register adds r to Rules, so Analyzer runs it, Config can set its
+// severity and Catalog lists it. Call it before the analyzer runs,
+// typically from an init function of a command that embeds Cog;
+// examples/customrule is such a command. Register returns an error when r
+// has no ID or Run function, or when a rule with the same ID is already
+// registered.
+func Register(r *Rule) error {
+	switch {
+	case r.ID == "":
+		return errors.New("cog: register: rule has no ID")
+	case r.Run == nil:
+		return fmt.Errorf("cog: register %s: rule has no Run function", r.ID)
+	case r.ID == unusedIgnoreRule.ID || slices.ContainsFunc(Rules, func(q *Rule) bool { return q.ID == r.ID }):
+		return fmt.Errorf("cog: register %s: a rule with this ID is already registered", r.ID)
+	}
+	Rules = append(Rules, r)
+	return nil
+}
+
+// MustRegister is like Register but panics on error, for use in init
+// functions, where a bad rule is a programming error.
+func MustRegister(r *Rule) {
+	if err := Register(r); err != nil {
+		panic(err)
+	}
+}
+
+// A Finding is one rule violation with its position resolved.
+type Finding struct {
+	Rule     string
+	Severity Severity
+	Pos      token.Position
+	End      token.Position
+	Message  string
+
+	// Fixes are the suggested fixes of the diagnostic, positions resolved.
+	Fixes []Fix
+
+	// Fingerprint identifies the finding within its file independently of
+	// its line number; baselines match findings by it.
+	Fingerprint string
+}
+
+// A Fix is a suggested fix of a Finding.
+type Fix struct {
+	Message string
+	Edits   []Edit
+}
+
+// An Edit replaces the text from Pos to End with NewText.
+type Edit struct {
+	Pos     token.Position
+	End     token.Position
+	NewText string
+}
+
+// Pass is the per-package state handed to a Rule. It embeds the underlying
+// analysis.Pass and adds the shared helpers every rule needs.
+type Pass struct {
+	*analysis.Pass
+
+	// Inspector walks the package's syntax trees.
+	Inspector *inspector.Inspector
+
+	// SSA is the package in SSA form, for rules that need data flow.
+	SSA *buildssa.SSA
+
+	rule     *Rule
+	severity Severity
+	ignores  map[lineKey][]*ignoreDirective
+	findings *[]Finding
+
+	baseline     map[baselineEntry]int
+	baselineUsed map[baselineEntry]int
+}
+
+// Report records a violation of the current rule spanning rng, unless a
+// `//cog:ignore` directive suppresses it or it is in the -baseline file.
+// The message is prefixed with the rule ID in the emitted diagnostic,
+// followed by the severity unless it is SeverityError:
+//
+//	CogTypedNil: returning typed nil *MyError as error
+//	CogBareReturn (warning): bare return in a function with named results ...
+func (p *Pass) Report(rng analysis.Range, msg string, fixes ...analysis.SuggestedFix) {
+	if p.suppressed(rng) {
+		return
+	}
+	fp := fingerprint(p, rng, msg)
+	pos := p.Fset.Position(rng.Pos())
+	if p.baselined(pos.Filename, fp) {
+		return
+	}
+	prefix := p.rule.ID
+	if p.severity != SeverityError {
+		prefix += " (" + string(p.severity) + ")"
+	}
+	p.Pass.Report(analysis.Diagnostic{
+		Pos:            rng.Pos(),
+		End:            rng.End(),
+		Category:       p.rule.ID,
+		Message:        prefix + ": " + msg,
+		SuggestedFixes: fixes,
+	})
+	*p.findings = append(*p.findings, Finding{
+		Rule:        p.rule.ID,
+		Severity:    p.severity,
+		Pos:         pos,
+		End:         p.Fset.Position(rng.End()),
+		Message:     msg,
+		Fixes:       p.resolveFixes(fixes),
+		Fingerprint: fp,
+	})
+}
+
+// resolveFixes converts suggested fixes to Fixes with resolved positions.
+func (p *Pass) resolveFixes(fixes []analysis.SuggestedFix) []Fix {
+	resolved := make([]Fix, 0, len(fixes))
+	for _, fix := range fixes {
+		edits := make([]Edit, 0, len(fix.TextEdits))
+		for _, e := range fix.TextEdits {
+			edits = append(edits, Edit{
+				Pos:     p.Fset.Position(e.Pos),
+				End:     p.Fset.Position(e.End),
+				NewText: string(e.NewText),
+			})
+		}
+		resolved = append(resolved, Fix{Message: fix.Message, Edits: edits})
+	}
+	return resolved
+}
+
+// run is the analysis.Analyzer entry point. The framework fixes its `any`
+// result type; the dynamic value is always []Finding.
+func run(pass *analysis.Pass) (any, error) {
+	cfg, err := loadActiveConfig(packageDir(pass), pass.Analyzer)
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+	base, err := readBaseline(baselinePath)
+	if err != nil {
+		return nil, fmt.Errorf("loading baseline: %w", err)
+	}
+	defer acquireDriverSlot()()
+	return analyze(pass, cfg, base, Rules)
+}
+
+// analyze runs rules over the package of pass, at the severities of cfg,
+// and returns the sorted findings, leaving out those in base.
+func analyze(pass *analysis.Pass, cfg *Config, base map[baselineEntry]int, rules []*Rule) ([]Finding, error) {
+	in, ok := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	if !ok {
+		return nil, errInspectorMissing
+	}
+	ssaPkg, ok := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
+	if !ok {
+		return nil, errSSAMissing
+	}
+
+	baselineUsed := make(map[baselineEntry]int)
+
+	ignores := ignoreDirectives(pass)
+	findings := make([]Finding, 0)
+	ran := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		sev := cfg.Severity(rule.ID)
+		if sev == SeverityOff || !categorySelected(rule.Category) {
+			continue
+		}
+		p := &Pass{
+			Pass:      pass,
+			Inspector: in,
+			SSA:       ssaPkg,
+			rule:      rule,
+			severity:  sev,
+			ignores:   ignores,
+			findings:  &findings,
+
+			baseline:     base,
+			baselineUsed: baselineUsed,
+		}
+		rule.Run(p)
+		ran = append(ran, rule.ID)
+	}
+	if reportUnusedIgnores {
+		reportUnused(&Pass{
+			Pass:      pass,
+			Inspector: in,
+			SSA:       ssaPkg,
+			rule:      unusedIgnoreRule,
+			severity:  unusedIgnoreRule.defaultSeverity(),
+			findings:  &findings,
+
+			baseline:     base,
+			baselineUsed: baselineUsed,
+		}, ignores, ran)
+	}
+
+	sortFindings(findings)
+	return findings, nil
+}
+
+// packageDir returns the directory of the package's first file, or "." for
+// a package without files.
+func packageDir(pass *analysis.Pass) string {
+	if len(pass.Files) == 0 {
+		return "."
+	}
+	return filepath.Dir(pass.Fset.File(pass.Files[0].Pos()).Name())
+}
+
+// sortFindings orders findings by file, then position, then rule ID.
+func sortFindings(fs []Finding) {
+	sort.SliceStable(fs, func(i, j int) bool {
+		a, b := fs[i], fs[j]
+		if a.Pos.Filename != b.Pos.Filename {
+			return a.Pos.Filename < b.Pos.Filename
+		}
+		if a.Pos.Offset != b.Pos.Offset {
+			return a.Pos.Offset < b.Pos.Offset
+		}
+		return a.Rule < b.Rule
+	})
+}
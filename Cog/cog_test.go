@@ -0,0 +1,184 @@
+package cog
+
+import (
+	"flag"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+// update rewrites the golden files instead of comparing against them:
+//
+//	go test -run Golden -update
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// checkGolden compares got with the golden file testdata/name, or rewrites
+// it with -update.
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("writing %s: %v", path, err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading %s: %v (run go test -update to create it)", path, err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("output differs from %s (run go test -update to accept it):\n%s", path, got)
+	}
+}
+
+// ruleAnalyzer returns an analyzer that runs rules alone, each at its
+// declared severity, or at error when it is off by default, for
+// analysistest to check their diagnostics and fixes against testdata/src.
+func ruleAnalyzer(rules ...*Rule) *analysis.Analyzer {
+	cfg := DefaultConfig()
+	for _, rule := range rules {
+		if cfg.Rules[rule.ID] == SeverityOff {
+			cfg.Rules[rule.ID] = SeverityError
+		}
+	}
+	return &analysis.Analyzer{
+		Name:     Analyzer.Name,
+		Doc:      Analyzer.Doc,
+		Requires: Analyzer.Requires,
+		Run: func(pass *analysis.Pass) (any, error) {
+			return analyze(pass, cfg, nil, rules)
+		},
+		ResultType: Analyzer.ResultType,
+	}
+}
+
+// testFindings are the findings the reporter tests write: two files, out
+// of order, with every severity, and a finding with a fix.
+func testFindings() []Finding {
+	pos := func(file string, line, col, offset int) token.Position {
+		return token.Position{Filename: file, Line: line, Column: col, Offset: offset}
+	}
+	return []Finding{
+		{
+			Rule:     "CogBareReturn",
+			Severity: SeverityInfo,
+			Pos:      pos("testdata/b.go", 7, 2, 80),
+			End:      pos("testdata/b.go", 7, 8, 86),
+			Message:  "bare return in a function with named results hides what is returned; write `return n, err`",
+		},
+		{
+			Rule:     "CogIgnoredError",
+			Severity: SeverityError,
+			Pos:      pos("testdata/a.go", 12, 2, 140),
+			End:      pos("testdata/a.go", 12, 22, 160),
+			Message:  "error returned by os.Remove is discarded; handle it with `if err := ...; err != nil`",
+			Fixes: []Fix{{
+				Message: "Check the error",
+				Edits: []Edit{{
+					Pos:     pos("testdata/a.go", 12, 2, 140),
+					End:     pos("testdata/a.go", 12, 22, 160),
+					NewText: "if err := os.Remove(\"scratch\"); err != nil {\n\t\treturn fmt.Errorf(\"os.Remove: %w\", err)\n\t}",
+				}},
+			}},
+		},
+		{
+			Rule:     "CogMapRangeOrder",
+			Severity: SeverityWarning,
+			Pos:      pos("testdata/a.go", 5, 2, 40),
+			End:      pos("testdata/a.go", 5, 20, 58),
+			Message:  "range over map m appends to ks in random order, and ks is returned unsorted on line 8",
+		},
+	}
+}
+
+// fixedFiles applies the suggested fixes of the diagnostics in results to
+// their files, as -fix does: identical edits are made once, imports added
+// at the same point are sorted, and an edit overlapping one already taken
+// fails the test. It returns the contents of the edited files by name.
+func fixedFiles(t *testing.T, results []*analysistest.Result) map[string][]byte {
+	t.Helper()
+	edits := make(map[string][]Edit)
+	for _, r := range results {
+		for _, d := range r.Diagnostics {
+			for _, fix := range d.SuggestedFixes {
+				for _, e := range fix.TextEdits {
+					edit := Edit{Pos: r.Pass.Fset.Position(e.Pos), End: r.Pass.Fset.Position(e.End), NewText: string(e.NewText)}
+					if conflicts(edits[edit.Pos.Filename], edit) {
+						t.Fatalf("%s: fix %q overlaps another", edit.Pos, fix.Message)
+					}
+					if !slices.Contains(edits[edit.Pos.Filename], edit) {
+						edits[edit.Pos.Filename] = append(edits[edit.Pos.Filename], edit)
+					}
+				}
+			}
+		}
+	}
+	fixed := make(map[string][]byte, len(edits))
+	for name, es := range edits {
+		src, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("reading %s: %v", name, err)
+		}
+		slices.SortStableFunc(es, compareEdits)
+		var out []byte
+		at := 0
+		for _, e := range es {
+			out = append(append(out, src[at:e.Pos.Offset]...), e.NewText...)
+			at = e.End.Offset
+		}
+		fixed[name] = append(out, src[at:]...)
+	}
+	return fixed
+}
+
+// testdataImporter imports the packages of testdata/src from source, and
+// the standard library with std.
+type testdataImporter struct {
+	fset *token.FileSet
+	std  types.Importer
+}
+
+func (imp testdataImporter) Import(path string) (*types.Package, error) {
+	dir := filepath.Join("testdata", "src", filepath.FromSlash(path))
+	if _, err := os.Stat(dir); err != nil {
+		return imp.std.Import(path)
+	}
+	pkgs, err := parser.ParseDir(imp.fset, dir, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]*ast.File, 0)
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Files {
+			files = append(files, f)
+		}
+	}
+	conf := types.Config{Importer: imp}
+	return conf.Check(path, imp.fset, files, nil)
+}
+
+// writeModule writes files, by slash-separated path, to a new temporary
+// directory and returns it.
+func writeModule(tb testing.TB, files map[string]string) string {
+	tb.Helper()
+	dir := tb.TempDir()
+	for name, src := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			tb.Fatalf("creating %s: %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			tb.Fatalf("writing %s: %v", path, err)
+		}
+	}
+	return dir
+}
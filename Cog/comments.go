@@ -0,0 +1,38 @@
+package cog
+
+import (
+	"go/token"
+	"strings"
+)
+
+// lineKey identifies a source line.
+type lineKey struct {
+	file string
+	line int
+}
+
+// markedLines returns the lines that carry a `//` comment starting with
+// marker, such as the "IGNORE:" and "CAPTURE:" annotations the Cog prompt
+// asks for. A rule can then honor an annotated line:
+//
+//	_ = w.Close() // IGNORE: best-effort cleanup
+func markedLines(p *Pass, marker string) map[lineKey]bool {
+	lines := make(map[lineKey]bool)
+	for _, f := range p.Files {
+		for _, group := range f.Comments {
+			for _, c := range group.List {
+				text := strings.TrimSpace(strings.TrimPrefix(c.Text, "//"))
+				if strings.HasPrefix(text, marker) {
+					lines[lineOf(p.Fset, c.Pos())] = true
+				}
+			}
+		}
+	}
+	return lines
+}
+
+// lineOf returns the line key of pos.
+func lineOf(fset *token.FileSet, pos token.Pos) lineKey {
+	position := fset.Position(pos)
+	return lineKey{file: position.Filename, line: position.Line}
+}
@@ -0,0 +1,292 @@
+package cog
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"go.yaml.in/yaml/v3"
+	"golang.org/x/tools/go/analysis"
+)
+
+// Severity says how seriously the findings of a rule are taken.
+type Severity string
+
+// The severities a Config may assign to a rule.
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+	SeverityOff     Severity = "off" // the rule does not run
+)
+
+// Config tunes Analyzer for a module. It is read from .cog.yaml or
+// .cog.toml at the module root:
+//
+//	rules:
+//	  CogTypeErasure: warning
+//	  CogBareReturn: off
+//	settings:
+//	  typeerasure.enable: true
+//	  ignorederror.allow: [fmt.Println, log.Printf]
+//
+// or, equivalently:
+//
+//	[rules]
+//	CogTypeErasure = "warning"
+//	CogBareReturn = "off"
+//
+//	[settings]
+//	"typeerasure.enable" = true
+//	"ignorederror.allow" = ["fmt.Println", "log.Printf"]
+//
+// A flag given on the command line overrides the file's setting for it.
+type Config struct {
+	// Rules maps rule IDs to a severity. A rule not listed keeps the
+	// severity it declares, or SeverityError.
+	Rules map[string]Severity `yaml:"rules" toml:"rules"`
+
+	// Settings maps analyzer flag names to values, applied as if given on
+	// the command line. A list value is joined with commas.
+	Settings map[string]any `yaml:"settings" toml:"settings"`
+}
+
+// configFiles are the names LoadConfig looks for, in order of preference.
+var configFiles = []string{".cog.yaml", ".cog.toml"}
+
+// DefaultConfig returns the configuration used when a module has no config
+// file: every rule at its declared severity, no settings.
+func DefaultConfig() *Config {
+	cfg := &Config{Rules: make(map[string]Severity, len(Rules)), Settings: make(map[string]any)}
+	for _, rule := range Rules {
+		cfg.Rules[rule.ID] = rule.defaultSeverity()
+	}
+	return cfg
+}
+
+// LoadConfig reads the config file at the root of the module containing
+// dir. It returns DefaultConfig when dir is not in a module or the module
+// has no config file, and an error when it has both a .cog.yaml and a
+// .cog.toml, since it is unclear which one is meant.
+func LoadConfig(dir string) (*Config, error) {
+	root, ok := moduleRoot(dir)
+	if !ok {
+		return DefaultConfig(), nil
+	}
+	found := make([]string, 0, len(configFiles))
+	for _, name := range configFiles {
+		if _, err := os.Stat(filepath.Join(root, name)); err == nil {
+			found = append(found, filepath.Join(root, name))
+		}
+	}
+	switch len(found) {
+	case 0:
+		return DefaultConfig(), nil
+	case 1:
+		return ReadConfig(found[0])
+	}
+	return nil, fmt.Errorf("cog: config: both %s and %s exist; keep one", found[0], found[1])
+}
+
+// ReadConfig reads the config file at path; its extension selects YAML
+// (.yaml, .yml) or TOML (.toml).
+func ReadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cog: config: %w", err)
+	}
+	return ParseConfig(path, data)
+}
+
+// ParseConfig decodes and validates a config file named name, merging it
+// over DefaultConfig.
+func ParseConfig(name string, data []byte) (*Config, error) {
+	var file Config
+	switch ext := filepath.Ext(name); ext {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("cog: config %s: %w", name, err)
+		}
+	case ".toml":
+		if err := toml.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("cog: config %s: %w", name, err)
+		}
+	default:
+		return nil, fmt.Errorf("cog: config %s: unknown format %q (want .yaml or .toml)", name, ext)
+	}
+	if err := file.validate(); err != nil {
+		return nil, fmt.Errorf("cog: config %s: %w", name, err)
+	}
+
+	return file.overDefaults(), nil
+}
+
+// overDefaults returns DefaultConfig with the rules and settings of c
+// merged over it.
+func (c *Config) overDefaults() *Config {
+	cfg := DefaultConfig()
+	for id, sev := range c.Rules {
+		cfg.Rules[id] = sev
+	}
+	for key, value := range c.Settings {
+		cfg.Settings[key] = value
+	}
+	return cfg
+}
+
+// Severity returns the severity of the rule with the given ID.
+func (c *Config) Severity(id string) Severity {
+	if sev, ok := c.Rules[id]; ok {
+		return sev
+	}
+	return SeverityError
+}
+
+// validate rejects unknown rule IDs and severities, which are most likely
+// typos that would otherwise be silently ignored. Unknown settings are
+// rejected when they are applied to the analyzer's flags.
+func (c *Config) validate() error {
+	errs := make([]error, 0)
+	for id, sev := range c.Rules {
+		if !slices.ContainsFunc(Rules, func(r *Rule) bool { return r.ID == id }) {
+			errs = append(errs, fmt.Errorf("unknown rule %q", id))
+		}
+		switch sev {
+		case SeverityError, SeverityWarning, SeverityInfo, SeverityOff:
+		default:
+			errs = append(errs, fmt.Errorf("rule %s: unknown severity %q (want error, warning, info or off)", id, sev))
+		}
+	}
+	slices.SortFunc(errs, func(a, b error) int { return strings.Compare(a.Error(), b.Error()) })
+	return errors.Join(errs...)
+}
+
+// apply sets the flags in fs named by c.Settings, except those for which
+// explicit reports true because the command line set them.
+func (c *Config) apply(fs *flag.FlagSet, explicit func(name string) bool) error {
+	keys := make([]string, 0, len(c.Settings))
+	for key := range c.Settings {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		if key == "config" || fs.Lookup(key) == nil {
+			return fmt.Errorf("cog: config: unknown setting %q", key)
+		}
+		if explicit(key) {
+			continue
+		}
+		if err := fs.Set(key, settingString(c.Settings[key])); err != nil {
+			return fmt.Errorf("cog: config setting %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// save returns a function that sets the flags in fs named by c.Settings
+// back to their current values.
+func (c *Config) save(fs *flag.FlagSet) func() {
+	saved := make(map[*flag.Flag]string, len(c.Settings))
+	for key := range c.Settings {
+		if f := fs.Lookup(key); f != nil {
+			saved[f] = f.Value.String()
+		}
+	}
+	return func() {
+		for f, value := range saved {
+			//cog:ignore-next-line CogIgnoredError the flag printed value itself, so it parses
+			f.Value.Set(value)
+		}
+	}
+}
+
+// settingString renders a decoded setting as flag text.
+func settingString(v any) string {
+	list, ok := v.([]any)
+	if !ok {
+		return fmt.Sprint(v)
+	}
+	items := make([]string, 0, len(list))
+	for _, item := range list {
+		items = append(items, fmt.Sprint(item))
+	}
+	return strings.Join(items, ",")
+}
+
+// moduleRoot returns the nearest directory at or above dir holding a go.mod.
+func moduleRoot(dir string) (string, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// configPath is the -config flag: an explicit config file to use instead of
+// the one at the module root.
+var configPath string
+
+func init() {
+	Analyzer.Flags.StringVar(&configPath, "config", "",
+		"config file (.cog.yaml or .cog.toml) to use instead of the one at the module root")
+}
+
+// activeConfig is the configuration of the current process, loaded once:
+// from -config when given, otherwise from the module of the first package
+// analyzed.
+var activeConfig struct {
+	once sync.Once
+	cfg  *Config
+	err  error
+}
+
+// loadActiveConfig returns activeConfig, loading it for dir on first use
+// and applying its settings to the flags of a.
+func loadActiveConfig(dir string, a *analysis.Analyzer) (*Config, error) {
+	activeConfig.once.Do(func() {
+		var cfg *Config
+		var err error
+		if configPath != "" {
+			cfg, err = ReadConfig(configPath)
+		} else {
+			cfg, err = LoadConfig(dir)
+		}
+		if err == nil {
+			err = cfg.apply(&a.Flags, func(name string) bool { return setOnCommandLine(a.Name, name) })
+		}
+		activeConfig.cfg, activeConfig.err = cfg, err
+	})
+	return activeConfig.cfg, activeConfig.err
+}
+
+// setOnCommandLine reports whether the command line set the flag name of
+// the analyzer called analyzer. Drivers register analyzer flags in
+// flag.CommandLine, unprefixed (cmd/cog, standalone or under go vet) or
+// prefixed as cog.name (multichecker drivers).
+func setOnCommandLine(analyzer, name string) bool {
+	if !flag.Parsed() {
+		return false
+	}
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name || f.Name == analyzer+"."+name {
+			set = true
+		}
+	})
+	return set
+}
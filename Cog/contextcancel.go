@@ -0,0 +1,167 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/cfg"
+)
+
+// contextCancelRule reports a cancel function from context.WithCancel,
+// WithTimeout or WithDeadline that is not called on every path out of the
+// function. The derived context and its timer live until the parent is
+// done.
+//
+//	ctx, cancel := context.WithTimeout(ctx, time.Second)
+//	if err := ping(ctx); err != nil {
+//		return err // leak: cancel is never called
+//	}
+//	defer cancel()
+//
+// `defer cancel()`, a direct call, or handing cancel to other code —
+// returning it, storing it, passing it to a function — satisfies the rule.
+// A cancel function assigned to _ is reported as well; go vet's lostcancel
+// check catches that case too, and this rule words it the same way as the
+// others.
+var contextCancelRule = &Rule{
+	ID:       "CogContextCancel",
+	Doc:      "report context cancel functions that are not called on every path",
+	Run:      runContextCancel,
+	Category: CategoryConcurrency,
+	Fixable:  true,
+}
+
+// cancelArgs maps the context constructors returning a cancel function to
+// the arguments a call of that function takes.
+var cancelArgs = map[string]string{
+	"context.WithCancel":        "",
+	"context.WithTimeout":       "",
+	"context.WithDeadline":      "",
+	"context.WithCancelCause":   "nil",
+	"context.WithTimeoutCause":  "",
+	"context.WithDeadlineCause": "",
+}
+
+func runContextCancel(p *Pass) {
+	cfgs := make(map[*ast.BlockStmt]*cfg.CFG)
+	for c := range p.Inspector.Root().Preorder((*ast.AssignStmt)(nil)) {
+		assign, ok := c.Node().(*ast.AssignStmt)
+		if !ok || len(assign.Lhs) != 2 || len(assign.Rhs) != 1 {
+			continue
+		}
+		call, ok := ast.Unparen(assign.Rhs[0]).(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+		what := calleeName(p.TypesInfo, call)
+		args, ok := cancelArgs[what]
+		if !ok {
+			continue
+		}
+
+		lhs := assign.Lhs[1]
+		if isBlank(lhs) {
+			p.Report(call, "the cancel function returned by "+what+" is discarded, so the context leaks "+
+				"until its parent is done; assign it and `defer cancel("+args+")`",
+				deferCancelFix(p, c, assign, "cancel", args)...)
+			continue
+		}
+		cancel := localVar(p, lhs)
+		if cancel == nil {
+			continue // stored elsewhere: its owner calls it
+		}
+
+		_, body := enclosingFunc(c)
+		if body == nil {
+			continue
+		}
+		g, ok := cfgs[body]
+		if !ok {
+			g = funcCFG(p, body)
+			cfgs[body] = g
+		}
+		exit, leaked := findLeak(p, g, body, assign, leakCheck{
+			released: func(n ast.Node) bool { return usesVar(p, n, cancel) },
+			unheld:   func(ast.Expr) int { return -1 },
+		})
+		if !leaked {
+			continue
+		}
+		name := cancel.Name()
+		msg := "the cancel function returned by " + what + " is not called on the path to line " +
+			strconv.Itoa(p.Fset.Position(exit).Line) + ", so the context leaks until its parent is done; "
+		if late := deferredCall(p, body, cancel); late != nil {
+			p.Report(call, msg+"move the `defer "+name+"(...)` on line "+
+				strconv.Itoa(p.Fset.Position(late.Pos()).Line)+" up to right after the call")
+			continue
+		}
+		p.Report(call, msg+"add `defer "+name+"("+args+")` after the call", deferCancelFix(p, c, assign, name, args)...)
+	}
+}
+
+// deferredCall returns the first `defer v(...)` statement in body, or nil.
+func deferredCall(p *Pass, body *ast.BlockStmt, v *types.Var) *ast.DeferStmt {
+	var found *ast.DeferStmt
+	ast.Inspect(body, func(n ast.Node) bool {
+		if d, ok := n.(*ast.DeferStmt); ok && found == nil {
+			if id, ok := ast.Unparen(d.Call.Fun).(*ast.Ident); ok && p.TypesInfo.Uses[id] == v {
+				found = d
+			}
+		}
+		return found == nil
+	})
+	return found
+}
+
+// usesVar reports whether n reads v: a call of v, or any use that hands it
+// to other code. Comparisons such as `cancel != nil` do not count.
+func usesVar(p *Pass, n ast.Node, v *types.Var) bool {
+	cur, ok := p.Inspector.Root().FindNode(n)
+	if !ok {
+		return false
+	}
+	for ic := range cur.Preorder((*ast.Ident)(nil)) {
+		id, ok := ic.Node().(*ast.Ident)
+		if !ok || p.TypesInfo.Uses[id] != v {
+			continue
+		}
+		switch pn := ic.Parent().Node().(type) {
+		case *ast.BinaryExpr:
+		case *ast.AssignStmt:
+			if !isLHS(pn, id) {
+				return true
+			}
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// deferCancelFix inserts `defer name(args)` after the assignment, naming a
+// discarded cancel function first. It offers nothing inside a loop, where
+// the deferred calls would pile up until the function returns.
+func deferCancelFix(p *Pass, c inspector.Cursor, assign *ast.AssignStmt, name, args string) []analysis.SuggestedFix {
+	if !inStatementList(c) || inLoopBody(c) {
+		return nil
+	}
+	edits := make([]analysis.TextEdit, 0, 2)
+	if lhs := assign.Lhs[1]; isBlank(lhs) {
+		scope := p.Pkg.Scope().Innermost(assign.Pos())
+		if assign.Tok != token.DEFINE || scope == nil || scope.Lookup(name) != nil {
+			return nil
+		}
+		edits = append(edits, analysis.TextEdit{Pos: lhs.Pos(), End: lhs.End(), NewText: []byte(name)})
+	}
+	indent, eol := lineIndent(p, assign.Pos()), lineEnd(p, assign.End())
+	edits = append(edits, analysis.TextEdit{
+		Pos:     eol,
+		End:     eol,
+		NewText: []byte("\n" + indent + "defer " + name + "(" + args + ")"),
+	})
+	return []analysis.SuggestedFix{{Message: "Defer the cancel call", TextEdits: edits}}
+}
@@ -0,0 +1,11 @@
+package cog
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestContextCancel(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), ruleAnalyzer(contextCancelRule), "contextcancel")
+}
@@ -0,0 +1,212 @@
+package cog
+
+import (
+	"go/ast"
+	"go/types"
+	"slices"
+	"strconv"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// contextFirstRule reports a function declaration whose context.Context
+// parameter is not the first one, as Go convention requires.
+//
+//	func fetch(url string, ctx context.Context) error // ctx must come first
+//
+// With -contextfirst.missing it also reports functions that take no context
+// at all but call a blocking operation listed in -contextfirst.blocking.
+// Functions receiving an *http.Request, which carries its own context, are
+// exempt, as are main and init.
+var contextFirstRule = &Rule{
+	ID:       "CogContextFirst",
+	Doc:      "report context.Context parameters that are not first, and optionally missing ones",
+	Run:      runContextFirst,
+	Category: CategoryStyle,
+	Fixable:  true,
+}
+
+var (
+	// contextFirstMissing reports blocking functions without a context.
+	contextFirstMissing bool
+
+	// contextFirstBlocking lists the calls treated as blocking.
+	contextFirstBlocking = listFlag{
+		"net/http.Get",
+		"net/http.Head",
+		"net/http.Post",
+		"net/http.PostForm",
+		"(*net/http.Client).Do",
+		"(*net/http.Client).Get",
+		"(*net/http.Client).Head",
+		"(*net/http.Client).Post",
+		"(*net/http.Client).PostForm",
+		"(*database/sql.DB).Begin",
+		"(*database/sql.DB).Exec",
+		"(*database/sql.DB).Ping",
+		"(*database/sql.DB).Prepare",
+		"(*database/sql.DB).Query",
+		"(*database/sql.DB).QueryRow",
+		"(*database/sql.Tx).Exec",
+		"(*database/sql.Tx).Query",
+		"(*database/sql.Tx).QueryRow",
+		"net.Dial",
+		"net.DialTimeout",
+		"(*os/exec.Cmd).CombinedOutput",
+		"(*os/exec.Cmd).Output",
+		"(*os/exec.Cmd).Run",
+		"time.Sleep",
+	}
+)
+
+func init() {
+	Analyzer.Flags.BoolVar(&contextFirstMissing, "contextfirst.missing", false,
+		"also report functions that call a blocking operation but take no context.Context")
+	Analyzer.Flags.Var(&contextFirstBlocking, "contextfirst.blocking",
+		"comma-separated calls treated as blocking by -contextfirst.missing, e.g. net/http.Get,(*database/sql.DB).Query")
+}
+
+func runContextFirst(p *Pass) {
+	for c := range p.Inspector.Root().Preorder((*ast.FuncDecl)(nil)) {
+		decl, ok := c.Node().(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		field, index := contextParam(p, decl.Type)
+		switch {
+		case field != nil && index > 0:
+			name := "context.Context"
+			if len(field.Names) > 0 {
+				name = field.Names[0].Name + " " + name
+			}
+			var fixes []analysis.SuggestedFix
+			if fix, ok := contextFirstFix(p, decl, field, index); ok {
+				fixes = append(fixes, fix)
+			}
+			p.Report(field, name+" is parameter "+strconv.Itoa(index+1)+" of "+funcDeclName(decl)+
+				"; by convention the context comes first", fixes...)
+
+		case field == nil && contextFirstMissing && decl.Body != nil && !contextExempt(p, decl):
+			if call := blockingCall(p, c); call != nil {
+				p.Report(decl.Name, funcDeclName(decl)+" calls "+types.ExprString(call.Fun)+
+					", which can block, but takes no context.Context; accept ctx as the first parameter and pass it on")
+			}
+		}
+	}
+}
+
+// contextParam returns the field declaring the first context.Context
+// parameter of ft and its position among the parameters, or nil.
+func contextParam(p *Pass, ft *ast.FuncType) (*ast.Field, int) {
+	index := 0
+	for _, field := range ft.Params.List {
+		if isContextType(p.TypesInfo.TypeOf(field.Type)) {
+			return field, index
+		}
+		index += max(len(field.Names), 1)
+	}
+	return nil, -1
+}
+
+// isContextType reports whether t is context.Context.
+func isContextType(t types.Type) bool { return isNamed(t, "context", "Context") }
+
+// contextExempt reports whether decl needs no context of its own: main,
+// init, and HTTP handlers, whose request carries one.
+func contextExempt(p *Pass, decl *ast.FuncDecl) bool {
+	if decl.Recv == nil && (decl.Name.Name == "init" || (decl.Name.Name == "main" && p.Pkg.Name() == "main")) {
+		return true
+	}
+	for _, field := range decl.Type.Params.List {
+		if t := p.TypesInfo.TypeOf(field.Type); t != nil && isNamedPointer(t, "net/http", "Request") {
+			return true
+		}
+	}
+	return false
+}
+
+// blockingCall returns the first call under c listed in
+// -contextfirst.blocking, or nil.
+func blockingCall(p *Pass, c inspector.Cursor) *ast.CallExpr {
+	for cc := range c.Preorder((*ast.CallExpr)(nil)) {
+		call, ok := cc.Node().(*ast.CallExpr)
+		if ok && contextFirstBlocking.contains(calleeName(p.TypesInfo, call)) {
+			return call
+		}
+	}
+	return nil
+}
+
+// contextFirstFix moves the context parameter of decl to the front and
+// reorders the arguments of every call in the package to match. It is only
+// offered for unexported functions, whose callers are all in the package,
+// and only when every use of the function is a direct call.
+func contextFirstFix(p *Pass, decl *ast.FuncDecl, field *ast.Field, index int) (analysis.SuggestedFix, bool) {
+	fn, ok := p.TypesInfo.Defs[decl.Name].(*types.Func)
+	if !ok || fn.Exported() || decl.Recv != nil || len(field.Names) > 1 {
+		return analysis.SuggestedFix{}, false
+	}
+	params := decl.Type.Params.List
+	fi := slices.Index(params, field)
+	text, ok := sourceText(p, field)
+	if !ok || fi <= 0 {
+		return analysis.SuggestedFix{}, false
+	}
+	edits := []analysis.TextEdit{
+		{Pos: params[0].Pos(), End: params[0].Pos(), NewText: []byte(text + ", ")},
+		{Pos: params[fi-1].End(), End: field.End()},
+	}
+
+	for id, obj := range p.TypesInfo.Uses {
+		if obj != fn {
+			continue
+		}
+		call, ok := directCall(p, id)
+		if !ok || call.Ellipsis.IsValid() || len(call.Args) <= index {
+			return analysis.SuggestedFix{}, false
+		}
+		arg, ok := sourceText(p, call.Args[index])
+		if !ok {
+			return analysis.SuggestedFix{}, false
+		}
+		edits = append(edits,
+			analysis.TextEdit{Pos: call.Args[0].Pos(), End: call.Args[0].Pos(), NewText: []byte(arg + ", ")},
+			analysis.TextEdit{Pos: call.Args[index-1].End(), End: call.Args[index].End()})
+	}
+
+	// A call nested in another call's moved argument would need both edits
+	// to apply to the same text.
+	slices.SortFunc(edits, func(a, b analysis.TextEdit) int { return int(a.Pos - b.Pos) })
+	for i := 1; i < len(edits); i++ {
+		if edits[i].Pos < edits[i-1].End {
+			return analysis.SuggestedFix{}, false
+		}
+	}
+	return analysis.SuggestedFix{
+		Message:   "Move " + text + " to the front",
+		TextEdits: edits,
+	}, true
+}
+
+// directCall returns the call whose callee is id, possibly instantiated
+// with explicit type arguments.
+func directCall(p *Pass, id *ast.Ident) (*ast.CallExpr, bool) {
+	cur, ok := p.Inspector.Root().FindNode(id)
+	if !ok {
+		return nil, false
+	}
+	var callee ast.Node = id
+	parent := cur.Parent()
+	for {
+		switch n := parent.Node().(type) {
+		case *ast.IndexExpr, *ast.IndexListExpr, *ast.ParenExpr:
+			callee = n
+			parent = parent.Parent()
+			continue
+		case *ast.CallExpr:
+			return n, n.Fun == callee
+		}
+		return nil, false
+	}
+}
@@ -0,0 +1,18 @@
+package cog
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestContextFirst(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), ruleAnalyzer(contextFirstRule), "contextfirst")
+}
+
+func TestContextFirstMissing(t *testing.T) {
+	saved := contextFirstMissing
+	t.Cleanup(func() { contextFirstMissing = saved })
+	contextFirstMissing = true
+	analysistest.Run(t, analysistest.TestData(), ruleAnalyzer(contextFirstRule), "contextfirstmissing")
+}
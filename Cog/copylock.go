@@ -0,0 +1,211 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// copyLockRule reports copies of values that contain a lock: a field whose
+// address implements sync.Locker, such as a sync.Mutex or sync.RWMutex,
+// directly or nested in struct and array fields. The copy has its own lock,
+// so code holding one excludes nothing that uses the other.
+//
+//	type Counter struct {
+//		sync.Mutex
+//		n int
+//	}
+//
+//	func (c Counter) Inc() { c.Lock(); c.n++; c.Unlock() } // locks a copy
+//
+// Copies are reported at value receivers and parameters, assignments and
+// declarations, call and append arguments, range values and returns. The
+// arguments of len, cap and unsafe.Sizeof, Alignof and Offsetof are only
+// measured, so they are not reported.
+// Pointers to locks and interfaces holding them are shared, not copied, and
+// composite literals and call results are fresh values, so none of those is
+// reported.
+var copyLockRule = &Rule{
+	ID:       "CogCopyLock",
+	Doc:      "report copies of values containing a sync.Mutex or other lock",
+	Run:      runCopyLock,
+	Category: CategoryConcurrency,
+}
+
+// lockerInterface is the method set of sync.Locker.
+var lockerInterface = types.NewInterfaceType([]*types.Func{
+	types.NewFunc(token.NoPos, nil, "Lock", types.NewSignatureType(nil, nil, nil, nil, nil, false)),
+	types.NewFunc(token.NoPos, nil, "Unlock", types.NewSignatureType(nil, nil, nil, nil, nil, false)),
+}, nil).Complete()
+
+func runCopyLock(p *Pass) {
+	filter := []ast.Node{
+		(*ast.FuncDecl)(nil), (*ast.FuncLit)(nil), (*ast.AssignStmt)(nil), (*ast.ValueSpec)(nil),
+		(*ast.CallExpr)(nil), (*ast.RangeStmt)(nil), (*ast.ReturnStmt)(nil),
+	}
+	for c := range p.Inspector.Root().Preorder(filter...) {
+		switch n := c.Node().(type) {
+		case *ast.FuncDecl:
+			if n.Recv != nil {
+				checkLockFields(p, n.Recv, "receiver")
+			}
+			checkLockFields(p, n.Type.Params, "parameter")
+		case *ast.FuncLit:
+			checkLockFields(p, n.Type.Params, "parameter")
+		case *ast.AssignStmt:
+			if n.Tok == token.ASSIGN || n.Tok == token.DEFINE {
+				for _, rhs := range n.Rhs {
+					checkLockCopy(p, rhs, "assignment")
+				}
+			}
+		case *ast.ValueSpec:
+			for _, v := range n.Values {
+				checkLockCopy(p, v, "declaration")
+			}
+		case *ast.CallExpr:
+			if tv, ok := p.TypesInfo.Types[n.Fun]; ok && tv.IsType() {
+				continue // a conversion is reported where its result is copied
+			}
+			if measuresOnly(p, n.Fun) {
+				continue
+			}
+			for _, arg := range n.Args {
+				checkLockCopy(p, arg, "call of "+types.ExprString(n.Fun))
+			}
+		case *ast.RangeStmt:
+			if n.Value == nil {
+				continue
+			}
+			if path, ok := lockPath(p.TypesInfo.TypeOf(n.Value)); ok {
+				p.Report(n.Value, "range value "+types.ExprString(n.Value)+" copies each element, "+path+"; range over indexes or pointers instead")
+			}
+		case *ast.ReturnStmt:
+			for _, res := range n.Results {
+				checkLockCopy(p, res, "return")
+			}
+		}
+	}
+}
+
+// measuresOnly reports whether fun is a builtin that reads only the type or
+// length of its arguments, such as len or unsafe.Sizeof, so that a call of
+// it copies nothing.
+func measuresOnly(p *Pass, fun ast.Expr) bool {
+	var id *ast.Ident
+	switch f := ast.Unparen(fun).(type) {
+	case *ast.Ident:
+		id = f
+	case *ast.SelectorExpr:
+		id = f.Sel
+	default:
+		return false
+	}
+	b, ok := p.TypesInfo.Uses[id].(*types.Builtin)
+	if !ok {
+		return false
+	}
+	switch b.Name() {
+	case "len", "cap", "Sizeof", "Alignof", "Offsetof":
+		return true
+	}
+	return false
+}
+
+// checkLockFields reports receivers or parameters passed by value with a
+// lock inside.
+func checkLockFields(p *Pass, fields *ast.FieldList, kind string) {
+	for _, field := range fields.List {
+		path, ok := lockPath(p.TypesInfo.TypeOf(field.Type))
+		if !ok {
+			continue
+		}
+		name := types.ExprString(field.Type)
+		if len(field.Names) > 0 {
+			name = field.Names[0].Name
+		}
+		p.Report(field, kind+" "+name+" is passed by value, "+path+"; use a pointer")
+	}
+}
+
+// checkLockCopy reports e when evaluating it copies an existing value that
+// contains a lock.
+func checkLockCopy(p *Pass, e ast.Expr, context string) {
+	switch ast.Unparen(e).(type) {
+	case *ast.Ident, *ast.SelectorExpr, *ast.IndexExpr, *ast.StarExpr:
+	default:
+		return // composite literals, calls and &x make no copy of a live lock
+	}
+	if id, ok := ast.Unparen(e).(*ast.Ident); ok && isNilIdent(p, id) {
+		return
+	}
+	tv, ok := p.TypesInfo.Types[e]
+	if !ok || !tv.IsValue() {
+		return
+	}
+	if sel, ok := ast.Unparen(e).(*ast.SelectorExpr); ok {
+		if s, ok := p.TypesInfo.Selections[sel]; ok && s.Kind() != types.FieldVal {
+			return // a method value
+		}
+	}
+	if path, ok := lockPath(tv.Type); ok {
+		p.Report(e, context+" copies "+types.ExprString(e)+", "+path+"; share it through a pointer")
+	}
+}
+
+// lockPath reports whether a value of type t contains a lock, describing
+// where: "which is a sync.Mutex" or "whose field mu is a sync.Mutex".
+func lockPath(t types.Type) (string, bool) {
+	if t == nil {
+		return "", false
+	}
+	fields, lock, ok := lockFields(t)
+	switch {
+	case !ok:
+		return "", false
+	case len(fields) > 0:
+		return "whose field " + strings.Join(fields, ".") + " is a " + types.TypeString(lock, nil), true
+	case types.Identical(lock, t):
+		return "which is a " + types.TypeString(lock, nil), true
+	}
+	return "which contains a " + types.TypeString(lock, nil), true
+}
+
+// lockFields returns the first lock held by value in t, and the path of
+// struct fields leading to it; array elements add nothing to the path.
+func lockFields(t types.Type) ([]string, types.Type, bool) {
+	if isLock(t) {
+		return nil, t, true
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Struct:
+		for i := range u.NumFields() {
+			f := u.Field(i)
+			if path, lock, ok := lockFields(f.Type()); ok {
+				return append([]string{f.Name()}, path...), lock, true
+			}
+		}
+	case *types.Array:
+		return lockFields(u.Elem())
+	}
+	return nil, nil, false
+}
+
+// isLock reports whether t is a lock held by value: not a pointer or
+// interface, with Lock and Unlock declared on *t itself rather than promoted
+// from an embedded field, which lockFields reports instead.
+func isLock(t types.Type) bool {
+	switch t.Underlying().(type) {
+	case *types.Pointer, *types.Interface:
+		return false
+	}
+	if _, ok := types.Unalias(t).(*types.TypeParam); ok {
+		return false
+	}
+	ptr := types.NewPointer(t)
+	if !types.Implements(ptr, lockerInterface) {
+		return false
+	}
+	_, index, _ := types.LookupFieldOrMethod(ptr, false, nil, "Lock")
+	return len(index) == 1
+}
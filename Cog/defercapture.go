@@ -0,0 +1,76 @@
+package cog
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+)
+
+// deferCaptureRule reports a deferred closure inside a loop that refers to
+// the loop variable instead of receiving it as an argument.
+//
+//	for i := 0; i < n; i++ {
+//		defer func() {
+//			release(i) // before Go 1.22 every call releases n
+//		}()
+//	}
+//
+// Unlike a goroutine, a deferred closure runs only when the function
+// returns, long after the loop has finished, so before Go 1.22 every one of
+// them reads the variable's final value. Arguments of a deferred call are
+// evaluated when the defer statement runs, which is why the fix passes each
+// captured variable as an argument of the same name:
+//
+//	defer func(i int) { release(i) }(i)
+//
+// As for CogLoopCapture, the rule only fires for files whose language
+// version, set by the module's go directive, is older than Go 1.22, unless
+// -defercapture.strict asks for the explicit-argument style everywhere.
+var deferCaptureRule = &Rule{
+	ID:       "CogDeferCapture",
+	Doc:      "report deferred closures that capture loop variables",
+	Run:      runDeferCapture,
+	Category: CategoryConcurrency,
+	Fixable:  true,
+}
+
+// deferCaptureStrict reports captures regardless of the Go version.
+var deferCaptureStrict bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&deferCaptureStrict, "defercapture.strict", false,
+		"report loop variables captured by deferred closures even when the file targets Go 1.22 or later")
+}
+
+func runDeferCapture(p *Pass) {
+	for c := range p.Inspector.Root().Preorder((*ast.DeferStmt)(nil)) {
+		d, ok := c.Node().(*ast.DeferStmt)
+		if !ok {
+			continue
+		}
+		lit, ok := d.Call.Fun.(*ast.FuncLit)
+		if !ok {
+			continue
+		}
+		file := enclosingFile(c)
+		if !deferCaptureStrict && perIterationLoopVars(p, file) {
+			continue
+		}
+
+		captured := capturedLoopVars(p, c, lit)
+		if len(captured) == 0 {
+			continue
+		}
+		names := make([]string, 0, len(captured))
+		params := make([]string, 0, len(captured))
+		for _, v := range captured {
+			names = append(names, v.Name())
+			params = append(params, v.Name()+" "+types.TypeString(v.Type(), types.RelativeTo(p.Pkg)))
+		}
+		list := strings.Join(names, ", ")
+		p.Report(d, "deferred closure reads loop variable "+list+" when the function returns, after the loop "+
+			"has ended; before Go 1.22 every deferred call sees the last value, so pass it as an argument: "+
+			"`defer func("+strings.Join(params, ", ")+") { ... }("+list+")`",
+			loopCaptureFix(p, file, d.Call, lit, captured)...)
+	}
+}
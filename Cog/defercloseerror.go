@@ -0,0 +1,242 @@
+package cog
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strconv"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// deferCloseErrorRule reports `defer f.Close()` on a file opened for
+// writing, which drops the error of the Close.
+//
+//	f, err := os.Create(path)
+//	if err != nil {
+//		return err
+//	}
+//	defer f.Close() // a failed flush to disk goes unnoticed
+//	_, err = f.Write(data)
+//	return err
+//
+// For a written file, Close is where delayed write errors surface, so
+// ignoring it can lose data silently. Files from os.Create, os.CreateTemp
+// and os.OpenFile with a constant flag including os.O_WRONLY or os.O_RDWR
+// are reported; read-only files are not, and neither is a file the function
+// also closes explicitly, checking the error. When the function returns an
+// error, the fix replaces the defer with one that returns the Close error
+// through the error result, naming the results if needed.
+var deferCloseErrorRule = &Rule{
+	ID:       "CogDeferCloseError",
+	Doc:      "report deferred Close calls that drop the error of a file opened for writing",
+	Run:      runDeferCloseError,
+	Category: CategoryErrors,
+	Fixable:  true,
+}
+
+func runDeferCloseError(p *Pass) {
+	for c := range p.Inspector.Root().Preorder((*ast.DeferStmt)(nil)) {
+		d, ok := c.Node().(*ast.DeferStmt)
+		if !ok || len(d.Call.Args) != 0 {
+			continue
+		}
+		sel, ok := ast.Unparen(d.Call.Fun).(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "Close" {
+			continue
+		}
+		f := localVar(p, sel.X)
+		_, body := enclosingFunc(c)
+		if f == nil || body == nil {
+			continue
+		}
+		open := writeOpen(p, body, f, d.Pos())
+		if open == nil || closeChecked(p, body, f) {
+			continue
+		}
+		name := f.Name()
+		p.Report(d, "defer "+name+".Close() drops the error of closing "+name+", opened for writing by "+
+			types.ExprString(open.Fun)+" on line "+strconv.Itoa(p.Fset.Position(open.Pos()).Line)+
+			", so a failed write can go unnoticed; return it through a named error result: `defer func() { "+
+			"if cerr := "+name+".Close(); cerr != nil && err == nil { err = cerr } }()`",
+			closeErrorFix(p, c, d, name)...)
+	}
+}
+
+// writeOpen returns the call that last assigned f before pos in body when
+// it opens a file for writing, or nil.
+func writeOpen(p *Pass, body *ast.BlockStmt, f *types.Var, pos token.Pos) *ast.CallExpr {
+	var open *ast.CallExpr
+	ast.Inspect(body, func(n ast.Node) bool {
+		assign, ok := n.(*ast.AssignStmt)
+		if !ok || assign.Pos() >= pos || len(assign.Rhs) != 1 || len(assign.Lhs) == 0 {
+			return n == nil || n.Pos() < pos
+		}
+		if id, ok := assign.Lhs[0].(*ast.Ident); !ok || p.TypesInfo.ObjectOf(id) != f {
+			return true
+		}
+		open = nil
+		if call, ok := ast.Unparen(assign.Rhs[0]).(*ast.CallExpr); ok && opensForWriting(p, call) {
+			open = call
+		}
+		return true
+	})
+	return open
+}
+
+// opensForWriting reports whether call opens a file that can be written.
+func opensForWriting(p *Pass, call *ast.CallExpr) bool {
+	fn, ok := typeutil.Callee(p.TypesInfo, call).(*types.Func)
+	if !ok {
+		return false
+	}
+	switch fn.FullName() {
+	case "os.Create", "os.CreateTemp":
+		return true
+	case "os.OpenFile":
+		if len(call.Args) != 3 {
+			return false
+		}
+		flag := p.TypesInfo.Types[call.Args[1]].Value
+		if flag == nil {
+			return false // not known statically
+		}
+		for _, mode := range []string{"O_WRONLY", "O_RDWR"} {
+			c, ok := fn.Pkg().Scope().Lookup(mode).(*types.Const)
+			if ok && constant.Sign(constant.BinaryOp(flag, token.AND, c.Val())) != 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// closeChecked reports whether body calls f.Close outside a defer statement
+// and uses its result.
+func closeChecked(p *Pass, body *ast.BlockStmt, f *types.Var) bool {
+	cur, ok := p.Inspector.Root().FindNode(body)
+	if !ok {
+		return false
+	}
+	for cc := range cur.Preorder((*ast.CallExpr)(nil)) {
+		call, ok := cc.Node().(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+		sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "Close" || localVar(p, sel.X) != f {
+			continue
+		}
+		switch cc.Parent().Node().(type) {
+		case *ast.ExprStmt, *ast.DeferStmt, *ast.GoStmt:
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// closeErrorFix replaces the defer statement d closing f with one that
+// assigns the Close error to the function's error result. An unnamed error
+// result is named err, and the other results _, unless that would
+// invalidate a declaration of err at the function's top level.
+func closeErrorFix(p *Pass, c inspector.Cursor, d *ast.DeferStmt, f string) []analysis.SuggestedFix {
+	ft, body := enclosingFunc(c)
+	if ft == nil || ft.Results == nil || len(ft.Results.List) == 0 {
+		return nil
+	}
+	last := ft.Results.List[len(ft.Results.List)-1]
+	if !isErrorType(p.TypesInfo.TypeOf(last.Type)) {
+		return nil
+	}
+	scope := p.TypesInfo.Scopes[ft]
+	inner := p.Pkg.Scope().Innermost(d.Pos())
+	if scope == nil || inner == nil {
+		return nil
+	}
+	edits := make([]analysis.TextEdit, 0, len(ft.Results.List)+1)
+	errName := "err"
+	if len(last.Names) > 0 {
+		errName = last.Names[len(last.Names)-1].Name
+	} else {
+		if obj := scope.Lookup(errName); obj != nil && (!isErrorType(obj.Type()) || !redeclarable(p, body, obj)) {
+			return nil
+		}
+		named, ok := nameResults(p, ft)
+		if !ok {
+			return nil
+		}
+		edits = append(edits, named...)
+	}
+	if _, obj := inner.LookupParent(errName, d.Pos()); errName == "_" || obj != scope.Lookup(errName) {
+		return nil // the result is shadowed where the defer runs
+	}
+	cerr := "cerr"
+	if errName == cerr {
+		cerr = "closeErr"
+	}
+	indent := lineIndent(p, d.Pos())
+	edits = append(edits, analysis.TextEdit{
+		Pos: d.Pos(),
+		End: d.End(),
+		NewText: []byte("defer func() {\n" +
+			indent + "\tif " + cerr + " := " + f + ".Close(); " + cerr + " != nil && " + errName + " == nil {\n" +
+			indent + "\t\t" + errName + " = " + cerr + "\n" +
+			indent + "\t}\n" +
+			indent + "}()"),
+	})
+	return []analysis.SuggestedFix{{Message: "Return the Close error through the error result", TextEdits: edits}}
+}
+
+// redeclarable reports whether obj, declared at the top level of body, is
+// declared by a `:=` that also declares another variable, so that it keeps
+// compiling, as an assignment, once a result of the same name exists.
+func redeclarable(p *Pass, body *ast.BlockStmt, obj types.Object) bool {
+	for _, stmt := range body.List {
+		assign, ok := stmt.(*ast.AssignStmt)
+		if !ok || assign.Tok != token.DEFINE {
+			continue
+		}
+		declares, others := false, false
+		for _, lhs := range assign.Lhs {
+			id, ok := lhs.(*ast.Ident)
+			if !ok || p.TypesInfo.Defs[id] == nil {
+				continue
+			}
+			if p.TypesInfo.Defs[id] == obj {
+				declares = true
+			} else {
+				others = true
+			}
+		}
+		if declares {
+			return others
+		}
+	}
+	return false
+}
+
+// nameResults names the unnamed results of ft: err for the last one, _ for
+// the others.
+func nameResults(p *Pass, ft *ast.FuncType) ([]analysis.TextEdit, bool) {
+	fields := ft.Results.List
+	if !ft.Results.Opening.IsValid() {
+		text, ok := sourceText(p, fields[0].Type)
+		if !ok {
+			return nil, false
+		}
+		return []analysis.TextEdit{{Pos: fields[0].Pos(), End: fields[0].End(), NewText: []byte("(err " + text + ")")}}, true
+	}
+	edits := make([]analysis.TextEdit, 0, len(fields))
+	for i, field := range fields {
+		name := "_ "
+		if i == len(fields)-1 {
+			name = "err "
+		}
+		edits = append(edits, analysis.TextEdit{Pos: field.Pos(), End: field.Pos(), NewText: []byte(name)})
+	}
+	return edits, true
+}
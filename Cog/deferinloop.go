@@ -0,0 +1,97 @@
+package cog
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// deferInLoopRule reports a cleanup deferred inside a loop body. Deferred
+// calls run when the function returns, not at the end of the iteration, so
+// every iteration's file, lock or context stays held until the loop is
+// done.
+//
+//	for _, p := range paths {
+//		f, err := os.Open(p)
+//		...
+//		defer f.Close() // all files stay open until the function returns
+//	}
+//
+// Only cleanups are reported: Close, Unlock, RUnlock and Stop methods, and
+// context.CancelFunc calls, including those inside a deferred closure.
+var deferInLoopRule = &Rule{
+	ID:       "CogDeferInLoop",
+	Doc:      "report resource cleanups deferred inside loops",
+	Run:      runDeferInLoop,
+	Category: CategoryPerformance,
+}
+
+func runDeferInLoop(p *Pass) {
+	for c := range p.Inspector.Root().Preorder((*ast.DeferStmt)(nil)) {
+		stmt, ok := c.Node().(*ast.DeferStmt)
+		if !ok || !inLoopBody(c) {
+			continue
+		}
+		cleanup := cleanupCall(p, stmt.Call)
+		if cleanup == nil {
+			continue
+		}
+		what := types.ExprString(cleanup.Fun) + "()"
+		p.Report(stmt, "defer "+what+" in a loop runs only when the function returns, so every iteration's resource "+
+			"is held until then; move the loop body into a helper function, or call "+what+" at the end of each iteration")
+	}
+}
+
+// inLoopBody reports whether c is inside a for or range loop of its own
+// function.
+func inLoopBody(c inspector.Cursor) bool {
+	for lc := range c.Enclosing((*ast.ForStmt)(nil), (*ast.RangeStmt)(nil), (*ast.FuncLit)(nil), (*ast.FuncDecl)(nil)) {
+		switch lc.Node().(type) {
+		case *ast.ForStmt, *ast.RangeStmt:
+			return true
+		}
+		return false
+	}
+	return false
+}
+
+// cleanupCall returns call when it releases a resource, or the releasing
+// call inside it when call invokes a function literal; otherwise nil.
+func cleanupCall(p *Pass, call *ast.CallExpr) *ast.CallExpr {
+	if lit, ok := ast.Unparen(call.Fun).(*ast.FuncLit); ok {
+		var found *ast.CallExpr
+		ast.Inspect(lit.Body, func(n ast.Node) bool {
+			if inner, ok := n.(*ast.CallExpr); ok && found == nil && isCleanup(p, inner) {
+				found = inner
+			}
+			_, nested := n.(*ast.FuncLit)
+			return found == nil && !nested
+		})
+		return found
+	}
+	if isCleanup(p, call) {
+		return call
+	}
+	return nil
+}
+
+// isCleanup reports whether call is a Close, Unlock, RUnlock or Stop method
+// call, or calls a context.CancelFunc.
+func isCleanup(p *Pass, call *ast.CallExpr) bool {
+	if t := p.TypesInfo.TypeOf(call.Fun); t != nil && isNamed(t, "context", "CancelFunc") {
+		return true
+	}
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	if _, method := p.TypesInfo.Selections[sel]; !method {
+		return false
+	}
+	switch sel.Sel.Name {
+	case "Close", "Unlock", "RUnlock", "Stop":
+		return true
+	}
+	return false
+}
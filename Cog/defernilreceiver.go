@@ -0,0 +1,131 @@
+package cog
+
+import (
+	"go/ast"
+	"go/types"
+	"strconv"
+)
+
+// deferNilReceiverRule reports a method call deferred on a value before the
+// error returned alongside it is checked.
+//
+//	f, err := os.Open(name)
+//	defer f.Close() // f is nil when Open fails: Close runs on a nil *os.File
+//	if err != nil {
+//		return err
+//	}
+//
+// By convention a function returning an error returns a nil pointer,
+// interface, map, channel or function with it, so a method deferred on
+// that value before the check runs on a nil receiver when the call failed.
+// What that does depends on the method: (*os.File).Close returns
+// os.ErrInvalid, which the defer discards, while a method of a nil
+// interface, a value method of a nil pointer, or a defer that reads a field
+// such as resp.Body panics. The rule fires when `x, err := f()` (or =) is
+// followed in the same block by `defer x.M(...)` or `defer x.f.M(...)` with
+// no statement in between that mentions err. Any use of err, such as an if,
+// counts as the check, which keeps the rule conservative.
+var deferNilReceiverRule = &Rule{
+	ID:       "CogDeferNilReceiver",
+	Doc:      "report methods deferred on a value before its error is checked",
+	Run:      runDeferNilReceiver,
+	Category: CategoryErrors,
+}
+
+func runDeferNilReceiver(p *Pass) {
+	for c := range p.Inspector.Root().Preorder((*ast.AssignStmt)(nil)) {
+		acq, ok := acquisition(p, c, isNilable)
+		if !ok || acq.resource == nil || acq.err == nil {
+			continue
+		}
+		for _, stmt := range followingStmts(c) {
+			if refersTo(p, stmt, acq.err) {
+				break
+			}
+			d, ok := stmt.(*ast.DeferStmt)
+			if !ok || !deferredOn(p, d.Call, acq.resource) {
+				continue
+			}
+			x, what := acq.resource.Name(), types.ExprString(acq.call.Fun)
+			effect := "the defer calls " + ast.Unparen(d.Call.Fun).(*ast.SelectorExpr).Sel.Name + " on a nil receiver"
+			if deferPanics(p, d.Call) {
+				effect = "the deferred call panics"
+			}
+			p.Report(d, "defer "+types.ExprString(d.Call)+" runs on "+x+" before "+acq.err.Name()+
+				" from "+what+" on line "+strconv.Itoa(p.Fset.Position(acq.call.Pos()).Line)+" is checked; when "+
+				what+" fails "+x+" is nil and "+effect+"; check "+acq.err.Name()+" first, then defer")
+			break
+		}
+	}
+}
+
+// refersTo reports whether n refers to v in any way, a comparison such as
+// `err != nil` included.
+func refersTo(p *Pass, n ast.Node, v *types.Var) bool {
+	found := false
+	ast.Inspect(n, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && p.TypesInfo.Uses[id] == v {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// isNilable reports whether a value of type t can be nil and has methods
+// or fields to call through: a pointer, interface, map, channel or
+// function, but not an error.
+func isNilable(t types.Type) bool {
+	if isErrorType(t) {
+		return false
+	}
+	switch t.Underlying().(type) {
+	case *types.Pointer, *types.Interface, *types.Map, *types.Chan, *types.Signature:
+		return true
+	}
+	return false
+}
+
+// deferredOn reports whether call is a method call whose receiver is v,
+// or a field of v: v.M() or v.f.M().
+func deferredOn(p *Pass, call *ast.CallExpr, v *types.Var) bool {
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	x := sel.X
+	for {
+		switch e := ast.Unparen(x).(type) {
+		case *ast.Ident:
+			return p.TypesInfo.Uses[e] == v
+		case *ast.SelectorExpr:
+			x = e.X
+		default:
+			return false
+		}
+	}
+}
+
+// deferPanics reports whether call, deferred by deferredOn on a nil value,
+// panics whatever its method does: when it reads a field through the value,
+// including an embedded one, calls a method of a nil interface or
+// dereferences a nil pointer for a value receiver.
+func deferPanics(p *Pass, call *ast.CallExpr) bool {
+	sel := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if _, ok := ast.Unparen(sel.X).(*ast.Ident); !ok {
+		return true // x.f.M() reads x.f
+	}
+	if types.IsInterface(p.TypesInfo.TypeOf(sel.X)) {
+		return true
+	}
+	s, ok := p.TypesInfo.Selections[sel]
+	if !ok || s.Kind() != types.MethodVal {
+		return true // a func-valued field or a nil map, channel or func
+	}
+	if len(s.Index()) > 1 {
+		return true // a method promoted from an embedded field
+	}
+	_, recvPtr := s.Obj().Type().(*types.Signature).Recv().Type().Underlying().(*types.Pointer)
+	_, xPtr := s.Recv().Underlying().(*types.Pointer)
+	return xPtr && !recvPtr
+}
@@ -0,0 +1,11 @@
+package cog
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestDeferNilReceiver(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), ruleAnalyzer(deferNilReceiverRule), "defernilreceiver")
+}
@@ -0,0 +1,145 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// errorsIsRule reports error checks that stop working once the error is
+// wrapped with %w: comparing with a sentinel using == or != instead of
+// errors.Is, and type assertions or type switches on an error instead of
+// errors.As.
+//
+//	if err == sql.ErrNoRows {         // false for fmt.Errorf("find: %w", sql.ErrNoRows)
+//	if e, ok := err.(*NotFound); ok { // likewise for a wrapped *NotFound
+//
+// A sentinel is a package-level variable whose type implements error.
+// Comparisons with nil are never reported, nor is anything inside an Is or
+// As method, where comparing the target directly is the contract.
+var errorsIsRule = &Rule{
+	ID:       "CogErrorsIs",
+	Doc:      "report == comparisons and type assertions on errors that should use errors.Is or errors.As",
+	Run:      runErrorsIs,
+	Category: CategoryErrors,
+	Fixable:  true,
+}
+
+func runErrorsIs(p *Pass) {
+	filter := []ast.Node{(*ast.BinaryExpr)(nil), (*ast.TypeAssertExpr)(nil), (*ast.SwitchStmt)(nil)}
+	for c := range p.Inspector.Root().Preorder(filter...) {
+		if inErrorMatcher(p, c) {
+			continue
+		}
+		switch n := c.Node().(type) {
+		case *ast.BinaryExpr:
+			if n.Op != token.EQL && n.Op != token.NEQ {
+				continue
+			}
+			err, sentinel := n.X, n.Y
+			if isSentinel(p, err) {
+				err, sentinel = sentinel, err
+			}
+			if !isErrorType(p.TypesInfo.TypeOf(err)) || !isSentinel(p, sentinel) {
+				continue
+			}
+			p.Report(n, types.ExprString(err)+" "+n.Op.String()+" "+types.ExprString(sentinel)+
+				" misses wrapped errors; use errors.Is", errorsIsFix(p, c, n, err, sentinel)...)
+
+		case *ast.TypeAssertExpr:
+			if !isErrorType(p.TypesInfo.TypeOf(n.X)) {
+				continue
+			}
+			if n.Type == nil {
+				p.Report(n, "type switch on "+types.ExprString(n.X)+" misses wrapped errors; use errors.As for each type")
+				continue
+			}
+			if isErrorType(p.TypesInfo.TypeOf(n.Type)) {
+				continue
+			}
+			p.Report(n, "type assertion "+types.ExprString(n)+" misses wrapped errors; use errors.As("+
+				types.ExprString(n.X)+", &target) with a target of type "+types.ExprString(n.Type))
+
+		case *ast.SwitchStmt:
+			if n.Tag == nil || !isErrorType(p.TypesInfo.TypeOf(n.Tag)) {
+				continue
+			}
+			for _, stmt := range n.Body.List {
+				clause, ok := stmt.(*ast.CaseClause)
+				if !ok {
+					continue
+				}
+				for _, e := range clause.List {
+					if isSentinel(p, e) {
+						p.Report(e, "switch on "+types.ExprString(n.Tag)+" compares with ==, which misses wrapped errors; "+
+							"use `case errors.Is("+types.ExprString(n.Tag)+", "+types.ExprString(e)+"):` in a tagless switch")
+					}
+				}
+			}
+		}
+	}
+}
+
+// isSentinel reports whether e names a package-level variable whose type
+// implements error, such as io.EOF or sql.ErrNoRows.
+func isSentinel(p *Pass, e ast.Expr) bool {
+	var id *ast.Ident
+	switch e := ast.Unparen(e).(type) {
+	case *ast.Ident:
+		id = e
+	case *ast.SelectorExpr:
+		id = e.Sel
+	default:
+		return false
+	}
+	v, ok := p.TypesInfo.Uses[id].(*types.Var)
+	if !ok || v.Pkg() == nil || v.Parent() != v.Pkg().Scope() {
+		return false
+	}
+	iface, _ := errorType.Underlying().(*types.Interface) // FALLBACK: error is always an interface
+	return types.Implements(v.Type(), iface)
+}
+
+// inErrorMatcher reports whether c lies in an `Is(error) bool` or
+// `As(any) bool` method, which errors.Is and errors.As call and which must
+// therefore compare their target directly.
+func inErrorMatcher(p *Pass, c inspector.Cursor) bool {
+	decl := enclosingDecl(c)
+	if decl == nil || decl.Recv == nil || (decl.Name.Name != "Is" && decl.Name.Name != "As") {
+		return false
+	}
+	fn, ok := p.TypesInfo.Defs[decl.Name].(*types.Func)
+	if !ok {
+		return false
+	}
+	sig := fn.Signature()
+	return sig.Params().Len() == 1 && sig.Results().Len() == 1 &&
+		types.Identical(sig.Results().At(0).Type(), types.Typ[types.Bool])
+}
+
+// errorsIsFix rewrites `err == sentinel` to `errors.Is(err, sentinel)` and
+// `err != sentinel` to `!errors.Is(err, sentinel)`.
+func errorsIsFix(p *Pass, c inspector.Cursor, bin *ast.BinaryExpr, err, sentinel ast.Expr) []analysis.SuggestedFix {
+	file := enclosingFile(c)
+	if file == nil || p.Pkg.Path() == "errors" {
+		return nil
+	}
+	errText, ok := sourceText(p, err)
+	if !ok {
+		return nil
+	}
+	sentinelText, ok := sourceText(p, sentinel)
+	if !ok {
+		return nil
+	}
+	pkg, edits := importName(p, file, "errors")
+	call := pkg + ".Is(" + errText + ", " + sentinelText + ")"
+	if bin.Op == token.NEQ {
+		call = "!" + call
+	}
+	edits = append(edits, analysis.TextEdit{Pos: bin.Pos(), End: bin.End(), NewText: []byte(call)})
+	return []analysis.SuggestedFix{{Message: "Use errors.Is", TextEdits: edits}}
+}
@@ -0,0 +1,122 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// errorStringRule reports error messages that start with a capital letter
+// or end with punctuation.
+//
+//	return errors.New("Invalid token.") // reads "parse: Invalid token." once wrapped
+//
+// Error strings are usually embedded in longer messages by their callers,
+// so Go convention keeps them lowercase and unpunctuated. The rule checks
+// the string literal passed to errors.New and the format of fmt.Errorf. A
+// first word with a capital after its first letter, such as HTTP or
+// ParseConfig, is taken for an acronym or identifier and left alone, as are
+// the words in -errorstring.allow. The fix lowercases the first letter and
+// strips the trailing punctuation. It overlaps with staticcheck's ST1005,
+// so it is opt-in (-errorstring.enable).
+var errorStringRule = &Rule{
+	ID:       "CogErrorString",
+	Doc:      "report error strings that are capitalized or end with punctuation",
+	Run:      runErrorString,
+	Category: CategoryStyle,
+	Fixable:  true,
+
+	Severity: SeverityWarning,
+}
+
+var (
+	// errorStringEnable turns the rule on.
+	errorStringEnable bool
+
+	// errorStringAllow are the capitalized first words left as they are.
+	errorStringAllow = listFlag{"HTTP", "HTTPS", "JSON", "XML", "YAML", "TOML", "SQL", "URL", "API", "ID", "EOF", "Go"}
+)
+
+func init() {
+	Analyzer.Flags.BoolVar(&errorStringEnable, "errorstring.enable", false,
+		"report errors.New and fmt.Errorf messages that are capitalized or end with punctuation")
+	Analyzer.Flags.Var(&errorStringAllow, "errorstring.allow",
+		"comma-separated words, such as proper nouns, that may start an error string capitalized")
+}
+
+// errorStringPunct are the characters an error string should not end with.
+const errorStringPunct = ".!?:;"
+
+func runErrorString(p *Pass) {
+	if !errorStringEnable {
+		return
+	}
+	for c := range p.Inspector.Root().Preorder((*ast.CallExpr)(nil)) {
+		call, ok := c.Node().(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			continue
+		}
+		if name := calleeName(p.TypesInfo, call); name != "errors.New" && name != "fmt.Errorf" {
+			continue
+		}
+		lit, ok := ast.Unparen(call.Args[0]).(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING || len(lit.Value) < 2 {
+			continue
+		}
+		text := lit.Value[1 : len(lit.Value)-1]
+		capital := capitalizedStart(text)
+		trimmed := strings.TrimRight(text, errorStringPunct)
+		if !capital && trimmed == text || trimmed == "" {
+			continue
+		}
+		var problem string
+		switch {
+		case capital && trimmed != text:
+			problem = "starts with a capital letter and ends with punctuation"
+		case capital:
+			problem = "starts with a capital letter"
+		default:
+			problem = "ends with punctuation"
+		}
+		p.Report(lit, "error string "+lit.Value+" "+problem+"; callers wrap error strings in longer "+
+			"messages, so keep them lowercase and unpunctuated", errorStringFix(lit, capital, len(text)-len(trimmed))...)
+	}
+}
+
+// capitalizedStart reports whether text starts with an upper-case letter
+// that is not part of an acronym, an identifier or an allowed word.
+func capitalizedStart(text string) bool {
+	r, _ := utf8.DecodeRuneInString(text)
+	if !unicode.IsUpper(r) {
+		return false
+	}
+	word, _, _ := strings.Cut(text, " ")
+	word = strings.TrimRight(word, errorStringPunct+",")
+	if slices.Contains(errorStringAllow, word) {
+		return false
+	}
+	return !strings.ContainsFunc(word[utf8.RuneLen(r):], unicode.IsUpper)
+}
+
+// errorStringFix lowercases the first letter of lit when capital is set,
+// and deletes the punct trailing bytes before its closing quote.
+func errorStringFix(lit *ast.BasicLit, capital bool, punct int) []analysis.SuggestedFix {
+	edits := make([]analysis.TextEdit, 0, 2)
+	if capital {
+		r, size := utf8.DecodeRuneInString(lit.Value[1:])
+		start := lit.Pos() + 1
+		edits = append(edits, analysis.TextEdit{
+			Pos: start, End: start + token.Pos(size), NewText: []byte(string(unicode.ToLower(r))),
+		})
+	}
+	if punct > 0 {
+		end := lit.End() - 1
+		edits = append(edits, analysis.TextEdit{Pos: end - token.Pos(punct), End: end})
+	}
+	return []analysis.SuggestedFix{{Message: "Lowercase and strip the error string", TextEdits: edits}}
+}
@@ -0,0 +1,14 @@
+package cog
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestErrorString(t *testing.T) {
+	saved := errorStringEnable
+	t.Cleanup(func() { errorStringEnable = saved })
+	errorStringEnable = true
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), ruleAnalyzer(errorStringRule), "errorstring")
+}
@@ -0,0 +1,216 @@
+package cog
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strconv"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// errorWrapRule reports Mistake 7: errors that leave a function without
+// context.
+//
+//	user, err := db.Find(id)
+//	if err != nil {
+//		return User{}, err // where did this come from?
+//	}
+//
+// It fires on `return err` when err was last assigned from a call and is
+// returned unchanged, and on fmt.Errorf calls that format an error with a
+// verb other than %w, which severs the chain errors.Is and errors.As follow.
+// Errors minted where they are returned (errors.New, fmt.Errorf, errors.Join)
+// are the original source and are not reported. The fix wraps the returned
+// error with the enclosing function's name as context, a default meant to
+// be edited: return User{}, fmt.Errorf("FindUser: %w", err).
+var errorWrapRule = &Rule{
+	ID:       "CogErrorWrap",
+	Doc:      "report errors returned without context or formatted without %w",
+	Run:      runErrorWrap,
+	Category: CategoryErrors,
+	Fixable:  true,
+}
+
+// errorWrapExempt exempts functions by name ("Func" or "Recv.Method").
+var errorWrapExempt regexpFlag
+
+func init() {
+	Analyzer.Flags.Var(&errorWrapExempt, "errorwrap.exempt",
+		"regexp of function names (Func or Recv.Method) exempt from CogErrorWrap")
+}
+
+// errorConstructors mint new errors rather than propagate existing ones.
+var errorConstructors = map[string]bool{
+	"errors.New":  true,
+	"errors.Join": true,
+	"fmt.Errorf":  true,
+}
+
+// errorSource records one assignment to an error variable.
+type errorSource struct {
+	pos  token.Pos
+	call *ast.CallExpr // nil when the value did not come from a call
+}
+
+func runErrorWrap(p *Pass) {
+	sources := errorSources(p)
+
+	filter := []ast.Node{(*ast.ReturnStmt)(nil), (*ast.CallExpr)(nil)}
+	for c := range p.Inspector.Root().Preorder(filter...) {
+		decl := enclosingDecl(c)
+		if decl != nil && errorWrapExempt.matches(funcDeclName(decl)) {
+			continue
+		}
+
+		switch n := c.Node().(type) {
+		case *ast.ReturnStmt:
+			if decl != nil && decl.Name.Name == "main" && decl.Recv == nil && p.Pkg.Name() == "main" {
+				continue // main is the top of the call chain
+			}
+			_, body := enclosingFunc(c)
+			if body == nil {
+				continue
+			}
+			file, context := enclosingFile(c), ""
+			if decl != nil {
+				context = funcDeclName(decl)
+			}
+			for _, res := range n.Results {
+				checkUnwrappedReturn(p, res, body, sources, file, context)
+			}
+		case *ast.CallExpr:
+			checkErrorfVerb(p, n)
+		}
+	}
+}
+
+// checkUnwrappedReturn reports res when it is an error variable last
+// assigned, within body, from a call that did not create the error. The
+// fix wraps it with context, and is offered when context is not empty.
+func checkUnwrappedReturn(p *Pass, res ast.Expr, body *ast.BlockStmt, sources map[*types.Var][]errorSource,
+	file *ast.File, context string) {
+	id, ok := ast.Unparen(res).(*ast.Ident)
+	if !ok {
+		return
+	}
+	v, ok := p.TypesInfo.Uses[id].(*types.Var)
+	if !ok || !isErrorType(v.Type()) {
+		return
+	}
+
+	var last *errorSource
+	for i, src := range sources[v] {
+		if src.pos < body.Pos() || src.pos >= id.Pos() {
+			continue
+		}
+		if last == nil || src.pos > last.pos {
+			last = &sources[v][i]
+		}
+	}
+	if last == nil || last.call == nil || errorConstructors[calleeName(p.TypesInfo, last.call)] {
+		return
+	}
+
+	fixes := make([]analysis.SuggestedFix, 0, 1)
+	if file != nil && context != "" {
+		fmtPkg, edits := importName(p, file, "fmt")
+		edits = append(edits, analysis.TextEdit{
+			Pos:     res.Pos(),
+			End:     res.End(),
+			NewText: []byte(fmtPkg + ".Errorf(" + strconv.Quote(context+": %w") + ", " + id.Name + ")"),
+		})
+		fixes = append(fixes, analysis.SuggestedFix{Message: "Wrap " + id.Name + " with context", TextEdits: edits})
+	}
+	p.Report(id, id.Name+" from "+types.ExprString(last.call.Fun)+
+		" is returned without context; wrap it with fmt.Errorf(\"...: %w\", "+id.Name+")", fixes...)
+}
+
+// checkErrorfVerb reports fmt.Errorf operands of type error that are
+// formatted with a verb other than %w.
+func checkErrorfVerb(p *Pass, call *ast.CallExpr) {
+	if calleeName(p.TypesInfo, call) != "fmt.Errorf" || len(call.Args) == 0 {
+		return
+	}
+	tv, ok := p.TypesInfo.Types[call.Args[0]]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return
+	}
+	verbs, ok := parseFormat(constant.StringVal(tv.Value))
+	if !ok {
+		return
+	}
+	operands := call.Args[1:]
+	for _, vb := range verbs {
+		if vb.verb == 'w' || vb.arg >= len(operands) {
+			continue
+		}
+		operand := operands[vb.arg]
+		if !isErrorType(p.TypesInfo.TypeOf(operand)) {
+			continue
+		}
+		p.Report(operand, "fmt.Errorf formats error "+types.ExprString(operand)+" with %"+
+			string(vb.verb)+"; use %w so callers can match it with errors.Is and errors.As")
+	}
+}
+
+// errorSources indexes every assignment to an error-typed variable.
+func errorSources(p *Pass) map[*types.Var][]errorSource {
+	sources := make(map[*types.Var][]errorSource)
+	record := func(lhs, rhs []ast.Expr) {
+		for i, l := range lhs {
+			id, ok := l.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			v, ok := p.TypesInfo.ObjectOf(id).(*types.Var)
+			if !ok || !isErrorType(v.Type()) {
+				continue
+			}
+			var r ast.Expr
+			switch {
+			case len(rhs) == 1 && len(lhs) > 1:
+				r = rhs[0]
+			case i < len(rhs):
+				r = rhs[i]
+			default:
+				continue
+			}
+			call, _ := ast.Unparen(r).(*ast.CallExpr) // FALLBACK: nil marks a non-call source
+			sources[v] = append(sources[v], errorSource{pos: id.Pos(), call: call})
+		}
+	}
+
+	for n := range p.Inspector.PreorderSeq((*ast.AssignStmt)(nil), (*ast.ValueSpec)(nil)) {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			record(n.Lhs, n.Rhs)
+		case *ast.ValueSpec:
+			lhs := make([]ast.Expr, 0, len(n.Names))
+			for _, name := range n.Names {
+				lhs = append(lhs, name)
+			}
+			record(lhs, n.Values)
+		}
+	}
+	return sources
+}
+
+// funcDeclName returns "Func" for functions and "Recv.Method" for methods.
+func funcDeclName(decl *ast.FuncDecl) string {
+	if decl.Recv == nil || len(decl.Recv.List) == 0 {
+		return decl.Name.Name
+	}
+	t := decl.Recv.List[0].Type
+	if star, ok := t.(*ast.StarExpr); ok {
+		t = star.X
+	}
+	switch t := t.(type) {
+	case *ast.IndexExpr:
+		return types.ExprString(t.X) + "." + decl.Name.Name
+	case *ast.IndexListExpr:
+		return types.ExprString(t.X) + "." + decl.Name.Name
+	}
+	return types.ExprString(t) + "." + decl.Name.Name
+}
@@ -0,0 +1,55 @@
+package cog
+
+import (
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestErrorWrap(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), ruleAnalyzer(errorWrapRule), "errorwrap")
+}
+
+// TestErrorWrapFixCompiles applies every wrap fix at once, as -fix does,
+// and checks that the files come out gofmt-clean, with fmt imported in
+// sorted position, and that the package still type-checks.
+func TestErrorWrapFixCompiles(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), ruleAnalyzer(errorWrapRule), "errorwrap")
+	fixed := fixedFiles(t, results)
+	if len(fixed) != 4 {
+		t.Fatalf("fixes edited %d files, want 4", len(fixed))
+	}
+
+	fset := token.NewFileSet()
+	files := make([]*ast.File, 0, len(results[0].Pass.Files))
+	for _, f := range results[0].Pass.Files {
+		name := results[0].Pass.Fset.File(f.Pos()).Name()
+		var src any // nil reads an unfixed file
+		if b, ok := fixed[name]; ok {
+			src = b
+			formatted, err := format.Source(b)
+			if err != nil {
+				t.Fatalf("formatting fixed %s: %v", filepath.Base(name), err)
+			}
+			if string(formatted) != string(b) {
+				t.Errorf("fixed %s is not gofmt-clean:\n%s", filepath.Base(name), b)
+			}
+		}
+		file, err := parser.ParseFile(fset, name, src, 0)
+		if err != nil {
+			t.Fatalf("parsing fixed %s: %v", filepath.Base(name), err)
+		}
+		files = append(files, file)
+	}
+	conf := types.Config{Importer: testdataImporter{fset: fset, std: importer.ForCompiler(fset, "source", nil)}}
+	if _, err := conf.Check("errorwrap", fset, files, nil); err != nil {
+		t.Errorf("fixed package does not type-check: %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+)
+
+// errShadowRule reports an error variable redeclared with := in a nested
+// scope when the outer variable of the same name is read after that scope
+// without being assigned in between — the read sees a stale error.
+//
+//	var err error
+//	if cond {
+//		x, err := f() // declares a new err
+//		use(x)
+//	}
+//	if err != nil { // reads the outer err, which f never set
+//
+// Shadowing that does not hide a later read, such as the common
+// `if v, err := f(); err != nil { return err }` followed by a fresh
+// assignment, is not reported.
+var errShadowRule = &Rule{
+	ID:       "CogErrShadow",
+	Doc:      "report shadowed error variables whose outer value is read afterwards",
+	Run:      runErrShadow,
+	Category: CategoryErrors,
+}
+
+func runErrShadow(p *Pass) {
+	refs := errorVarRefs(p)
+
+	for c := range p.Inspector.Root().Preorder((*ast.AssignStmt)(nil)) {
+		assign, ok := c.Node().(*ast.AssignStmt)
+		if !ok || assign.Tok != token.DEFINE {
+			continue
+		}
+		for _, lhs := range assign.Lhs {
+			id, ok := lhs.(*ast.Ident)
+			if !ok || id.Name == "_" {
+				continue
+			}
+			inner, ok := p.TypesInfo.Defs[id].(*types.Var)
+			if !ok || !isErrorType(inner.Type()) || inner.Parent() == nil || inner.Parent().Parent() == nil {
+				continue
+			}
+			_, obj := inner.Parent().Parent().LookupParent(id.Name, id.Pos())
+			outer, ok := obj.(*types.Var)
+			if !ok || !isErrorType(outer.Type()) || outer.Parent() == p.Pkg.Scope() {
+				continue
+			}
+			// A closure declaring its own err is not meant to update the
+			// enclosing function's.
+			if lit := enclosingFuncLit(c); lit != nil && outer.Pos() < lit.Pos() {
+				continue
+			}
+
+			next, ok := nextRef(refs[outer], inner.Parent().End())
+			if !ok || next.write {
+				continue
+			}
+			p.Report(id, id.Name+" declared here shadows the "+id.Name+" declared on line "+
+				strconv.Itoa(p.Fset.Position(outer.Pos()).Line)+"; the read on line "+
+				strconv.Itoa(p.Fset.Position(next.pos).Line)+" sees the outer "+id.Name+
+				", not this one; assign with = instead of :=")
+		}
+	}
+}
+
+// varRef is one occurrence of a variable.
+type varRef struct {
+	pos   token.Pos
+	write bool // the occurrence is assigned to rather than read
+}
+
+// errorVarRefs indexes the occurrences of every error-typed local variable
+// in source order.
+func errorVarRefs(p *Pass) map[*types.Var][]varRef {
+	writes := make(map[*ast.Ident]bool)
+	for n := range p.Inspector.PreorderSeq((*ast.AssignStmt)(nil)) {
+		assign, ok := n.(*ast.AssignStmt)
+		if !ok || (assign.Tok != token.ASSIGN && assign.Tok != token.DEFINE) {
+			continue
+		}
+		for _, lhs := range assign.Lhs {
+			if id, ok := lhs.(*ast.Ident); ok {
+				writes[id] = true
+			}
+		}
+	}
+
+	refs := make(map[*types.Var][]varRef)
+	for n := range p.Inspector.PreorderSeq((*ast.Ident)(nil)) {
+		id, ok := n.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		v, ok := p.TypesInfo.Uses[id].(*types.Var)
+		if !ok || !isErrorType(v.Type()) {
+			continue
+		}
+		refs[v] = append(refs[v], varRef{pos: id.Pos(), write: writes[id]})
+	}
+	return refs
+}
+
+// nextRef returns the first reference at or after pos.
+func nextRef(refs []varRef, pos token.Pos) (varRef, bool) {
+	for _, r := range refs {
+		if r.pos >= pos {
+			return r, true
+		}
+	}
+	return varRef{}, false
+}
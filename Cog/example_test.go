@@ -0,0 +1,25 @@
+package cog_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+
+	cog "github.com/PCfVW/Amphigraphic-Strict/Cog"
+)
+
+func ExampleRun() {
+	findings, err := cog.Run(context.Background(), []string{"./testdata/run"}, cog.Config{
+		Rules: map[string]cog.Severity{"CogBareReturn": cog.SeverityWarning},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, f := range findings {
+		fmt.Printf("%s:%d: %s (%s)\n", filepath.Base(f.Pos.Filename), f.Pos.Line, f.Rule, f.Severity)
+	}
+	// Output:
+	// run.go:6: CogIgnoredError (error)
+	// run.go:11: CogBareReturn (warning)
+}
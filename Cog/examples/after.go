@@ -1,5 +1,6 @@
 // after.go - Cog-compliant versions
 // These patterns follow Cog rules and eliminate the subtle bugs.
+// `cog explain` quotes the FIX sections by their headers; keep them numbered.
 
 package examples
 
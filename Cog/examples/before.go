@@ -1,5 +1,6 @@
 // before.go - Common AI mistakes in Go
 // These patterns compile but contain subtle bugs that AI assistants frequently generate.
+// `cog explain` quotes the MISTAKE sections by their headers; keep them numbered.
 
 package examples
 
@@ -0,0 +1,51 @@
+// Command customrule is cog with one organization-specific rule added: it
+// shows how to extend Cog without forking it. Build it in place of cmd/cog
+// and run it the same way:
+//
+//	go build -o cog ./examples/customrule
+//	./cog ./...
+//
+// The rule can then be set in .cog.yaml like any built-in one:
+//
+//	rules:
+//	  AcmeNoPrintln: warning
+package main
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis/singlechecker"
+	"golang.org/x/tools/go/types/typeutil"
+
+	cog "github.com/PCfVW/Amphigraphic-Strict/Cog"
+)
+
+// noPrintlnRule reports fmt.Println in favor of the structured logger.
+var noPrintlnRule = &cog.Rule{
+	ID:       "AcmeNoPrintln",
+	Doc:      "report fmt.Println, which bypasses the structured logger",
+	Run:      runNoPrintln,
+	Category: cog.CategoryStyle,
+	Severity: cog.SeverityWarning,
+}
+
+func init() {
+	cog.MustRegister(noPrintlnRule)
+}
+
+func runNoPrintln(p *cog.Pass) {
+	for c := range p.Inspector.Root().Preorder((*ast.CallExpr)(nil)) {
+		call, ok := c.Node().(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+		if fn, ok := typeutil.Callee(p.TypesInfo, call).(*types.Func); ok && fn.FullName() == "fmt.Println" {
+			p.Report(call, "fmt.Println bypasses the structured logger; use slog.Info")
+		}
+	}
+}
+
+func main() {
+	singlechecker.Main(cog.Analyzer)
+}
@@ -0,0 +1,162 @@
+package cog
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// sources holds the package's own files, whose rule doc comments are the
+// rationale Explain prints, and the examples it quotes.
+//
+//go:embed *.go examples/before.go examples/after.go
+var sources embed.FS
+
+// ErrUnknownRule is returned by Explain for an ID no registered rule has.
+var ErrUnknownRule = errors.New("cog: unknown rule")
+
+// An Explanation is the long-form documentation of a rule, as printed by
+// `cog explain`.
+type Explanation struct {
+	RuleInfo
+
+	// Rationale is the rule's documentation: what it reports and why. It
+	// is the Summary for rules registered from outside this package.
+	Rationale string
+
+	// Before and After are the buggy and fixed code of the rule's mistake
+	// in examples/before.go and examples/after.go, or empty when the
+	// examples do not cover the rule.
+	Before, After string
+}
+
+// exampleMistakes maps rule IDs to the number of the MISTAKE and FIX
+// sections of examples/before.go and examples/after.go that show them.
+var exampleMistakes = map[string]int{
+	"CogTypeErasure":  1,
+	"CogJSONMapAny":   1,
+	"CogIgnoredError": 2,
+	"CogBareReturn":   3,
+	"CogTypedNil":     4,
+	"CogLoopCapture":  5,
+	"CogNilSliceJSON": 6,
+	"CogErrorWrap":    7,
+}
+
+// Explain returns the documentation of the rule with the given ID, matched
+// without regard to case, or ErrUnknownRule when no such rule is
+// registered.
+func Explain(id string) (Explanation, error) {
+	var info RuleInfo
+	found := false
+	for _, ri := range Catalog() {
+		if strings.EqualFold(ri.ID, id) {
+			info, found = ri, true
+			break
+		}
+	}
+	if !found {
+		return Explanation{}, fmt.Errorf("%w %q", ErrUnknownRule, id)
+	}
+	ex := Explanation{RuleInfo: info, Rationale: info.Summary}
+	if doc, ok := ruleDoc(info.ID); ok {
+		ex.Rationale = doc
+	}
+	if n, ok := exampleMistakes[info.ID]; ok {
+		ex.Before = exampleSection("examples/before.go", "// --- MISTAKE "+strconv.Itoa(n)+":")
+		ex.After = exampleSection("examples/after.go", "// --- FIX "+strconv.Itoa(n)+":")
+	}
+	return ex, nil
+}
+
+// ruleDoc returns the doc comment of the package-level *Rule variable with
+// the given ID, its first word, the variable name, replaced by the ID.
+func ruleDoc(id string) (string, bool) {
+	files, err := sources.ReadDir(".")
+	if err != nil {
+		return "", false
+	}
+	fset := token.NewFileSet()
+	for _, f := range files {
+		data, err := sources.ReadFile(f.Name())
+		if err != nil || !strings.Contains(string(data), strconv.Quote(id)) {
+			continue
+		}
+		file, err := parser.ParseFile(fset, f.Name(), data, parser.ParseComments)
+		if err != nil {
+			continue
+		}
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR || len(gen.Specs) != 1 || gen.Doc == nil {
+				continue
+			}
+			spec, ok := gen.Specs[0].(*ast.ValueSpec)
+			if !ok || len(spec.Names) != 1 || len(spec.Values) != 1 || ruleLitID(spec.Values[0]) != id {
+				continue
+			}
+			doc := gen.Doc.Text()
+			return id + strings.TrimPrefix(doc, spec.Names[0].Name), true
+		}
+	}
+	return "", false
+}
+
+// ruleLitID returns the ID field of a &Rule{...} literal, or "".
+func ruleLitID(e ast.Expr) string {
+	addr, ok := e.(*ast.UnaryExpr)
+	if !ok || addr.Op != token.AND {
+		return ""
+	}
+	lit, ok := addr.X.(*ast.CompositeLit)
+	if !ok {
+		return ""
+	}
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		if key, ok := kv.Key.(*ast.Ident); ok && key.Name == "ID" {
+			if v, ok := kv.Value.(*ast.BasicLit); ok {
+				id, err := strconv.Unquote(v.Value)
+				if err == nil {
+					return id
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// exampleSection returns the lines of the embedded file name from the one
+// starting with header up to the next section header, or "" when there is
+// no such line.
+func exampleSection(name, header string) string {
+	data, err := sources.ReadFile(name)
+	if err != nil {
+		return ""
+	}
+	lines := strings.Split(string(data), "\n")
+	start := -1
+	for i, line := range lines {
+		if start < 0 {
+			if strings.HasPrefix(line, header) {
+				start = i
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "// --- ") || strings.HasPrefix(line, "// Helper types") {
+			return strings.TrimSpace(strings.Join(lines[start:i], "\n")) + "\n"
+		}
+	}
+	if start < 0 {
+		return ""
+	}
+	return strings.TrimSpace(strings.Join(lines[start:], "\n")) + "\n"
+}
@@ -0,0 +1,52 @@
+package cog
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	ex, err := Explain("CogTypedNil")
+	if err != nil {
+		t.Fatalf("Explain: %v", err)
+	}
+	if ex.ID != "CogTypedNil" || ex.DocURL == "" {
+		t.Errorf("Explain returned %+v, want the catalog entry of CogTypedNil", ex.RuleInfo)
+	}
+	if !strings.HasPrefix(ex.Rationale, "CogTypedNil reports") {
+		t.Errorf("Rationale = %q, want the rule's doc comment starting with its ID", ex.Rationale)
+	}
+	if !strings.HasPrefix(ex.Before, "// --- MISTAKE 4:") {
+		t.Errorf("Before = %q, want MISTAKE 4 of examples/before.go", ex.Before)
+	}
+	if !strings.HasPrefix(ex.After, "// --- FIX 4:") {
+		t.Errorf("After = %q, want FIX 4 of examples/after.go", ex.After)
+	}
+}
+
+func TestExplainNoExample(t *testing.T) {
+	ex, err := Explain("CogRowsErr")
+	if err != nil {
+		t.Fatalf("Explain: %v", err)
+	}
+	if !strings.HasPrefix(ex.Rationale, "CogRowsErr ") || ex.Before != "" || ex.After != "" {
+		t.Errorf("Explain = %+v, want the rationale alone", ex)
+	}
+}
+
+func TestExplainCaseInsensitive(t *testing.T) {
+	ex, err := Explain("cogtypednil")
+	if err != nil {
+		t.Fatalf("Explain: %v", err)
+	}
+	if ex.ID != "CogTypedNil" {
+		t.Errorf("Explain(%q).ID = %q, want CogTypedNil", "cogtypednil", ex.ID)
+	}
+}
+
+func TestExplainUnknownRule(t *testing.T) {
+	if _, err := Explain("CogNoSuchRule"); !errors.Is(err, ErrUnknownRule) {
+		t.Errorf("Explain of an unknown rule returned %v, want ErrUnknownRule", err)
+	}
+}
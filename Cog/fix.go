@@ -0,0 +1,273 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// zeroValue renders the zero value of t as source text valid in file.
+func zeroValue(p *Pass, file *ast.File, t types.Type) (string, bool) {
+	if _, ok := types.Unalias(t).(*types.TypeParam); ok {
+		typ, ok := typeExpr(p, file, t)
+		return "*new(" + typ + ")", ok
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		// Untyped constants are assignable to named basic types too, so
+		// the literal needs no conversion.
+		switch {
+		case u.Info()&types.IsBoolean != 0:
+			return "false", true
+		case u.Info()&types.IsString != 0:
+			return `""`, true
+		case u.Info()&types.IsNumeric != 0:
+			return "0", true
+		case u.Kind() == types.UnsafePointer:
+			return "nil", true
+		}
+	case *types.Pointer, *types.Slice, *types.Map, *types.Chan, *types.Signature, *types.Interface:
+		return "nil", true
+	case *types.Struct, *types.Array:
+		typ, ok := typeExpr(p, file, t)
+		if !ok {
+			return "", false
+		}
+		return typ + "{}", true
+	}
+	typ, ok := typeExpr(p, file, t)
+	if !ok {
+		return "", false
+	}
+	return "*new(" + typ + ")", true
+}
+
+// returnZeros renders the results of a return statement for sig that yields
+// zero values everywhere except the last error result, which is errName.
+// It reports false when sig has no error result to carry errName.
+func returnZeros(p *Pass, file *ast.File, sig *types.Signature, errName string) (string, bool) {
+	results := sig.Results()
+	if results.Len() == 0 || !isErrorType(results.At(results.Len()-1).Type()) {
+		return "", false
+	}
+	parts := make([]string, 0, results.Len())
+	for i := range results.Len() - 1 {
+		zero, ok := zeroValue(p, file, results.At(i).Type())
+		if !ok {
+			return "", false
+		}
+		parts = append(parts, zero)
+	}
+	parts = append(parts, errName)
+	return strings.Join(parts, ", "), true
+}
+
+// importName returns the name file uses for the package at path, adding an
+// import edit when file does not import it yet. The import goes where
+// gofmt would keep it: in sorted order within the first group of the
+// file's parenthesized import declaration that holds standard library
+// imports if path is one, others if not, or else within its first group;
+// without such a declaration, as a declaration of its own next to the
+// file's first import, or after the package clause. The edit only
+// inserts text, so that fixes adding different imports to one file merge;
+// compareEdits keeps such insertions at the same point in sorted order.
+func importName(p *Pass, file *ast.File, path string) (string, []analysis.TextEdit) {
+	edits := make([]analysis.TextEdit, 0, 1)
+	for _, imp := range file.Imports {
+		if imp.Path.Value != strconv.Quote(path) {
+			continue
+		}
+		if pn := p.TypesInfo.PkgNameOf(imp); pn != nil && pn.Name() != "_" && pn.Name() != "." {
+			return pn.Name(), edits
+		}
+	}
+
+	name := path[strings.LastIndex(path, "/")+1:]
+	quoted := strconv.Quote(path)
+	var first, block *ast.GenDecl
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT || len(gen.Specs) == 0 {
+			continue
+		}
+		if first == nil {
+			first = gen
+		}
+		if gen.Lparen.IsValid() {
+			block = gen
+			break
+		}
+	}
+	var group []*ast.ImportSpec
+	if block != nil {
+		groups := importGroups(p, block)
+		group = groups[0]
+		for _, g := range groups {
+			if isStdImport(importPath(g[0])) == isStdImport(path) {
+				group = g
+				break
+			}
+		}
+	}
+
+	switch {
+	case group != nil:
+		var after *ast.ImportSpec
+		for _, spec := range group {
+			if importPath(spec) < path {
+				after = spec
+			}
+		}
+		if after == nil {
+			pos := group[0].Pos()
+			if group[0].Doc != nil {
+				pos = group[0].Doc.Pos()
+			}
+			edits = append(edits, analysis.TextEdit{Pos: pos, End: pos, NewText: []byte(quoted + "\n\t")})
+		} else {
+			eol := lineEnd(p, after.End())
+			edits = append(edits, analysis.TextEdit{Pos: eol, End: eol, NewText: []byte("\n\t" + quoted)})
+		}
+	case first != nil:
+		spec, ok := first.Specs[0].(*ast.ImportSpec)
+		if ok && importPath(spec) < path {
+			eol := lineEnd(p, first.End())
+			edits = append(edits, analysis.TextEdit{Pos: eol, End: eol, NewText: []byte("\nimport " + quoted)})
+		} else {
+			pos := first.Pos()
+			if first.Doc != nil {
+				pos = first.Doc.Pos()
+			}
+			edits = append(edits, analysis.TextEdit{Pos: pos, End: pos, NewText: []byte("import " + quoted + "\n")})
+		}
+	default:
+		edits = append(edits, analysis.TextEdit{
+			Pos:     file.Name.End(),
+			End:     file.Name.End(),
+			NewText: []byte("\n\nimport " + quoted),
+		})
+	}
+	return name, edits
+}
+
+// insertedImport returns the path of the import that e adds when it is an
+// insertion made by importName, or "".
+func insertedImport(e Edit) string {
+	if e.Pos.Offset != e.End.Offset {
+		return ""
+	}
+	text := strings.TrimPrefix(strings.TrimSpace(e.NewText), "import ")
+	path, err := strconv.Unquote(text)
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+// importGroups splits the specs of the parenthesized import declaration
+// gen into the groups that blank lines separate, which gofmt sorts apart.
+func importGroups(p *Pass, gen *ast.GenDecl) [][]*ast.ImportSpec {
+	groups := make([][]*ast.ImportSpec, 0, 1)
+	lastLine := 0
+	for _, s := range gen.Specs {
+		spec, ok := s.(*ast.ImportSpec)
+		if !ok {
+			continue
+		}
+		start := spec.Pos()
+		if spec.Doc != nil {
+			start = spec.Doc.Pos()
+		}
+		if len(groups) == 0 || p.Fset.Position(start).Line > lastLine+1 {
+			groups = append(groups, nil)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], spec)
+		lastLine = p.Fset.Position(spec.End()).Line
+	}
+	return groups
+}
+
+// isStdImport reports whether path names a standard library package: its
+// first element has no dot.
+func isStdImport(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
+
+// importPath returns the unquoted path of spec.
+func importPath(spec *ast.ImportSpec) string {
+	path, err := strconv.Unquote(spec.Path.Value)
+	if err != nil {
+		return spec.Path.Value // FALLBACK: the parser only accepts valid literals
+	}
+	return path
+}
+
+// lineIndent returns the leading whitespace of the line containing pos.
+func lineIndent(p *Pass, pos token.Pos) string {
+	position := p.Fset.Position(pos)
+	src, err := p.ReadFile(position.Filename)
+	if err != nil {
+		return "" // FALLBACK: gofmt restores the indentation
+	}
+	start := position.Offset - (position.Column - 1)
+	end := start
+	for end < len(src) && (src[end] == ' ' || src[end] == '\t') {
+		end++
+	}
+	return string(src[start:end])
+}
+
+// sourceText returns the source of n as written.
+func sourceText(p *Pass, n ast.Node) (string, bool) {
+	return sourceRange(p, n.Pos(), n.End())
+}
+
+// sourceRange returns the source text from pos to end.
+func sourceRange(p *Pass, pos, end token.Pos) (string, bool) {
+	start, stop := p.Fset.Position(pos), p.Fset.Position(end)
+	src, err := p.ReadFile(start.Filename)
+	if err != nil || stop.Offset > len(src) || start.Offset > stop.Offset {
+		return "", false
+	}
+	return string(src[start.Offset:stop.Offset]), true
+}
+
+// lineEnd returns the position of the newline ending the line containing
+// pos, so that inserted lines land after any trailing comment.
+func lineEnd(p *Pass, pos token.Pos) token.Pos {
+	position := p.Fset.Position(pos)
+	src, err := p.ReadFile(position.Filename)
+	if err != nil {
+		return pos // FALLBACK: insert right after the node
+	}
+	end := position.Offset
+	for end < len(src) && src[end] != '\n' {
+		end++
+	}
+	return pos + token.Pos(end-position.Offset)
+}
+
+// enclosingSignature returns the signature of the innermost function
+// declaration or literal containing c, or nil at package level.
+func enclosingSignature(p *Pass, c inspector.Cursor) *types.Signature {
+	for fc := range c.Enclosing((*ast.FuncDecl)(nil), (*ast.FuncLit)(nil)) {
+		switch fn := fc.Node().(type) {
+		case *ast.FuncDecl:
+			if obj, ok := p.TypesInfo.Defs[fn.Name].(*types.Func); ok {
+				return obj.Signature()
+			}
+		case *ast.FuncLit:
+			if sig, ok := p.TypesInfo.TypeOf(fn).(*types.Signature); ok {
+				return sig
+			}
+		}
+		return nil
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package cog
+
+import (
+	"go/format"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+// TestImportsMergeSorted applies two fixes that add different imports at
+// the same point, math before fmt, and checks that the merged imports come
+// out sorted and the file gofmt-clean.
+func TestImportsMergeSorted(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), ruleAnalyzer(floatEqualityRule, errorWrapRule), "importmerge")
+	fixed := fixedFiles(t, results)
+	if len(fixed) != 1 {
+		t.Fatalf("fixes edited %d files, want 1", len(fixed))
+	}
+	for name, src := range fixed {
+		formatted, err := format.Source(src)
+		if err != nil {
+			t.Fatalf("formatting fixed %s: %v", name, err)
+		}
+		if string(formatted) != string(src) {
+			t.Errorf("fixed %s is not gofmt-clean:\n%s", name, src)
+		}
+	}
+}
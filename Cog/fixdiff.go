@@ -0,0 +1,212 @@
+package cog
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines WriteFixDiff shows around
+// each change, as diff -u does.
+const diffContext = 3
+
+// WriteFixDiff writes the first suggested fix of each finding to w as a
+// unified diff, without changing any file, so that -fix can be previewed:
+//
+//	--- a/examples/before.go
+//	+++ b/examples/before.go
+//	@@ -52,7 +52,7 @@
+//	...
+//
+// The diff has one section per file, in path order, with paths relative
+// to the working directory under a/ and b/ prefixes, so that git apply
+// applies it from there. A fix whose edits overlap those of a fix already
+// taken is left out, as -fix leaves it; identical edits, such as the same
+// import added by two fixes, are made once, and insertions at the same
+// point are all made. Findings without fixes are ignored.
+func WriteFixDiff(w io.Writer, findings []Finding) error {
+	edits := make(map[string][]Edit)
+	for _, f := range findings {
+		if len(f.Fixes) == 0 {
+			continue
+		}
+		fix := f.Fixes[0]
+		if slices.ContainsFunc(fix.Edits, func(e Edit) bool { return conflicts(edits[e.Pos.Filename], e) }) {
+			continue
+		}
+		for _, e := range fix.Edits {
+			if !slices.Contains(edits[e.Pos.Filename], e) {
+				edits[e.Pos.Filename] = append(edits[e.Pos.Filename], e)
+			}
+		}
+	}
+
+	var b strings.Builder
+	files := make([]string, 0, len(edits))
+	for file := range edits {
+		files = append(files, file)
+	}
+	slices.SortFunc(files, func(x, y string) int { return strings.Compare(displayPath(x), displayPath(y)) })
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("cog: fix diff: %w", err)
+		}
+		fileDiff(&b, filepath.ToSlash(displayPath(file)), src, edits[file])
+	}
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("cog: fix diff: %w", err)
+	}
+	return nil
+}
+
+// conflicts reports whether e overlaps one of taken. An edit identical to
+// one taken does not conflict, and neither do insertions at the same
+// point, which are made in the order taken, as -fix merges them.
+func conflicts(taken []Edit, e Edit) bool {
+	for _, t := range taken {
+		if t != e && t.Pos.Offset < e.End.Offset && e.Pos.Offset < t.End.Offset {
+			return true
+		}
+	}
+	return false
+}
+
+// compareEdits orders edits by position, as they are applied. Insertions
+// at the same point keep the order they were taken in, except that imports
+// added by different fixes are ordered by path, so that they stay sorted
+// as gofmt keeps them.
+func compareEdits(x, y Edit) int {
+	if c := cmp.Or(cmp.Compare(x.Pos.Offset, y.Pos.Offset), cmp.Compare(x.End.Offset, y.End.Offset)); c != 0 {
+		return c
+	}
+	xp, yp := insertedImport(x), insertedImport(y)
+	if xp == "" || yp == "" {
+		return 0
+	}
+	return strings.Compare(xp, yp)
+}
+
+// A diffBlock replaces the lines old through end-1 of a file with the lines
+// of text.
+type diffBlock struct {
+	old, end int
+	text     []string
+}
+
+// fileDiff writes to b the diff of the file at path, with contents src,
+// that edits make. Edits do not overlap.
+func fileDiff(b *strings.Builder, path string, src []byte, edits []Edit) {
+	lines := strings.SplitAfter(string(src), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	starts := make([]int, 0, len(lines)+1)
+	offset := 0
+	for _, line := range lines {
+		starts = append(starts, offset)
+		offset += len(line)
+	}
+	starts = append(starts, offset)
+	lineOf := func(offset int) int {
+		i, found := slices.BinarySearch(starts, offset)
+		if !found {
+			i--
+		}
+		return i
+	}
+
+	// Group the edits into blocks of whole lines; edits touching the same
+	// line share a block.
+	slices.SortStableFunc(edits, compareEdits)
+	var blocks []diffBlock
+	var pending []Edit
+	flush := func() {
+		if len(pending) == 0 {
+			return
+		}
+		first, last := lineOf(pending[0].Pos.Offset), lineOf(max(pending[len(pending)-1].End.Offset-1, pending[len(pending)-1].Pos.Offset))
+		end := min(last+1, len(lines))
+		var text bytes.Buffer
+		at := starts[first]
+		for _, e := range pending {
+			text.Write(src[at:e.Pos.Offset])
+			text.WriteString(e.NewText)
+			at = e.End.Offset
+		}
+		text.Write(src[at:starts[end]])
+		if newText := text.String(); newText != string(src[starts[first]:starts[end]]) {
+			blocks = append(blocks, diffBlock{old: first, end: end, text: strings.SplitAfter(newText, "\n")})
+			if blk := &blocks[len(blocks)-1]; blk.text[len(blk.text)-1] == "" {
+				blk.text = blk.text[:len(blk.text)-1]
+			}
+		}
+		pending = pending[:0]
+	}
+	for _, e := range edits {
+		if len(pending) > 0 {
+			last := pending[len(pending)-1]
+			if lineOf(e.Pos.Offset) > lineOf(max(last.End.Offset-1, last.Pos.Offset)) {
+				flush()
+			}
+		}
+		pending = append(pending, e)
+	}
+	flush()
+	if len(blocks) == 0 {
+		return
+	}
+
+	b.WriteString("--- a/" + path + "\n+++ b/" + path + "\n")
+	delta := 0
+	for i := 0; i < len(blocks); {
+		// A hunk takes the following blocks while the unchanged lines
+		// between them would be shown as context anyway.
+		j := i + 1
+		for j < len(blocks) && blocks[j].old-blocks[j-1].end <= 2*diffContext {
+			j++
+		}
+		from, to := max(blocks[i].old-diffContext, 0), min(blocks[j-1].end+diffContext, len(lines))
+		var body strings.Builder
+		oldCount, newCount := to-from, to-from
+		at := from
+		for _, blk := range blocks[i:j] {
+			diffLines(&body, " ", lines[at:blk.old])
+			diffLines(&body, "-", lines[blk.old:blk.end])
+			diffLines(&body, "+", blk.text)
+			newCount += len(blk.text) - (blk.end - blk.old)
+			at = blk.end
+		}
+		diffLines(&body, " ", lines[at:to])
+		b.WriteString("@@ -" + hunkRange(from, oldCount) + " +" + hunkRange(from+delta, newCount) + " @@\n")
+		b.WriteString(body.String())
+		delta += newCount - oldCount
+		i = j
+	}
+}
+
+// diffLines writes lines to b, each after prefix, marking a last line
+// without a newline as diff does.
+func diffLines(b *strings.Builder, prefix string, lines []string) {
+	for _, line := range lines {
+		b.WriteString(prefix + line)
+		if !strings.HasSuffix(line, "\n") {
+			b.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats the range of count lines after the first from lines
+// for a hunk header. An empty range names the line before it.
+func hunkRange(from, count int) string {
+	if count == 0 {
+		return strconv.Itoa(from) + ",0"
+	}
+	return strconv.Itoa(from+1) + "," + strconv.Itoa(count)
+}
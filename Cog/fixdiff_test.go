@@ -0,0 +1,172 @@
+package cog
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fixdiffEdit returns an Edit of file replacing the n-th occurrence (from
+// 1) of old with text.
+func fixdiffEdit(t *testing.T, file, old string, n int, text string) Edit {
+	t.Helper()
+	src, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("reading %s: %v", file, err)
+	}
+	at := -1
+	for range n {
+		i := strings.Index(string(src[at+1:]), old)
+		if i < 0 {
+			t.Fatalf("%s: occurrence %d of %q not found", file, n, old)
+		}
+		at += 1 + i
+	}
+	return Edit{
+		Pos:     positionAt(file, at),
+		End:     positionAt(file, at+len(old)),
+		NewText: text,
+	}
+}
+
+// fixdiffInsert returns an Edit of file inserting text right after the
+// first occurrence of old.
+func fixdiffInsert(t *testing.T, file, old, text string) Edit {
+	t.Helper()
+	e := fixdiffEdit(t, file, old, 1, text)
+	e.Pos = e.End
+	return e
+}
+
+// positionAt is the position of byte offset in file, enough for
+// WriteFixDiff, which only reads the offset.
+func positionAt(file string, offset int) token.Position {
+	return token.Position{Filename: file, Offset: offset}
+}
+
+func TestWriteFixDiffGolden(t *testing.T) {
+	a, b := filepath.Join("testdata", "fixdiff", "a.go.txt"), filepath.Join("testdata", "fixdiff", "b.go.txt")
+	c := filepath.Join("testdata", "fixdiff", "c.go.txt")
+	importFmt := fixdiffEdit(t, a, "import \"os\"\n", 1, "import (\n\t\"fmt\"\n\t\"os\"\n)\n")
+	findings := []Finding{
+		// The second file first: the diff is in path order.
+		{Rule: "CogChannelRecvOk", Fixes: []Fix{{Edits: []Edit{fixdiffEdit(t, b, "for {\n\t\t<-in", 1, "for range in {")}}}},
+		// Two fixes adding the same import: it is added once.
+		{Rule: "CogErrorWrap", Fixes: []Fix{{Edits: []Edit{
+			fixdiffEdit(t, a, "return nil, err", 1, `return nil, fmt.Errorf("read: %w", err)`), importFmt,
+		}}}},
+		{Rule: "CogErrorWrap", Fixes: []Fix{{Edits: []Edit{
+			fixdiffEdit(t, a, "return err", 1, `return fmt.Errorf("stat: %w", err)`), importFmt,
+		}}}},
+		// Overlaps the first fix: left out.
+		{Rule: "CogErrorWrap", Fixes: []Fix{{Edits: []Edit{fixdiffEdit(t, a, "nil, err", 1, "nil, nil")}}}},
+		// Only the first fix of a finding is taken.
+		{Rule: "CogBareReturn", Fixes: []Fix{
+			{Edits: []Edit{fixdiffEdit(t, a, "return 1", 1, "return 10")}},
+			{Edits: []Edit{fixdiffEdit(t, a, "return 4", 1, "return 40")}},
+		}},
+		// No fixes: ignored.
+		{Rule: "CogTypedNil"},
+		// Two fixes adding different imports at the same point, math
+		// first: the imports come out sorted.
+		{Rule: "CogFloatEquality", Fixes: []Fix{{Edits: []Edit{
+			fixdiffInsert(t, c, "\"errors\"", "\n\t\"math\""), fixdiffEdit(t, c, "x != x", 1, "math.IsNaN(x)"),
+		}}}},
+		{Rule: "CogErrorWrap", Fixes: []Fix{{Edits: []Edit{
+			fixdiffInsert(t, c, "\"errors\"", "\n\t\"fmt\""), fixdiffEdit(t, c, "return 0, err", 1, `return 0, fmt.Errorf("size: %w", err)`),
+		}}}},
+	}
+	var out bytes.Buffer
+	if err := WriteFixDiff(&out, findings); err != nil {
+		t.Fatalf("WriteFixDiff: %v", err)
+	}
+	checkGolden(t, "fixdiff.golden", out.Bytes())
+
+	src, err := os.ReadFile(c)
+	if err != nil {
+		t.Fatalf("reading %s: %v", c, err)
+	}
+	patched := applyFileDiff(t, string(src), out.String(), filepath.ToSlash(c))
+	formatted, err := format.Source([]byte(patched))
+	if err != nil {
+		t.Fatalf("formatting patched %s: %v", c, err)
+	}
+	if string(formatted) != patched {
+		t.Errorf("patched %s is not gofmt-clean:\n%s", c, patched)
+	}
+}
+
+// applyFileDiff applies the section of the unified diff for the file at
+// path, as git apply would, to src, the file's contents.
+func applyFileDiff(t *testing.T, src, diff, path string) string {
+	t.Helper()
+	_, section, ok := strings.Cut(diff, "--- a/"+path+"\n+++ b/"+path+"\n")
+	if !ok {
+		t.Fatalf("no diff for %s in:\n%s", path, diff)
+	}
+	section, _, _ = strings.Cut(section, "\n--- a/")
+	lines := strings.SplitAfter(src, "\n")
+	var out strings.Builder
+	at := 0
+	for _, line := range strings.SplitAfter(section, "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@ -"):
+			var from int
+			if _, err := fmt.Sscanf(line, "@@ -%d", &from); err != nil {
+				t.Fatalf("bad hunk header %q: %v", line, err)
+			}
+			for ; at < from-1; at++ {
+				out.WriteString(lines[at])
+			}
+		case strings.HasPrefix(line, " "):
+			out.WriteString(lines[at])
+			at++
+		case strings.HasPrefix(line, "-"):
+			at++
+		case strings.HasPrefix(line, "+"):
+			out.WriteString(line[1:])
+		}
+	}
+	for ; at < len(lines); at++ {
+		out.WriteString(lines[at])
+	}
+	return out.String()
+}
+
+func TestWriteFixDiffNoFixes(t *testing.T) {
+	var out bytes.Buffer
+	if err := WriteFixDiff(&out, []Finding{{Rule: "CogTypedNil"}}); err != nil {
+		t.Fatalf("WriteFixDiff: %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("WriteFixDiff without fixes wrote %q, want nothing", out.String())
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	edit := func(pos, end int, text string) Edit {
+		return Edit{Pos: positionAt("a.go", pos), End: positionAt("a.go", end), NewText: text}
+	}
+	taken := []Edit{edit(10, 10, "\"fmt\"\n\t"), edit(20, 30, "x")}
+	for _, tt := range []struct {
+		name string
+		e    Edit
+		want bool
+	}{
+		{"identical insertion", edit(10, 10, "\"fmt\"\n\t"), false},
+		{"other insertion at the same point", edit(10, 10, "\"errors\"\n\t"), false},
+		{"insertion at the start of a replacement", edit(20, 20, "y"), false},
+		{"insertion inside a replacement", edit(25, 25, "y"), true},
+		{"overlapping replacement", edit(28, 35, "y"), true},
+		{"adjacent replacement", edit(30, 35, "y"), false},
+	} {
+		if got := conflicts(taken, tt.e); got != tt.want {
+			t.Errorf("%s: conflicts = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package cog
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// listFlag is a flag.Value holding a comma-separated list of strings.
+type listFlag []string
+
+func (l *listFlag) String() string { return strings.Join(*l, ",") }
+
+func (l *listFlag) Set(s string) error {
+	items := make([]string, 0)
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	*l = items
+	return nil
+}
+
+// contains reports whether s is one of the listed values.
+func (l *listFlag) contains(s string) bool {
+	for _, item := range *l {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// regexpFlag is a flag.Value holding an optional regular expression.
+type regexpFlag struct {
+	re *regexp.Regexp
+}
+
+func (r *regexpFlag) String() string {
+	if r.re == nil {
+		return ""
+	}
+	return r.re.String()
+}
+
+func (r *regexpFlag) Set(s string) error {
+	if s == "" {
+		r.re = nil
+		return nil
+	}
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return fmt.Errorf("invalid regexp %q: %w", s, err)
+	}
+	r.re = re
+	return nil
+}
+
+// matches reports whether the expression is set and matches s.
+func (r *regexpFlag) matches(s string) bool {
+	return r.re != nil && r.re.MatchString(s)
+}
+
+// funcIndexFlag is a flag.Value holding a comma-separated list of
+// functions, each optionally followed by a colon and the index of one of
+// its parameters: "example.com/log.Infof:0,(*example.com/log.Logger).Debugf".
+type funcIndexFlag []funcIndex
+
+// A funcIndex is one entry of a funcIndexFlag. index is -1 when the entry
+// gives none.
+type funcIndex struct {
+	name  string
+	index int
+}
+
+func (f *funcIndexFlag) String() string {
+	items := make([]string, 0, len(*f))
+	for _, fi := range *f {
+		if fi.index < 0 {
+			items = append(items, fi.name)
+		} else {
+			items = append(items, fi.name+":"+strconv.Itoa(fi.index))
+		}
+	}
+	return strings.Join(items, ",")
+}
+
+func (f *funcIndexFlag) Set(s string) error {
+	items := make(funcIndexFlag, 0)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, index, hasIndex := strings.Cut(item, ":")
+		fi := funcIndex{name: name, index: -1}
+		if hasIndex {
+			n, err := strconv.Atoi(index)
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid parameter index in %q: want name:index with index 0 or more", item)
+			}
+			fi.index = n
+		}
+		items = append(items, fi)
+	}
+	*f = items
+	return nil
+}
+
+// lookup returns the index given for the function name, and whether name
+// is listed.
+func (f *funcIndexFlag) lookup(name string) (int, bool) {
+	for _, fi := range *f {
+		if fi.name == name {
+			return fi.index, true
+		}
+	}
+	return 0, false
+}
+
+// categoryFlag is a flag.Value holding a comma-separated list of rule
+// categories, each of which some registered rule must belong to.
+type categoryFlag []Category
+
+func (f *categoryFlag) String() string {
+	items := make([]string, 0, len(*f))
+	for _, c := range *f {
+		items = append(items, string(c))
+	}
+	return strings.Join(items, ",")
+}
+
+func (f *categoryFlag) Set(s string) error {
+	items := make(categoryFlag, 0)
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		c := Category(item)
+		if !slices.ContainsFunc(Rules, func(r *Rule) bool { return r.Category == c }) {
+			return fmt.Errorf("unknown rule category %q; run cog rules to list them", item)
+		}
+		items = append(items, c)
+	}
+	*f = items
+	return nil
+}
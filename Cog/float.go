@@ -0,0 +1,22 @@
+package cog
+
+import "math"
+
+// FloatEqual reports whether a and b are equal within eps, the comparison
+// CogFloatEquality recommends instead of ==. The tolerance is absolute for
+// values near zero and relative to the larger magnitude otherwise, so it
+// suits both tiny and huge values:
+//
+//	FloatEqual(0.1+0.2, 0.3, 1e-9) // true, although 0.1+0.2 != 0.3
+//
+// Equal infinities are equal; NaN is equal to nothing, as with ==.
+func FloatEqual(a, b, eps float64) bool {
+	if a == b { //cog:ignore CogFloatEquality -- exact equality covers the infinities
+		return true
+	}
+	if math.IsInf(a, 0) || math.IsInf(b, 0) {
+		return false // an infinity is only equal to itself
+	}
+	diff := math.Abs(a - b)
+	return diff <= eps || diff <= eps*max(math.Abs(a), math.Abs(b))
+}
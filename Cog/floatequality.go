@@ -0,0 +1,135 @@
+package cog
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// floatEqualityRule reports == and != between floating-point values, which
+// compares exact bit patterns that rounding rarely reproduces.
+//
+//	if total == 0.3 { // false for 0.1 + 0.2
+//
+// Compare within a tolerance instead, with cog.FloatEqual or
+// math.Abs(a-b) <= eps. Comparisons with a constant 0 are not reported by
+// default, since checking for an exact zero (a divisor, an unset value) is
+// usually intended; -floatequality.zero reports them too. `x != x` is
+// reported as the NaN test it is, with a fix to math.IsNaN, and a
+// comparison with math.NaN() is reported as always false or always true.
+var floatEqualityRule = &Rule{
+	ID:       "CogFloatEquality",
+	Doc:      "report exact equality comparisons between floating-point values",
+	Run:      runFloatEquality,
+	Category: CategoryTypes,
+	Fixable:  true,
+}
+
+// floatEqualityZero reports comparisons with a constant zero as well.
+var floatEqualityZero bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&floatEqualityZero, "floatequality.zero", false,
+		"also report float comparisons with a constant 0")
+}
+
+func runFloatEquality(p *Pass) {
+	for c := range p.Inspector.Root().Preorder((*ast.BinaryExpr)(nil)) {
+		bin, ok := c.Node().(*ast.BinaryExpr)
+		if !ok || (bin.Op != token.EQL && bin.Op != token.NEQ) {
+			continue
+		}
+		if !isFloat(p.TypesInfo.TypeOf(bin.X)) || !isFloat(p.TypesInfo.TypeOf(bin.Y)) {
+			continue
+		}
+		x, y := p.TypesInfo.Types[bin.X].Value, p.TypesInfo.Types[bin.Y].Value
+		if x != nil && y != nil {
+			continue // folded at compile time
+		}
+		if !floatEqualityZero && (isZeroConst(x) || isZeroConst(y)) {
+			continue
+		}
+
+		left, right := types.ExprString(bin.X), types.ExprString(bin.Y)
+		selfCompare := left == right && !hasCall(bin.X)
+		switch {
+		case selfCompare && bin.Op == token.NEQ:
+			p.Report(bin, left+" != "+left+" is a NaN test; write math.IsNaN("+left+")", isNaNFix(p, c, bin, false)...)
+		case selfCompare:
+			p.Report(bin, left+" == "+left+" is true unless "+left+" is NaN; write !math.IsNaN("+left+")",
+				isNaNFix(p, c, bin, true)...)
+		case isNaNCall(p, bin.X) || isNaNCall(p, bin.Y):
+			always := "false"
+			if bin.Op == token.NEQ {
+				always = "true"
+			}
+			p.Report(bin, "comparison with math.NaN() is always "+always+", since NaN equals nothing; use math.IsNaN")
+		default:
+			p.Report(bin, "exact comparison of floating-point values "+left+" "+bin.Op.String()+" "+right+
+				" is sensitive to rounding; compare within a tolerance, such as cog.FloatEqual("+left+", "+right+
+				", 1e-9) or math.Abs("+left+"-"+right+") <= eps")
+		}
+	}
+}
+
+// isFloat reports whether t is a floating-point type.
+func isFloat(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Info()&types.IsFloat != 0
+}
+
+// isZeroConst reports whether v is a numeric constant equal to 0.
+func isZeroConst(v constant.Value) bool {
+	return v != nil && v.Kind() != constant.Unknown && constant.Sign(v) == 0
+}
+
+// isNaNCall reports whether e is a call of math.NaN.
+func isNaNCall(p *Pass, e ast.Expr) bool {
+	call, ok := ast.Unparen(e).(*ast.CallExpr)
+	return ok && calleeName(p.TypesInfo, call) == "math.NaN"
+}
+
+// hasCall reports whether e contains a function call, so that evaluating it
+// twice may give two values.
+func hasCall(e ast.Expr) bool {
+	found := false
+	ast.Inspect(e, func(n ast.Node) bool {
+		if _, ok := n.(*ast.CallExpr); ok {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// isNaNFix rewrites `x != x` to `math.IsNaN(x)`, or `x == x` to
+// `!math.IsNaN(x)` when negate is set.
+func isNaNFix(p *Pass, c inspector.Cursor, bin *ast.BinaryExpr, negate bool) []analysis.SuggestedFix {
+	if !isFloat64(p.TypesInfo.TypeOf(bin.X)) {
+		return nil // math.IsNaN takes a float64
+	}
+	file := enclosingFile(c)
+	operand, ok := sourceText(p, bin.X)
+	if file == nil || !ok {
+		return nil
+	}
+	mathPkg, edits := importName(p, file, "math")
+	text := mathPkg + ".IsNaN(" + operand + ")"
+	if negate {
+		text = "!" + text
+	}
+	edits = append(edits, analysis.TextEdit{Pos: bin.Pos(), End: bin.End(), NewText: []byte(text)})
+	return []analysis.SuggestedFix{{Message: "Use math.IsNaN", TextEdits: edits}}
+}
+
+// isFloat64 reports whether t is float64 itself, not a named float type.
+func isFloat64(t types.Type) bool {
+	return types.Identical(t, types.Typ[types.Float64])
+}
@@ -0,0 +1,42 @@
+package cog
+
+import (
+	"math"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestFloatEquality(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), ruleAnalyzer(floatEqualityRule), "floatequality")
+}
+
+func TestFloatEqualityZero(t *testing.T) {
+	defer func(zero bool) { floatEqualityZero = zero }(floatEqualityZero)
+	floatEqualityZero = true
+	analysistest.Run(t, analysistest.TestData(), ruleAnalyzer(floatEqualityRule), "floatequalityzero")
+}
+
+func TestFloatEqual(t *testing.T) {
+	inf, nan := math.Inf(1), math.NaN()
+	for _, tt := range []struct {
+		a, b, eps float64
+		want      bool
+	}{
+		{0.1 + 0.2, 0.3, 1e-9, true},
+		{1, 1.1, 1e-9, false},
+		{0, 1e-12, 1e-9, true},         // absolute near zero
+		{1e20, 1e20 + 1e5, 1e-9, true}, // relative for large values
+		{1e20, 1.1e20, 1e-9, false},
+		{inf, inf, 1e-9, true},
+		{inf, -inf, 1e-9, false},
+		{inf, math.MaxFloat64, 1, false},
+		{nan, nan, 1e-9, false},
+		{nan, 0, math.Inf(1), false},
+		{0, nan, 1e-9, false},
+	} {
+		if got := FloatEqual(tt.a, tt.b, tt.eps); got != tt.want {
+			t.Errorf("FloatEqual(%v, %v, %v) = %v, want %v", tt.a, tt.b, tt.eps, got, tt.want)
+		}
+	}
+}
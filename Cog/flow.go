@@ -0,0 +1,153 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+// funcCFG builds the control-flow graph of a function body. Calls to panic,
+// os.Exit and log.Fatal* end their block without a successor.
+func funcCFG(p *Pass, body *ast.BlockStmt) *cfg.CFG {
+	return cfg.New(body, func(call *ast.CallExpr) bool {
+		return !isNoReturn(p, call)
+	})
+}
+
+// isNoReturn reports whether call never returns normally.
+func isNoReturn(p *Pass, call *ast.CallExpr) bool {
+	if id, ok := ast.Unparen(call.Fun).(*ast.Ident); ok {
+		if _, builtin := p.TypesInfo.Uses[id].(*types.Builtin); builtin && id.Name == "panic" {
+			return true
+		}
+	}
+	switch calleeName(p.TypesInfo, call) {
+	case "os.Exit", "log.Fatal", "log.Fatalf", "log.Fatalln", "log.Panic", "log.Panicf", "log.Panicln",
+		"(*testing.common).Fatal", "(*testing.common).Fatalf", "(*testing.common).FailNow",
+		"(*testing.common).Skip", "(*testing.common).Skipf", "(*testing.common).SkipNow":
+		return true
+	}
+	return false
+}
+
+// leakCheck describes a resource acquired by one statement that must be
+// released on every path to the function exit.
+type leakCheck struct {
+	// acquire is the acquiring statement; findLeak sets it. A path that
+	// loops back to it re-acquires the resource and is not followed.
+	acquire ast.Stmt
+
+	// released reports whether n releases the resource or hands it off to
+	// code that becomes responsible for it.
+	released func(n ast.Node) bool
+
+	// unheld returns, for a branch condition, the index of the successor
+	// taken when the resource was never acquired (typically the
+	// `err != nil` branch), or -1 when the condition says nothing about it.
+	unheld func(cond ast.Expr) int
+}
+
+// findLeak searches g for a path from the statement acquire to the function
+// exit that never passes a releasing node. It returns the position of the
+// exit reached: the offending return statement or the closing brace of
+// body. Paths ending in a panic or another non-returning call do not count.
+func findLeak(p *Pass, g *cfg.CFG, body *ast.BlockStmt, acquire ast.Stmt, lc leakCheck) (token.Pos, bool) {
+	for _, b := range g.Blocks {
+		for i, n := range b.Nodes {
+			if n == acquire {
+				lc.acquire = acquire
+				return walkLeak(p, b, i+1, body, lc, make(map[*cfg.Block]bool))
+			}
+		}
+	}
+	return token.NoPos, false
+}
+
+// walkLeak continues findLeak from node index start of block b.
+func walkLeak(p *Pass, b *cfg.Block, start int, body *ast.BlockStmt, lc leakCheck, seen map[*cfg.Block]bool) (token.Pos, bool) {
+	if start == 0 {
+		if seen[b] {
+			return token.NoPos, false
+		}
+		seen[b] = true
+	}
+	if !b.Live {
+		return token.NoPos, false
+	}
+
+	for _, n := range b.Nodes[start:] {
+		if n == lc.acquire || lc.released(n) {
+			return token.NoPos, false
+		}
+	}
+
+	if len(b.Succs) == 0 {
+		if len(b.Nodes) > 0 {
+			switch last := b.Nodes[len(b.Nodes)-1].(type) {
+			case *ast.ReturnStmt:
+				return last.Pos(), true
+			case *ast.ExprStmt:
+				if call, ok := last.X.(*ast.CallExpr); ok && isNoReturn(p, call) {
+					return token.NoPos, false
+				}
+			}
+		}
+		return body.Rbrace, true
+	}
+
+	skip := -1
+	if len(b.Succs) == 2 && len(b.Nodes) > 0 {
+		if cond, ok := b.Nodes[len(b.Nodes)-1].(ast.Expr); ok {
+			skip = lc.unheld(cond)
+		}
+	}
+	for i, succ := range b.Succs {
+		if i == skip {
+			continue
+		}
+		if pos, ok := walkLeak(p, succ, 0, body, lc, seen); ok {
+			return pos, true
+		}
+	}
+	return token.NoPos, false
+}
+
+// nilCheckBranch returns the successor index taken when v is nil, for a
+// condition of the form `v == nil` or `v != nil`, or -1 otherwise. With
+// whenNonNil it returns the index taken when v is non-nil instead, which is
+// the branch on which a resource returned alongside the error v is unheld.
+func nilCheckBranch(p *Pass, cond ast.Expr, v *types.Var, whenNonNil bool) int {
+	bin, ok := ast.Unparen(cond).(*ast.BinaryExpr)
+	if !ok || (bin.Op != token.EQL && bin.Op != token.NEQ) || v == nil {
+		return -1
+	}
+	x, y := ast.Unparen(bin.X), ast.Unparen(bin.Y)
+	if isNilIdent(p, x) {
+		x, y = y, x
+	}
+	id, ok := x.(*ast.Ident)
+	if !ok || p.TypesInfo.Uses[id] != v || !isNilIdent(p, y) {
+		return -1
+	}
+	// Succs[0] is the true branch.
+	nilBranch := 0
+	if bin.Op == token.NEQ {
+		nilBranch = 1
+	}
+	if whenNonNil {
+		return 1 - nilBranch
+	}
+	return nilBranch
+}
+
+// isNilIdent reports whether e is the predeclared nil.
+func isNilIdent(p *Pass, e ast.Expr) bool {
+	id, ok := e.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	_, isNil := p.TypesInfo.Uses[id].(*types.Nil)
+	return isNil
+}
@@ -0,0 +1,63 @@
+package cog
+
+import "unicode/utf8"
+
+// fmtVerb is one formatting directive in a printf-style format string.
+type fmtVerb struct {
+	verb rune // the verb letter, e.g. 'v' or 'w'
+	arg  int  // index of the operand it consumes, counting from 0
+	pos  int  // byte offset of the '%' in the format string
+}
+
+// parseFormat splits a printf-style format string into its verbs. It reports
+// false for formats it does not model (explicit argument indexes such as
+// %[1]d), so callers can stay quiet rather than guess.
+func parseFormat(format string) ([]fmtVerb, bool) {
+	verbs := make([]fmtVerb, 0)
+	arg := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		start := i
+		i++
+		if i < len(format) && format[i] == '%' {
+			continue
+		}
+		for i < len(format) && isFmtFlag(format[i]) {
+			i++
+		}
+		i, arg = skipFmtNumber(format, i, arg)
+		if i < len(format) && format[i] == '.' {
+			i++
+			i, arg = skipFmtNumber(format, i, arg)
+		}
+		if i >= len(format) {
+			return verbs, true
+		}
+		if format[i] == '[' {
+			return nil, false
+		}
+		r, size := utf8.DecodeRuneInString(format[i:])
+		verbs = append(verbs, fmtVerb{verb: r, arg: arg, pos: start})
+		arg++
+		i += size - 1
+	}
+	return verbs, true
+}
+
+func isFmtFlag(c byte) bool {
+	return c == '+' || c == '-' || c == '#' || c == ' ' || c == '0'
+}
+
+// skipFmtNumber skips a width or precision at format[i:]. A '*' consumes an
+// operand, so the updated operand index is returned alongside the offset.
+func skipFmtNumber(format string, i, arg int) (int, int) {
+	if i < len(format) && format[i] == '*' {
+		return i + 1, arg + 1
+	}
+	for i < len(format) && format[i] >= '0' && format[i] <= '9' {
+		i++
+	}
+	return i, arg
+}
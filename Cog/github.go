@@ -0,0 +1,64 @@
+package cog
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// WriteGitHub writes findings to w as GitHub Actions workflow commands, one
+// per line, which the Actions runner turns into annotations on the pull
+// request:
+//
+//	::error file=examples/before.go,line=55,col=9,endLine=55,endColumn=12,title=CogTypedNil::err may be a nil ...
+//
+// Errors become ::error, warnings ::warning and infos ::notice commands.
+// Paths under the working directory, the repository checkout in a
+// workflow, are written relative to it.
+func WriteGitHub(w io.Writer, findings []Finding) error {
+	sorted := slices.Clone(findings)
+	sortFindings(sorted)
+	var b strings.Builder
+	for _, f := range sorted {
+		props := []string{
+			"file=" + githubProperty(filepath.ToSlash(displayPath(f.Pos.Filename))),
+			"line=" + strconv.Itoa(f.Pos.Line),
+			"col=" + strconv.Itoa(f.Pos.Column),
+		}
+		if f.End.IsValid() {
+			props = append(props, "endLine="+strconv.Itoa(f.End.Line), "endColumn="+strconv.Itoa(f.End.Column))
+		}
+		props = append(props, "title="+githubProperty(f.Rule))
+		b.WriteString("::" + githubCommand(f.Severity) + " " + strings.Join(props, ",") + "::" +
+			githubData(f.Message) + "\n")
+	}
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("cog: github: %w", err)
+	}
+	return nil
+}
+
+// githubCommand maps a severity to the workflow command annotating it.
+func githubCommand(sev Severity) string {
+	switch sev {
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "notice"
+	}
+	return "error"
+}
+
+// githubData escapes the message of a workflow command.
+func githubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// githubProperty escapes a property value of a workflow command, which
+// also may not contain the separators ':' and ','.
+func githubProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
@@ -0,0 +1,174 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// httpNoTimeoutsRule reports HTTP servers started without timeouts.
+//
+//	http.ListenAndServe(":8080", mux) // no timeouts can be set
+//
+//	srv := &http.Server{Addr: ":8080", Handler: mux} // ReadTimeout, WriteTimeout, IdleTimeout unset
+//
+// An http.Server without timeouts waits forever for a slow client, so a
+// few connections that trickle their headers in (Slowloris) or never read
+// the response hold server resources indefinitely. http.ListenAndServe and
+// http.ListenAndServeTLS use such a server and cannot be given timeouts;
+// the fix replaces them with an http.Server literal that sets each field
+// of -httpnotimeouts.fields to a conservative default. An http.Server
+// literal is reported with the fields of -httpnotimeouts.fields it leaves
+// unset, unless the variable holding it has them assigned later.
+var httpNoTimeoutsRule = &Rule{
+	ID:       "CogHTTPNoTimeouts",
+	Doc:      "report HTTP servers started without read, write and idle timeouts",
+	Run:      runHTTPNoTimeouts,
+	Category: CategorySecurity,
+	Fixable:  true,
+}
+
+// httpTimeoutFields are the http.Server fields a server must set.
+var httpTimeoutFields = listFlag{"ReadTimeout", "WriteTimeout", "IdleTimeout"}
+
+func init() {
+	Analyzer.Flags.Var(&httpTimeoutFields, "httpnotimeouts.fields",
+		"comma-separated http.Server fields that CogHTTPNoTimeouts requires, e.g. ReadHeaderTimeout,WriteTimeout")
+}
+
+// httpTimeoutDefaults are the values the fix gives each timeout field.
+var httpTimeoutDefaults = map[string]string{
+	"ReadHeaderTimeout": "5 * time.Second",
+	"ReadTimeout":       "10 * time.Second",
+	"WriteTimeout":      "30 * time.Second",
+	"IdleTimeout":       "120 * time.Second",
+}
+
+func runHTTPNoTimeouts(p *Pass) {
+	for c := range p.Inspector.Root().Preorder((*ast.CallExpr)(nil), (*ast.CompositeLit)(nil)) {
+		switch n := c.Node().(type) {
+		case *ast.CallExpr:
+			name := calleeName(p.TypesInfo, n)
+			if name != "net/http.ListenAndServe" && name != "net/http.ListenAndServeTLS" {
+				continue
+			}
+			fn := name[strings.LastIndex(name, ".")+1:]
+			p.Report(n, "http."+fn+" serves with no timeouts, so slow clients can hold connections open "+
+				"forever; start an http.Server with "+strings.Join(httpTimeoutFields, ", ")+" set and call its "+
+				fn+" method", serverFix(p, c, n, fn)...)
+		case *ast.CompositeLit:
+			if !isNamed(p.TypesInfo.TypeOf(n), "net/http", "Server") {
+				continue
+			}
+			missing := unsetTimeouts(p, c, n)
+			if len(missing) == 0 {
+				continue
+			}
+			p.Report(n, "http.Server leaves "+strings.Join(missing, ", ")+" unset, so slow clients can hold "+
+				"connections open forever; set explicit timeouts")
+		}
+	}
+}
+
+// unsetTimeouts returns the fields of httpTimeoutFields that the literal
+// lit at c does not set, and that are not assigned later to the variable
+// it initializes.
+func unsetTimeouts(p *Pass, c inspector.Cursor, lit *ast.CompositeLit) []string {
+	set := make(map[string]bool)
+	for _, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			if key, ok := kv.Key.(*ast.Ident); ok {
+				set[key.Name] = true
+			}
+		}
+	}
+	if v := serverVar(p, c); v != nil {
+		for ac := range p.Inspector.Root().Preorder((*ast.AssignStmt)(nil)) {
+			assign, ok := ac.Node().(*ast.AssignStmt)
+			if !ok {
+				continue
+			}
+			for _, lhs := range assign.Lhs {
+				sel, ok := lhs.(*ast.SelectorExpr)
+				if !ok {
+					continue
+				}
+				if id, ok := ast.Unparen(sel.X).(*ast.Ident); ok && p.TypesInfo.Uses[id] == v {
+					set[sel.Sel.Name] = true
+				}
+			}
+		}
+	}
+	missing := make([]string, 0, len(httpTimeoutFields))
+	for _, field := range httpTimeoutFields {
+		if !set[field] {
+			missing = append(missing, field)
+		}
+	}
+	return missing
+}
+
+// serverVar returns the variable the literal at c, or its address, is
+// assigned to, or nil.
+func serverVar(p *Pass, c inspector.Cursor) *types.Var {
+	cur := c.Parent()
+	if u, ok := cur.Node().(*ast.UnaryExpr); ok && u.Op == token.AND {
+		cur = cur.Parent()
+	}
+	switch n := cur.Node().(type) {
+	case *ast.AssignStmt:
+		if len(n.Lhs) == 1 && len(n.Rhs) == 1 {
+			return localVar(p, n.Lhs[0])
+		}
+	case *ast.ValueSpec:
+		if len(n.Names) == 1 && len(n.Values) == 1 {
+			return identVar(p, n.Names[0])
+		}
+	}
+	return nil
+}
+
+// serverFix rewrites http.ListenAndServe(addr, h) to
+// (&http.Server{Addr: addr, Handler: h, ...}).ListenAndServe(), and
+// ListenAndServeTLS likewise, setting each required timeout to its default.
+func serverFix(p *Pass, c inspector.Cursor, call *ast.CallExpr, fn string) []analysis.SuggestedFix {
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok || len(call.Args) < 2 || call.Ellipsis.IsValid() {
+		return nil
+	}
+	args := make([]string, 0, len(call.Args))
+	for _, arg := range call.Args {
+		text, ok := sourceText(p, arg)
+		if !ok {
+			return nil
+		}
+		args = append(args, text)
+	}
+	file := enclosingFile(c)
+	if file == nil {
+		return nil
+	}
+	timeName, edits := importName(p, file, "time")
+	fields := []string{"Addr: " + args[0]}
+	if handler := call.Args[len(call.Args)-1]; !isNilIdent(p, handler) {
+		fields = append(fields, "Handler: "+args[len(args)-1])
+	}
+	for _, field := range httpTimeoutFields {
+		value, ok := httpTimeoutDefaults[field]
+		if !ok {
+			return nil // no default known for a configured field
+		}
+		fields = append(fields, field+": "+strings.Replace(value, "time.", timeName+".", 1))
+	}
+	method := fn + "()"
+	if fn == "ListenAndServeTLS" {
+		method = fn + "(" + strings.Join(args[1:len(args)-1], ", ") + ")"
+	}
+	text := "(&" + types.ExprString(sel.X) + ".Server{" + strings.Join(fields, ", ") + "})." + method
+	edits = append(edits, analysis.TextEdit{Pos: call.Pos(), End: call.End(), NewText: []byte(text)})
+	return []analysis.SuggestedFix{{Message: "Serve with an http.Server that sets timeouts", TextEdits: edits}}
+}
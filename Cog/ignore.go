@@ -0,0 +1,139 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// An ignoreDirective is a `//cog:ignore` comment suppressing findings on
+// one line:
+//
+//	x := f() //cog:ignore
+//	x := f() //cog:ignore CogTypedNil,CogBodyClose
+//
+//	//cog:ignore-next-line CogIgnoredError
+//	_ = w.Close()
+//
+// The rule list is optional; without it every rule is suppressed. Text
+// after the list (or after the directive, when its first word does not
+// start with "Cog") is a free-form reason.
+type ignoreDirective struct {
+	comment *ast.Comment
+	rules   []string // nil for every rule
+	used    bool
+}
+
+// covers reports whether the directive suppresses rule.
+func (d *ignoreDirective) covers(rule string) bool {
+	return d.rules == nil || slices.Contains(d.rules, rule)
+}
+
+// reportUnusedIgnores reports directives that suppressed nothing.
+var reportUnusedIgnores bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&reportUnusedIgnores, "report-unused-ignores", false,
+		"report //cog:ignore comments that suppress no finding")
+}
+
+// unusedIgnoreRule is the pseudo-rule under which unused directives are
+// reported. It is not in Rules: it runs after them, and only on request.
+var unusedIgnoreRule = &Rule{
+	ID:       "CogUnusedIgnore",
+	Doc:      "report //cog:ignore comments that suppress no finding",
+	Category: CategoryStyle,
+	Fixable:  true,
+	Severity: SeverityWarning,
+}
+
+// ignoreDirectives indexes the package's `//cog:ignore` comments by the
+// line they suppress.
+func ignoreDirectives(pass *analysis.Pass) map[lineKey][]*ignoreDirective {
+	directives := make(map[lineKey][]*ignoreDirective)
+	for _, f := range pass.Files {
+		for _, group := range f.Comments {
+			for _, c := range group.List {
+				text, ok := strings.CutPrefix(c.Text, "//cog:ignore")
+				if !ok {
+					continue
+				}
+				line := lineOf(pass.Fset, c.Pos())
+				if rest, ok := strings.CutPrefix(text, "-next-line"); ok {
+					text = rest
+					line.line++
+				}
+				if text != "" && text[0] != ' ' && text[0] != '\t' {
+					continue // some other directive, like //cog:ignored
+				}
+				d := &ignoreDirective{comment: c}
+				if fields := strings.Fields(text); len(fields) > 0 && strings.HasPrefix(fields[0], "Cog") {
+					d.rules = strings.Split(fields[0], ",")
+				}
+				directives[line] = append(directives[line], d)
+			}
+		}
+	}
+	return directives
+}
+
+// suppressed reports whether a directive on the line of rng suppresses the
+// current rule, marking the directive used.
+func (p *Pass) suppressed(rng analysis.Range) bool {
+	for _, d := range p.ignores[lineOf(p.Fset, rng.Pos())] {
+		if d.covers(p.rule.ID) {
+			d.used = true
+			return true
+		}
+	}
+	return false
+}
+
+// reportUnused reports through p, whose rule is unusedIgnoreRule, every
+// directive that suppressed nothing although a rule it covers ran.
+func reportUnused(p *Pass, directives map[lineKey][]*ignoreDirective, ran []string) {
+	unused := make([]*ignoreDirective, 0)
+	for _, ds := range directives {
+		for _, d := range ds {
+			if !d.used && slices.ContainsFunc(ran, d.covers) {
+				unused = append(unused, d)
+			}
+		}
+	}
+	slices.SortFunc(unused, func(a, b *ignoreDirective) int { return int(a.comment.Pos() - b.comment.Pos()) })
+
+	for _, d := range unused {
+		what := "finding"
+		if d.rules != nil {
+			what = strings.Join(d.rules, " or ") + " finding"
+		}
+		p.Report(d.comment, "this //cog:ignore suppresses no "+what+"; remove it", analysis.SuggestedFix{
+			Message:   "Remove the unused directive",
+			TextEdits: []analysis.TextEdit{commentRemoval(p, d.comment)},
+		})
+	}
+}
+
+// commentRemoval deletes c with the blanks before it, or its whole line
+// when the comment stands alone.
+func commentRemoval(p *Pass, c *ast.Comment) analysis.TextEdit {
+	edit := analysis.TextEdit{Pos: c.Pos(), End: c.End()}
+	position := p.Fset.Position(c.Pos())
+	src, err := p.ReadFile(position.Filename)
+	if err != nil {
+		return edit // FALLBACK: leave the blanks for gofmt
+	}
+	start := position.Offset
+	for start > 0 && (src[start-1] == ' ' || src[start-1] == '\t') {
+		start--
+	}
+	edit.Pos -= token.Pos(position.Offset - start)
+	end := p.Fset.Position(c.End()).Offset
+	if (start == 0 || src[start-1] == '\n') && end < len(src) && src[end] == '\n' {
+		edit.End++ // the line holds only the comment
+	}
+	return edit
+}
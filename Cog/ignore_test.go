@@ -0,0 +1,14 @@
+package cog
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestUnusedIgnore(t *testing.T) {
+	saved := reportUnusedIgnores
+	t.Cleanup(func() { reportUnusedIgnores = saved })
+	reportUnusedIgnores = true
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), ruleAnalyzer(bareReturnRule), "unusedignore")
+}
@@ -0,0 +1,253 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// ignoredErrorRule reports Mistake 2: an error result that is discarded,
+// either by assigning it to the blank identifier or by calling the function
+// as a statement.
+//
+//	data, _ := readFile("config.json") // error silently ignored
+//	json.Unmarshal(data, &result)       // error dropped entirely
+//
+// Calls listed in -ignorederror.allow are exempt, and so are fmt.Fprint,
+// Fprintf and Fprintln into a writer whose Write method is listed, such as
+// a *bytes.Buffer, as is any line annotated with an `// IGNORE:` comment
+// explaining why the error does not matter.
+// The fix checks the error and returns it wrapped with the callee's name,
+// as CogErrorWrap asks, or logs it in a function that returns no error.
+var ignoredErrorRule = &Rule{
+	ID:       "CogIgnoredError",
+	Doc:      "report error results that are discarded without comment",
+	Run:      runIgnoredError,
+	Category: CategoryErrors,
+	Fixable:  true,
+}
+
+// ignoredErrorAllow lists functions whose errors are conventionally ignored.
+var ignoredErrorAllow = listFlag{
+	"fmt.Print",
+	"fmt.Printf",
+	"fmt.Println",
+	"(*bytes.Buffer).Write",
+	"(*bytes.Buffer).WriteByte",
+	"(*bytes.Buffer).WriteRune",
+	"(*bytes.Buffer).WriteString",
+	"(*strings.Builder).Write",
+	"(*strings.Builder).WriteByte",
+	"(*strings.Builder).WriteRune",
+	"(*strings.Builder).WriteString",
+}
+
+func init() {
+	Analyzer.Flags.Var(&ignoredErrorAllow, "ignorederror.allow",
+		"comma-separated functions whose error results may be ignored, e.g. fmt.Println,(*bytes.Buffer).Write")
+}
+
+func runIgnoredError(p *Pass) {
+	annotated := markedLines(p, "IGNORE:")
+	ignored := func(call *ast.CallExpr) bool {
+		return annotated[lineOf(p.Fset, call.Pos())] || ignoredErrorAllow.contains(calleeName(p.TypesInfo, call)) ||
+			fprintToAllowed(p, call)
+	}
+
+	filter := []ast.Node{(*ast.ExprStmt)(nil), (*ast.AssignStmt)(nil), (*ast.ValueSpec)(nil)}
+	for c := range p.Inspector.Root().Preorder(filter...) {
+		switch n := c.Node().(type) {
+		case *ast.ExprStmt:
+			call, ok := ast.Unparen(n.X).(*ast.CallExpr)
+			if !ok {
+				continue
+			}
+			results := resultTypes(p.TypesInfo, call)
+			if len(results) == 0 || !isErrorType(results[len(results)-1]) || ignored(call) {
+				continue
+			}
+			p.Report(call, "error returned by "+types.ExprString(call.Fun)+
+				" is discarded; handle it with `if err := ...; err != nil`",
+				wrapInErrorCheck(p, c, n, len(results))...)
+
+		case *ast.AssignStmt:
+			checkBlankError(p, c, n, n.Lhs, n.Rhs, ignored)
+
+		case *ast.ValueSpec:
+			lhs := make([]ast.Expr, 0, len(n.Names))
+			for _, name := range n.Names {
+				lhs = append(lhs, name)
+			}
+			checkBlankError(p, c, nil, lhs, n.Values, ignored)
+		}
+	}
+}
+
+// fprintToAllowed reports whether call is a fmt.Fprint, Fprintf or Fprintln
+// call whose writer's static type has its Write method in
+// -ignorederror.allow: such a call fails only when that Write does.
+func fprintToAllowed(p *Pass, call *ast.CallExpr) bool {
+	switch calleeName(p.TypesInfo, call) {
+	case "fmt.Fprint", "fmt.Fprintf", "fmt.Fprintln":
+	default:
+		return false
+	}
+	if len(call.Args) == 0 {
+		return false
+	}
+	w := p.TypesInfo.TypeOf(call.Args[0])
+	return w != nil && ignoredErrorAllow.contains("("+types.TypeString(w, nil)+").Write")
+}
+
+// checkBlankError reports `v, _ := f()` and `_ = f()` where the blank
+// identifier receives an error result. stmt is nil for `var` declarations,
+// which get no suggested fix.
+func checkBlankError(p *Pass, c inspector.Cursor, stmt *ast.AssignStmt, lhs, rhs []ast.Expr, ignored func(*ast.CallExpr) bool) {
+	// Only the single-call form `a, b := f()` spreads one call's results
+	// across several operands; otherwise operands pair up one to one.
+	if len(rhs) == 1 && len(lhs) > 1 {
+		call, ok := ast.Unparen(rhs[0]).(*ast.CallExpr)
+		if !ok {
+			return
+		}
+		results := resultTypes(p.TypesInfo, call)
+		if len(results) != len(lhs) {
+			return
+		}
+		for i, r := range results {
+			if isBlank(lhs[i]) && isErrorType(r) && !ignored(call) {
+				fixes := make([]analysis.SuggestedFix, 0, 1)
+				if stmt != nil {
+					fixes = bindErrorAndCheck(p, c, stmt, lhs[i])
+				}
+				p.Report(call, "error returned by "+types.ExprString(call.Fun)+
+					" is assigned to _; bind it and check `if err != nil`", fixes...)
+				return
+			}
+		}
+		return
+	}
+
+	for i, r := range rhs {
+		if i >= len(lhs) || !isBlank(lhs[i]) {
+			continue
+		}
+		call, ok := ast.Unparen(r).(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+		results := resultTypes(p.TypesInfo, call)
+		if len(results) == 1 && isErrorType(results[0]) && !ignored(call) {
+			fixes := make([]analysis.SuggestedFix, 0, 1)
+			if stmt != nil && len(lhs) == 1 {
+				fixes = wrapInErrorCheck(p, c, stmt, 1)
+			}
+			p.Report(call, "error returned by "+types.ExprString(call.Fun)+
+				" is assigned to _; handle it with `if err := ...; err != nil`", fixes...)
+		}
+	}
+}
+
+// wrapInErrorCheck turns the statement `f()` (or `_ = f()`) at c into
+// `if _, err := f(); err != nil { ... }`, where f has n results.
+func wrapInErrorCheck(p *Pass, c inspector.Cursor, stmt ast.Stmt, n int) []analysis.SuggestedFix {
+	if !inStatementList(c) {
+		return nil
+	}
+	indent, eol := lineIndent(p, stmt.Pos()), lineEnd(p, stmt.End())
+	body, edits, ok := errorHandler(p, c, stmt)
+	if !ok {
+		return nil
+	}
+
+	lhs := strings.Repeat("_, ", n-1) + "err"
+	start := stmt.Pos()
+	if assign, ok := stmt.(*ast.AssignStmt); ok {
+		start = assign.Rhs[0].Pos() // drop the `_ = `
+	}
+	edits = append(edits,
+		analysis.TextEdit{Pos: stmt.Pos(), End: start, NewText: []byte("if " + lhs + " := ")},
+		analysis.TextEdit{Pos: stmt.End(), End: stmt.End(), NewText: []byte("; err != nil {")},
+		analysis.TextEdit{Pos: eol, End: eol, NewText: []byte("\n" + indent + "\t" + body + "\n" + indent + "}")},
+	)
+	return []analysis.SuggestedFix{{Message: "Check the error", TextEdits: edits}}
+}
+
+// bindErrorAndCheck turns `v, _ := f()` into `v, err := f()` followed by an
+// `if err != nil` block, where blank is the `_` receiving the error.
+func bindErrorAndCheck(p *Pass, c inspector.Cursor, stmt *ast.AssignStmt, blank ast.Expr) []analysis.SuggestedFix {
+	if !inStatementList(c) {
+		return nil
+	}
+	// With `=`, err must already be an error variable in scope; with `:=`,
+	// it must not be declared in this scope with another type.
+	scope := p.Pkg.Scope().Innermost(stmt.Pos())
+	if scope == nil {
+		return nil
+	}
+	if stmt.Tok == token.ASSIGN {
+		if _, obj := scope.LookupParent("err", stmt.Pos()); obj == nil || !isErrorType(obj.Type()) {
+			return nil
+		}
+	} else if obj := scope.Lookup("err"); obj != nil && !isErrorType(obj.Type()) {
+		return nil
+	}
+
+	indent, eol := lineIndent(p, stmt.Pos()), lineEnd(p, stmt.End())
+	body, edits, ok := errorHandler(p, c, stmt)
+	if !ok {
+		return nil
+	}
+	edits = append(edits,
+		analysis.TextEdit{Pos: blank.Pos(), End: blank.End(), NewText: []byte("err")},
+		analysis.TextEdit{Pos: eol, End: eol, NewText: []byte("\n" + indent + "if err != nil {\n" + indent + "\t" + body + "\n" + indent + "}")},
+	)
+	return []analysis.SuggestedFix{{Message: "Bind and check the error", TextEdits: edits}}
+}
+
+// errorHandler renders the statement that handles err inside the enclosing
+// function: `return <zero values>, fmt.Errorf("<callee>: %w", err)` when it
+// returns an error, otherwise a log call marked TODO. Any import the
+// statement needs is returned as an edit.
+func errorHandler(p *Pass, c inspector.Cursor, stmt ast.Stmt) (string, []analysis.TextEdit, bool) {
+	file := enclosingFile(c)
+	sig := enclosingSignature(p, c)
+	if file == nil || sig == nil {
+		return "", nil, false
+	}
+	callee := "call"
+	ast.Inspect(stmt, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok {
+			callee = types.ExprString(call.Fun)
+			return false
+		}
+		return true
+	})
+	fmtPkg, imports := importName(p, file, "fmt")
+	if ret, ok := returnZeros(p, file, sig, fmtPkg+".Errorf("+strconv.Quote(callee+": %w")+", err)"); ok {
+		return "return " + ret, imports, true
+	}
+	logPkg, edits := importName(p, file, "log")
+	return logPkg + ".Printf(" + strconv.Quote(callee+": %v") + ", err) // TODO: handle error", edits, true
+}
+
+// inStatementList reports whether the statement at c sits directly in a
+// block or case body, where statements can be inserted after it.
+func inStatementList(c inspector.Cursor) bool {
+	switch c.Parent().Node().(type) {
+	case *ast.BlockStmt, *ast.CaseClause, *ast.CommClause:
+		return true
+	}
+	return false
+}
+
+// isBlank reports whether e is the blank identifier.
+func isBlank(e ast.Expr) bool {
+	id, ok := e.(*ast.Ident)
+	return ok && id.Name == "_"
+}
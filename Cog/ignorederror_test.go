@@ -0,0 +1,26 @@
+package cog
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestIgnoredError(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), ruleAnalyzer(ignoredErrorRule), "ignorederror")
+}
+
+// TestIgnoredErrorFixWrapsErrors checks that the fixed code passes
+// CogErrorWrap: the errors it returns are wrapped.
+func TestIgnoredErrorFixWrapsErrors(t *testing.T) {
+	golden, err := os.ReadFile(filepath.Join("testdata", "src", "ignorederror", "a.go.golden"))
+	if err != nil {
+		t.Fatalf("reading the golden file: %v", err)
+	}
+	fixed := regexp.MustCompile(` // want .*`).ReplaceAllString(string(golden), "")
+	dir := writeModule(t, map[string]string{"src/ignorederror/a.go": fixed})
+	analysistest.Run(t, dir, ruleAnalyzer(errorWrapRule), "ignorederror")
+}
@@ -0,0 +1,174 @@
+package cog
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// indexBoundsRule reports a constant index into the result of a split that
+// was never checked to be long enough.
+//
+//	parts := strings.Split(addr, ":")
+//	return parts[1] // panics when addr has no colon
+//
+// strings.Split, strings.Fields and their bytes counterparts return as few
+// elements as the input has separators, so indexing past the first one
+// assumes something about the input. The rule is conservative: it fires
+// only for an index above 0, into a local variable assigned once from such
+// a split, when no len of the variable appears between the split and the
+// index. Indexing the call directly, strings.Split(s, ":")[1], is reported
+// too. An `if len(parts) < 2` guard, or strings.Cut for a single
+// separator, handles the short input.
+var indexBoundsRule = &Rule{
+	ID:       "CogIndexBounds",
+	Doc:      "report constant indexes into strings.Split and strings.Fields results without a length check",
+	Run:      runIndexBounds,
+	Category: CategoryErrors,
+}
+
+// splitFuncs are the functions whose result length depends on the input.
+var splitFuncs = []string{
+	"strings.Split", "strings.SplitN", "strings.SplitAfter", "strings.SplitAfterN",
+	"strings.Fields", "strings.FieldsFunc",
+	"bytes.Split", "bytes.SplitN", "bytes.SplitAfter", "bytes.SplitAfterN",
+	"bytes.Fields", "bytes.FieldsFunc",
+}
+
+// A splitIndex is the first unchecked constant index into a split result,
+// with the largest index used up to the first length check.
+type splitIndex struct {
+	first *ast.IndexExpr
+	split *ast.CallExpr
+	max   int64
+}
+
+func runIndexBounds(p *Pass) {
+	unchecked := make(map[*types.Var]*splitIndex)
+	var order []*types.Var
+	for c := range p.Inspector.Root().Preorder((*ast.IndexExpr)(nil)) {
+		idx, ok := c.Node().(*ast.IndexExpr)
+		if !ok {
+			continue
+		}
+		k, ok := constIndex(p, idx.Index)
+		if !ok || k <= 0 {
+			continue
+		}
+		if call, ok := ast.Unparen(idx.X).(*ast.CallExpr); ok && isSplit(p, call) {
+			name := calleeName(p.TypesInfo, call)
+			msg := types.ExprString(idx) + " panics when " + name + " returns fewer than " +
+				strconv.FormatInt(k+1, 10) + " elements; assign the result and check its length first"
+			if strings.Contains(name, "Split") {
+				msg += ", or use " + strings.Split(name, ".")[0] + ".Cut for a single separator"
+			}
+			p.Report(idx, msg)
+			continue
+		}
+		v := localVar(p, ast.Unparen(idx.X))
+		if v == nil {
+			continue
+		}
+		_, body := enclosingFunc(c)
+		if si, ok := unchecked[v]; ok {
+			if si != nil && !lenChecked(p, si.split.End(), idx.Pos(), v, body) {
+				si.max = max(si.max, k)
+			}
+			continue
+		}
+		split := onlySplit(p, body, v)
+		if split == nil || split.End() > idx.Pos() || lenChecked(p, split.End(), idx.Pos(), v, body) {
+			unchecked[v] = nil // checked or not a split: never report v
+			continue
+		}
+		unchecked[v] = &splitIndex{first: idx, split: split, max: k}
+		order = append(order, v)
+	}
+	for _, v := range order {
+		si := unchecked[v]
+		name, n := v.Name(), strconv.FormatInt(si.max+1, 10)
+		p.Report(si.first, types.ExprString(si.first)+" assumes "+calleeName(p.TypesInfo, si.split)+
+			" returned at least "+n+" elements, and panics on input with fewer; check `if len("+name+") < "+n+"` first")
+	}
+}
+
+// constIndex returns the value of a constant integer index expression.
+func constIndex(p *Pass, e ast.Expr) (int64, bool) {
+	val := p.TypesInfo.Types[e].Value
+	if val == nil || val.Kind() != constant.Int {
+		return 0, false
+	}
+	return constant.Int64Val(val)
+}
+
+// isSplit reports whether call is one of splitFuncs.
+func isSplit(p *Pass, call *ast.CallExpr) bool {
+	return slices.Contains(splitFuncs, calleeName(p.TypesInfo, call))
+}
+
+// onlySplit returns the split call assigned to v when that is the only
+// assignment to v in body, and v's address is never taken.
+func onlySplit(p *Pass, body *ast.BlockStmt, v *types.Var) *ast.CallExpr {
+	if body == nil {
+		return nil
+	}
+	var split *ast.CallExpr
+	assigns := 0
+	ast.Inspect(body, func(n ast.Node) bool {
+		var lhs, rhs []ast.Expr
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			lhs, rhs = n.Lhs, n.Rhs
+		case *ast.ValueSpec:
+			for _, name := range n.Names {
+				lhs = append(lhs, name)
+			}
+			rhs = n.Values
+		case *ast.RangeStmt:
+			lhs = []ast.Expr{n.Key, n.Value}
+		case *ast.UnaryExpr:
+			if n.Op == token.AND && localVar(p, ast.Unparen(n.X)) == v {
+				assigns += 2 // may be written through the pointer
+			}
+			return true
+		default:
+			return true
+		}
+		for i, e := range lhs {
+			if e == nil || localVar(p, e) != v {
+				continue
+			}
+			assigns++
+			if len(lhs) == len(rhs) {
+				if call, ok := ast.Unparen(rhs[i]).(*ast.CallExpr); ok && isSplit(p, call) {
+					split = call
+				}
+			}
+		}
+		return true
+	})
+	if assigns != 1 {
+		return nil
+	}
+	return split
+}
+
+// lenChecked reports whether len(v) is called within n between from and to.
+func lenChecked(p *Pass, from, to token.Pos, v *types.Var, n ast.Node) bool {
+	found := false
+	ast.Inspect(n, func(n ast.Node) bool {
+		if found || n == nil || n.End() <= from || n.Pos() >= to {
+			return false
+		}
+		call, ok := n.(*ast.CallExpr)
+		if ok && isBuiltin(p, call.Fun, "len") && len(call.Args) == 1 && localVar(p, ast.Unparen(call.Args[0])) == v {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
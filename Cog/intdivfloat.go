@@ -0,0 +1,91 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// intDivFloatRule reports an integer division converted to a floating-point
+// type, which truncates before the conversion.
+//
+//	avg := float64(sum / count) // 7 / 2 gives 3.0, not 3.5
+//
+// The conversion applies to the already-truncated quotient; convert the
+// operands instead: float64(sum) / float64(count). Constant expressions
+// are not reported, nor are divisions of time.Duration values, where
+// float64(d / time.Millisecond) deliberately counts whole units. The fix
+// converts each operand, leaving untyped constants as they are.
+var intDivFloatRule = &Rule{
+	ID:       "CogIntDivFloat",
+	Doc:      "report integer divisions whose truncated result is converted to a float",
+	Run:      runIntDivFloat,
+	Category: CategoryTypes,
+	Fixable:  true,
+}
+
+func runIntDivFloat(p *Pass) {
+	for c := range p.Inspector.Root().Preorder((*ast.CallExpr)(nil)) {
+		call, ok := c.Node().(*ast.CallExpr)
+		if !ok || len(call.Args) != 1 {
+			continue
+		}
+		if tv := p.TypesInfo.Types[call.Fun]; !tv.IsType() || !isFloat(tv.Type) {
+			continue
+		}
+		div, ok := ast.Unparen(call.Args[0]).(*ast.BinaryExpr)
+		if !ok || div.Op != token.QUO || p.TypesInfo.Types[div].Value != nil {
+			continue
+		}
+		t := p.TypesInfo.TypeOf(div)
+		if !isInteger(t) || isNamed(t, "time", "Duration") {
+			continue
+		}
+		conv := types.ExprString(call.Fun)
+		x, y := types.ExprString(div.X), types.ExprString(div.Y)
+		p.Report(call, conv+"("+x+" / "+y+") converts the result of an integer division, which has already "+
+			"been truncated; convert the operands first: "+conv+"("+x+") / "+conv+"("+y+")",
+			convertOperandsFix(p, c, call, div)...)
+	}
+}
+
+// isInteger reports whether t is an integer type.
+func isInteger(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Info()&types.IsInteger != 0
+}
+
+// convertOperandsFix rewrites `T(x / y)` to `T(x) / T(y)`, parenthesized
+// when the conversion is an operand itself.
+func convertOperandsFix(p *Pass, c inspector.Cursor, call *ast.CallExpr, div *ast.BinaryExpr) []analysis.SuggestedFix {
+	conv, ok := sourceText(p, call.Fun)
+	if !ok {
+		return nil
+	}
+	operands := make([]string, 0, 2)
+	for _, e := range []ast.Expr{div.X, div.Y} {
+		text, ok := sourceText(p, e)
+		if !ok {
+			return nil
+		}
+		if _, lit := e.(*ast.BasicLit); !lit {
+			text = conv + "(" + text + ")"
+		}
+		operands = append(operands, text)
+	}
+	text := operands[0] + " / " + operands[1]
+	switch c.Parent().Node().(type) {
+	case *ast.BinaryExpr, *ast.UnaryExpr:
+		text = "(" + text + ")"
+	}
+	return []analysis.SuggestedFix{{
+		Message:   "Convert the operands before dividing",
+		TextEdits: []analysis.TextEdit{{Pos: call.Pos(), End: call.End(), NewText: []byte(text)}},
+	}}
+}
@@ -0,0 +1,143 @@
+package cog
+
+import (
+	"go/ast"
+	"go/types"
+)
+
+// interfaceAssertRule reports exported types that implement an interface
+// the package only checks at run time, with no compile-time guard.
+//
+//	type Plugin interface{ Start(ctx context.Context) error }
+//
+//	type Exporter struct{} // no var _ Plugin = (*Exporter)(nil)
+//
+//	func (e *Exporter) Start() error { ... } // drifted: no longer a Plugin
+//
+//	p, ok := v.(Plugin) // now false for every *Exporter, silently
+//
+// When values reach an interface through any, a registry or reflection,
+// nothing ties the implementation to the interface at compile time: a
+// method whose signature drifts makes the type stop implementing it, and
+// the only symptom is a type assertion that fails at run time. The rule
+// collects the interfaces declared in the package that appear in a type
+// assertion or a type switch case, and reports each exported type of the
+// package that implements one of them, directly or through its pointer,
+// without a `var _ Iface = ...` guard naming that type. The rule is a nudge
+// rather than a bug finder, so it is opt-in (-interfaceassert.enable).
+var interfaceAssertRule = &Rule{
+	ID:       "CogInterfaceAssert",
+	Doc:      "report exported types implementing a runtime-asserted interface without a compile-time guard",
+	Run:      runInterfaceAssert,
+	Category: CategoryTypes,
+
+	Severity: SeverityWarning,
+}
+
+// interfaceAssertEnable turns the rule on.
+var interfaceAssertEnable bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&interfaceAssertEnable, "interfaceassert.enable", false,
+		"report exported types implementing a runtime-asserted interface without a var _ Iface = (*T)(nil) guard")
+}
+
+func runInterfaceAssert(p *Pass) {
+	if !interfaceAssertEnable {
+		return
+	}
+	asserted := assertedInterfaces(p)
+	if len(asserted) == 0 {
+		return
+	}
+	guards := interfaceGuards(p)
+	for c := range p.Inspector.Root().Preorder((*ast.TypeSpec)(nil)) {
+		spec, ok := c.Node().(*ast.TypeSpec)
+		if !ok || !spec.Name.IsExported() || spec.TypeParams != nil {
+			continue
+		}
+		tn, ok := p.TypesInfo.Defs[spec.Name].(*types.TypeName)
+		if !ok || tn.Parent() != p.Pkg.Scope() || tn.IsAlias() || types.IsInterface(tn.Type()) {
+			continue
+		}
+		for _, iface := range asserted {
+			it, ok := iface.Type().Underlying().(*types.Interface)
+			if !ok || guards[guardKey{iface, tn}] {
+				continue
+			}
+			if !types.Implements(tn.Type(), it) && !types.Implements(types.NewPointer(tn.Type()), it) {
+				continue
+			}
+			p.Report(spec.Name, tn.Name()+" implements "+iface.Name()+", which the package checks only at run time; "+
+				"add var _ "+iface.Name()+" = (*"+tn.Name()+")(nil) so that a drifting method fails to compile")
+		}
+	}
+}
+
+// assertedInterfaces returns the interfaces with methods, declared in the
+// package, that a type assertion or type switch case tests for.
+func assertedInterfaces(p *Pass) []*types.TypeName {
+	seen := make(map[*types.TypeName]bool)
+	asserted := make([]*types.TypeName, 0)
+	record := func(e ast.Expr) {
+		named, ok := types.Unalias(p.TypesInfo.TypeOf(e)).(*types.Named)
+		if !ok || named.Obj().Pkg() != p.Pkg || named.TypeParams() != nil || seen[named.Obj()] {
+			return
+		}
+		if it, ok := named.Underlying().(*types.Interface); ok && it.NumMethods() > 0 {
+			seen[named.Obj()] = true
+			asserted = append(asserted, named.Obj())
+		}
+	}
+	for n := range p.Inspector.PreorderSeq((*ast.TypeAssertExpr)(nil), (*ast.TypeSwitchStmt)(nil)) {
+		switch n := n.(type) {
+		case *ast.TypeAssertExpr:
+			if n.Type != nil {
+				record(n.Type)
+			}
+		case *ast.TypeSwitchStmt:
+			for _, stmt := range n.Body.List {
+				if clause, ok := stmt.(*ast.CaseClause); ok {
+					for _, e := range clause.List {
+						record(e)
+					}
+				}
+			}
+		}
+	}
+	return asserted
+}
+
+// A guardKey pairs an interface with a type guarded against it.
+type guardKey struct {
+	iface, typ *types.TypeName
+}
+
+// interfaceGuards indexes the `var _ Iface = value` declarations of the
+// package by the interface and the named type, or pointer to one, of value.
+func interfaceGuards(p *Pass) map[guardKey]bool {
+	guards := make(map[guardKey]bool)
+	for n := range p.Inspector.PreorderSeq((*ast.ValueSpec)(nil)) {
+		spec, ok := n.(*ast.ValueSpec)
+		if !ok || spec.Type == nil {
+			continue
+		}
+		iface, ok := types.Unalias(p.TypesInfo.TypeOf(spec.Type)).(*types.Named)
+		if !ok {
+			continue
+		}
+		for i, name := range spec.Names {
+			if name.Name != "_" || i >= len(spec.Values) {
+				continue
+			}
+			t := p.TypesInfo.TypeOf(spec.Values[i])
+			if ptr, ok := t.(*types.Pointer); ok {
+				t = ptr.Elem()
+			}
+			if named, ok := types.Unalias(t).(*types.Named); ok {
+				guards[guardKey{iface.Obj(), named.Obj()}] = true
+			}
+		}
+	}
+	return guards
+}
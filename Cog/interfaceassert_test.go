@@ -0,0 +1,14 @@
+package cog
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestInterfaceAssert(t *testing.T) {
+	saved := interfaceAssertEnable
+	t.Cleanup(func() { interfaceAssertEnable = saved })
+	interfaceAssertEnable = true
+	analysistest.Run(t, analysistest.TestData(), ruleAnalyzer(interfaceAssertRule), "interfaceassert")
+}
@@ -0,0 +1,113 @@
+package cog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// jsonFinding is the stable JSON form of a Finding. Fields keep this order
+// and these names; new fields are only ever added at the end.
+type jsonFinding struct {
+	Rule         string   `json:"rule"`
+	Severity     Severity `json:"severity"`
+	File         string   `json:"file"`
+	Line         int      `json:"line"`
+	Col          int      `json:"col"`
+	EndLine      int      `json:"endLine"`
+	EndCol       int      `json:"endCol"`
+	Message      string   `json:"message"`
+	SuggestedFix *jsonFix `json:"suggestedFix,omitempty"`
+}
+
+// jsonFix is the JSON form of the first suggested fix of a Finding.
+type jsonFix struct {
+	Message string     `json:"message"`
+	Edits   []jsonEdit `json:"edits"`
+}
+
+// jsonEdit is the JSON form of an Edit.
+type jsonEdit struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Col     int    `json:"col"`
+	EndLine int    `json:"endLine"`
+	EndCol  int    `json:"endCol"`
+	NewText string `json:"newText"`
+}
+
+// WriteJSON writes findings to w as a JSON array, sorted by file, then
+// position, then rule, so that output diffs cleanly between runs:
+//
+//	[
+//	  {
+//	    "rule": "CogBareReturn",
+//	    "severity": "error",
+//	    "file": "examples/before.go",
+//	    "line": 34,
+//	    "col": 3,
+//	    "endLine": 34,
+//	    "endCol": 9,
+//	    "message": "bare return in a function with named results ...",
+//	    "suggestedFix": {
+//	      "message": "Return the named results explicitly",
+//	      "edits": [
+//	        {
+//	          "file": "examples/before.go",
+//	          "line": 34,
+//	          "col": 9,
+//	          "endLine": 34,
+//	          "endCol": 9,
+//	          "newText": " result, err"
+//	        }
+//	      ]
+//	    }
+//	  }
+//	]
+//
+// Lines and columns are 1-based; columns count bytes. suggestedFix, the
+// first of the finding's fixes, is omitted when it has none, and severity
+// defaults to "error". The field names and order are stable.
+func WriteJSON(w io.Writer, findings []Finding) error {
+	sorted := slices.Clone(findings)
+	sortFindings(sorted)
+	out := make([]jsonFinding, 0, len(sorted))
+	for _, f := range sorted {
+		jf := jsonFinding{
+			Rule:     f.Rule,
+			Severity: f.Severity,
+			File:     f.Pos.Filename,
+			Line:     f.Pos.Line,
+			Col:      f.Pos.Column,
+			EndLine:  f.End.Line,
+			EndCol:   f.End.Column,
+			Message:  f.Message,
+		}
+		if jf.Severity == "" {
+			jf.Severity = SeverityError
+		}
+		if len(f.Fixes) > 0 {
+			fix := f.Fixes[0]
+			jf.SuggestedFix = &jsonFix{Message: fix.Message, Edits: make([]jsonEdit, 0, len(fix.Edits))}
+			for _, e := range fix.Edits {
+				jf.SuggestedFix.Edits = append(jf.SuggestedFix.Edits, jsonEdit{
+					File:    e.Pos.Filename,
+					Line:    e.Pos.Line,
+					Col:     e.Pos.Column,
+					EndLine: e.End.Line,
+					EndCol:  e.End.Column,
+					NewText: e.NewText,
+				})
+			}
+		}
+		out = append(out, jf)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		return fmt.Errorf("cog: json: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package cog
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteJSON(t *testing.T) {
+	const want = `[
+  {
+    "rule": "CogMapRangeOrder",
+    "severity": "warning",
+    "file": "testdata/a.go",
+    "line": 5,
+    "col": 2,
+    "endLine": 5,
+    "endCol": 20,
+    "message": "range over map m appends to ks in random order, and ks is returned unsorted on line 8"
+  },
+  {
+    "rule": "CogIgnoredError",
+    "severity": "error",
+    "file": "testdata/a.go",
+    "line": 12,
+    "col": 2,
+    "endLine": 12,
+    "endCol": 22,
+    "message": "error returned by os.Remove is discarded; handle it with ` + "`if err := ...; err != nil`" + `",
+    "suggestedFix": {
+      "message": "Check the error",
+      "edits": [
+        {
+          "file": "testdata/a.go",
+          "line": 12,
+          "col": 2,
+          "endLine": 12,
+          "endCol": 22,
+          "newText": "if err := os.Remove(\"scratch\"); err != nil {\n\t\treturn fmt.Errorf(\"os.Remove: %w\", err)\n\t}"
+        }
+      ]
+    }
+  },
+  {
+    "rule": "CogBareReturn",
+    "severity": "info",
+    "file": "testdata/b.go",
+    "line": 7,
+    "col": 2,
+    "endLine": 7,
+    "endCol": 8,
+    "message": "bare return in a function with named results hides what is returned; write ` + "`return n, err`" + `"
+  }
+]
+`
+	var b bytes.Buffer
+	if err := WriteJSON(&b, testFindings()); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+	if got := b.String(); got != want {
+		t.Errorf("WriteJSON wrote\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteJSONEmpty(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteJSON(&b, nil); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+	if got, want := b.String(), "[]\n"; got != want {
+		t.Errorf("WriteJSON(nil) = %q, want %q", got, want)
+	}
+}
+
+func TestWriteJSONDefaultSeverity(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteJSON(&b, []Finding{{Rule: "CogTypedNil"}}); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+	if !bytes.Contains(b.Bytes(), []byte(`"severity": "error"`)) {
+		t.Errorf("WriteJSON of a finding without severity wrote %s, want severity error", b.Bytes())
+	}
+}
@@ -0,0 +1,80 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+// jsonMapAnyRule reports JSON decoded into a map[string]any, the JSON form
+// of Mistake 1.
+//
+//	var cfg map[string]any
+//	if err := json.Unmarshal(data, &cfg); err != nil { ... }
+//	port := cfg["port"].(float64) // every field needs an assertion
+//
+// Every value comes back as any, so each use needs a type assertion, and a
+// misspelled key or an unexpected type only shows up at run time. A struct
+// with json tags states the expected shape and lets the decoder check it.
+// The rule looks at the target of json.Unmarshal and (*json.Decoder).Decode.
+// A payload whose shape is genuinely dynamic is what the map is for, so the
+// rule is opt-in (-jsonmapany.enable); suppress it with //cog:ignore there.
+var jsonMapAnyRule = &Rule{
+	ID:       "CogJSONMapAny",
+	Doc:      "report JSON decoded into map[string]any instead of a typed struct",
+	Run:      runJSONMapAny,
+	Category: CategoryTypes,
+
+	Severity: SeverityWarning,
+}
+
+// jsonMapAnyEnable turns the rule on.
+var jsonMapAnyEnable bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&jsonMapAnyEnable, "jsonmapany.enable", false,
+		"report JSON decoded into map[string]any instead of a typed struct")
+}
+
+func runJSONMapAny(p *Pass) {
+	if !jsonMapAnyEnable {
+		return
+	}
+	for c := range p.Inspector.Root().Preorder((*ast.CallExpr)(nil)) {
+		call, ok := c.Node().(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+		var target ast.Expr
+		switch name := calleeName(p.TypesInfo, call); {
+		case name == "encoding/json.Unmarshal" && len(call.Args) == 2:
+			target = call.Args[1]
+		case name == "(*encoding/json.Decoder).Decode" && len(call.Args) == 1:
+			target = call.Args[0]
+		default:
+			continue
+		}
+		ptr, ok := p.TypesInfo.TypeOf(target).(*types.Pointer)
+		if !ok || !isStringAnyMap(ptr.Elem()) {
+			continue
+		}
+		name := types.ExprString(target)
+		if addr, ok := ast.Unparen(target).(*ast.UnaryExpr); ok && addr.Op == token.AND {
+			name = types.ExprString(addr.X)
+		}
+		p.Report(target, "JSON is decoded into "+name+", a map[string]any, so every value must be type-asserted "+
+			"and a wrong key or type only fails at run time; decode into a struct with json tags for the "+
+			"expected fields, or suppress this with //cog:ignore if the shape is truly dynamic")
+	}
+}
+
+// isStringAnyMap reports whether t is a map from a string type to an empty
+// interface.
+func isStringAnyMap(t types.Type) bool {
+	m, ok := t.Underlying().(*types.Map)
+	if !ok {
+		return false
+	}
+	iface, ok := m.Elem().Underlying().(*types.Interface)
+	return ok && iface.Empty() && isString(m.Key())
+}
@@ -0,0 +1,14 @@
+package cog
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestJSONMapAny(t *testing.T) {
+	saved := jsonMapAnyEnable
+	t.Cleanup(func() { jsonMapAnyEnable = saved })
+	jsonMapAnyEnable = true
+	analysistest.Run(t, analysistest.TestData(), ruleAnalyzer(jsonMapAnyRule), "jsonmapany")
+}
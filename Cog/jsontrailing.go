@@ -0,0 +1,113 @@
+package cog
+
+import (
+	"go/ast"
+	"go/types"
+)
+
+// jsonTrailingRule reports a json.Decoder used to decode a single value
+// without checking for input after it.
+//
+//	var req Request
+//	if err := json.NewDecoder(r.Body).Decode(&req); err != nil { // accepts `{"id":1}garbage`
+//		return err
+//	}
+//
+// Decode reads one JSON value and stops: whatever follows it, a second
+// object or plain garbage, is left unread and no error is returned, where
+// json.Unmarshal would fail with "invalid character after top-level
+// value". The rule fires on a Decode call outside a loop whose decoder is
+// either discarded, as in json.NewDecoder(r).Decode(&v), or a local
+// variable set from json.NewDecoder that the function uses for nothing
+// else but UseNumber and DisallowUnknownFields, so that no More, Token or
+// further Decode call looks at the rest. Decoding in a loop reads a stream
+// of values and is not reported. Most inputs are trusted enough for this
+// not to matter, so the rule is opt-in (-jsontrailing.enable).
+var jsonTrailingRule = &Rule{
+	ID:       "CogJSONTrailing",
+	Doc:      "report single-value json.Decoder.Decode calls that ignore trailing data",
+	Run:      runJSONTrailing,
+	Category: CategoryTypes,
+
+	Severity: SeverityWarning,
+}
+
+// jsonTrailingEnable turns the rule on.
+var jsonTrailingEnable bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&jsonTrailingEnable, "jsontrailing.enable", false,
+		"report json.Decoder.Decode calls that decode one value without checking for trailing data")
+}
+
+func runJSONTrailing(p *Pass) {
+	if !jsonTrailingEnable {
+		return
+	}
+	for c := range p.Inspector.Root().Preorder((*ast.CallExpr)(nil)) {
+		call, ok := c.Node().(*ast.CallExpr)
+		if !ok || calleeName(p.TypesInfo, call) != "(*encoding/json.Decoder).Decode" || inLoopBody(c) {
+			continue
+		}
+		sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+		if !ok {
+			continue
+		}
+		dec, keep := "dec", "keep the decoder in dec and "
+		if recv, ok := ast.Unparen(sel.X).(*ast.CallExpr); ok {
+			if calleeName(p.TypesInfo, recv) != "encoding/json.NewDecoder" {
+				continue
+			}
+		} else {
+			v := localVar(p, ast.Unparen(sel.X))
+			_, body := enclosingFunc(c)
+			if v == nil || body == nil || !decodesOnce(p, body, v, sel) {
+				continue
+			}
+			dec, keep = v.Name(), ""
+		}
+		p.Report(call, "Decode reads one JSON value and silently ignores any data after it; "+keep+"check that "+
+			dec+".More() is false or that "+dec+".Token() returns io.EOF afterwards, or json.Unmarshal the "+
+			"whole input")
+	}
+}
+
+// decodesOnce reports whether body sets dec from json.NewDecoder and uses
+// it only for the Decode call through sel and for configuration.
+func decodesOnce(p *Pass, body *ast.BlockStmt, dec *types.Var, sel *ast.SelectorExpr) bool {
+	created, other := false, false
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			for i, lhs := range n.Lhs {
+				if id, ok := lhs.(*ast.Ident); ok && p.TypesInfo.ObjectOf(id) == dec && len(n.Rhs) == len(n.Lhs) {
+					call, ok := ast.Unparen(n.Rhs[i]).(*ast.CallExpr)
+					created = created || ok && calleeName(p.TypesInfo, call) == "encoding/json.NewDecoder"
+				}
+			}
+		case *ast.ValueSpec:
+			for i, name := range n.Names {
+				if p.TypesInfo.Defs[name] == dec && len(n.Values) == len(n.Names) {
+					call, ok := ast.Unparen(n.Values[i]).(*ast.CallExpr)
+					created = created || ok && calleeName(p.TypesInfo, call) == "encoding/json.NewDecoder"
+				}
+			}
+		case *ast.SelectorExpr:
+			if id, ok := ast.Unparen(n.X).(*ast.Ident); ok && p.TypesInfo.Uses[id] == dec {
+				switch {
+				case n == sel, n.Sel.Name == "UseNumber", n.Sel.Name == "DisallowUnknownFields":
+				default:
+					other = true
+				}
+				return false
+			}
+		case *ast.Ident:
+			// A use outside a selector hands the decoder to other code.
+			if p.TypesInfo.Uses[n] == dec {
+				other = true
+			}
+		}
+		return !other
+	})
+	return created && !other
+}
@@ -0,0 +1,14 @@
+package cog
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestJSONTrailing(t *testing.T) {
+	saved := jsonTrailingEnable
+	t.Cleanup(func() { jsonTrailingEnable = saved })
+	jsonTrailingEnable = true
+	analysistest.Run(t, analysistest.TestData(), ruleAnalyzer(jsonTrailingRule), "jsontrailing")
+}
@@ -0,0 +1,121 @@
+package cog
+
+import (
+	"go/ast"
+	"go/build"
+	"go/types"
+	"slices"
+	"strconv"
+)
+
+// largeValueCopyRule reports struct receivers and parameters passed by
+// value whose size exceeds -largevaluecopy.size bytes.
+//
+//	type Report struct {
+//		Title  string
+//		Totals [32]float64
+//	}
+//
+//	func (r Report) Summary() string // copies 272 bytes on every call
+//
+// Every call copies the whole value onto the callee's stack, which shows up
+// in hot paths. Sizes are those of the target platform: the driver's
+// types.Sizes, or types.SizesFor for the gc compiler and GOARCH. Generic
+// structs whose size depends on a type parameter are not reported. Types
+// meant to be copied, such as small immutable values, can be listed in
+// -largevaluecopy.allow, or a single declaration silenced with a
+// `//cog:ignore CogLargeValueCopy` directive.
+var largeValueCopyRule = &Rule{
+	ID:       "CogLargeValueCopy",
+	Doc:      "report large structs passed by value as receivers or parameters",
+	Run:      runLargeValueCopy,
+	Category: CategoryPerformance,
+
+	Severity: SeverityWarning,
+}
+
+var (
+	// largeValueSize is the size in bytes above which a copy is reported.
+	largeValueSize = 128
+
+	// largeValueAllow are the types that may be passed by value at any size.
+	largeValueAllow listFlag
+)
+
+func init() {
+	Analyzer.Flags.IntVar(&largeValueSize, "largevaluecopy.size", largeValueSize,
+		"report struct receivers and parameters passed by value that are larger than this many bytes")
+	Analyzer.Flags.Var(&largeValueAllow, "largevaluecopy.allow",
+		"comma-separated types, as written in the package (e.g. Config or big.Float), that CogLargeValueCopy lets be passed by value")
+}
+
+func runLargeValueCopy(p *Pass) {
+	sizes := p.TypesSizes
+	if sizes == nil {
+		sizes = types.SizesFor("gc", build.Default.GOARCH)
+	}
+	for c := range p.Inspector.Root().Preorder((*ast.FuncDecl)(nil), (*ast.FuncLit)(nil)) {
+		switch n := c.Node().(type) {
+		case *ast.FuncDecl:
+			if n.Recv != nil {
+				checkLargeFields(p, sizes, n.Recv, "receiver")
+			}
+			checkLargeFields(p, sizes, n.Type.Params, "parameter")
+		case *ast.FuncLit:
+			checkLargeFields(p, sizes, n.Type.Params, "parameter")
+		}
+	}
+}
+
+// checkLargeFields reports the fields of a receiver or parameter list
+// whose struct type is larger than largeValueSize.
+func checkLargeFields(p *Pass, sizes types.Sizes, fields *ast.FieldList, kind string) {
+	for _, field := range fields.List {
+		t := p.TypesInfo.TypeOf(field.Type)
+		if t == nil || !fixedSize(t) {
+			continue
+		}
+		if _, ok := t.Underlying().(*types.Struct); !ok {
+			continue
+		}
+		name := types.TypeString(t, func(pkg *types.Package) string {
+			if pkg == p.Pkg {
+				return ""
+			}
+			return pkg.Name()
+		})
+		size := sizes.Sizeof(t)
+		if size <= int64(largeValueSize) || slices.Contains(largeValueAllow, name) {
+			continue
+		}
+		what := kind + " of type " + name
+		if len(field.Names) > 0 {
+			what = kind + " " + field.Names[0].Name + " of type " + name
+		}
+		advice := "pass a *" + name
+		if kind == "receiver" {
+			advice = "use a pointer receiver"
+		}
+		p.Report(field, what+" is "+strconv.FormatInt(size, 10)+" bytes, copied on every call; "+advice+
+			", or list "+name+" in -largevaluecopy.allow if it is meant to be copied")
+	}
+}
+
+// fixedSize reports whether the size of t is known without instantiating
+// a type parameter.
+func fixedSize(t types.Type) bool {
+	if _, ok := types.Unalias(t).(*types.TypeParam); ok {
+		return false
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Struct:
+		for i := range u.NumFields() {
+			if !fixedSize(u.Field(i).Type()) {
+				return false
+			}
+		}
+	case *types.Array:
+		return fixedSize(u.Elem())
+	}
+	return true
+}
@@ -0,0 +1,18 @@
+package cog
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestLargeValueCopy(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), ruleAnalyzer(largeValueCopyRule), "largevaluecopy")
+}
+
+func TestLargeValueCopyAllow(t *testing.T) {
+	saved := largeValueAllow
+	t.Cleanup(func() { largeValueAllow = saved })
+	largeValueAllow = listFlag{"Report"}
+	analysistest.Run(t, analysistest.TestData(), ruleAnalyzer(largeValueCopyRule), "largevaluecopyallow")
+}
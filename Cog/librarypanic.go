@@ -0,0 +1,60 @@
+package cog
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// libraryPanicRule reports panic and Must calls in library code, where they
+// turn an error the caller could handle into a crash of the whole program.
+//
+//	func Load(path string) Config {
+//		data := cog.Must(os.ReadFile(path)) // the caller cannot recover
+//
+// Package main and _test.go files may panic, as may package-level variable
+// initializers (`var re = regexp.MustCompile(...)`) and functions whose
+// name starts with Must, for which panicking is the contract. A panic that
+// guards a state which cannot occur, such as the default case of an
+// exhaustive switch, is marked with an `// UNREACHABLE:` comment on its
+// line.
+var libraryPanicRule = &Rule{
+	ID:       "CogLibraryPanic",
+	Doc:      "report panic and Must calls in library code",
+	Run:      runLibraryPanic,
+	Category: CategoryErrors,
+}
+
+func runLibraryPanic(p *Pass) {
+	if p.Pkg.Name() == "main" {
+		return
+	}
+	unreachable := markedLines(p, "UNREACHABLE:")
+	for c := range p.Inspector.Root().Preorder((*ast.CallExpr)(nil)) {
+		call, ok := c.Node().(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+		isPanic := isBuiltin(p, call.Fun, "panic")
+		fn, _ := typeutil.Callee(p.TypesInfo, call).(*types.Func) // FALLBACK: nil for builtins and func values
+		if !isPanic && (fn == nil || fn.Name() != "Must") {
+			continue
+		}
+		if strings.HasSuffix(p.Fset.Position(call.Pos()).Filename, "_test.go") || unreachable[lineOf(p.Fset, call.Pos())] {
+			continue
+		}
+		decl := enclosingDecl(c)
+		if decl == nil || strings.HasPrefix(decl.Name.Name, "Must") {
+			continue
+		}
+		if isPanic {
+			p.Report(call, "panic in library code crashes every caller instead of letting them handle the "+
+				"error; return an error, or mark a truly unreachable state with an // UNREACHABLE: comment")
+			continue
+		}
+		p.Report(call, types.ExprString(call.Fun)+" panics on error in library code; return the error "+
+			"instead, or keep it as a Result with cog.Try")
+	}
+}
@@ -0,0 +1,155 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// loopCaptureRule reports Mistake 5: a `go` closure inside a loop that refers
+// to the loop variable instead of receiving it as an argument.
+//
+//	for _, item := range items {
+//		go func() {
+//			fmt.Println(item) // before Go 1.22 every goroutine sees the last item
+//		}()
+//	}
+//
+// Go 1.22 gave each iteration its own variables, so the rule only fires for
+// files whose language version is older, unless -loopcapture.strict asks for
+// the explicit-argument style everywhere. The suggested fix passes each
+// captured variable as an argument of the same name. Deferred closures are
+// reported by CogDeferCapture.
+var loopCaptureRule = &Rule{
+	ID:       "CogLoopCapture",
+	Doc:      "report goroutine closures that capture loop variables",
+	Run:      runLoopCapture,
+	Category: CategoryConcurrency,
+	Fixable:  true,
+}
+
+// loopCaptureStrict reports captures regardless of the Go version.
+var loopCaptureStrict bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&loopCaptureStrict, "loopcapture.strict", false,
+		"report loop variable captures even when the file targets Go 1.22 or later")
+}
+
+func runLoopCapture(p *Pass) {
+	for c := range p.Inspector.Root().Preorder((*ast.GoStmt)(nil)) {
+		g, ok := c.Node().(*ast.GoStmt)
+		if !ok {
+			continue
+		}
+		call := g.Call
+		lit, ok := call.Fun.(*ast.FuncLit)
+		if !ok {
+			continue
+		}
+		file := enclosingFile(c)
+		if !loopCaptureStrict && perIterationLoopVars(p, file) {
+			continue
+		}
+
+		captured := capturedLoopVars(p, c, lit)
+		if len(captured) == 0 {
+			continue
+		}
+		names := make([]string, 0, len(captured))
+		for _, v := range captured {
+			names = append(names, v.Name())
+		}
+		p.Report(call, "goroutine captures loop variable "+strings.Join(names, ", ")+
+			" by reference; before Go 1.22 every iteration shares it, so pass it as an argument",
+			loopCaptureFix(p, file, call, lit, captured)...)
+	}
+}
+
+// capturedLoopVars returns the variables of loops enclosing c (within the
+// same function) that lit refers to, in order of first use.
+func capturedLoopVars(p *Pass, c inspector.Cursor, lit *ast.FuncLit) []*types.Var {
+	loopVars := make(map[*types.Var]bool)
+loops:
+	for lc := range c.Enclosing((*ast.ForStmt)(nil), (*ast.RangeStmt)(nil), (*ast.FuncLit)(nil), (*ast.FuncDecl)(nil)) {
+		switch n := lc.Node().(type) {
+		case *ast.RangeStmt:
+			if n.Tok == token.DEFINE {
+				for _, e := range []ast.Expr{n.Key, n.Value} {
+					addDefinedVar(p, e, loopVars)
+				}
+			}
+		case *ast.ForStmt:
+			if init, ok := n.Init.(*ast.AssignStmt); ok && init.Tok == token.DEFINE {
+				for _, e := range init.Lhs {
+					addDefinedVar(p, e, loopVars)
+				}
+			}
+		default:
+			break loops // the function boundary
+		}
+	}
+
+	captured := make([]*types.Var, 0)
+	seen := make(map[*types.Var]bool)
+	ast.Inspect(lit.Body, func(n ast.Node) bool {
+		id, ok := n.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		v, ok := p.TypesInfo.Uses[id].(*types.Var)
+		if ok && loopVars[v] && !seen[v] {
+			seen[v] = true
+			captured = append(captured, v)
+		}
+		return true
+	})
+	return captured
+}
+
+// addDefinedVar adds the variable e declares, if any, to vars.
+func addDefinedVar(p *Pass, e ast.Expr, vars map[*types.Var]bool) {
+	id, ok := e.(*ast.Ident)
+	if !ok {
+		return
+	}
+	if v, ok := p.TypesInfo.Defs[id].(*types.Var); ok {
+		vars[v] = true
+	}
+}
+
+// loopCaptureFix rewrites `func() { ... }()` into `func(v T) { ... }(v)` for
+// every captured variable. The parameters reuse the variable names, so the
+// body is unchanged. No fix is offered when a type cannot be spelled in file.
+func loopCaptureFix(p *Pass, file *ast.File, call *ast.CallExpr, lit *ast.FuncLit, captured []*types.Var) []analysis.SuggestedFix {
+	var params, args strings.Builder
+	if lit.Type.Params.NumFields() > 0 {
+		params.WriteString(", ")
+	}
+	if len(call.Args) > 0 {
+		args.WriteString(", ")
+	}
+	for i, v := range captured {
+		typ, ok := typeExpr(p, file, v.Type())
+		if !ok {
+			return nil
+		}
+		if i > 0 {
+			params.WriteString(", ")
+			args.WriteString(", ")
+		}
+		params.WriteString(v.Name() + " " + typ)
+		args.WriteString(v.Name())
+	}
+	return []analysis.SuggestedFix{{
+		Message: "Pass loop variables as arguments",
+		TextEdits: []analysis.TextEdit{
+			{Pos: lit.Type.Params.Closing, End: lit.Type.Params.Closing, NewText: []byte(params.String())},
+			{Pos: call.Rparen, End: call.Rparen, NewText: []byte(args.String())},
+		},
+	}}
+}
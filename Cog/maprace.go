@@ -0,0 +1,307 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// mapRaceRule reports a local map written by a goroutine while another
+// goroutine, or the function that started it, also uses it.
+//
+//	counts := make(map[string]int)
+//	for _, w := range words {
+//		go func() { counts[w]++ }() // every goroutine writes counts
+//	}
+//	go func() { counts["total"] = len(words) }()
+//
+// Maps are not safe for concurrent use: a write concurrent with any other
+// access is a data race, and the runtime may abort with "concurrent map
+// writes". Guard the map with a sync.Mutex or sync.RWMutex, or use a
+// sync.Map.
+//
+// The rule is a conservative heuristic over the function declaring the
+// map. It fires only when the map is used by at least two `go` statements
+// with a function literal, or by one of them and by the function itself
+// after the goroutine starts, with a write on at least one side, or is
+// written by such a `go` statement in a loop, whose goroutines race with
+// each other. It does not fire when:
+//
+//   - the function locks a sync.Mutex or sync.RWMutex anywhere;
+//   - the map escapes: passed to a call, stored, returned or reassigned;
+//   - the function waits (a Wait call, a channel receive or a select)
+//     between starting one goroutine and the other use, or, for a `go`
+//     statement in a loop, anywhere in the loop.
+//
+// Goroutines started by a named function (`go worker(m)`) or through an
+// API such as errgroup.Group.Go are not seen.
+var mapRaceRule = &Rule{
+	ID:       "CogMapRace",
+	Doc:      "report maps used by several goroutines without a mutex",
+	Run:      runMapRace,
+	Category: CategoryConcurrency,
+}
+
+// A mapAccess is one use of a map variable.
+type mapAccess struct {
+	id    *ast.Ident
+	write bool
+	g     *ast.GoStmt // the goroutine making it; nil for the declaring function
+	loop  ast.Stmt    // the loop running g once per iteration, or nil
+}
+
+func runMapRace(p *Pass) {
+	for c := range p.Inspector.Root().Preorder((*ast.FuncDecl)(nil)) {
+		decl, ok := c.Node().(*ast.FuncDecl)
+		if !ok || decl.Body == nil || locksMutex(p, c) {
+			continue
+		}
+		accesses, order := mapAccesses(p, c)
+		waits := waitPoints(p, c)
+		for _, m := range order {
+			a, b, ok := racingAccesses(accesses[m], waits)
+			if !ok {
+				continue
+			}
+			other := "by the function after starting it, on line " + strconv.Itoa(p.Fset.Position(b.id.Pos()).Line)
+			switch {
+			case b.g == a.g:
+				other = "by the goroutines the same statement starts in the other iterations of the loop on line " +
+					strconv.Itoa(p.Fset.Position(a.loop.Pos()).Line)
+			case b.g != nil:
+				other = "by the goroutine started on line " + strconv.Itoa(p.Fset.Position(b.g.Pos()).Line)
+			}
+			p.Report(a.id, "map "+m.Name()+" is "+accessVerb(a)+" by the goroutine started on line "+
+				strconv.Itoa(p.Fset.Position(a.g.Pos()).Line)+" and "+accessVerb(b)+" "+other+", with no mutex "+
+				"held; concurrent map access is a data race that can crash the program, so guard "+m.Name()+
+				" with a sync.Mutex or sync.RWMutex, or use a sync.Map")
+		}
+	}
+}
+
+// mapAccesses returns the uses of the map variables declared in the
+// function at c, in order of declaration, leaving out maps that escape.
+func mapAccesses(p *Pass, c inspector.Cursor) (map[*types.Var][]mapAccess, []*types.Var) {
+	accesses := make(map[*types.Var][]mapAccess)
+	escaped := make(map[*types.Var]bool)
+	order := make([]*types.Var, 0)
+	for ic := range c.Preorder((*ast.Ident)(nil)) {
+		id, ok := ic.Node().(*ast.Ident)
+		if !ok {
+			continue
+		}
+		v, ok := p.TypesInfo.Uses[id].(*types.Var)
+		if !ok || v.Pos() < c.Node().Pos() || v.Pos() >= c.Node().End() {
+			continue // not declared in this function
+		}
+		if _, ok := v.Type().Underlying().(*types.Map); !ok || escaped[v] {
+			continue
+		}
+		write, ok := mapUse(p, ic)
+		if !ok {
+			escaped[v] = true
+			continue
+		}
+		if _, seen := accesses[v]; !seen {
+			order = append(order, v)
+		}
+		access := mapAccess{id: id, write: write, g: startingGo(ic, v)}
+		if access.g != nil {
+			access.loop = goLoop(ic, access.g, v)
+		}
+		accesses[v] = append(accesses[v], access)
+	}
+	kept := make([]*types.Var, 0, len(order))
+	for _, v := range order {
+		if !escaped[v] {
+			kept = append(kept, v)
+		}
+	}
+	return accesses, kept
+}
+
+// mapUse classifies the use of a map at c as a write or a read. It returns
+// false for any other use, through which the map may escape.
+func mapUse(p *Pass, c inspector.Cursor) (write, ok bool) {
+	id := c.Node()
+	switch pn := c.Parent().Node().(type) {
+	case *ast.IndexExpr:
+		if pn.X != id {
+			return false, false
+		}
+		switch gp := c.Parent().Parent().Node().(type) {
+		case *ast.AssignStmt:
+			return isLHS(gp, pn), true
+		case *ast.IncDecStmt:
+			return true, true
+		}
+		return false, true
+	case *ast.CallExpr:
+		switch {
+		case len(pn.Args) > 0 && pn.Args[0] == id && (isBuiltin(p, pn.Fun, "delete") || isBuiltin(p, pn.Fun, "clear")):
+			return true, true
+		case isBuiltin(p, pn.Fun, "len"):
+			return false, true
+		}
+	case *ast.RangeStmt:
+		return false, pn.X == id
+	}
+	return false, false
+}
+
+// startingGo returns the innermost `go` statement of a function literal
+// that encloses c but not the declaration of v, or nil.
+func startingGo(c inspector.Cursor, v *types.Var) *ast.GoStmt {
+	for lc := range c.Enclosing((*ast.FuncLit)(nil)) {
+		lit := lc.Node()
+		if lit.Pos() <= v.Pos() && v.Pos() < lit.End() {
+			return nil
+		}
+		call, ok := lc.Parent().Node().(*ast.CallExpr)
+		if !ok || call.Fun != lit {
+			continue
+		}
+		if g, ok := lc.Parent().Parent().Node().(*ast.GoStmt); ok {
+			return g
+		}
+	}
+	return nil
+}
+
+// goLoop returns the innermost loop around g, which encloses c, within the
+// same function, or nil when there is none or it encloses the declaration
+// of v, which then gives each iteration its own map.
+func goLoop(c inspector.Cursor, g *ast.GoStmt, v *types.Var) ast.Stmt {
+	inGo := false
+	for lc := range c.Enclosing((*ast.GoStmt)(nil), (*ast.ForStmt)(nil), (*ast.RangeStmt)(nil), (*ast.FuncLit)(nil), (*ast.FuncDecl)(nil)) {
+		n := lc.Node()
+		if !inGo {
+			inGo = n == g
+			continue
+		}
+		switch n := n.(type) {
+		case *ast.ForStmt, *ast.RangeStmt:
+			if n.Pos() <= v.Pos() && v.Pos() < n.End() {
+				return nil
+			}
+			return n.(ast.Stmt)
+		case *ast.FuncLit, *ast.FuncDecl:
+			return nil
+		}
+	}
+	return nil
+}
+
+// racingAccesses returns an access by a goroutine and another access that
+// may run concurrently with it, at least one of them a write: one by a
+// different goroutine, or one by the declaring function after the goroutine
+// starts, with no wait in between. Failing that, it returns a write by a
+// goroutine started in a loop with no wait, twice: the goroutines of the
+// different iterations race.
+func racingAccesses(accesses []mapAccess, waits []token.Pos) (mapAccess, mapAccess, bool) {
+	for _, a := range accesses {
+		if a.g == nil {
+			continue
+		}
+		for _, b := range accesses {
+			if b.g == a.g || !a.write && !b.write {
+				continue
+			}
+			from, to := a.g.End(), b.id.Pos()
+			if b.g != nil {
+				from, to = min(a.g.Pos(), b.g.Pos()), max(a.g.Pos(), b.g.Pos())
+			}
+			if from < to && !waitsBetween(waits, from, to) {
+				return a, b, true
+			}
+		}
+	}
+	for _, a := range accesses {
+		if a.write && a.loop != nil && !waitsBetween(waits, a.loop.Pos(), a.loop.End()) {
+			return a, a, true
+		}
+	}
+	return mapAccess{}, mapAccess{}, false
+}
+
+// accessVerb describes a as "written" or "read".
+func accessVerb(a mapAccess) string {
+	if a.write {
+		return "written"
+	}
+	return "read"
+}
+
+// waitPoints returns the positions in the function at c, outside `go`
+// statements, where it may wait for a goroutine: Wait calls, channel
+// receives and select statements.
+func waitPoints(p *Pass, c inspector.Cursor) []token.Pos {
+	waits := make([]token.Pos, 0)
+	for wc := range c.Preorder((*ast.CallExpr)(nil), (*ast.UnaryExpr)(nil), (*ast.SelectStmt)(nil), (*ast.RangeStmt)(nil)) {
+		if inGoStmt(wc) {
+			continue
+		}
+		switch n := wc.Node().(type) {
+		case *ast.CallExpr:
+			if sel, ok := ast.Unparen(n.Fun).(*ast.SelectorExpr); ok && sel.Sel.Name == "Wait" {
+				waits = append(waits, n.Pos())
+			}
+		case *ast.UnaryExpr:
+			if n.Op == token.ARROW {
+				waits = append(waits, n.Pos())
+			}
+		case *ast.SelectStmt:
+			waits = append(waits, n.Pos())
+		case *ast.RangeStmt:
+			if t := p.TypesInfo.TypeOf(n.X); t != nil {
+				if _, ok := t.Underlying().(*types.Chan); ok {
+					waits = append(waits, n.Pos())
+				}
+			}
+		}
+	}
+	return waits
+}
+
+// inGoStmt reports whether c lies in a go statement.
+func inGoStmt(c inspector.Cursor) bool {
+	for range c.Enclosing((*ast.GoStmt)(nil)) {
+		return true
+	}
+	return false
+}
+
+// waitsBetween reports whether some wait lies between from and to.
+func waitsBetween(waits []token.Pos, from, to token.Pos) bool {
+	for _, w := range waits {
+		if from < w && w < to {
+			return true
+		}
+	}
+	return false
+}
+
+// locksMutex reports whether the function at c locks a sync.Mutex or
+// sync.RWMutex.
+func locksMutex(p *Pass, c inspector.Cursor) bool {
+	for cc := range c.Preorder((*ast.CallExpr)(nil)) {
+		call, ok := cc.Node().(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+		fn, ok := typeutil.Callee(p.TypesInfo, call).(*types.Func)
+		if !ok {
+			continue
+		}
+		switch fn.FullName() {
+		case "(*sync.Mutex).Lock", "(*sync.Mutex).TryLock", "(*sync.RWMutex).Lock", "(*sync.RWMutex).RLock",
+			"(*sync.RWMutex).TryLock", "(*sync.RWMutex).TryRLock":
+			return true
+		}
+	}
+	return false
+}
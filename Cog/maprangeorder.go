@@ -0,0 +1,176 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// mapRangeOrderRule reports a slice filled while ranging over a map and
+// then used in an order-dependent way without being sorted. Map iteration
+// order is randomized, so the result differs from run to run.
+//
+//	names := make([]string, 0, len(users))
+//	for name := range users {
+//		names = append(names, name)
+//	}
+//	return names // a different order on every call
+//
+// The rule is a heuristic, so it is opt-in (-maprangeorder.enable). It
+// fires when all of these hold:
+//
+//   - the body of a range loop over a map appends to a local slice declared
+//     before the loop, outside any function literal;
+//   - after the loop, the slice is returned, or passed to json.Marshal,
+//     json.MarshalIndent, (*json.Encoder).Encode, strings.Join or a fmt
+//     print function;
+//   - no call to a function of package sort, or to slices.Sort,
+//     slices.SortFunc or slices.SortStableFunc, mentions the slice between
+//     the loop and that use.
+var mapRangeOrderRule = &Rule{
+	ID:       "CogMapRangeOrder",
+	Doc:      "report slices built from map iteration and used without sorting",
+	Run:      runMapRangeOrder,
+	Category: CategoryTypes,
+
+	Severity: SeverityWarning,
+}
+
+// mapRangeOrderEnable turns the rule on.
+var mapRangeOrderEnable bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&mapRangeOrderEnable, "maprangeorder.enable", false,
+		"report slices built from map iteration and returned or encoded unsorted (heuristic)")
+}
+
+// orderedSinks are the calls whose output depends on the order of a slice
+// argument.
+var orderedSinks = map[string]bool{
+	"encoding/json.Marshal":           true,
+	"encoding/json.MarshalIndent":     true,
+	"(*encoding/json.Encoder).Encode": true,
+	"strings.Join":                    true,
+	"fmt.Print":                       true,
+	"fmt.Printf":                      true,
+	"fmt.Println":                     true,
+	"fmt.Sprint":                      true,
+	"fmt.Sprintf":                     true,
+	"fmt.Sprintln":                    true,
+	"fmt.Fprint":                      true,
+	"fmt.Fprintf":                     true,
+	"fmt.Fprintln":                    true,
+}
+
+func runMapRangeOrder(p *Pass) {
+	if !mapRangeOrderEnable {
+		return
+	}
+	for c := range p.Inspector.Root().Preorder((*ast.RangeStmt)(nil)) {
+		rng, ok := c.Node().(*ast.RangeStmt)
+		if !ok {
+			continue
+		}
+		if _, isMap := p.TypesInfo.TypeOf(rng.X).Underlying().(*types.Map); !isMap {
+			continue
+		}
+		_, body := enclosingFunc(c)
+		if body == nil {
+			continue
+		}
+		for _, s := range appendedSlices(p, c, rng) {
+			use, ok := orderedUse(p, body, s, rng.End())
+			if !ok {
+				continue
+			}
+			m := types.ExprString(rng.X)
+			p.Report(rng, "range over map "+m+" appends to "+s.Name()+" in random order, and "+s.Name()+
+				" is "+use.verb+" unsorted on line "+strconv.Itoa(p.Fset.Position(use.pos).Line)+
+				"; range over slices.Sorted(maps.Keys("+m+")) or sort "+s.Name()+" first")
+			break
+		}
+	}
+}
+
+// appendedSlices returns the local slices declared before rng that its
+// body appends to, in order of first append.
+func appendedSlices(p *Pass, c inspector.Cursor, rng *ast.RangeStmt) []*types.Var {
+	vars := make([]*types.Var, 0)
+	seen := make(map[*types.Var]bool)
+	for ac := range c.Preorder((*ast.AssignStmt)(nil)) {
+		assign, ok := ac.Node().(*ast.AssignStmt)
+		if !ok || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 || enclosingFuncLit(ac) != enclosingFuncLit(c) {
+			continue
+		}
+		call, ok := ast.Unparen(assign.Rhs[0]).(*ast.CallExpr)
+		if !ok || !isBuiltin(p, call.Fun, "append") {
+			continue
+		}
+		v := localVar(p, assign.Lhs[0])
+		if v == nil || seen[v] || v.Pos() >= rng.Pos() {
+			continue
+		}
+		seen[v] = true
+		vars = append(vars, v)
+	}
+	return vars
+}
+
+// sliceUse is an order-dependent use of a slice.
+type sliceUse struct {
+	pos  token.Pos
+	verb string
+}
+
+// orderedUse returns the first order-dependent use of s in body after pos,
+// unless the slice is sorted before it.
+func orderedUse(p *Pass, body *ast.BlockStmt, s *types.Var, after token.Pos) (sliceUse, bool) {
+	cur, ok := p.Inspector.Root().FindNode(body)
+	if !ok {
+		return sliceUse{}, false
+	}
+	for n := range cur.Preorder((*ast.ReturnStmt)(nil), (*ast.CallExpr)(nil)) {
+		node := n.Node()
+		if node.Pos() < after {
+			continue
+		}
+		switch node := node.(type) {
+		case *ast.CallExpr:
+			if !mentions(p, node.Args, s) {
+				continue
+			}
+			name := calleeName(p.TypesInfo, node)
+			if strings.HasPrefix(name, "sort.") || strings.HasPrefix(name, "slices.Sort") {
+				return sliceUse{}, false
+			}
+			if orderedSinks[name] {
+				return sliceUse{pos: node.Pos(), verb: "passed to " + types.ExprString(node.Fun)}, true
+			}
+		case *ast.ReturnStmt:
+			for _, r := range node.Results {
+				if id, ok := ast.Unparen(r).(*ast.Ident); ok && p.TypesInfo.Uses[id] == s {
+					return sliceUse{pos: node.Pos(), verb: "returned"}, true
+				}
+			}
+		}
+	}
+	return sliceUse{}, false
+}
+
+// mentions reports whether any of exprs refers to v.
+func mentions(p *Pass, exprs []ast.Expr, v *types.Var) bool {
+	found := false
+	for _, e := range exprs {
+		ast.Inspect(e, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok && p.TypesInfo.Uses[id] == v {
+				found = true
+			}
+			return !found
+		})
+	}
+	return found
+}
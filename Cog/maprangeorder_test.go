@@ -0,0 +1,14 @@
+package cog
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestMapRangeOrder(t *testing.T) {
+	saved := mapRangeOrderEnable
+	t.Cleanup(func() { mapRangeOrderEnable = saved })
+	mapRangeOrderEnable = true
+	analysistest.Run(t, analysistest.TestData(), ruleAnalyzer(mapRangeOrderRule), "maprangeorder")
+}
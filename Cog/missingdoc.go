@@ -0,0 +1,157 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"path"
+	"strings"
+)
+
+// missingDocRule reports exported identifiers of public API packages that
+// have no doc comment.
+//
+//	func NewClient(addr string) (*Client, error) { // no doc comment
+//
+// Code generated in a hurry often documents nothing, and in a package
+// others import the doc comment is the API's contract. The rule checks the
+// packages whose import path matches one of -missingdoc.packages, each a
+// path.Match pattern or a path ending in /... for the packages below it,
+// and does nothing until it is set. It reports exported functions, methods
+// of exported types, types, constants and variables declared without a
+// doc comment; a comment on a parenthesized const, var or type group covers
+// its members. With -missingdoc.prefix it also reports doc comments that
+// do not start with the identifier's name, optionally after "A", "An" or
+// "The", as Go convention asks. _test.go files are not checked.
+var missingDocRule = &Rule{
+	ID:       "CogMissingDoc",
+	Doc:      "report exported identifiers of public API packages without a doc comment",
+	Run:      runMissingDoc,
+	Category: CategoryStyle,
+
+	Severity: SeverityWarning,
+}
+
+// missingDocPackages lists the import path patterns of the packages to
+// check.
+var missingDocPackages listFlag
+
+// missingDocPrefix also reports doc comments not starting with the name.
+var missingDocPrefix bool
+
+func init() {
+	Analyzer.Flags.Var(&missingDocPackages, "missingdoc.packages",
+		"comma-separated import path patterns of public API packages whose exported identifiers need doc comments, "+
+			"e.g. example.com/lib/api,example.com/lib/client/...")
+	Analyzer.Flags.BoolVar(&missingDocPrefix, "missingdoc.prefix", false,
+		"also report doc comments that do not start with the name of the identifier they document")
+}
+
+func runMissingDoc(p *Pass) {
+	if !publicAPIPackage(p.Pkg.Path()) {
+		return
+	}
+	for _, file := range p.Files {
+		if strings.HasSuffix(p.Fset.Position(file.Package).Filename, "_test.go") {
+			continue
+		}
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Name.IsExported() && exportedReceiver(decl) {
+					checkDoc(p, decl.Name, decl.Doc, funcKind(decl))
+				}
+			case *ast.GenDecl:
+				checkGenDoc(p, decl)
+			}
+		}
+	}
+}
+
+// publicAPIPackage reports whether the import path pkg matches one of
+// -missingdoc.packages.
+func publicAPIPackage(pkg string) bool {
+	for _, pattern := range missingDocPackages {
+		if prefix, ok := strings.CutSuffix(pattern, "/..."); ok {
+			if pkg == prefix || strings.HasPrefix(pkg, prefix+"/") {
+				return true
+			}
+			continue
+		}
+		if matched, err := path.Match(pattern, pkg); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// exportedReceiver reports whether decl is a function, or a method of an
+// exported type.
+func exportedReceiver(decl *ast.FuncDecl) bool {
+	if decl.Recv == nil || len(decl.Recv.List) == 0 {
+		return true
+	}
+	name := funcDeclName(decl)
+	return ast.IsExported(name[:strings.IndexByte(name, '.')])
+}
+
+// funcKind names the kind of decl for messages.
+func funcKind(decl *ast.FuncDecl) string {
+	if decl.Recv != nil {
+		return "method"
+	}
+	return "function"
+}
+
+// checkGenDoc checks the exported names of the const, var or type
+// declaration decl. The doc comment of a parenthesized group covers the
+// specs that have none of their own.
+func checkGenDoc(p *Pass, decl *ast.GenDecl) {
+	kind := map[token.Token]string{token.CONST: "constant", token.VAR: "variable", token.TYPE: "type"}[decl.Tok]
+	if kind == "" {
+		return
+	}
+	for _, spec := range decl.Specs {
+		var doc *ast.CommentGroup
+		var names []*ast.Ident
+		switch spec := spec.(type) {
+		case *ast.TypeSpec:
+			doc, names = spec.Doc, []*ast.Ident{spec.Name}
+		case *ast.ValueSpec:
+			doc, names = spec.Doc, spec.Names
+		}
+		if !decl.Lparen.IsValid() {
+			doc = decl.Doc
+		} else if doc == nil && decl.Doc != nil {
+			continue
+		}
+		// One comment documents every name of a spec, so only the first
+		// exported one is checked.
+		for _, name := range names {
+			if name.IsExported() {
+				checkDoc(p, name, doc, kind)
+				break
+			}
+		}
+	}
+}
+
+// checkDoc reports the exported name when doc is missing or, with
+// -missingdoc.prefix, when it does not start with the name.
+func checkDoc(p *Pass, name *ast.Ident, doc *ast.CommentGroup, kind string) {
+	if doc == nil || strings.TrimSpace(doc.Text()) == "" {
+		p.Report(name, "exported "+kind+" "+name.Name+" has no doc comment; document it with a comment "+
+			"starting with "+name.Name)
+		return
+	}
+	if !missingDocPrefix {
+		return
+	}
+	text := doc.Text()
+	for _, article := range []string{"A ", "An ", "The "} {
+		text = strings.TrimPrefix(text, article)
+	}
+	rest, ok := strings.CutPrefix(text, name.Name)
+	if !ok || rest != "" && !strings.ContainsAny(rest[:1], " \n\t,.:;'") {
+		p.Report(name, "doc comment of exported "+kind+" "+name.Name+" should start with "+name.Name)
+	}
+}
@@ -0,0 +1,21 @@
+package cog
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestMissingDoc(t *testing.T) {
+	saved := missingDocPackages
+	t.Cleanup(func() { missingDocPackages = saved })
+	missingDocPackages = listFlag{"missingdoc"}
+	analysistest.Run(t, analysistest.TestData(), ruleAnalyzer(missingDocRule), "missingdoc", "missingdocoff")
+}
+
+func TestMissingDocPrefix(t *testing.T) {
+	savedPackages, savedPrefix := missingDocPackages, missingDocPrefix
+	t.Cleanup(func() { missingDocPackages, missingDocPrefix = savedPackages, savedPrefix })
+	missingDocPackages, missingDocPrefix = listFlag{"missingdocprefix"}, true
+	analysistest.Run(t, analysistest.TestData(), ruleAnalyzer(missingDocRule), "missingdocprefix")
+}
@@ -0,0 +1,115 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// missingReturnRule reports an HTTP error response written in an error
+// branch that then falls through to the rest of the handler.
+//
+//	user, err := load(r)
+//	if err != nil {
+//		http.Error(w, "not found", http.StatusNotFound)
+//	}
+//	json.NewEncoder(w).Encode(user) // still runs: a second response
+//
+// http.Error and w.WriteHeader write the response but do not stop the
+// handler, so the success path runs too, writing a body after the error
+// and logging "superfluous response.WriteHeader call". The rule fires on
+// an http.Error or ResponseWriter.WriteHeader statement directly inside an
+// `if` whose condition tests err != nil, when the branch ends without a
+// return, a branch statement or a call that does not return, and
+// statements follow the `if`. Branches ending in another `if`, a loop or a
+// switch are not followed. When the function has no results, the fix adds
+// the return.
+var missingReturnRule = &Rule{
+	ID:       "CogMissingReturn",
+	Doc:      "report http.Error and WriteHeader in an error branch that falls through",
+	Run:      runMissingReturn,
+	Category: CategoryErrors,
+	Fixable:  true,
+}
+
+func runMissingReturn(p *Pass) {
+	for c := range p.Inspector.Root().Preorder((*ast.IfStmt)(nil)) {
+		ifStmt, ok := c.Node().(*ast.IfStmt)
+		if !ok || len(ifStmt.Body.List) == 0 || !checksError(p, ifStmt.Cond) || len(followingStmts(c)) == 0 {
+			continue
+		}
+		last := ifStmt.Body.List[len(ifStmt.Body.List)-1]
+		if !fallsThrough(p, last) {
+			continue
+		}
+		for _, stmt := range ifStmt.Body.List {
+			call := writesHTTPError(p, stmt)
+			if call == nil {
+				continue
+			}
+			fixes := make([]analysis.SuggestedFix, 0, 1)
+			if sig := enclosingSignature(p, c); sig != nil && sig.Results().Len() == 0 {
+				eol := lineEnd(p, last.End())
+				fixes = append(fixes, analysis.SuggestedFix{
+					Message:   "Return after writing the error",
+					TextEdits: []analysis.TextEdit{{Pos: eol, End: eol, NewText: []byte("\n" + lineIndent(p, last.Pos()) + "return")}},
+				})
+			}
+			p.Report(call, types.ExprString(call.Fun)+" writes the error response, but the branch falls through "+
+				"and the handler goes on to write another; return after it", fixes...)
+			break
+		}
+	}
+}
+
+// checksError reports whether cond tests an error for being non-nil.
+func checksError(p *Pass, cond ast.Expr) bool {
+	found := false
+	ast.Inspect(cond, func(n ast.Node) bool {
+		bin, ok := n.(*ast.BinaryExpr)
+		if !ok || bin.Op != token.NEQ {
+			return !found
+		}
+		for _, pair := range [][2]ast.Expr{{bin.X, bin.Y}, {bin.Y, bin.X}} {
+			if isNilIdent(p, ast.Unparen(pair[1])) && isErrorType(p.TypesInfo.TypeOf(pair[0])) {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// fallsThrough reports whether control continues past stmt, the last
+// statement of a branch: it is a simple statement that is not a return, a
+// branch statement or a call that does not return.
+func fallsThrough(p *Pass, stmt ast.Stmt) bool {
+	switch s := stmt.(type) {
+	case *ast.ExprStmt:
+		call, ok := ast.Unparen(s.X).(*ast.CallExpr)
+		return !ok || !isNoReturn(p, call)
+	case *ast.AssignStmt, *ast.IncDecStmt, *ast.SendStmt, *ast.DeclStmt, *ast.DeferStmt, *ast.GoStmt:
+		return true
+	}
+	return false
+}
+
+// writesHTTPError returns the call when stmt is a call of http.Error or of
+// the WriteHeader method of an http.ResponseWriter, or nil.
+func writesHTTPError(p *Pass, stmt ast.Stmt) *ast.CallExpr {
+	es, ok := stmt.(*ast.ExprStmt)
+	if !ok {
+		return nil
+	}
+	call, ok := ast.Unparen(es.X).(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+	switch calleeName(p.TypesInfo, call) {
+	case "net/http.Error", "(net/http.ResponseWriter).WriteHeader":
+		return call
+	}
+	return nil
+}
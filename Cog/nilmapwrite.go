@@ -0,0 +1,212 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// nilMapWriteRule reports a write to a map that is still nil on some path
+// to it, which panics at run time.
+//
+//	var counts map[string]int // nil
+//	counts["x"]++             // panic: assignment to entry in nil map
+//
+// Like CogTypedNil the rule follows SSA values, so a map declared with var
+// is tracked through branches: the write is reported when no make or
+// literal reaches it on at least one path. A value that a nil check guards,
+// as in the lazy initialization
+//
+//	if m == nil {
+//		m = make(map[string]int)
+//	}
+//	m[k]++
+//
+// is non-nil on the path that skips the make. For a struct field, the rule
+// fires when the struct is a local value whose map field is never set in
+// the function; values from elsewhere, such as a constructor that makes the
+// map, are assumed initialized. delete on a nil map is a no-op and is not
+// reported.
+//
+// The fix initializes a `var m map[K]V` declaration with make. It is only
+// offered when the function cannot tell the two apart: when m is used
+// only to index, range over, or pass to len, delete or clear, and not
+// returned, compared with nil or assigned.
+var nilMapWriteRule = &Rule{
+	ID:       "CogNilMapWrite",
+	Doc:      "report writes to maps that may still be nil",
+	Run:      runNilMapWrite,
+	Category: CategoryErrors,
+	Fixable:  true,
+}
+
+func runNilMapWrite(p *Pass) {
+	writes := make(map[token.Pos]*ast.IndexExpr)
+	for n := range p.Inspector.PreorderSeq((*ast.IndexExpr)(nil)) {
+		if ix, ok := n.(*ast.IndexExpr); ok {
+			writes[ix.Lbrack] = ix
+		}
+	}
+
+	for _, fn := range p.SSA.SrcFuncs {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				update, ok := instr.(*ssa.MapUpdate)
+				if !ok {
+					continue
+				}
+				ix, ok := writes[update.Pos()]
+				if !ok {
+					continue
+				}
+				typ := types.TypeString(update.Map.Type(), types.RelativeTo(p.Pkg))
+				if uninitializedField(update.Map) {
+					p.Report(ix, types.ExprString(ix.X)+" is never initialized in this function, so writing to "+
+						"the nil map panics; set the field to make("+typ+") first, for example in a constructor")
+					continue
+				}
+				if !mapMayBeNil(update.Map, make(map[ssa.Value]bool)) || provenNonNil(update.Map, b) {
+					continue
+				}
+				reportNilMapWrite(p, ix, typ)
+			}
+		}
+	}
+}
+
+// mapMayBeNil is mayBeNil for maps, except that a phi edge whose value a
+// nil check proved non-nil on the way in does not count.
+func mapMayBeNil(v ssa.Value, seen map[ssa.Value]bool) bool {
+	if seen[v] {
+		return false
+	}
+	seen[v] = true
+
+	switch v := v.(type) {
+	case *ssa.Const:
+		return v.IsNil()
+	case *ssa.Phi:
+		for i, e := range v.Edges {
+			if !guardedEdge(e, v.Block().Preds[i], v.Block()) && mapMayBeNil(e, seen) {
+				return true
+			}
+		}
+		return false
+	case *ssa.ChangeType:
+		return mapMayBeNil(v.X, seen)
+	default:
+		return false
+	}
+}
+
+// guardedEdge reports whether v is non-nil on the edge from block pred to
+// block succ: pred branches on a nil check of v and succ is its non-nil
+// side, or every path to pred took that side of an earlier check.
+func guardedEdge(v ssa.Value, pred, succ *ssa.BasicBlock) bool {
+	if ifInstr, ok := pred.Instrs[len(pred.Instrs)-1].(*ssa.If); ok {
+		if cmp, ok := ifInstr.Cond.(*ssa.BinOp); ok && (cmp.Op == token.EQL || cmp.Op == token.NEQ) && comparesWithNil(cmp, v) {
+			nonNil := pred.Succs[0]
+			if cmp.Op == token.EQL {
+				nonNil = pred.Succs[1]
+			}
+			return succ == nonNil
+		}
+	}
+	return provenNonNil(v, pred)
+}
+
+// reportNilMapWrite reports the write ix and, when its map is declared by
+// a plain `var m map[K]V` that the function uses only in ways a nil and an
+// empty map behave alike, offers to initialize the declaration with make.
+func reportNilMapWrite(p *Pass, ix *ast.IndexExpr, typ string) {
+	name := types.ExprString(ix.X)
+	msg := name + " may be a nil map here, and writing to a nil map panics; initialize it with make(" + typ + ")"
+	id, ok := ast.Unparen(ix.X).(*ast.Ident)
+	if !ok {
+		p.Report(ix, msg)
+		return
+	}
+	obj := p.TypesInfo.Uses[id]
+	decl, spec := varDeclStmt(p, obj)
+	if decl == nil || nilObservable(p, obj) {
+		p.Report(ix, msg)
+		return
+	}
+	p.Report(ix, msg, analysis.SuggestedFix{
+		Message: "Initialize " + id.Name + " with make",
+		TextEdits: []analysis.TextEdit{{
+			Pos:     decl.Pos(),
+			End:     decl.End(),
+			NewText: []byte(id.Name + " := make(" + types.ExprString(spec.Type) + ")"),
+		}},
+	})
+}
+
+// nilObservable reports whether a use of the map variable obj could tell a
+// nil map from an empty one: any use other than indexing it, ranging over
+// it, or passing it to len, delete or clear.
+func nilObservable(p *Pass, obj types.Object) bool {
+	for c := range p.Inspector.Root().Preorder((*ast.Ident)(nil)) {
+		id, ok := c.Node().(*ast.Ident)
+		if !ok || p.TypesInfo.Uses[id] != obj {
+			continue
+		}
+		switch parent := c.Parent().Node().(type) {
+		case *ast.IndexExpr:
+			if parent.X == id {
+				continue // m[k] reads or writes an element, not m
+			}
+		case *ast.RangeStmt:
+			if parent.X == id {
+				continue
+			}
+		case *ast.CallExpr:
+			if isBuiltin(p, parent.Fun, "len") || isBuiltin(p, parent.Fun, "delete") || isBuiltin(p, parent.Fun, "clear") {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// uninitializedField reports whether m loads a map field of a local struct
+// that the function never stores to, and the struct never escapes to code
+// that could set the field.
+func uninitializedField(m ssa.Value) bool {
+	load, ok := m.(*ssa.UnOp)
+	if !ok || load.Op != token.MUL {
+		return false
+	}
+	fa, ok := load.X.(*ssa.FieldAddr)
+	if !ok {
+		return false
+	}
+	alloc, ok := fa.X.(*ssa.Alloc)
+	if !ok {
+		return false
+	}
+	for _, ref := range *alloc.Referrers() {
+		switch ref := ref.(type) {
+		case *ssa.FieldAddr:
+			if ref.Field != fa.Field {
+				continue
+			}
+			for _, use := range *ref.Referrers() {
+				if store, ok := use.(*ssa.Store); ok && store.Addr == ref {
+					return false // the field is assigned
+				}
+				if _, ok := use.(*ssa.UnOp); !ok {
+					return false // its address escapes
+				}
+			}
+		case *ssa.DebugRef:
+		default:
+			return false // the struct escapes or is overwritten whole
+		}
+	}
+	return true
+}
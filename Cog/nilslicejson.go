@@ -0,0 +1,242 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// nilSliceJSONRule reports Mistake 6: a slice declared with `var x []T`
+// that reaches a JSON-tagged struct field while still nil.
+//
+//	var items []string // nil slice
+//	if found {
+//		items = append(items, "item1")
+//	}
+//	return Response{Items: items} // {"items":null} when !found
+//
+// By default the rule only fires for struct types the package passes to
+// json.Marshal, json.MarshalIndent or (*json.Encoder).Encode, directly or
+// nested. With -nilslicejson.strict it fires for every JSON-tagged field.
+// Fields tagged omitempty are skipped: nil and empty slices both vanish.
+var nilSliceJSONRule = &Rule{
+	ID:       "CogNilSliceJSON",
+	Doc:      "report nil slices stored in JSON-tagged struct fields",
+	Run:      runNilSliceJSON,
+	Category: CategoryTypes,
+	Fixable:  true,
+}
+
+// nilSliceJSONStrict reports JSON-tagged fields whether or not the package
+// marshals the struct.
+var nilSliceJSONStrict bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&nilSliceJSONStrict, "nilslicejson.strict", false,
+		"report nil slices in any JSON-tagged field, not only in structs the package marshals")
+}
+
+func runNilSliceJSON(p *Pass) {
+	values := fieldStoreValues(p)
+	marshaled := marshaledStructs(p)
+
+	for _, fn := range p.SSA.SrcFuncs {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				store, ok := instr.(*ssa.Store)
+				if !ok {
+					continue
+				}
+				fa, ok := store.Addr.(*ssa.FieldAddr)
+				if !ok {
+					continue
+				}
+				st, ok := derefUnderlying(fa.X.Type()).(*types.Struct)
+				if !ok {
+					continue
+				}
+				field := st.Field(fa.Field)
+				slice, ok := field.Type().Underlying().(*types.Slice)
+				if !ok || !jsonEncodedField(st.Tag(fa.Field)) {
+					continue
+				}
+				if !nilSliceJSONStrict && !marshaled[types.Unalias(derefType(fa.X.Type()))] {
+					continue
+				}
+				if !mayBeNil(store.Val, make(map[ssa.Value]bool)) {
+					continue
+				}
+				expr, ok := values[store.Pos()]
+				if !ok {
+					continue
+				}
+				reportNilSliceJSON(p, expr, field, slice)
+			}
+		}
+	}
+}
+
+// reportNilSliceJSON reports expr and, when it names a plain `var x []T`
+// declaration, offers to initialize the variable with make.
+func reportNilSliceJSON(p *Pass, expr ast.Expr, field *types.Var, slice *types.Slice) {
+	typ := types.TypeString(slice, types.RelativeTo(p.Pkg))
+	msg := types.ExprString(expr) + " may be a nil " + typ + " when stored in JSON field " +
+		field.Name() + ", which encodes as null; initialize it with make(" + typ + ", 0)"
+
+	id, ok := ast.Unparen(expr).(*ast.Ident)
+	if !ok {
+		p.Report(expr, msg)
+		return
+	}
+	decl, spec := varDeclStmt(p, p.TypesInfo.Uses[id])
+	if decl == nil {
+		p.Report(expr, msg)
+		return
+	}
+	p.Report(expr, msg, analysis.SuggestedFix{
+		Message: "Initialize " + id.Name + " with make",
+		TextEdits: []analysis.TextEdit{{
+			Pos:     decl.Pos(),
+			End:     decl.End(),
+			NewText: []byte(id.Name + " := make(" + types.ExprString(spec.Type) + ", 0)"),
+		}},
+	})
+}
+
+// varDeclStmt returns the `var x T` statement declaring obj, and its spec,
+// when obj is the statement's only name and has no initializer.
+func varDeclStmt(p *Pass, obj types.Object) (*ast.DeclStmt, *ast.ValueSpec) {
+	if obj == nil {
+		return nil, nil
+	}
+	for n := range p.Inspector.PreorderSeq((*ast.DeclStmt)(nil)) {
+		decl, ok := n.(*ast.DeclStmt)
+		if !ok || decl.Pos() > obj.Pos() || decl.End() < obj.Pos() {
+			continue
+		}
+		gen, ok := decl.Decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR || len(gen.Specs) != 1 {
+			return nil, nil
+		}
+		spec, ok := gen.Specs[0].(*ast.ValueSpec)
+		if !ok || len(spec.Names) != 1 || len(spec.Values) != 0 || spec.Type == nil || p.TypesInfo.Defs[spec.Names[0]] != obj {
+			return nil, nil
+		}
+		return decl, spec
+	}
+	return nil, nil
+}
+
+// fieldStoreValues maps the position SSA gives a struct field store to the
+// expression being stored: the colon of a keyed composite literal element,
+// the start of an unkeyed one, or the selector of `x.f = v`.
+func fieldStoreValues(p *Pass) map[token.Pos]ast.Expr {
+	values := make(map[token.Pos]ast.Expr)
+	for n := range p.Inspector.PreorderSeq((*ast.CompositeLit)(nil), (*ast.AssignStmt)(nil)) {
+		switch n := n.(type) {
+		case *ast.CompositeLit:
+			if _, ok := derefUnderlying(p.TypesInfo.TypeOf(n)).(*types.Struct); !ok {
+				continue
+			}
+			for _, elt := range n.Elts {
+				if kv, ok := elt.(*ast.KeyValueExpr); ok {
+					values[kv.Colon] = kv.Value
+				} else {
+					values[elt.Pos()] = elt
+				}
+			}
+		case *ast.AssignStmt:
+			if len(n.Lhs) != len(n.Rhs) {
+				continue
+			}
+			for i, lhs := range n.Lhs {
+				if sel, ok := lhs.(*ast.SelectorExpr); ok {
+					values[sel.Sel.Pos()] = n.Rhs[i]
+				}
+			}
+		}
+	}
+	return values
+}
+
+// marshaledStructs returns the named types reachable from the arguments of
+// the package's json.Marshal, json.MarshalIndent and Encoder.Encode calls.
+func marshaledStructs(p *Pass) map[types.Type]bool {
+	seen := make(map[types.Type]bool)
+	for n := range p.Inspector.PreorderSeq((*ast.CallExpr)(nil)) {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			continue
+		}
+		switch calleeName(p.TypesInfo, call) {
+		case "encoding/json.Marshal", "encoding/json.MarshalIndent", "(*encoding/json.Encoder).Encode":
+			collectNamedTypes(p.TypesInfo.TypeOf(call.Args[0]), seen)
+		}
+	}
+	return seen
+}
+
+// collectNamedTypes adds every named type reachable from t to seen.
+func collectNamedTypes(t types.Type, seen map[types.Type]bool) {
+	if t == nil {
+		return
+	}
+	t = types.Unalias(t)
+	if named, ok := t.(*types.Named); ok {
+		if seen[named] {
+			return
+		}
+		seen[named] = true
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Pointer:
+		collectNamedTypes(u.Elem(), seen)
+	case *types.Slice:
+		collectNamedTypes(u.Elem(), seen)
+	case *types.Array:
+		collectNamedTypes(u.Elem(), seen)
+	case *types.Map:
+		collectNamedTypes(u.Elem(), seen)
+	case *types.Struct:
+		for i := range u.NumFields() {
+			collectNamedTypes(u.Field(i).Type(), seen)
+		}
+	}
+}
+
+// jsonEncodedField reports whether a struct tag names a JSON field that is
+// always encoded: tagged, not "-", and not omitempty.
+func jsonEncodedField(tag string) bool {
+	value, ok := reflect.StructTag(tag).Lookup("json")
+	if !ok || value == "-" {
+		return false
+	}
+	opts := strings.Split(value, ",")[1:]
+	for _, opt := range opts {
+		if opt == "omitempty" || opt == "omitzero" {
+			return false
+		}
+	}
+	return true
+}
+
+// derefType returns the element type of a pointer, or t itself.
+func derefType(t types.Type) types.Type {
+	if ptr, ok := t.Underlying().(*types.Pointer); ok {
+		return ptr.Elem()
+	}
+	return t
+}
+
+// derefUnderlying returns the underlying type of t after one dereference.
+func derefUnderlying(t types.Type) types.Type {
+	if t == nil {
+		return nil
+	}
+	return derefType(t).Underlying()
+}
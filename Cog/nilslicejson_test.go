@@ -0,0 +1,18 @@
+package cog
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestNilSliceJSON(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), ruleAnalyzer(nilSliceJSONRule), "nilslicejson")
+}
+
+func TestNilSliceJSONStrict(t *testing.T) {
+	saved := nilSliceJSONStrict
+	t.Cleanup(func() { nilSliceJSONStrict = saved })
+	nilSliceJSONStrict = true
+	analysistest.Run(t, analysistest.TestData(), ruleAnalyzer(nilSliceJSONRule), "nilslicejsonstrict")
+}
@@ -0,0 +1,115 @@
+package cog
+
+import (
+	"go/ast"
+	"go/types"
+	"strconv"
+
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// osExitRule reports os.Exit and log.Fatal calls that end the program from
+// the wrong place.
+//
+//	func loadConfig(path string) Config {
+//		f, err := os.Open(path)
+//		if err != nil {
+//			log.Fatal(err) // exits from a helper: untestable
+//		}
+//		defer f.Close()
+//		...
+//		os.Exit(1) // f.Close never runs
+//
+// Outside main, init and TestMain an exit takes the decision away from the
+// caller and makes the function impossible to test; the error belongs in a
+// return value, up to main. Anywhere, an exit after a defer of the same
+// function skips that deferred call. -osexit.deferonly drops the first
+// check, for command-line tools that exit deep in the call stack on
+// purpose.
+var osExitRule = &Rule{
+	ID:       "CogOsExit",
+	Doc:      "report os.Exit and log.Fatal outside main and after pending defers",
+	Run:      runOsExit,
+	Category: CategoryErrors,
+}
+
+// osExitDeferOnly limits the rule to exits that skip deferred calls.
+var osExitDeferOnly bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&osExitDeferOnly, "osexit.deferonly", false,
+		"only report exits that skip deferred calls, allowing exits outside main")
+}
+
+// exitFuncs are the calls that end the process without returning.
+var exitFuncs = map[string]bool{
+	"os.Exit":               true,
+	"log.Fatal":             true,
+	"log.Fatalf":            true,
+	"log.Fatalln":           true,
+	"(*log.Logger).Fatal":   true,
+	"(*log.Logger).Fatalf":  true,
+	"(*log.Logger).Fatalln": true,
+}
+
+func runOsExit(p *Pass) {
+	for c := range p.Inspector.Root().Preorder((*ast.CallExpr)(nil)) {
+		call, ok := c.Node().(*ast.CallExpr)
+		if !ok || !exitFuncs[calleeName(p.TypesInfo, call)] {
+			continue
+		}
+		what := types.ExprString(call.Fun)
+		if d := pendingDefer(c); d != nil {
+			deferred, _ := sourceText(p, d.Call)
+			advice := "return instead, passing the error up to main, so that deferred calls run"
+			if decl := enclosingDecl(c); decl != nil && decl.Name.Name == "main" && decl.Recv == nil {
+				advice = "move the body into a `run() error` function and exit in main when it fails"
+			}
+			p.Report(call, what+" skips the call deferred on line "+strconv.Itoa(p.Fset.Position(d.Pos()).Line)+
+				" ("+deferred+"); "+advice)
+			continue
+		}
+		if osExitDeferOnly || exitAllowed(p, c) {
+			continue
+		}
+		p.Report(call, what+" outside main ends the program from a helper, which callers cannot handle "+
+			"and tests cannot survive; return an error up to main and exit there")
+	}
+}
+
+// pendingDefer returns a defer statement of the function containing the
+// exit at c that has run on every path to it: one that precedes c in a
+// block enclosing c. It returns nil when there is none.
+func pendingDefer(c inspector.Cursor) *ast.DeferStmt {
+	for bc := range c.Enclosing((*ast.BlockStmt)(nil), (*ast.FuncLit)(nil), (*ast.FuncDecl)(nil)) {
+		block, ok := bc.Node().(*ast.BlockStmt)
+		if !ok {
+			return nil
+		}
+		for _, stmt := range block.List {
+			if stmt.Pos() >= c.Node().Pos() {
+				break
+			}
+			if d, ok := stmt.(*ast.DeferStmt); ok {
+				return d
+			}
+		}
+	}
+	return nil
+}
+
+// exitAllowed reports whether the exit at c lies in main or init of package
+// main, or in a TestMain.
+func exitAllowed(p *Pass, c inspector.Cursor) bool {
+	decl := enclosingDecl(c)
+	if decl == nil || decl.Recv != nil {
+		return false
+	}
+	switch decl.Name.Name {
+	case "main", "init":
+		return p.Pkg.Name() == "main"
+	case "TestMain":
+		return true
+	}
+	return false
+}
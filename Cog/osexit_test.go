@@ -0,0 +1,18 @@
+package cog
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestOsExit(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), ruleAnalyzer(osExitRule), "osexit", "osexitmain")
+}
+
+func TestOsExitDeferOnly(t *testing.T) {
+	saved := osExitDeferOnly
+	t.Cleanup(func() { osExitDeferOnly = saved })
+	osExitDeferOnly = true
+	analysistest.Run(t, analysistest.TestData(), ruleAnalyzer(osExitRule), "osexitdeferonly")
+}
@@ -0,0 +1,135 @@
+package cog
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// panicStringRule reports panics with a message built at run time instead
+// of an error.
+//
+//	panic("load config: " + err.Error()) // recover sees a string: errors.Is and errors.As cannot match it
+//	panic(fmt.Sprintf("bad state %d", s))
+//
+// A handler that recovers usually turns the value into an error with
+// `if err, ok := v.(error)`; a string fails that assertion, and the error
+// it was built from is lost from the chain. The rule fires on panic of a
+// string built by fmt.Sprintf, fmt.Sprint, fmt.Sprintln or a non-constant
+// concatenation. Constant messages such as panic("unreachable") mark
+// impossible states and are left alone. The fix panics with an error
+// instead: fmt.Sprintf becomes fmt.Errorf, fmt.Sprint and fmt.Sprintln are
+// wrapped in errors.New, and a concatenation becomes the equivalent
+// fmt.Errorf, with %w for the operands of the form err.Error():
+//
+//	panic(fmt.Errorf("load config: %w", err))
+var panicStringRule = &Rule{
+	ID:       "CogPanicString",
+	Doc:      "report panics with a formatted string instead of an error",
+	Run:      runPanicString,
+	Category: CategoryErrors,
+	Fixable:  true,
+}
+
+func runPanicString(p *Pass) {
+	for c := range p.Inspector.Root().Preorder((*ast.CallExpr)(nil)) {
+		call, ok := c.Node().(*ast.CallExpr)
+		if !ok || len(call.Args) != 1 || !isBuiltin(p, call.Fun, "panic") {
+			continue
+		}
+		arg := ast.Unparen(call.Args[0])
+		tv := p.TypesInfo.Types[arg]
+		if b, ok := types.Unalias(tv.Type).(*types.Basic); !ok || b.Info()&types.IsString == 0 || tv.Value != nil {
+			continue
+		}
+		var built string
+		switch arg := arg.(type) {
+		case *ast.CallExpr:
+			switch name := calleeName(p.TypesInfo, arg); name {
+			case "fmt.Sprintf", "fmt.Sprint", "fmt.Sprintln":
+				built = name
+			}
+		case *ast.BinaryExpr:
+			if arg.Op == token.ADD {
+				built = "concatenation"
+			}
+		}
+		if built == "" {
+			continue
+		}
+		fixes := make([]analysis.SuggestedFix, 0, 1)
+		if file := enclosingFile(c); file != nil {
+			if replacement, imports, ok := panicErrorExpr(p, file, arg); ok {
+				edits := append(imports, analysis.TextEdit{Pos: arg.Pos(), End: arg.End(), NewText: []byte(replacement)})
+				fixes = append(fixes, analysis.SuggestedFix{Message: "Panic with an error", TextEdits: edits})
+			}
+		}
+		p.Report(call, "panic with a string built by "+built+"; a recover that checks for an error cannot "+
+			"match it, so panic with an error such as fmt.Errorf(...)", fixes...)
+	}
+}
+
+// panicErrorExpr renders an error expression equivalent to the string
+// expression arg, with the import edits it needs.
+func panicErrorExpr(p *Pass, file *ast.File, arg ast.Expr) (string, []analysis.TextEdit, bool) {
+	if call, ok := arg.(*ast.CallExpr); ok {
+		src, ok := sourceText(p, call)
+		if !ok {
+			return "", nil, false
+		}
+		if calleeName(p.TypesInfo, call) != "fmt.Sprintf" {
+			// fmt.Sprint and fmt.Sprintln have no format to reuse.
+			errorsPkg, imports := importName(p, file, "errors")
+			return errorsPkg + ".New(" + src + ")", imports, true
+		}
+		sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+		if !ok {
+			return "", nil, false
+		}
+		head, ok := sourceRange(p, call.Pos(), sel.Sel.Pos())
+		if !ok {
+			return "", nil, false
+		}
+		return head + "Errorf" + src[int(sel.Sel.End()-call.Pos()):], nil, true
+	}
+
+	fmtPkg, imports := importName(p, file, "fmt")
+
+	var format strings.Builder
+	args := make([]string, 0)
+	for _, operand := range concatOperands(arg) {
+		if v := p.TypesInfo.Types[operand].Value; v != nil && v.Kind() == constant.String {
+			format.WriteString(strings.ReplaceAll(constant.StringVal(v), "%", "%%"))
+			continue
+		}
+		verb, expr := "%s", operand
+		if call, ok := ast.Unparen(operand).(*ast.CallExpr); ok && len(call.Args) == 0 {
+			if sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr); ok && sel.Sel.Name == "Error" &&
+				isErrorType(p.TypesInfo.TypeOf(sel.X)) {
+				verb, expr = "%w", sel.X
+			}
+		}
+		src, ok := sourceText(p, expr)
+		if !ok {
+			return "", nil, false
+		}
+		format.WriteString(verb)
+		args = append(args, src)
+	}
+	return fmtPkg + ".Errorf(" + strconv.Quote(format.String()) + ", " + strings.Join(args, ", ") + ")", imports, true
+}
+
+// concatOperands flattens the string concatenation e into its operands,
+// left to right.
+func concatOperands(e ast.Expr) []ast.Expr {
+	bin, ok := ast.Unparen(e).(*ast.BinaryExpr)
+	if !ok || bin.Op != token.ADD {
+		return []ast.Expr{e}
+	}
+	return append(concatOperands(bin.X), concatOperands(bin.Y)...)
+}
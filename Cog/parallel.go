@@ -0,0 +1,55 @@
+package cog
+
+import (
+	"runtime"
+	"sync"
+)
+
+// parallelJobs is the -j flag: how many packages the rules analyze at once.
+var parallelJobs int
+
+func init() {
+	Analyzer.Flags.IntVar(&parallelJobs, "j", 0,
+		"number of packages to analyze concurrently; 0 means GOMAXPROCS")
+}
+
+// parallelism returns -j, or GOMAXPROCS when -j is not positive.
+func parallelism() int {
+	if parallelJobs > 0 {
+		return parallelJobs
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// A semaphore bounds how many goroutines run a section at once. The
+// analysis drivers start a goroutine per package; the rules of at most cap
+// of them run at a time.
+type semaphore chan struct{}
+
+// newSemaphore returns a semaphore admitting n goroutines at once.
+func newSemaphore(n int) semaphore {
+	return make(semaphore, n)
+}
+
+// acquire blocks until the semaphore admits the calling goroutine, and
+// returns the function that releases it:
+//
+//	defer sem.acquire()()
+func (s semaphore) acquire() func() {
+	s <- struct{}{}
+	return func() { <-s }
+}
+
+// driverSemaphore bounds the packages analyzed at once under a go/analysis
+// driver. It is sized on first use, after the flags and the config file
+// have been applied.
+var driverSemaphore struct {
+	once sync.Once
+	sem  semaphore
+}
+
+// acquireDriverSlot acquires driverSemaphore and returns its release.
+func acquireDriverSlot() func() {
+	driverSemaphore.once.Do(func() { driverSemaphore.sem = newSemaphore(parallelism()) })
+	return driverSemaphore.sem.acquire()
+}
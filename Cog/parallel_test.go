@@ -0,0 +1,93 @@
+package cog
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// benchPackages is the number of packages in the module BenchmarkRun
+// analyzes.
+const benchPackages = 16
+
+// benchSource is the source of each package of that module, with findings
+// for several rules.
+const benchSource = `package %s
+
+import (
+	"os"
+	"strconv"
+)
+
+func Read(name string) ([]byte, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func Sum(xs []string) (total int) {
+	for _, x := range xs {
+		n, _ := strconv.Atoi(x)
+		total += n
+	}
+	return
+}
+`
+
+// writeBenchModule writes the module BenchmarkRun analyzes and changes to
+// its directory.
+func writeBenchModule(tb testing.TB) {
+	tb.Helper()
+	files := map[string]string{"go.mod": "module bench\n\ngo 1.26\n"}
+	for i := range benchPackages {
+		pkg := fmt.Sprintf("pkg%d", i)
+		files[pkg+"/"+pkg+".go"] = fmt.Sprintf(benchSource, pkg)
+	}
+	tb.Chdir(writeModule(tb, files))
+}
+
+// BenchmarkRun compares analyzing a multi-package module one package at a
+// time with analyzing GOMAXPROCS packages at once:
+//
+//	go test -run '^$' -bench Run -cpu 1,4
+func BenchmarkRun(b *testing.B) {
+	writeBenchModule(b)
+	defer func(jobs int) { parallelJobs = jobs }(parallelJobs)
+	for _, jobs := range []int{1, 0} {
+		parallelJobs = jobs
+		b.Run(fmt.Sprintf("j=%d", parallelism()), func(b *testing.B) {
+			for b.Loop() {
+				if _, err := Run(b.Context(), []string{"./..."}, Config{}); err != nil {
+					b.Fatalf("Run: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// TestRunParallelDeterministic checks that the findings do not depend on
+// how many packages are analyzed at once.
+func TestRunParallelDeterministic(t *testing.T) {
+	writeBenchModule(t)
+	defer func(jobs int) { parallelJobs = jobs }(parallelJobs)
+	var want []Finding
+	for _, jobs := range []int{1, 4} {
+		parallelJobs = jobs
+		got, err := Run(t.Context(), []string{"./..."}, Config{})
+		if err != nil {
+			t.Fatalf("Run with -j=%d: %v", jobs, err)
+		}
+		if len(got) == 0 {
+			t.Fatalf("Run with -j=%d found nothing", jobs)
+		}
+		if want == nil {
+			want = got
+			continue
+		}
+		if !slices.EqualFunc(got, want, func(x, y Finding) bool { return x.Fingerprint == y.Fingerprint && x.Pos == y.Pos }) {
+			t.Errorf("Run with -j=%d = %v, want %v as with -j=1", jobs, got, want)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// pointerToLoopVarRule reports the address of a range variable kept beyond
+// its iteration.
+//
+//	for _, item := range items {
+//		ptrs = append(ptrs, &item) // before Go 1.22 every element is the same pointer
+//	}
+//
+// Before Go 1.22 a range loop declares its variables once and assigns them
+// on every iteration, so &item is the same address each time and, after
+// the loop, every stored pointer sees the last item. The rule fires on
+// &v, for a variable declared by an enclosing range statement of the same
+// function, when the pointer escapes the iteration: appended to a slice,
+// assigned to an element, a field or a variable declared outside the
+// loop, or sent on a channel, directly or inside a composite literal.
+// Returning &v is not reported: the loop stops there, so nothing reassigns
+// v. The fix copies the variable at the top of the loop body:
+//
+//	for _, item := range items {
+//		item := item
+//
+// As for CogLoopCapture, the rule only fires for files whose language
+// version is older than Go 1.22, unless -pointertoloopvar.strict asks for
+// the copy everywhere. Goroutines and deferred closures capturing loop
+// variables are reported by CogLoopCapture and CogDeferCapture.
+var pointerToLoopVarRule = &Rule{
+	ID:       "CogPointerToLoopVar",
+	Doc:      "report pointers to range loop variables that outlive the iteration",
+	Run:      runPointerToLoopVar,
+	Category: CategoryTypes,
+	Fixable:  true,
+}
+
+// pointerToLoopVarStrict reports escaping pointers regardless of the Go
+// version.
+var pointerToLoopVarStrict bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&pointerToLoopVarStrict, "pointertoloopvar.strict", false,
+		"report pointers to range variables that escape the iteration even when the file targets Go 1.22 or later")
+}
+
+func runPointerToLoopVar(p *Pass) {
+	for c := range p.Inspector.Root().Preorder((*ast.UnaryExpr)(nil)) {
+		u, ok := c.Node().(*ast.UnaryExpr)
+		if !ok || u.Op != token.AND {
+			continue
+		}
+		id, ok := ast.Unparen(u.X).(*ast.Ident)
+		if !ok {
+			continue
+		}
+		v, ok := p.TypesInfo.Uses[id].(*types.Var)
+		if !ok {
+			continue
+		}
+		loop := declaringRange(p, c, v)
+		if loop == nil || !pointerToLoopVarStrict && perIterationLoopVars(p, enclosingFile(c)) {
+			continue
+		}
+		where := pointerEscape(p, c, loop)
+		if where == "" {
+			continue
+		}
+		indent := lineIndent(p, loop.Pos())
+		p.Report(u, "&"+v.Name()+" is "+where+", but before Go 1.22 "+v.Name()+" is one variable reused by every "+
+			"iteration, so every stored pointer ends up at the last element; copy it first with "+v.Name()+" := "+v.Name(),
+			analysis.SuggestedFix{
+				Message: "Copy " + v.Name() + " at the top of the loop body",
+				TextEdits: []analysis.TextEdit{{
+					Pos:     loop.Body.Lbrace + 1,
+					End:     loop.Body.Lbrace + 1,
+					NewText: []byte("\n" + indent + "\t" + v.Name() + " := " + v.Name()),
+				}},
+			})
+	}
+}
+
+// declaringRange returns the range statement enclosing c, within the same
+// function, that declares v as its key or value, or nil.
+func declaringRange(p *Pass, c inspector.Cursor, v *types.Var) *ast.RangeStmt {
+	for lc := range c.Enclosing((*ast.RangeStmt)(nil), (*ast.FuncLit)(nil), (*ast.FuncDecl)(nil)) {
+		loop, ok := lc.Node().(*ast.RangeStmt)
+		if !ok {
+			return nil // the function boundary
+		}
+		if loop.Tok != token.DEFINE {
+			continue
+		}
+		for _, e := range []ast.Expr{loop.Key, loop.Value} {
+			if id, ok := e.(*ast.Ident); ok && p.TypesInfo.Defs[id] == v {
+				return loop
+			}
+		}
+	}
+	return nil
+}
+
+// pointerEscape describes where the pointer built at c leaves an iteration
+// of loop, such as "appended to ptrs", or returns "" when it does not,
+// looking through composite literals that contain it.
+func pointerEscape(p *Pass, c inspector.Cursor, loop *ast.RangeStmt) string {
+	expr := c.Node()
+	for pc := c.Parent(); ; pc = pc.Parent() {
+		switch parent := pc.Node().(type) {
+		case *ast.CompositeLit, *ast.KeyValueExpr, *ast.ParenExpr:
+			expr = parent
+			continue
+		case *ast.UnaryExpr:
+			if parent.Op == token.AND {
+				expr = parent
+				continue
+			}
+		case *ast.CallExpr:
+			if isBuiltin(p, parent.Fun, "append") && len(parent.Args) > 1 && parent.Args[0] != expr {
+				return "appended to " + types.ExprString(parent.Args[0])
+			}
+		case *ast.AssignStmt:
+			if parent.Tok != token.ASSIGN || len(parent.Lhs) != len(parent.Rhs) {
+				return ""
+			}
+			for i, rhs := range parent.Rhs {
+				if rhs == expr && outlivesIteration(p, parent.Lhs[i], loop) {
+					return "stored in " + types.ExprString(parent.Lhs[i])
+				}
+			}
+		case *ast.SendStmt:
+			if parent.Value == expr {
+				return "sent on " + types.ExprString(parent.Chan)
+			}
+		}
+		return ""
+	}
+}
+
+// outlivesIteration reports whether a value assigned to lhs outlives an
+// iteration of loop: lhs is an element, a field, a dereference or a
+// variable declared outside the loop.
+func outlivesIteration(p *Pass, lhs ast.Expr, loop *ast.RangeStmt) bool {
+	switch lhs := ast.Unparen(lhs).(type) {
+	case *ast.IndexExpr, *ast.SelectorExpr, *ast.StarExpr:
+		return true
+	case *ast.Ident:
+		v, ok := p.TypesInfo.Uses[lhs].(*types.Var)
+		return ok && (v.Pos() < loop.Pos() || v.Pos() >= loop.End())
+	}
+	return false
+}
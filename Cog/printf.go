@@ -0,0 +1,265 @@
+package cog
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// printfRule checks calls to the printf-like functions listed in
+// -printf.funcs, which go vet's printf check does not know about.
+//
+//	log.Infof("user %d logged in from %s", user.Name) // %d of a string, and %s has no argument
+//
+// Teams wrap fmt and their logger in helpers such as Infof or Failf; vet
+// checks only the functions it can prove forward to fmt, so misuse of the
+// others goes unnoticed until the log line reads %!d(string=alice). Each
+// entry of -printf.funcs names a function or method as
+// importpath.Func or (*importpath.Type).Method, optionally followed by a
+// colon and the index of its format parameter; without an index the format
+// is the last parameter before the final ...any. When the format is a
+// constant, the rule reports unknown verbs, verbs whose argument has the
+// wrong type, and calls passing more or fewer arguments than the format
+// reads. Arguments of interface type, or whose type has its own Format,
+// String or Error method, are not type-checked, and calls that pass a slice
+// with ... or use explicit argument indexes such as %[2]d are not checked
+// at all.
+var printfRule = &Rule{
+	ID:       "CogPrintf",
+	Doc:      "check format strings and arguments of custom printf-like functions",
+	Run:      runPrintf,
+	Category: CategoryTypes,
+}
+
+// printfFuncs lists the printf-like functions to check.
+var printfFuncs funcIndexFlag
+
+func init() {
+	Analyzer.Flags.Var(&printfFuncs, "printf.funcs",
+		"comma-separated printf-like functions to check, each name optionally followed by :index of its format parameter, "+
+			"e.g. (*example.com/log.Logger).Infof,example.com/errs.Failf:1")
+}
+
+func runPrintf(p *Pass) {
+	if len(printfFuncs) == 0 {
+		return
+	}
+	for c := range p.Inspector.Root().Preorder((*ast.CallExpr)(nil)) {
+		call, ok := c.Node().(*ast.CallExpr)
+		if !ok || call.Ellipsis.IsValid() {
+			continue
+		}
+		name := calleeName(p.TypesInfo, call)
+		index, listed := printfFuncs.lookup(name)
+		if !listed {
+			continue
+		}
+		if index < 0 {
+			index = formatIndex(p, call)
+		}
+		if index < 0 || index >= len(call.Args) {
+			continue
+		}
+		format := p.TypesInfo.Types[call.Args[index]].Value
+		if format == nil || format.Kind() != constant.String {
+			continue
+		}
+		checkPrintf(p, call, types.ExprString(call.Fun), constant.StringVal(format), call.Args[index+1:])
+	}
+}
+
+// formatIndex returns the index of the format parameter of the function
+// call calls: the string parameter just before the final ...any, or -1.
+func formatIndex(p *Pass, call *ast.CallExpr) int {
+	fn, ok := typeutil.Callee(p.TypesInfo, call).(*types.Func)
+	if !ok {
+		return -1
+	}
+	sig, ok := fn.Type().(*types.Signature)
+	if !ok || !sig.Variadic() || sig.Params().Len() < 2 {
+		return -1
+	}
+	i := sig.Params().Len() - 2
+	if b, ok := sig.Params().At(i).Type().Underlying().(*types.Basic); !ok || b.Info()&types.IsString == 0 {
+		return -1
+	}
+	return i
+}
+
+// A printfVerb is one conversion of a format string.
+type printfVerb struct {
+	text string // as written, such as "%-8.2f"
+	verb rune
+	args int // arguments read: the value, plus one per * width or precision
+}
+
+// checkPrintf reports the verbs of format that do not match args, and a
+// mismatch in their number, for the call of fn.
+func checkPrintf(p *Pass, call *ast.CallExpr, fn, format string, args []ast.Expr) {
+	verbs, ok := parsePrintf(format)
+	if !ok {
+		return // explicit argument indexes
+	}
+	next := 0
+	for _, v := range verbs {
+		if v.verb == '%' {
+			continue
+		}
+		if v.verb == utf8.RuneError {
+			p.Report(call, fn+" format "+strconv.Quote(v.text)+" is missing a verb")
+			return
+		}
+		if !strings.ContainsRune(printfVerbs, v.verb) {
+			p.Report(call, fn+" format "+v.text+" has unknown verb "+string(v.verb))
+			return
+		}
+		stars := v.args - 1
+		for range stars {
+			if next < len(args) && !printfArgOK(p, args[next], '*') {
+				p.Report(args[next], fn+" format "+v.text+" uses non-int "+types.ExprString(args[next])+" as width or precision")
+			}
+			next++
+		}
+		if next >= len(args) {
+			p.Report(call, fn+" format "+v.text+" reads arg #"+strconv.Itoa(next+1)+", but call has "+
+				plural(len(args), "arg"))
+			return
+		}
+		if !printfArgOK(p, args[next], v.verb) {
+			p.Report(args[next], fn+" format "+v.text+" has arg "+types.ExprString(args[next])+" of wrong type "+
+				types.TypeString(p.TypesInfo.TypeOf(args[next]), types.RelativeTo(p.Pkg)))
+		}
+		next++
+	}
+	if next < len(args) {
+		p.Report(call, fn+" call needs "+plural(next, "arg")+" but has "+plural(len(args), "arg"))
+	}
+}
+
+// plural renders n and noun, adding an s unless n is 1.
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return strconv.Itoa(n) + " " + noun + "s"
+}
+
+// printfVerbs are the verbs fmt knows, besides %%.
+const printfVerbs = "vTtbcdoOqxXUeEfFgGspw"
+
+// parsePrintf splits format into its verbs. A trailing % without a verb
+// gives a verb of utf8.RuneError. It reports false when a verb uses an
+// explicit argument index, which the rule does not follow.
+func parsePrintf(format string) ([]printfVerb, bool) {
+	verbs := make([]printfVerb, 0)
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		v, start := printfVerb{args: 1}, i
+		i++
+		for i < len(format) && strings.IndexByte("+-# 0", format[i]) >= 0 {
+			i++
+		}
+		i = printfNumber(format, i, &v)
+		if i < len(format) && format[i] == '.' {
+			i = printfNumber(format, i+1, &v)
+		}
+		if i < len(format) && format[i] == '[' {
+			return nil, false
+		}
+		if i >= len(format) {
+			verbs = append(verbs, printfVerb{text: format[start:], verb: utf8.RuneError})
+			break
+		}
+		r, size := utf8.DecodeRuneInString(format[i:])
+		v.verb, v.text = r, format[start:i+size]
+		if r == '%' {
+			v.args = 0
+		}
+		verbs = append(verbs, v)
+		i += size - 1
+	}
+	return verbs, true
+}
+
+// printfNumber skips the width or precision starting at format[i],
+// counting a * as an argument of v, and returns the index after it. An
+// explicit argument index is left for parsePrintf to find.
+func printfNumber(format string, i int, v *printfVerb) int {
+	if i < len(format) && format[i] == '*' {
+		v.args++
+		return i + 1
+	}
+	for i < len(format) && format[i] >= '0' && format[i] <= '9' {
+		i++
+	}
+	return i
+}
+
+// printfArgOK reports whether arg may be formatted with verb; '*' stands
+// for a width or precision, which must be an int.
+func printfArgOK(p *Pass, arg ast.Expr, verb rune) bool {
+	t := p.TypesInfo.TypeOf(arg)
+	if t == nil {
+		return true
+	}
+	if verb == '*' {
+		b, ok := t.Underlying().(*types.Basic)
+		return types.IsInterface(t) || ok && b.Info()&types.IsInteger != 0
+	}
+	if verb == 'v' || verb == 'T' || types.IsInterface(t) || hasMethod(t, "Format") {
+		return true
+	}
+	if strings.ContainsRune("sqxXv", verb) && (hasMethod(t, "String") || hasMethod(t, "Error")) {
+		return true
+	}
+	if verb == 'w' {
+		return hasMethod(t, "Error")
+	}
+	b, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return verb != 'p' || isPointerLike(t)
+	}
+	info := b.Info()
+	switch verb {
+	case 't':
+		return info&types.IsBoolean != 0
+	case 'c', 'd', 'o', 'O', 'U':
+		return info&types.IsInteger != 0
+	case 'b':
+		return info&(types.IsInteger|types.IsFloat|types.IsComplex) != 0
+	case 'e', 'E', 'f', 'F', 'g', 'G':
+		return info&(types.IsFloat|types.IsComplex) != 0
+	case 's':
+		return info&types.IsString != 0
+	case 'q':
+		return info&(types.IsString|types.IsInteger) != 0
+	case 'x', 'X':
+		return info&(types.IsString|types.IsInteger|types.IsFloat|types.IsComplex) != 0
+	case 'p':
+		return b.Kind() == types.UnsafePointer
+	}
+	return true
+}
+
+// hasMethod reports whether t or *t has a method called name.
+func hasMethod(t types.Type, name string) bool {
+	obj, _, _ := types.LookupFieldOrMethod(t, true, nil, name)
+	_, ok := obj.(*types.Func)
+	return ok
+}
+
+// isPointerLike reports whether %p can print a value of type t.
+func isPointerLike(t types.Type) bool {
+	switch t.Underlying().(type) {
+	case *types.Pointer, *types.Slice, *types.Map, *types.Chan, *types.Signature:
+		return true
+	}
+	return false
+}
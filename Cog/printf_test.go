@@ -0,0 +1,16 @@
+package cog
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestPrintf(t *testing.T) {
+	saved := printfFuncs
+	t.Cleanup(func() { printfFuncs = saved })
+	if err := printfFuncs.Set("(*printf.logger).Infof,printf.failf:1"); err != nil {
+		t.Fatal(err)
+	}
+	analysistest.Run(t, analysistest.TestData(), ruleAnalyzer(printfRule), "printf")
+}
@@ -0,0 +1,113 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// recoverSwallowRule reports a recover whose value is thrown away.
+//
+//	defer func() {
+//		recover() // the panic, and what caused it, vanish
+//	}()
+//
+// Recovering stops the panic, so discarding the value hides the crash
+// entirely: nothing is logged and the caller sees a normal return. The rule
+// fires when recover() is a statement of its own or is assigned to _, and
+// when its value is only compared with nil, directly or through a variable:
+// that stops the panic but still loses what it was. Any other use of the
+// value, such as logging it or wrapping it in an error, silences the rule,
+// and so does any panic call in the recovering function, which turns the
+// panic into another rather than swallowing it.
+var recoverSwallowRule = &Rule{
+	ID:       "CogRecoverSwallow",
+	Doc:      "report recover calls whose recovered value is discarded",
+	Run:      runRecoverSwallow,
+	Category: CategoryErrors,
+}
+
+func runRecoverSwallow(p *Pass) {
+	for c := range p.Inspector.Root().Preorder((*ast.CallExpr)(nil)) {
+		call, ok := c.Node().(*ast.CallExpr)
+		if !ok || !isBuiltin(p, call.Fun, "recover") || !recoveredDiscarded(p, c) || panicsAgain(p, c) {
+			continue
+		}
+		p.Report(call, "the value of recover() is discarded, so the panic is swallowed and its cause lost; "+
+			"capture it with `if r := recover(); r != nil` and log it, panic again, or return it as an error "+
+			"through a named error result")
+	}
+}
+
+// recoveredDiscarded reports whether the value of the recover call at c is
+// never used, other than in comparisons with nil.
+func recoveredDiscarded(p *Pass, c inspector.Cursor) bool {
+	switch parent := c.Parent().Node().(type) {
+	case *ast.ExprStmt:
+		return true
+	case *ast.BinaryExpr:
+		return isNilComparison(p, parent)
+	case *ast.AssignStmt:
+		if len(parent.Lhs) != 1 || len(parent.Rhs) != 1 {
+			return false
+		}
+		id, ok := parent.Lhs[0].(*ast.Ident)
+		if !ok {
+			return false
+		}
+		if id.Name == "_" {
+			return true
+		}
+		v := identVar(p, id)
+		_, body := enclosingFunc(c)
+		return v != nil && body != nil && onlyNilCompared(p, body, v, id)
+	}
+	return false
+}
+
+// panicsAgain reports whether the function around the recover call at c
+// calls panic, outside any function literal nested in it.
+func panicsAgain(p *Pass, c inspector.Cursor) bool {
+	_, body := enclosingFunc(c)
+	if body == nil {
+		return false
+	}
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.CallExpr:
+			found = found || isBuiltin(p, n.Fun, "panic")
+		}
+		return !found
+	})
+	return found
+}
+
+// onlyNilCompared reports whether every use of v in body other than def is
+// a comparison with nil.
+func onlyNilCompared(p *Pass, body *ast.BlockStmt, v *types.Var, def *ast.Ident) bool {
+	bc, ok := p.Inspector.Root().FindNode(body)
+	if !ok {
+		return false
+	}
+	for ic := range bc.Preorder((*ast.Ident)(nil)) {
+		id, ok := ic.Node().(*ast.Ident)
+		if !ok || id == def || p.TypesInfo.ObjectOf(id) != v {
+			continue
+		}
+		bin, ok := ic.Parent().Node().(*ast.BinaryExpr)
+		if !ok || !isNilComparison(p, bin) {
+			return false
+		}
+	}
+	return true
+}
+
+// isNilComparison reports whether bin is `x == nil` or `x != nil`.
+func isNilComparison(p *Pass, bin *ast.BinaryExpr) bool {
+	return (bin.Op == token.EQL || bin.Op == token.NEQ) && (isNilIdent(p, bin.X) || isNilIdent(p, bin.Y))
+}
@@ -0,0 +1,85 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// regexpMustRule reports regexp.MustCompile called with a pattern that is
+// not a constant.
+//
+//	re := regexp.MustCompile("^" + userPrefix) // panics on "(" from a user
+//
+// MustCompile panics on an invalid pattern. For a constant pattern that is
+// a bug found the first time the code runs, usually at init; for a pattern
+// built at run time it is a crash waiting for the wrong input. Patterns the
+// type checker can evaluate as constants are allowed. When the call
+// initializes a variable with := in a function returning an error, the fix
+// switches to regexp.Compile and returns the error.
+var regexpMustRule = &Rule{
+	ID:       "CogRegexpMust",
+	Doc:      "report regexp.MustCompile with a pattern that is not a constant",
+	Run:      runRegexpMust,
+	Category: CategoryErrors,
+	Fixable:  true,
+}
+
+func runRegexpMust(p *Pass) {
+	for c := range p.Inspector.Root().Preorder((*ast.CallExpr)(nil)) {
+		call, ok := c.Node().(*ast.CallExpr)
+		if !ok || len(call.Args) != 1 || p.TypesInfo.Types[call.Args[0]].Value != nil {
+			continue
+		}
+		name := calleeName(p.TypesInfo, call)
+		if name != "regexp.MustCompile" && name != "regexp.MustCompilePOSIX" {
+			continue
+		}
+		fn := strings.TrimPrefix(name, "regexp.")
+		compile := strings.TrimPrefix(fn, "Must")
+		p.Report(call, "regexp."+fn+" panics if the pattern built at run time is invalid; use regexp."+
+			compile+" and handle its error", compileFix(p, c, call, compile)...)
+	}
+}
+
+// compileFix turns `re := regexp.MustCompile(x)` into `re, err :=
+// regexp.Compile(x)` followed by an `if err != nil` block returning the
+// error, when the enclosing function returns one.
+func compileFix(p *Pass, c inspector.Cursor, call *ast.CallExpr, compile string) []analysis.SuggestedFix {
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return nil
+	}
+	stmt, ok := c.Parent().Node().(*ast.AssignStmt)
+	if !ok || stmt.Tok != token.DEFINE || len(stmt.Lhs) != 1 || !inStatementList(c.Parent()) {
+		return nil
+	}
+	// `err` must be new in this scope, or an error that := can reuse.
+	scope := p.Pkg.Scope().Innermost(stmt.Pos())
+	if scope == nil {
+		return nil
+	}
+	if obj := scope.Lookup("err"); obj != nil && !isErrorType(obj.Type()) {
+		return nil
+	}
+	file, sig := enclosingFile(c), enclosingSignature(p, c)
+	if file == nil || sig == nil {
+		return nil
+	}
+	fmtPkg, imports := importName(p, file, "fmt")
+	ret, ok := returnZeros(p, file, sig, fmtPkg+`.Errorf("compile pattern: %w", err)`)
+	if !ok {
+		return nil
+	}
+
+	indent, eol := lineIndent(p, stmt.Pos()), lineEnd(p, stmt.End())
+	edits := append(imports,
+		analysis.TextEdit{Pos: stmt.Lhs[0].End(), End: stmt.Lhs[0].End(), NewText: []byte(", err")},
+		analysis.TextEdit{Pos: sel.Sel.Pos(), End: sel.Sel.End(), NewText: []byte(compile)},
+		analysis.TextEdit{Pos: eol, End: eol, NewText: []byte("\n" + indent + "if err != nil {\n" + indent + "\treturn " + ret + "\n" + indent + "}")},
+	)
+	return []analysis.SuggestedFix{{Message: "Use regexp." + compile + " and return its error", TextEdits: edits}}
+}
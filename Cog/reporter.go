@@ -0,0 +1,40 @@
+package cog
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// A Reporter writes findings to w in one output format.
+type Reporter func(w io.Writer, findings []Finding) error
+
+// reporters are the output formats by name. Adding a format is adding an
+// entry here.
+var reporters = map[string]Reporter{
+	"text": func(w io.Writer, findings []Finding) error {
+		return WriteText(w, findings, TextOptions{})
+	},
+	"json":   WriteJSON,
+	"sarif":  WriteSARIF,
+	"github": WriteGitHub,
+}
+
+// Formats returns the names of the output formats NewReporter accepts,
+// sorted.
+func Formats() []string {
+	return slices.Sorted(maps.Keys(reporters))
+}
+
+// NewReporter returns the Reporter of the named format: "text" (WriteText
+// with default options), "json" (WriteJSON), "sarif" (WriteSARIF) or
+// "github" (WriteGitHub).
+func NewReporter(format string) (Reporter, error) {
+	r, ok := reporters[format]
+	if !ok {
+		return nil, fmt.Errorf("cog: unknown format %q (want %s)", format, strings.Join(Formats(), ", "))
+	}
+	return r, nil
+}
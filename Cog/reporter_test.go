@@ -0,0 +1,89 @@
+package cog
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestFormats(t *testing.T) {
+	if got, want := Formats(), []string{"github", "json", "sarif", "text"}; !slices.Equal(got, want) {
+		t.Errorf("Formats() = %q, want %q", got, want)
+	}
+	if _, err := NewReporter("xml"); err == nil {
+		t.Error(`NewReporter("xml") succeeded, want an error`)
+	}
+}
+
+// TestReporters smoke-tests every format: it writes the test findings and
+// checks for what identifies the format.
+func TestReporters(t *testing.T) {
+	checks := map[string]func(t *testing.T, out []byte){
+		"text": func(t *testing.T, out []byte) {
+			for _, want := range []string{"testdata/a.go (2 findings)", "error[CogIgnoredError]", "warning[CogMapRangeOrder]",
+				"info[CogBareReturn]", "--> testdata/a.go:12:2"} {
+				if !bytes.Contains(out, []byte(want)) {
+					t.Errorf("text output lacks %q:\n%s", want, out)
+				}
+			}
+		},
+		"json": func(t *testing.T, out []byte) {
+			var got []map[string]any
+			if err := json.Unmarshal(out, &got); err != nil || len(got) != 3 {
+				t.Errorf("json output is not an array of 3 findings (%v):\n%s", err, out)
+			}
+		},
+		"sarif": func(t *testing.T, out []byte) {
+			var got struct {
+				Version string `json:"version"`
+				Runs    []struct {
+					Results []any `json:"results"`
+				} `json:"runs"`
+			}
+			if err := json.Unmarshal(out, &got); err != nil || got.Version != "2.1.0" || len(got.Runs) != 1 || len(got.Runs[0].Results) != 3 {
+				t.Errorf("sarif output is not a SARIF 2.1.0 log of 3 results (%v):\n%s", err, out)
+			}
+		},
+		"github": func(t *testing.T, out []byte) {
+			const want = "::warning file=testdata/a.go,line=5,col=2,endLine=5,endColumn=20,title=CogMapRangeOrder::" +
+				"range over map m appends to ks in random order, and ks is returned unsorted on line 8\n" +
+				"::error file=testdata/a.go,line=12,col=2,endLine=12,endColumn=22,title=CogIgnoredError::" +
+				"error returned by os.Remove is discarded; handle it with `if err := ...; err != nil`\n" +
+				"::notice file=testdata/b.go,line=7,col=2,endLine=7,endColumn=8,title=CogBareReturn::" +
+				"bare return in a function with named results hides what is returned; write `return n, err`\n"
+			if string(out) != want {
+				t.Errorf("github output =\n%s\nwant\n%s", out, want)
+			}
+		},
+	}
+	for _, format := range Formats() {
+		t.Run(format, func(t *testing.T) {
+			check, ok := checks[format]
+			if !ok {
+				t.Fatalf("no smoke test for format %q", format)
+			}
+			report, err := NewReporter(format)
+			if err != nil {
+				t.Fatalf("NewReporter: %v", err)
+			}
+			var out bytes.Buffer
+			if err := report(&out, testFindings()); err != nil {
+				t.Fatalf("writing: %v", err)
+			}
+			check(t, out.Bytes())
+		})
+	}
+}
+
+func TestWriteGitHubEscapes(t *testing.T) {
+	var out bytes.Buffer
+	f := Finding{Rule: "CogTypedNil", Message: "50% of\nlines", Pos: testFindings()[0].Pos}
+	f.Pos.Filename = "dir,with:colon/a.go"
+	if err := WriteGitHub(&out, []Finding{f}); err != nil {
+		t.Fatalf("WriteGitHub: %v", err)
+	}
+	if want := "::error file=dir%2Cwith%3Acolon/a.go,line=7,col=2,title=CogTypedNil::50%25 of%0Alines\n"; out.String() != want {
+		t.Errorf("WriteGitHub = %q, want %q", out.String(), want)
+	}
+}
@@ -0,0 +1,120 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+// resourceCloseRule reports values obtained from a call that must be closed
+// — *sql.Rows, *os.File, and by default anything implementing io.Closer —
+// but are not closed on some path out of the function.
+//
+//	f, err := os.Open(path)
+//	if err != nil {
+//		return err
+//	}
+//	if bad(f) {
+//		return errBad // leak: f is never closed on this path
+//	}
+//	defer f.Close()
+//
+// `defer x.Close()`, a direct Close, passing x to a function whose name
+// mentions close, or transferring ownership — returning x or storing it —
+// satisfies the rule.
+var resourceCloseRule = &Rule{
+	ID:       "CogResourceClose",
+	Doc:      "report closable resources that are not closed on every path",
+	Run:      runResourceClose,
+	Category: CategoryPerformance,
+}
+
+var (
+	// resourceCloseTypes lists extra tracked types as "importpath.Name";
+	// values of the type or a pointer to it are tracked.
+	resourceCloseTypes = listFlag{"database/sql.Rows", "database/sql.Stmt", "os.File"}
+
+	// resourceCloseAnyCloser also tracks every io.Closer implementation.
+	resourceCloseAnyCloser = true
+)
+
+func init() {
+	Analyzer.Flags.Var(&resourceCloseTypes, "resourceclose.types",
+		"comma-separated types (importpath.Name) whose values must be closed")
+	Analyzer.Flags.BoolVar(&resourceCloseAnyCloser, "resourceclose.anycloser", true,
+		"also track every value implementing io.Closer")
+}
+
+// closerInterface is interface{ Close() error }, the method set of io.Closer.
+var closerInterface = types.NewInterfaceType([]*types.Func{
+	types.NewFunc(token.NoPos, nil, "Close", types.NewSignatureType(nil, nil, nil, nil,
+		types.NewTuple(types.NewParam(token.NoPos, nil, "", errorType)), false)),
+}, nil).Complete()
+
+func runResourceClose(p *Pass) {
+	cfgs := make(map[*ast.BlockStmt]*cfg.CFG)
+	for c := range p.Inspector.Root().Preorder((*ast.AssignStmt)(nil), (*ast.ExprStmt)(nil)) {
+		acq, ok := acquisition(p, c, isTrackedResource)
+		if !ok {
+			continue
+		}
+		what := types.ExprString(acq.call.Fun)
+		if acq.resource == nil {
+			p.Report(acq.call, "closable result of "+what+" is discarded, so it is never closed")
+			continue
+		}
+
+		_, body := enclosingFunc(c)
+		if body == nil {
+			continue
+		}
+		g, ok := cfgs[body]
+		if !ok {
+			g = funcCFG(p, body)
+			cfgs[body] = g
+		}
+		res := acq.resource
+		exit, leaked := findLeak(p, g, body, acq.stmt, leakCheck{
+			released: func(n ast.Node) bool { return releasesResource(p, n, res) },
+			unheld:   acq.unheld(p),
+		})
+		if !leaked {
+			continue
+		}
+		p.Report(acq.call, res.Name()+" ("+types.TypeString(res.Type(), types.RelativeTo(p.Pkg))+") from "+what+
+			" is not closed on the path to line "+strconv.Itoa(p.Fset.Position(exit).Line)+
+			"; add `defer "+res.Name()+".Close()` after checking the error")
+	}
+}
+
+// isTrackedResource reports whether values of type t must be closed.
+func isTrackedResource(t types.Type) bool {
+	if isHTTPResponse(t) {
+		return false // CogBodyClose tracks responses through their Body
+	}
+	base := derefType(t)
+	if named, ok := types.Unalias(base).(*types.Named); ok && named.Obj().Pkg() != nil {
+		if resourceCloseTypes.contains(named.Obj().Pkg().Path() + "." + named.Obj().Name()) {
+			return true
+		}
+	}
+	return resourceCloseAnyCloser && types.Implements(t, closerInterface)
+}
+
+// releasesResource reports whether n closes res or hands it off.
+func releasesResource(p *Pass, n ast.Node, res *types.Var) bool {
+	cur, ok := p.Inspector.Root().FindNode(n)
+	if !ok {
+		return false
+	}
+	for ic := range cur.Preorder((*ast.Ident)(nil)) {
+		id, ok := ic.Node().(*ast.Ident)
+		if ok && p.TypesInfo.Uses[id] == res && releasesValue(p, ic) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,574 @@
+package cog
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// ErrZeroResult is the error reported by a failed Result that holds no
+// error: the zero Result[T], or one built with Err(nil).
+var ErrZeroResult = errors.New("cog: result holds neither a value nor an error")
+
+// Result holds either a value or the error that prevented computing it.
+// It is the Result type from examples/after.go, shipped for use in code
+// that follows Cog: the zero Result is a failure reporting ErrZeroResult,
+// never a success, so a forgotten initialization cannot masquerade as a
+// value.
+type Result[T any] struct {
+	value T
+	err   error
+	ok    bool
+}
+
+// Ok returns a successful Result holding v.
+func Ok[T any](v T) Result[T] { return Result[T]{value: v, ok: true} }
+
+// Err returns a failed Result holding e.
+func Err[T any](e error) Result[T] { return Result[T]{err: e, ok: false} }
+
+// IsOk reports whether r holds a value.
+func (r Result[T]) IsOk() bool { return r.ok }
+
+// IsErr reports whether r holds an error.
+func (r Result[T]) IsErr() bool { return !r.ok }
+
+// Err returns the error of a failed Result, or nil on success. A failed
+// Result without a stored error reports ErrZeroResult.
+func (r Result[T]) Err() error {
+	if r.ok {
+		return nil
+	}
+	return r.failure()
+}
+
+// Unwrap returns the value and a nil error on success, or the zero value and
+// the stored error on failure.
+func (r Result[T]) Unwrap() (T, error) {
+	if !r.ok {
+		return r.value, r.failure()
+	}
+	return r.value, nil
+}
+
+// UnwrapOr returns the value on success and def on failure.
+func (r Result[T]) UnwrapOr(def T) T {
+	if !r.ok {
+		return def
+	}
+	return r.value
+}
+
+// UnwrapOrElse returns the value on success. On failure it returns f called
+// with the stored error, or with ErrZeroResult when there is none.
+func (r Result[T]) UnwrapOrElse(f func(error) T) T {
+	if !r.ok {
+		return f(r.failure())
+	}
+	return r.value
+}
+
+// Inspect calls f with the value of a successful Result, for a side effect
+// such as logging, and returns r unchanged. f is not called on failure.
+//
+//	res := cog.Map(load(path), parse).Inspect(func(c Config) { log.Printf("loaded %s", c.Name) })
+func (r Result[T]) Inspect(f func(T)) Result[T] {
+	if r.ok {
+		f(r.value)
+	}
+	return r
+}
+
+// InspectErr calls f with the error of a failed Result, or ErrZeroResult
+// when it holds none, and returns r unchanged. f is not called on success.
+func (r Result[T]) InspectErr(f func(error)) Result[T] {
+	if !r.ok {
+		f(r.failure())
+	}
+	return r
+}
+
+// Filter returns Err(err) when r succeeds with a value that pred rejects,
+// and r otherwise. pred is not called on failure, so the first error of a
+// chain of checks is kept. A nil err fails with ErrZeroResult.
+//
+//	age := cog.Try(strconv.Atoi(s)).
+//		Filter(func(n int) bool { return n >= 0 }, errNegative).
+//		Filter(func(n int) bool { return n < 150 }, errTooOld)
+func (r Result[T]) Filter(pred func(T) bool, err error) Result[T] {
+	if r.ok && !pred(r.value) {
+		return Err[T](err)
+	}
+	return r
+}
+
+// failure returns the error of a failed Result, substituting ErrZeroResult
+// so that a failure is never reported as a nil error.
+func (r Result[T]) failure() error {
+	if r.err == nil {
+		return ErrZeroResult
+	}
+	return r.err
+}
+
+// Try turns the (value, error) pair a function returns into a Result:
+//
+//	r := cog.Try(os.ReadFile(path))
+//
+// It is the bridge from ordinary Go APIs into Result pipelines.
+func Try[T any](v T, err error) Result[T] {
+	if err != nil {
+		return Err[T](err)
+	}
+	return Ok(v)
+}
+
+// Try2 is Try for functions returning two values and an error, packing the
+// values into a Tuple:
+//
+//	r := cog.Try2(net.SplitHostPort(addr)) // t.First() is the host, t.Second() the port
+func Try2[A, B any](a A, b B, err error) Result[Tuple[A, B]] {
+	if err != nil {
+		return Err[Tuple[A, B]](err)
+	}
+	return Ok(Tuple[A, B]{first: a, second: b})
+}
+
+// Try3 is Try for functions returning three values and an error. The
+// values are packed as Tuple[A, Tuple[B, C]], so the second and third are
+// t.Second().First() and t.Second().Second().
+func Try3[A, B, C any](a A, b B, c C, err error) Result[Tuple[A, Tuple[B, C]]] {
+	if err != nil {
+		return Err[Tuple[A, Tuple[B, C]]](err)
+	}
+	return Ok(Tuple[A, Tuple[B, C]]{first: a, second: Tuple[B, C]{first: b, second: c}})
+}
+
+// Must returns v, or panics with err when it is non-nil. A panic is only
+// appropriate where an error means the program itself is broken: in main,
+// in tests, or for package-level values built from constants. Library code
+// should return the error, or a Result built with Try; CogLibraryPanic
+// reports Must and panic there.
+func Must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// PanicError is the error of a Result built by Recover from a panic.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value any
+
+	// Stack is the stack of the panicking goroutine, as debug.Stack
+	// formats it.
+	Stack []byte
+}
+
+// Error describes the panic value.
+func (e *PanicError) Error() string { return fmt.Sprintf("panic: %v", e.Value) }
+
+// Unwrap returns the panic value when it is an error, so that errors.Is
+// and errors.As see through the panic, and nil otherwise.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error) // FALLBACK: nil for non-error panic values
+	return err
+}
+
+// Recover runs f and returns its value, or, if f panics, a failed Result
+// whose error is a *PanicError holding the panic value and stack. It is
+// the bridge from code that panics, such as a Must-style third-party API,
+// into Result pipelines; the panic does not propagate.
+//
+//	r := cog.Recover(func() *template.Template { return template.Must(template.New("t").Parse(src)) })
+func Recover[T any](f func() T) (res Result[T]) {
+	defer func() {
+		if v := recover(); v != nil {
+			res = Err[T](&PanicError{Value: v, Stack: debug.Stack()})
+		}
+	}()
+	return Ok(f())
+}
+
+// Map applies f to the value of a successful Result. A failed Result is
+// returned with its error untouched and f is not called.
+func Map[T, U any](r Result[T], f func(T) U) Result[U] {
+	if !r.ok {
+		return Result[U]{err: r.err, ok: false}
+	}
+	return Ok(f(r.value))
+}
+
+// FlatMap applies the fallible f to the value of a successful Result and
+// returns its Result. A failed Result short-circuits: its error is returned
+// untouched and f is not called.
+func FlatMap[T, U any](r Result[T], f func(T) Result[U]) Result[U] {
+	if !r.ok {
+		return Result[U]{err: r.err, ok: false}
+	}
+	return f(r.value)
+}
+
+// MapErr applies f to the error of a failed Result, typically to add
+// context while keeping the original in the chain:
+//
+//	r := cog.MapErr(loadUser(id), func(err error) error {
+//		return fmt.Errorf("loading user %d: %w", id, err)
+//	})
+//
+// A successful Result is returned untouched and f is not called. If f
+// returns nil, the Result stays failed and reports ErrZeroResult.
+func MapErr[T any](r Result[T], f func(error) error) Result[T] {
+	if r.ok {
+		return r
+	}
+	return Result[T]{err: f(r.failure()), ok: false}
+}
+
+// Pipe2 composes two fallible stages into one: the returned function runs f,
+// then g on its value. The first failure short-circuits the pipeline; its
+// error is returned untouched and later stages are not called.
+//
+//	parseUser := cog.Pipe2(decodeJSON, validateUser)
+//	r := parseUser(body) // validateUser only runs on decoded input
+func Pipe2[A, B, C any](f func(A) Result[B], g func(B) Result[C]) func(A) Result[C] {
+	return func(a A) Result[C] { return FlatMap(f(a), g) }
+}
+
+// Pipe3 is Pipe2 with a third stage h.
+func Pipe3[A, B, C, D any](f func(A) Result[B], g func(B) Result[C], h func(C) Result[D]) func(A) Result[D] {
+	return func(a A) Result[D] { return FlatMap(FlatMap(f(a), g), h) }
+}
+
+// Collect turns a slice of Results into a Result of their values. It
+// returns the first failure, or Ok of every value in order. The collected
+// slice is never nil, so it encodes to [] even for empty input.
+func Collect[T any](rs []Result[T]) Result[[]T] {
+	values := make([]T, 0, len(rs))
+	for _, r := range rs {
+		if !r.ok {
+			return Err[[]T](r.failure())
+		}
+		values = append(values, r.value)
+	}
+	return Ok(values)
+}
+
+// CollectAll is like Collect but reports every failure, combined with
+// errors.Join, instead of stopping at the first.
+func CollectAll[T any](rs []Result[T]) Result[[]T] {
+	values := make([]T, 0, len(rs))
+	errs := make([]error, 0)
+	for _, r := range rs {
+		if !r.ok {
+			errs = append(errs, r.failure())
+			continue
+		}
+		values = append(values, r.value)
+	}
+	if len(errs) > 0 {
+		return Err[[]T](errors.Join(errs...))
+	}
+	return Ok(values)
+}
+
+// Partition splits rs into the values of its successes and the errors of
+// its failures, each in order. Unlike Collect it does not stop at a
+// failure, so every error can be reported at once. Neither slice is nil,
+// so both encode to [] when empty.
+func Partition[T any](rs []Result[T]) (oks []T, errs []error) {
+	oks = make([]T, 0, len(rs))
+	errs = make([]error, 0)
+	for _, r := range rs {
+		if !r.ok {
+			errs = append(errs, r.failure())
+			continue
+		}
+		oks = append(oks, r.value)
+	}
+	return oks, errs
+}
+
+// Fold combines the values of rs, in order, into one: it starts from init
+// and replaces it with f(acc, v) for each value. It returns the first
+// failure, without calling f for it or any later Result, or Ok of the
+// final accumulator.
+//
+//	total := cog.Fold(sizes, int64(0), func(sum, n int64) int64 { return sum + n })
+func Fold[T, A any](rs []Result[T], init A, f func(A, T) A) Result[A] {
+	acc := init
+	for _, r := range rs {
+		if !r.ok {
+			return Err[A](r.failure())
+		}
+		acc = f(acc, r.value)
+	}
+	return Ok(acc)
+}
+
+// FoldChan is Fold over the Results received from rs until it is closed.
+// On the first failure it stops receiving and returns it, so the sender
+// must not block forever on a send nobody receives: give it a buffer, or a
+// context to cancel when FoldChan returns.
+func FoldChan[T, A any](rs <-chan Result[T], init A, f func(A, T) A) Result[A] {
+	acc := init
+	for r := range rs {
+		if !r.ok {
+			return Err[A](r.failure())
+		}
+		acc = f(acc, r.value)
+	}
+	return Ok(acc)
+}
+
+// Gather runs fns concurrently, at most GOMAXPROCS at a time, and returns Ok
+// of their values in the order of fns, or the first error one returns.
+// Each function receives a context derived from ctx that is cancelled as
+// soon as one fails, or when ctx is done, so the others can stop early;
+// Gather returns once all have. The values are collected in a preallocated
+// slice, which is never nil.
+//
+//	pages := cog.Gather(ctx, []func(context.Context) (Page, error){fetchA, fetchB})
+func Gather[T any](ctx context.Context, fns []func(ctx context.Context) (T, error)) Result[[]T] {
+	values := make([]T, len(fns))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(runtime.GOMAXPROCS(0))
+	for i, fn := range fns {
+		g.Go(func() error {
+			v, err := fn(gctx)
+			if err != nil {
+				return err //cog:ignore CogErrorWrap Gather returns the function's error as is
+			}
+			values[i] = v
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return Err[[]T](err)
+	}
+	return Ok(values)
+}
+
+// JoinErrors combines the errors accumulated by a loop into one. Nil errors
+// are dropped, and of errors with the same message only the first is kept,
+// so a failure repeated on every iteration is reported once. It returns nil
+// when no error remains. The result is built with errors.Join, so errors.Is
+// and errors.As still find each kept error.
+func JoinErrors(errs ...error) error {
+	kept := make([]error, 0, len(errs))
+	seen := make(map[string]bool, len(errs))
+	for _, err := range errs {
+		if err == nil || seen[err.Error()] {
+			continue
+		}
+		seen[err.Error()] = true
+		kept = append(kept, err)
+	}
+	return errors.Join(kept...)
+}
+
+// Tuple is a pair of values, as combined by Zip and packed by Try2 and
+// Try3.
+type Tuple[A, B any] struct {
+	first  A
+	second B
+}
+
+// First returns the first value of t.
+func (t Tuple[A, B]) First() A { return t.first }
+
+// Second returns the second value of t.
+func (t Tuple[A, B]) Second() B { return t.second }
+
+// Zip combines two independent Results into one holding both values. It
+// returns the failure of a, else that of b, else Ok of the pair:
+//
+//	pair, err := cog.Zip(loadUser(id), loadPrefs(id)).Unwrap()
+//	// on success, pair.First() is the user and pair.Second() the prefs
+func Zip[A, B any](a Result[A], b Result[B]) Result[Tuple[A, B]] {
+	if !a.ok {
+		return Err[Tuple[A, B]](a.failure())
+	}
+	if !b.ok {
+		return Err[Tuple[A, B]](b.failure())
+	}
+	return Ok(Tuple[A, B]{first: a.value, second: b.value})
+}
+
+// Option holds a value or nothing. It replaces the (value, ok) idiom where
+// the zero value is easily mistaken for a real one; the zero Option is None.
+type Option[T any] struct {
+	value T
+	ok    bool
+}
+
+// Some returns an Option holding v.
+func Some[T any](v T) Option[T] { return Option[T]{value: v, ok: true} }
+
+// None returns an empty Option.
+func None[T any]() Option[T] { return Option[T]{} }
+
+// Get returns the value and true, or the zero value and false for None.
+func (o Option[T]) Get() (T, bool) { return o.value, o.ok }
+
+// IsSome reports whether o holds a value.
+func (o Option[T]) IsSome() bool { return o.ok }
+
+// UnwrapOr returns the value, or def for None.
+func (o Option[T]) UnwrapOr(def T) T {
+	if !o.ok {
+		return def
+	}
+	return o.value
+}
+
+// ToResult returns Ok of the value, or Err(err) for None.
+func (o Option[T]) ToResult(err error) Result[T] {
+	if !o.ok {
+		return Err[T](err)
+	}
+	return Ok(o.value)
+}
+
+// MapOption applies f to the value of o. None is returned unchanged and f is
+// not called. It is the Option counterpart of Map; Go methods cannot
+// introduce the result type parameter.
+func MapOption[T, U any](o Option[T], f func(T) U) Option[U] {
+	if !o.ok {
+		return None[U]()
+	}
+	return Some(f(o.value))
+}
+
+// resultJSON is the wire form of a Result:
+//
+//	{"ok":true,"value":...}
+//	{"ok":false,"error":"..."}
+type resultJSON struct {
+	Ok    bool            `json:"ok"`
+	Value json.RawMessage `json:"value,omitempty"`
+	Error *string         `json:"error,omitempty"`
+}
+
+// MarshalJSON encodes a success as {"ok":true,"value":...} and a failure as
+// {"ok":false,"error":"..."}. Following Mistake 6, nil slices in the value
+// are encoded as [] rather than null: the value itself, and those reached
+// through exported struct fields, array and slice elements, map values,
+// pointers and interfaces. Values of types that implement json.Marshaler
+// or encoding.TextMarshaler encode themselves and are left as they are.
+// The value is not modified.
+func (r Result[T]) MarshalJSON() ([]byte, error) {
+	if !r.ok {
+		msg := r.failure().Error()
+		data, err := json.Marshal(resultJSON{Ok: false, Error: &msg})
+		if err != nil {
+			return nil, fmt.Errorf("encode result error: %w", err)
+		}
+		return data, nil
+	}
+
+	value, err := json.Marshal(emptySlices(reflect.ValueOf(&r.value).Elem(), make(map[uintptr]bool)).Interface())
+	if err != nil {
+		return nil, fmt.Errorf("encode result value: %w", err)
+	}
+	data, err := json.Marshal(resultJSON{Ok: true, Value: value})
+	if err != nil {
+		return nil, fmt.Errorf("encode result: %w", err)
+	}
+	return data, nil
+}
+
+// marshalerTypes are the interfaces of types that encode themselves.
+var marshalerTypes = []reflect.Type{reflect.TypeFor[json.Marshaler](), reflect.TypeFor[encoding.TextMarshaler]()}
+
+// emptySlices returns a copy of v in which the nil slices MarshalJSON
+// encodes as [] are empty. Pointers on the path from the value, in seen,
+// are not followed again, so that a cycle ends; json.Marshal reports it.
+func emptySlices(v reflect.Value, seen map[uintptr]bool) reflect.Value {
+	for _, m := range marshalerTypes {
+		if v.Type().Implements(m) || reflect.PointerTo(v.Type()).Implements(m) {
+			return v
+		}
+	}
+	out := reflect.New(v.Type()).Elem()
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.IsNil() {
+			return reflect.MakeSlice(v.Type(), 0, 0) // EMPTY SLICE: JSON encodes to []
+		}
+		out.Set(reflect.MakeSlice(v.Type(), v.Len(), v.Len()))
+		for i := range v.Len() {
+			out.Index(i).Set(emptySlices(v.Index(i), seen))
+		}
+	case reflect.Array:
+		for i := range v.Len() {
+			out.Index(i).Set(emptySlices(v.Index(i), seen))
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out.Set(reflect.MakeMapWithSize(v.Type(), v.Len()))
+		for iter := v.MapRange(); iter.Next(); {
+			out.SetMapIndex(iter.Key(), emptySlices(iter.Value(), seen))
+		}
+	case reflect.Pointer:
+		if v.IsNil() || seen[v.Pointer()] {
+			return v
+		}
+		seen[v.Pointer()] = true
+		defer delete(seen, v.Pointer())
+		out.Set(reflect.New(v.Type().Elem()))
+		out.Elem().Set(emptySlices(v.Elem(), seen))
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out.Set(emptySlices(v.Elem(), seen))
+	case reflect.Struct:
+		out.Set(v) // unexported fields, which json skips, are copied as they are
+		for i := range v.NumField() {
+			if out.Field(i).CanSet() {
+				out.Field(i).Set(emptySlices(v.Field(i), seen))
+			}
+		}
+	default:
+		return v
+	}
+	return out
+}
+
+// UnmarshalJSON decodes the form written by MarshalJSON. The original error
+// type cannot survive the round trip, so a failure is rebuilt with
+// errors.New from its message.
+func (r *Result[T]) UnmarshalJSON(data []byte) error {
+	var wire resultJSON
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return fmt.Errorf("decode result: %w", err)
+	}
+	if !wire.Ok {
+		if wire.Error == nil {
+			*r = Err[T](ErrZeroResult)
+			return nil
+		}
+		*r = Err[T](errors.New(*wire.Error))
+		return nil
+	}
+	if len(wire.Value) == 0 {
+		return errors.New("decode result: ok result has no value")
+	}
+	var value T
+	if err := json.Unmarshal(wire.Value, &value); err != nil {
+		return fmt.Errorf("decode result value: %w", err)
+	}
+	*r = Ok(value)
+	return nil
+}
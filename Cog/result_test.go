@@ -0,0 +1,746 @@
+package cog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type jsonItem struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+type jsonOrder struct {
+	ID       int                 `json:"id"`
+	Items    []jsonItem          `json:"items"`
+	Notes    []string            `json:"notes"`
+	Parent   *jsonItem           `json:"parent"`
+	ByName   map[string][]string `json:"byName"`
+	Extra    any                 `json:"extra"`
+	Fixed    [1]jsonItem         `json:"fixed"`
+	Raw      json.RawMessage     `json:"raw"`
+	Deadline time.Time           `json:"deadline"`
+	internal []string
+}
+
+func TestResultMarshalJSON(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		r    json.Marshaler
+		want string
+	}{
+		{"ok", Ok(42), `{"ok":true,"value":42}`},
+		{"nil slice", Ok([]int(nil)), `{"ok":true,"value":[]}`},
+		{"empty slice", Ok([]int{}), `{"ok":true,"value":[]}`},
+		{"nil map", Ok(map[string]int(nil)), `{"ok":true,"value":null}`},
+		{"nil pointer", Ok((*jsonItem)(nil)), `{"ok":true,"value":null}`},
+		{"nested nil slice", Ok(jsonItem{Name: "a"}), `{"ok":true,"value":{"name":"a","tags":[]}}`},
+		{"nil slices at depth", Ok(jsonOrder{
+			ID:     1,
+			Items:  []jsonItem{{Name: "a"}},
+			Parent: &jsonItem{Name: "p"},
+			ByName: map[string][]string{"a": nil},
+			Extra:  jsonItem{Name: "x"},
+		}), `{"ok":true,"value":{"id":1,"items":[{"name":"a","tags":[]}],"notes":[],"parent":{"name":"p","tags":[]},` +
+			`"byName":{"a":[]},"extra":{"name":"x","tags":[]},"fixed":[{"name":"","tags":[]}],"raw":null,` +
+			`"deadline":"0001-01-01T00:00:00Z"}}`},
+		{"error", Err[int](errors.New("boom")), `{"ok":false,"error":"boom"}`},
+		{"zero", Result[int]{}, `{"ok":false,"error":"cog: result holds neither a value nor an error"}`},
+	} {
+		got, err := json.Marshal(tt.r)
+		if err != nil {
+			t.Errorf("%s: Marshal: %v", tt.name, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s: Marshal = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestResultMarshalJSONLeavesValue(t *testing.T) {
+	parent := &jsonItem{Name: "p"}
+	order := jsonOrder{Parent: parent, ByName: map[string][]string{"a": nil}, internal: []string{"kept"}}
+	if _, err := json.Marshal(Ok(order)); err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if order.Notes != nil || parent.Tags != nil || order.ByName["a"] != nil {
+		t.Errorf("Marshal modified the value: %+v, parent %+v", order, *parent)
+	}
+}
+
+func TestResultMarshalJSONCycle(t *testing.T) {
+	type node struct {
+		Next *node
+	}
+	n := &node{}
+	n.Next = n
+	if _, err := json.Marshal(Ok(n)); err == nil {
+		t.Error("Marshal of a cyclic value succeeded, want an error")
+	}
+}
+
+func TestResultJSONRoundTrip(t *testing.T) {
+	in := Ok(jsonOrder{
+		ID:     7,
+		Items:  []jsonItem{{Name: "a", Tags: []string{"x"}}, {Name: "b"}},
+		Parent: &jsonItem{Name: "p"},
+		ByName: map[string][]string{"a": {"x"}, "b": nil},
+		Raw:    json.RawMessage(`{"k":1}`),
+	})
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var out Result[jsonOrder]
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("Unmarshal(%s): %v", data, err)
+	}
+	got, err := out.Unwrap()
+	if err != nil {
+		t.Fatalf("round trip of %s failed: %v", data, err)
+	}
+	want := jsonOrder{
+		ID:     7,
+		Items:  []jsonItem{{Name: "a", Tags: []string{"x"}}, {Name: "b", Tags: []string{}}},
+		Notes:  []string{},
+		Parent: &jsonItem{Name: "p", Tags: []string{}},
+		ByName: map[string][]string{"a": {"x"}, "b": {}},
+		Fixed:  [1]jsonItem{{Tags: []string{}}},
+		Raw:    json.RawMessage(`{"k":1}`),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip of %s = %+v, want %+v", data, got, want)
+	}
+}
+
+func TestResultJSONRoundTripEmptySlice(t *testing.T) {
+	for _, in := range []Result[[]int]{Ok([]int(nil)), Ok([]int{})} {
+		data, err := json.Marshal(in)
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+		var out Result[[]int]
+		if err := json.Unmarshal(data, &out); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", data, err)
+		}
+		if got, err := out.Unwrap(); err != nil || got == nil || len(got) != 0 {
+			t.Errorf("round trip of %s = %#v, %v, want an empty slice", data, got, err)
+		}
+	}
+}
+
+func TestResultJSONRoundTripError(t *testing.T) {
+	data, err := json.Marshal(Err[jsonItem](errors.New("not found")))
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var out Result[jsonItem]
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("Unmarshal(%s): %v", data, err)
+	}
+	if err := out.Err(); err == nil || err.Error() != "not found" {
+		t.Errorf("round trip of %s has error %v, want not found", data, err)
+	}
+}
+
+func TestResultUnmarshalJSONInvalid(t *testing.T) {
+	for _, data := range []string{`{"ok":true}`, `{"ok":true,"value":"x"}`, `[]`} {
+		var r Result[int]
+		if err := json.Unmarshal([]byte(data), &r); err == nil {
+			t.Errorf("Unmarshal(%s) succeeded, want an error", data)
+		}
+	}
+}
+
+var errBoom = errors.New("boom")
+
+func TestMap(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		r       Result[int]
+		want    string
+		wantErr error
+		called  bool
+	}{
+		{"ok", Ok(2), "4", nil, true},
+		{"err", Err[int](errBoom), "", errBoom, false},
+		{"zero", Result[int]{}, "", ErrZeroResult, false},
+	} {
+		called := false
+		got, err := Map(tt.r, func(n int) string {
+			called = true
+			return strconv.Itoa(n * 2)
+		}).Unwrap()
+		if got != tt.want || err != tt.wantErr || called != tt.called {
+			t.Errorf("%s: Map = %q, %v (f called %v), want %q, %v (f called %v)",
+				tt.name, got, err, called, tt.want, tt.wantErr, tt.called)
+		}
+	}
+}
+
+func TestFlatMap(t *testing.T) {
+	errOdd := errors.New("odd")
+	half := func(n int) Result[int] {
+		if n%2 != 0 {
+			return Err[int](errOdd)
+		}
+		return Ok(n / 2)
+	}
+	for _, tt := range []struct {
+		name    string
+		r       Result[int]
+		want    int
+		wantErr error
+		called  bool
+	}{
+		{"ok to ok", Ok(4), 2, nil, true},
+		{"ok to err", Ok(3), 0, errOdd, true},
+		{"err", Err[int](errBoom), 0, errBoom, false},
+		{"zero", Result[int]{}, 0, ErrZeroResult, false},
+	} {
+		called := false
+		r := FlatMap(tt.r, func(n int) Result[int] {
+			called = true
+			return half(n)
+		})
+		got, err := r.Unwrap()
+		if got != tt.want || err != tt.wantErr || called != tt.called || r.IsOk() != (tt.wantErr == nil) {
+			t.Errorf("%s: FlatMap = %d, %v (f called %v), want %d, %v (f called %v)",
+				tt.name, got, err, called, tt.want, tt.wantErr, tt.called)
+		}
+	}
+}
+
+func TestUnwrapOr(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		r    Result[int]
+		want int
+	}{
+		{"ok", Ok(1), 1},
+		{"ok zero value", Ok(0), 0},
+		{"err", Err[int](errBoom), -1},
+		{"zero Result", Result[int]{}, -1},
+		{"Err(nil)", Err[int](nil), -1},
+	} {
+		if got := tt.r.UnwrapOr(-1); got != tt.want {
+			t.Errorf("%s: UnwrapOr(-1) = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUnwrapOrElse(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		r       Result[int]
+		want    int
+		wantErr error // the error f receives; nil when f must not be called
+	}{
+		{"ok", Ok(1), 1, nil},
+		{"err", Err[int](errBoom), -1, errBoom},
+		{"zero Result", Result[int]{}, -1, ErrZeroResult},
+		{"Err(nil)", Err[int](nil), -1, ErrZeroResult},
+	} {
+		var gotErr error
+		got := tt.r.UnwrapOrElse(func(err error) int {
+			gotErr = err
+			return -1
+		})
+		if got != tt.want || gotErr != tt.wantErr {
+			t.Errorf("%s: UnwrapOrElse = %d, f got %v, want %d, f got %v", tt.name, got, gotErr, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestCollect(t *testing.T) {
+	errFirst, errSecond := errors.New("first"), errors.New("second")
+	for _, tt := range []struct {
+		name    string
+		rs      []Result[int]
+		want    []int
+		wantErr error
+	}{
+		{"nil input", nil, []int{}, nil},
+		{"empty input", []Result[int]{}, []int{}, nil},
+		{"all ok", []Result[int]{Ok(1), Ok(2), Ok(3)}, []int{1, 2, 3}, nil},
+		{"mixed", []Result[int]{Ok(1), Err[int](errFirst), Ok(3), Err[int](errSecond)}, nil, errFirst},
+		{"zero Result", []Result[int]{Ok(1), {}}, nil, ErrZeroResult},
+	} {
+		got, err := Collect(tt.rs).Unwrap()
+		if err != tt.wantErr || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Collect = %#v, %v, want %#v, %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestCollectEncodesEmpty(t *testing.T) {
+	data, err := json.Marshal(Collect[int](nil))
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if want := `{"ok":true,"value":[]}`; string(data) != want {
+		t.Errorf("Collect(nil) encodes as %s, want %s", data, want)
+	}
+}
+
+func TestCollectAll(t *testing.T) {
+	errFirst, errSecond := errors.New("first"), errors.New("second")
+
+	got, err := CollectAll([]Result[int]{}).Unwrap()
+	if err != nil || got == nil || len(got) != 0 {
+		t.Errorf("CollectAll of no Results = %#v, %v, want an empty slice", got, err)
+	}
+	got, err = CollectAll([]Result[int]{Ok(1), Ok(2)}).Unwrap()
+	if err != nil || !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Errorf("CollectAll of successes = %v, %v, want [1 2]", got, err)
+	}
+
+	err = CollectAll([]Result[int]{Ok(1), Err[int](errFirst), Ok(3), Err[int](errSecond)}).Err()
+	if !errors.Is(err, errFirst) || !errors.Is(err, errSecond) {
+		t.Errorf("CollectAll of mixed Results failed with %v, want both errors", err)
+	}
+	if want := "first\nsecond"; err == nil || err.Error() != want {
+		t.Errorf("CollectAll of mixed Results failed with %q, want %q", err, want)
+	}
+}
+
+func TestOptionSome(t *testing.T) {
+	o := Some(0)
+	if v, ok := o.Get(); v != 0 || !ok {
+		t.Errorf("Some(0).Get() = %d, %v, want 0, true", v, ok)
+	}
+	if !o.IsSome() {
+		t.Error("Some(0).IsSome() = false, want true")
+	}
+	if got := o.UnwrapOr(7); got != 0 {
+		t.Errorf("Some(0).UnwrapOr(7) = %d, want 0", got)
+	}
+	if got, err := o.ToResult(errBoom).Unwrap(); got != 0 || err != nil {
+		t.Errorf("Some(0).ToResult = %d, %v, want 0, nil", got, err)
+	}
+	if got, ok := MapOption(o, strconv.Itoa).Get(); got != "0" || !ok {
+		t.Errorf("MapOption(Some(0), strconv.Itoa) = %q, %v, want \"0\", true", got, ok)
+	}
+}
+
+func TestOptionNone(t *testing.T) {
+	for name, o := range map[string]Option[*int]{"None": None[*int](), "zero Option": {}} {
+		if v, ok := o.Get(); v != nil || ok {
+			t.Errorf("%s.Get() = %v, %v, want nil, false", name, v, ok)
+		}
+		if o.IsSome() {
+			t.Errorf("%s.IsSome() = true, want false", name)
+		}
+		def := new(int)
+		if got := o.UnwrapOr(def); got != def {
+			t.Errorf("%s.UnwrapOr(def) = %v, want def", name, got)
+		}
+		if err := o.ToResult(errBoom).Err(); err != errBoom {
+			t.Errorf("%s.ToResult(errBoom) failed with %v, want errBoom", name, err)
+		}
+		if err := o.ToResult(nil).Err(); err != ErrZeroResult {
+			t.Errorf("%s.ToResult(nil) failed with %v, want ErrZeroResult", name, err)
+		}
+		called := false
+		mapped := MapOption(o, func(p *int) int {
+			called = true
+			return *p // would panic on the nil value of None
+		})
+		if mapped.IsSome() || called {
+			t.Errorf("MapOption(%s) = %v (f called %v), want None without calling f", name, mapped, called)
+		}
+	}
+}
+
+// stage returns a pipeline stage that records its call in calls and adds
+// n to its input, or fails with err when err is not nil.
+func stage(calls *[]string, name string, n int, err error) func(int) Result[int] {
+	return func(v int) Result[int] {
+		*calls = append(*calls, name)
+		if err != nil {
+			return Err[int](err)
+		}
+		return Ok(v + n)
+	}
+}
+
+func TestPipe2(t *testing.T) {
+	var calls []string
+	got, err := Pipe2(stage(&calls, "f", 1, nil), stage(&calls, "g", 10, nil))(0).Unwrap()
+	if got != 11 || err != nil || !reflect.DeepEqual(calls, []string{"f", "g"}) {
+		t.Errorf("Pipe2 of successes = %d, %v, calling %v, want 11, nil, calling [f g]", got, err, calls)
+	}
+
+	calls = nil
+	err = Pipe2(stage(&calls, "f", 1, errBoom), stage(&calls, "g", 10, nil))(0).Err()
+	if err != errBoom || !reflect.DeepEqual(calls, []string{"f"}) {
+		t.Errorf("Pipe2 failing in f failed with %v, calling %v, want errBoom, calling [f]", err, calls)
+	}
+}
+
+func TestPipe3(t *testing.T) {
+	errG := errors.New("g failed")
+	for _, tt := range []struct {
+		name      string
+		errs      [3]error
+		want      int
+		wantErr   error
+		wantCalls []string
+	}{
+		{"all succeed", [3]error{}, 111, nil, []string{"f", "g", "h"}},
+		{"f fails", [3]error{errBoom, nil, nil}, 0, errBoom, []string{"f"}},
+		{"g fails", [3]error{nil, errG, nil}, 0, errG, []string{"f", "g"}},
+		{"h fails", [3]error{nil, nil, errBoom}, 0, errBoom, []string{"f", "g", "h"}},
+	} {
+		var calls []string
+		pipeline := Pipe3(stage(&calls, "f", 1, tt.errs[0]), stage(&calls, "g", 10, tt.errs[1]), stage(&calls, "h", 100, tt.errs[2]))
+		got, err := pipeline(0).Unwrap()
+		if got != tt.want || err != tt.wantErr || !reflect.DeepEqual(calls, tt.wantCalls) {
+			t.Errorf("%s: Pipe3 = %d, %v, calling %v, want %d, %v, calling %v",
+				tt.name, got, err, calls, tt.want, tt.wantErr, tt.wantCalls)
+		}
+	}
+}
+
+func TestZip(t *testing.T) {
+	errA, errB := errors.New("a failed"), errors.New("b failed")
+	for _, tt := range []struct {
+		name    string
+		a       Result[int]
+		b       Result[string]
+		wantErr error
+	}{
+		{"both ok", Ok(1), Ok("one"), nil},
+		{"a fails", Err[int](errA), Ok("one"), errA},
+		{"b fails", Ok(1), Err[string](errB), errB},
+		{"both fail", Err[int](errA), Err[string](errB), errA},
+	} {
+		got, err := Zip(tt.a, tt.b).Unwrap()
+		if err != tt.wantErr {
+			t.Errorf("%s: Zip failed with %v, want %v", tt.name, err, tt.wantErr)
+		}
+		if err == nil && (got.First() != 1 || got.Second() != "one") {
+			t.Errorf("%s: Zip = (%d, %q), want (1, \"one\")", tt.name, got.First(), got.Second())
+		}
+	}
+}
+
+func TestJoinErrors(t *testing.T) {
+	if err := JoinErrors(); err != nil {
+		t.Errorf("JoinErrors() = %v, want nil", err)
+	}
+	if err := JoinErrors(nil, nil); err != nil {
+		t.Errorf("JoinErrors(nil, nil) = %v, want nil", err)
+	}
+
+	errA, errB := errors.New("a"), errors.New("b")
+	errA2 := errors.New("a") // a distinct error with the same message
+	err := JoinErrors(nil, errA, errB, errA, errA2, nil)
+	if want := "a\nb"; err == nil || err.Error() != want {
+		t.Fatalf("JoinErrors with duplicates = %q, want %q", err, want)
+	}
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Errorf("errors.Is does not find the kept errors in %v", err)
+	}
+
+	wrapped := fmt.Errorf("reading: %w", &PanicError{Value: "x"})
+	var pe *PanicError
+	if !errors.As(JoinErrors(errA, wrapped), &pe) || pe.Value != "x" {
+		t.Errorf("errors.As does not find the wrapped *PanicError")
+	}
+}
+
+func TestFold(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		rs        []Result[int]
+		want      int
+		wantErr   error
+		wantCalls int
+	}{
+		{"empty", nil, 100, nil, 0},
+		{"all ok", []Result[int]{Ok(1), Ok(2), Ok(3)}, 106, nil, 3},
+		{"error", []Result[int]{Ok(1), Err[int](errBoom), Ok(3)}, 0, errBoom, 1},
+		{"zero Result first", []Result[int]{{}, Ok(1)}, 0, ErrZeroResult, 0},
+	} {
+		calls := 0
+		got, err := Fold(tt.rs, 100, func(acc, v int) int {
+			calls++
+			return acc + v
+		}).Unwrap()
+		if got != tt.want || err != tt.wantErr || calls != tt.wantCalls {
+			t.Errorf("%s: Fold = %d, %v, calling f %d times, want %d, %v, %d times",
+				tt.name, got, err, calls, tt.want, tt.wantErr, tt.wantCalls)
+		}
+	}
+}
+
+func TestFoldChan(t *testing.T) {
+	rs := make(chan Result[int], 4)
+	rs <- Ok(1)
+	rs <- Ok(2)
+	close(rs)
+	if got, err := FoldChan(rs, 0, func(acc, v int) int { return acc + v }).Unwrap(); got != 3 || err != nil {
+		t.Errorf("FoldChan of successes = %d, %v, want 3, nil", got, err)
+	}
+
+	rs = make(chan Result[int], 4)
+	rs <- Ok(1)
+	rs <- Err[int](errBoom)
+	rs <- Ok(3)
+	close(rs)
+	calls := 0
+	err := FoldChan(rs, 0, func(acc, v int) int {
+		calls++
+		return acc + v
+	}).Err()
+	if err != errBoom || calls != 1 {
+		t.Errorf("FoldChan failed with %v, calling f %d times, want errBoom, once", err, calls)
+	}
+	if r, ok := <-rs; !ok || r.UnwrapOr(0) != 3 {
+		t.Error("FoldChan received past the first failure")
+	}
+}
+
+func TestMapErr(t *testing.T) {
+	wrap := func(err error) error { return fmt.Errorf("loading user 7: %w", err) }
+
+	r := MapErr(Err[int](errBoom), wrap)
+	err := r.Err()
+	if !errors.Is(err, errBoom) || err.Error() != "loading user 7: boom" {
+		t.Errorf("MapErr failed with %v, want the wrapped errBoom", err)
+	}
+
+	called := false
+	got, err := MapErr(Ok(1), func(err error) error {
+		called = true
+		return err
+	}).Unwrap()
+	if got != 1 || err != nil || called {
+		t.Errorf("MapErr(Ok(1)) = %d, %v (f called %v), want 1, nil without calling f", got, err, called)
+	}
+
+	if err := MapErr(Result[int]{}, wrap).Err(); !errors.Is(err, ErrZeroResult) {
+		t.Errorf("MapErr of the zero Result failed with %v, want the wrapped ErrZeroResult", err)
+	}
+	if r := MapErr(Err[int](errBoom), func(error) error { return nil }); r.IsOk() || r.Err() != ErrZeroResult {
+		t.Errorf("MapErr to nil = %v, want a failure reporting ErrZeroResult", r.Err())
+	}
+}
+
+func TestRecover(t *testing.T) {
+	if got, err := Recover(func() int { return 1 }).Unwrap(); got != 1 || err != nil {
+		t.Errorf("Recover of a normal return = %d, %v, want 1, nil", got, err)
+	}
+
+	for _, tt := range []struct {
+		name    string
+		value   any
+		wantMsg string
+		wantIs  error
+	}{
+		{"string panic", "bad input", "panic: bad input", nil},
+		{"error panic", errBoom, "panic: boom", errBoom},
+	} {
+		err := Recover(func() int { panic(tt.value) }).Err()
+		var pe *PanicError
+		if !errors.As(err, &pe) {
+			t.Errorf("%s: Recover failed with %v, want a *PanicError", tt.name, err)
+			continue
+		}
+		if pe.Value != tt.value || err.Error() != tt.wantMsg {
+			t.Errorf("%s: Recover failed with value %v, message %q, want %v, %q", tt.name, pe.Value, err, tt.value, tt.wantMsg)
+		}
+		if !bytes.Contains(pe.Stack, []byte("TestRecover")) {
+			t.Errorf("%s: the stack does not show the panicking function:\n%s", tt.name, pe.Stack)
+		}
+		if tt.wantIs != nil && !errors.Is(err, tt.wantIs) {
+			t.Errorf("%s: errors.Is does not find the panic value in %v", tt.name, err)
+		}
+		if tt.wantIs == nil && errors.Unwrap(err) != nil {
+			t.Errorf("%s: Unwrap = %v, want nil for a non-error panic value", tt.name, errors.Unwrap(err))
+		}
+	}
+}
+
+func TestPartition(t *testing.T) {
+	errA, errB := errors.New("a"), errors.New("b")
+	for _, tt := range []struct {
+		name     string
+		rs       []Result[int]
+		wantOks  []int
+		wantErrs []error
+	}{
+		{"nil input", nil, []int{}, []error{}},
+		{"all ok", []Result[int]{Ok(1), Ok(2)}, []int{1, 2}, []error{}},
+		{"all err", []Result[int]{Err[int](errA), {}}, []int{}, []error{errA, ErrZeroResult}},
+		{"mixed", []Result[int]{Ok(1), Err[int](errA), Ok(2), Err[int](errB)}, []int{1, 2}, []error{errA, errB}},
+	} {
+		oks, errs := Partition(tt.rs)
+		if oks == nil || errs == nil {
+			t.Errorf("%s: Partition = %#v, %#v, want non-nil slices", tt.name, oks, errs)
+		}
+		if !reflect.DeepEqual(oks, tt.wantOks) || !reflect.DeepEqual(errs, tt.wantErrs) {
+			t.Errorf("%s: Partition = %v, %v, want %v, %v", tt.name, oks, errs, tt.wantOks, tt.wantErrs)
+		}
+	}
+
+	oks, errs := Partition[int](nil)
+	data, err := json.Marshal(map[string]any{"errs": errs, "oks": oks})
+	if err != nil || string(data) != `{"errs":[],"oks":[]}` {
+		t.Errorf("Partition(nil) encodes as %s, %v, want empty arrays", data, err)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		r         Result[int]
+		wantValue []int
+		wantErrs  []error
+	}{
+		{"ok", Ok(1), []int{1}, nil},
+		{"err", Err[int](errBoom), nil, []error{errBoom}},
+		{"zero", Result[int]{}, nil, []error{ErrZeroResult}},
+	} {
+		var values []int
+		var errs []error
+		got := tt.r.Inspect(func(v int) {
+			values = append(values, v)
+		}).InspectErr(func(err error) {
+			errs = append(errs, err)
+		})
+		if got != tt.r {
+			t.Errorf("%s: Inspect and InspectErr returned %+v, want the receiver %+v", tt.name, got, tt.r)
+		}
+		if !slices.Equal(values, tt.wantValue) || !slices.Equal(errs, tt.wantErrs) {
+			t.Errorf("%s: Inspect got %v, InspectErr got %v, want %v, %v", tt.name, values, errs, tt.wantValue, tt.wantErrs)
+		}
+	}
+}
+
+func TestTry(t *testing.T) {
+	if got, err := Try(strconv.Atoi("12")).Unwrap(); got != 12 || err != nil {
+		t.Errorf("Try(strconv.Atoi(\"12\")) = %d, %v, want 12, nil", got, err)
+	}
+	if err := Try(strconv.Atoi("x")).Err(); !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("Try(strconv.Atoi(\"x\")) failed with %v, want strconv.ErrSyntax", err)
+	}
+}
+
+func TestTry2(t *testing.T) {
+	pair, err := Try2(net.SplitHostPort("example.com:80")).Unwrap()
+	if pair.First() != "example.com" || pair.Second() != "80" || err != nil {
+		t.Errorf("Try2 of a success = (%q, %q), %v, want (example.com, 80), nil", pair.First(), pair.Second(), err)
+	}
+	if err := Try2("host", 80, errBoom).Err(); err != errBoom {
+		t.Errorf("Try2 of a failure failed with %v, want errBoom", err)
+	}
+}
+
+func TestTry3(t *testing.T) {
+	three := func(fail bool) (string, int, bool, error) {
+		if fail {
+			return "partial", 1, true, errBoom
+		}
+		return "a", 2, true, nil
+	}
+	tuple, err := Try3(three(false)).Unwrap()
+	if tuple.First() != "a" || tuple.Second().First() != 2 || !tuple.Second().Second() || err != nil {
+		t.Errorf("Try3 of a success = (%q, %d, %v), %v, want (a, 2, true), nil",
+			tuple.First(), tuple.Second().First(), tuple.Second().Second(), err)
+	}
+	r := Try3(three(true))
+	if r.Err() != errBoom || r.IsOk() {
+		t.Errorf("Try3 of a failure failed with %v, want errBoom", r.Err())
+	}
+}
+
+func TestGather(t *testing.T) {
+	fns := make([]func(context.Context) (int, error), 0, 8)
+	for i := range 8 {
+		fns = append(fns, func(context.Context) (int, error) { return i * i, nil })
+	}
+	got, err := Gather(t.Context(), fns).Unwrap()
+	if want := []int{0, 1, 4, 9, 16, 25, 36, 49}; err != nil || !slices.Equal(got, want) {
+		t.Errorf("Gather = %v, %v, want %v in order", got, err, want)
+	}
+
+	got, err = Gather[int](t.Context(), nil).Unwrap()
+	if err != nil || got == nil || len(got) != 0 {
+		t.Errorf("Gather of no functions = %#v, %v, want an empty slice", got, err)
+	}
+}
+
+// TestGatherCancelsOnError checks that a failure cancels the context of
+// the other functions, and that Gather returns the first error once they
+// have stopped. The failing function comes first, so that it runs even
+// when GOMAXPROCS, the limit, is 1.
+func TestGatherCancelsOnError(t *testing.T) {
+	var stopped atomic.Int32
+	wait := func(ctx context.Context) (int, error) {
+		<-ctx.Done() // blocks forever unless the failure cancels ctx
+		stopped.Add(1)
+		return 0, ctx.Err()
+	}
+	fail := func(context.Context) (int, error) { return 0, errBoom }
+	err := Gather(t.Context(), []func(context.Context) (int, error){fail, wait, wait}).Err()
+	if err != errBoom {
+		t.Errorf("Gather failed with %v, want errBoom", err)
+	}
+	if n := stopped.Load(); n != 2 {
+		t.Errorf("Gather returned with %d of the 2 waiting functions stopped", n)
+	}
+}
+
+func TestGatherParentCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	err := Gather(ctx, []func(context.Context) (int, error){func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}}).Err()
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Gather under a cancelled context failed with %v, want context.Canceled", err)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	errNegative := errors.New("negative")
+	for _, tt := range []struct {
+		name       string
+		r          Result[int]
+		want       int
+		wantErr    error
+		wantCalled bool
+	}{
+		{"pass", Ok(1), 1, nil, true},
+		{"fail to err", Ok(-1), 0, errNegative, true},
+		{"error passthrough", Err[int](errBoom), 0, errBoom, false},
+		{"zero passthrough", Result[int]{}, 0, ErrZeroResult, false},
+	} {
+		called := false
+		got, err := tt.r.Filter(func(n int) bool {
+			called = true
+			return n >= 0
+		}, errNegative).Unwrap()
+		if got != tt.want || err != tt.wantErr || called != tt.wantCalled {
+			t.Errorf("%s: Filter = %d, %v (pred called %v), want %d, %v (pred called %v)",
+				tt.name, got, err, called, tt.want, tt.wantErr, tt.wantCalled)
+		}
+	}
+	if err := Ok(-1).Filter(func(n int) bool { return n >= 0 }, nil).Err(); err != ErrZeroResult {
+		t.Errorf("Filter rejecting with a nil error failed with %v, want ErrZeroResult", err)
+	}
+}
@@ -0,0 +1,169 @@
+package cog
+
+import (
+	"go/ast"
+	"go/types"
+	"strconv"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// rowsErrRule reports a `for rows.Next()` loop over database rows that is
+// not followed by a check of rows.Err().
+//
+//	for rows.Next() {
+//		if err := rows.Scan(&u.ID, &u.Name); err != nil {
+//			return nil, err
+//		}
+//		users = append(users, u)
+//	}
+//	return users, nil // a dropped connection looks like a short result
+//
+// Next returns false both at the end of the rows and when fetching the next
+// one fails, and only Err tells the two apart. Rows are matched by
+// variable identity; the check may come anywhere after the loop, or in a
+// deferred call, and its result must be used. Rows handed to other code after
+// the loop are not reported. -rowserr.types lists the rows types, values of
+// which or pointers to which are tracked; the default covers database/sql
+// and pgx. The fix inserts the standard check after the loop.
+var rowsErrRule = &Rule{
+	ID:       "CogRowsErr",
+	Doc:      "report rows.Next loops not followed by a rows.Err check",
+	Run:      runRowsErr,
+	Category: CategoryErrors,
+	Fixable:  true,
+}
+
+// rowsErrTypes lists the rows types, as "importpath.Name".
+var rowsErrTypes = listFlag{
+	"database/sql.Rows",
+	"github.com/jackc/pgx/v4.Rows",
+	"github.com/jackc/pgx/v5.Rows",
+}
+
+func init() {
+	Analyzer.Flags.Var(&rowsErrTypes, "rowserr.types",
+		"comma-separated rows types (importpath.Name) whose Next loops must be followed by an Err check")
+}
+
+func runRowsErr(p *Pass) {
+	for c := range p.Inspector.Root().Preorder((*ast.ForStmt)(nil)) {
+		loop, ok := c.Node().(*ast.ForStmt)
+		if !ok || loop.Init != nil || loop.Post != nil {
+			continue
+		}
+		rows := rowsNext(p, loop.Cond)
+		_, body := enclosingFunc(c)
+		if rows == nil || body == nil || rowsErrChecked(p, body, rows, loop) {
+			continue
+		}
+		name := rows.Name()
+		p.Report(loop, "the "+name+".Next() loop ending on line "+strconv.Itoa(p.Fset.Position(loop.End()).Line)+
+			" is not followed by a "+name+".Err() check; Next also returns false when fetching a row fails, "+
+			"so add `if err := "+name+".Err(); err != nil { ... }` after the loop", rowsErrFix(p, c, loop, name)...)
+	}
+}
+
+// rowsNext returns the rows variable when cond is `rows.Next()` on a
+// tracked rows type, or nil.
+func rowsNext(p *Pass, cond ast.Expr) *types.Var {
+	call, ok := ast.Unparen(cond).(*ast.CallExpr)
+	if !ok || len(call.Args) != 0 {
+		return nil
+	}
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Next" {
+		return nil
+	}
+	v := localVar(p, ast.Unparen(sel.X))
+	if v == nil {
+		return nil
+	}
+	named, ok := types.Unalias(derefType(v.Type())).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil || !rowsErrTypes.contains(named.Obj().Pkg().Path()+"."+named.Obj().Name()) {
+		return nil
+	}
+	return v
+}
+
+// rowsErrChecked reports whether body uses the result of rows.Err() after
+// loop or in a defer statement, or hands rows to other code after loop.
+func rowsErrChecked(p *Pass, body *ast.BlockStmt, rows *types.Var, loop *ast.ForStmt) bool {
+	cur, ok := p.Inspector.Root().FindNode(body)
+	if !ok {
+		return false
+	}
+	for ic := range cur.Preorder((*ast.Ident)(nil)) {
+		id, ok := ic.Node().(*ast.Ident)
+		if !ok || p.TypesInfo.Uses[id] != rows {
+			continue
+		}
+		if id.Pos() < loop.End() && !inDefer(ic) {
+			continue
+		}
+		sel, ok := ic.Parent().Node().(*ast.SelectorExpr)
+		if !ok {
+			if id.Pos() > loop.End() && handedOff(ic) {
+				return true
+			}
+			continue
+		}
+		call, ok := ic.Parent().Parent().Node().(*ast.CallExpr)
+		if !ok || call.Fun != sel || sel.Sel.Name != "Err" {
+			continue
+		}
+		if _, discarded := ic.Parent().Parent().Parent().Node().(*ast.ExprStmt); !discarded {
+			return true
+		}
+	}
+	return false
+}
+
+// inDefer reports whether c lies in a defer statement.
+func inDefer(c inspector.Cursor) bool {
+	for range c.Enclosing((*ast.DeferStmt)(nil)) {
+		return true
+	}
+	return false
+}
+
+// handedOff reports whether the variable at c is passed to a function,
+// returned, stored or has its address taken.
+func handedOff(c inspector.Cursor) bool {
+	switch pn := c.Parent().Node().(type) {
+	case *ast.CallExpr:
+		return pn.Fun != c.Node()
+	case *ast.ReturnStmt, *ast.CompositeLit, *ast.KeyValueExpr, *ast.UnaryExpr:
+		return true
+	case *ast.AssignStmt:
+		return !isLHS(pn, c.Node())
+	}
+	return false
+}
+
+// rowsErrFix inserts `if err := rows.Err(); err != nil { ... }` after loop,
+// returning the error wrapped when the function returns one.
+func rowsErrFix(p *Pass, c inspector.Cursor, loop *ast.ForStmt, rows string) []analysis.SuggestedFix {
+	if !inStatementList(c) {
+		return nil
+	}
+	body := "// TODO: handle the error"
+	edits := make([]analysis.TextEdit, 0, 2)
+	file, sig := enclosingFile(c), enclosingSignature(p, c)
+	if file != nil && sig != nil {
+		fmtPkg, imports := importName(p, file, "fmt")
+		if ret, ok := returnZeros(p, file, sig, fmtPkg+`.Errorf("iterating `+rows+`: %w", err)`); ok {
+			body = "return " + ret
+			edits = append(edits, imports...)
+		}
+	}
+	indent, eol := lineIndent(p, loop.Pos()), lineEnd(p, loop.End())
+	edits = append(edits, analysis.TextEdit{
+		Pos: eol,
+		End: eol,
+		NewText: []byte("\n" + indent + "if err := " + rows + ".Err(); err != nil {\n" +
+			indent + "\t" + body + "\n" + indent + "}"),
+	})
+	return []analysis.SuggestedFix{{Message: "Check " + rows + ".Err() after the loop", TextEdits: edits}}
+}
@@ -0,0 +1,184 @@
+package cog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/packages"
+)
+
+// loadMode is what Run needs of the packages it loads: syntax and type
+// information for them and all their dependencies, since buildssa relies on
+// facts that ctrlflow computes for every imported package.
+const loadMode = packages.LoadAllSyntax
+
+// Run loads the packages matching patterns, as the go command does from the
+// current directory, runs the rules over each at the severities of cfg and
+// returns the findings, sorted by file, then position, then rule ID. Test
+// files are not loaded.
+//
+// cfg is merged over DefaultConfig, so the zero Config runs every rule at
+// its declared severity. Its settings are applied to the flags of Analyzer,
+// as those of a config file are, for the duration of the call: Run sets
+// the flags back to their earlier values before it returns.
+//
+//	findings, err := cog.Run(ctx, []string{"./..."}, cog.Config{
+//		Rules:    map[string]cog.Severity{"CogBareReturn": cog.SeverityOff},
+//		Settings: map[string]any{"typeerasure.enable": true},
+//	})
+//	if err != nil {
+//		return fmt.Errorf("lint: %w", err)
+//	}
+//	return cog.WriteText(os.Stdout, findings, cog.TextOptions{})
+//
+// Errors loading or type-checking a package are returned, wrapped, before
+// any rule runs. Run stops analyzing packages once ctx is done and returns
+// its error, wrapped.
+//
+// With -cache-dir set, a package whose sources, dependencies and
+// configuration are unchanged since an earlier Run is not analyzed again:
+// its findings are read from the cache (see cacheEntry for the layout).
+//
+// Packages are analyzed concurrently, at most -j at a time. Calls to Run
+// are serialized, since the settings they apply are process-wide.
+func Run(ctx context.Context, patterns []string, cfg Config) ([]Finding, error) {
+	findings, _, err := RunStats(ctx, patterns, cfg)
+	return findings, err //cog:ignore CogErrorWrap RunStats wraps it
+}
+
+// Stats describes what a run covered.
+type Stats struct {
+	// Packages and Files count the packages matching the patterns and
+	// their Go files, whether analyzed or read from the cache.
+	Packages int
+	Files    int
+
+	// Duration is the time the run took, loading included.
+	Duration time.Duration
+}
+
+// RunStats is Run, also returning the Stats of the run.
+func RunStats(ctx context.Context, patterns []string, cfg Config) ([]Finding, Stats, error) {
+	start := time.Now()
+	runMu.Lock()
+	defer runMu.Unlock()
+
+	if err := cfg.validate(); err != nil {
+		return nil, Stats{}, fmt.Errorf("cog: config: %w", err)
+	}
+	active := cfg.overDefaults()
+	defer active.save(&Analyzer.Flags)()
+	if err := active.apply(&Analyzer.Flags, func(string) bool { return false }); err != nil {
+		return nil, Stats{}, err //cog:ignore CogErrorWrap apply names the config setting
+	}
+
+	base, err := readBaseline(baselinePath)
+	if err != nil {
+		return nil, Stats{}, err //cog:ignore CogErrorWrap readBaseline names the file
+	}
+
+	pkgs, err := packages.Load(&packages.Config{Context: ctx, Mode: loadMode}, patterns...)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, Stats{}, fmt.Errorf("cog: load: %w", ctxErr) // packages.Load does not wrap it
+	}
+	if err != nil {
+		return nil, Stats{}, fmt.Errorf("cog: load: %w", err)
+	}
+	if err := loadErrors(pkgs); err != nil {
+		return nil, Stats{}, fmt.Errorf("cog: load: %w", err)
+	}
+
+	findings := make([]Finding, 0)
+	misses := pkgs
+	var cache *resultCache
+	if cacheDir != "" {
+		if cache, err = newResultCache(cacheDir, active); err != nil {
+			return nil, Stats{}, fmt.Errorf("cog: cache: %w", err)
+		}
+		if findings, misses, err = cachedFindings(cache, pkgs); err != nil {
+			return nil, Stats{}, fmt.Errorf("cog: cache: %w", err)
+		}
+	}
+
+	sem := newSemaphore(parallelism())
+	a := &analysis.Analyzer{
+		Name:     Analyzer.Name,
+		Doc:      Analyzer.Doc,
+		URL:      Analyzer.URL,
+		Requires: Analyzer.Requires,
+		Run: func(pass *analysis.Pass) (any, error) {
+			if err := ctx.Err(); err != nil {
+				return nil, err //cog:ignore CogErrorWrap RunStats wraps it below
+			}
+			defer sem.acquire()()
+			return analyze(pass, active, base, Rules)
+		},
+		ResultType: Analyzer.ResultType,
+	}
+	// One graph for all packages, so that the analysis of their shared
+	// dependencies runs once.
+	graph, err := checker.Analyze([]*analysis.Analyzer{a}, misses, nil)
+	if err != nil {
+		return nil, Stats{}, fmt.Errorf("cog: analyze: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, Stats{}, fmt.Errorf("cog: %w", err)
+	}
+	for _, act := range graph.Roots {
+		if act.Err != nil {
+			return nil, Stats{}, fmt.Errorf("cog: %s: %w", act.Package.PkgPath, act.Err)
+		}
+		fs, _ := act.Result.([]Finding) // the result of analyze
+		if cache != nil {
+			if err := cache.put(act.Package, fs); err != nil {
+				return nil, Stats{}, fmt.Errorf("cog: cache: %w", err)
+			}
+		}
+		findings = append(findings, fs...)
+	}
+	sortFindings(findings) // graph.Roots are in no particular order
+	stats := Stats{Packages: len(pkgs), Duration: time.Since(start)}
+	for _, pkg := range pkgs {
+		stats.Files += len(pkg.GoFiles)
+	}
+	return findings, stats, nil
+}
+
+// cachedFindings returns the findings cached for pkgs, and the packages
+// that have none.
+func cachedFindings(cache *resultCache, pkgs []*packages.Package) ([]Finding, []*packages.Package, error) {
+	findings := make([]Finding, 0)
+	misses := make([]*packages.Package, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		fs, ok, err := cache.get(pkg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("looking up %s: %w", pkg.PkgPath, err)
+		}
+		if !ok {
+			misses = append(misses, pkg)
+			continue
+		}
+		findings = append(findings, fs...)
+	}
+	return findings, misses, nil
+}
+
+// runMu serializes calls to Run.
+var runMu sync.Mutex
+
+// loadErrors joins the errors of pkgs and their dependencies, in
+// dependency order.
+func loadErrors(pkgs []*packages.Package) error {
+	errs := make([]error, 0)
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		for _, e := range pkg.Errors {
+			errs = append(errs, e)
+		}
+	})
+	return errors.Join(errs...)
+}
@@ -0,0 +1,38 @@
+package cog
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRunRestoresSettings(t *testing.T) {
+	cfg := Config{Rules: map[string]Severity{"CogBareReturn": SeverityWarning}}
+	rules := func(findings []Finding) []string {
+		ids := make([]string, 0, len(findings))
+		for _, f := range findings {
+			ids = append(ids, f.Rule)
+		}
+		return ids
+	}
+
+	styleOnly := cfg
+	styleOnly.Settings = map[string]any{"only": "style"}
+	findings, err := Run(t.Context(), []string{"./testdata/run"}, styleOnly)
+	if err != nil {
+		t.Fatalf("Run with -only=style: %v", err)
+	}
+	if got, want := rules(findings), []string{"CogBareReturn"}; !slices.Equal(got, want) {
+		t.Errorf("Run with -only=style found %v, want %v", got, want)
+	}
+	if len(onlyCategories) != 0 {
+		t.Errorf("-only is %q after Run, want it unset", onlyCategories.String())
+	}
+
+	findings, err = Run(t.Context(), []string{"./testdata/run"}, cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if got, want := rules(findings), []string{"CogIgnoredError", "CogBareReturn"}; !slices.Equal(got, want) {
+		t.Errorf("Run after a Run with -only=style found %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,175 @@
+package cog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// The SARIF 2.1.0 subset WriteSARIF emits; see
+// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html.
+type (
+	sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+	sarifDriver struct {
+		Name           string                `json:"name"`
+		InformationURI string                `json:"informationUri"`
+		Rules          []sarifReportingDescr `json:"rules"`
+	}
+	sarifReportingDescr struct {
+		ID                   string             `json:"id"`
+		ShortDescription     sarifText          `json:"shortDescription"`
+		Help                 sarifText          `json:"help"`
+		HelpURI              string             `json:"helpUri"`
+		DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+	}
+	sarifConfiguration struct {
+		Level string `json:"level"`
+	}
+	sarifText struct {
+		Text string `json:"text"`
+	}
+	sarifResult struct {
+		RuleID    string          `json:"ruleId"`
+		RuleIndex int             `json:"ruleIndex"`
+		Level     string          `json:"level"`
+		Message   sarifText       `json:"message"`
+		Locations []sarifLocation `json:"locations"`
+	}
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           sarifRegion           `json:"region"`
+	}
+	sarifArtifactLocation struct {
+		URI       string `json:"uri"`
+		URIBaseID string `json:"uriBaseId,omitempty"`
+	}
+	sarifRegion struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn"`
+		EndLine     int `json:"endLine"`
+		EndColumn   int `json:"endColumn"`
+	}
+)
+
+// WriteSARIF writes findings to w as a SARIF 2.1.0 log, for GitHub code
+// scanning and other SARIF consumers. Every rule is described in the log's
+// tool metadata, with its severity as the default level. File paths under
+// the working directory, where CI runs, are written relative to it with
+// the %SRCROOT% base; others are written as file URIs.
+func WriteSARIF(w io.Writer, findings []Finding) error {
+	rules := sarifRules()
+	index := make(map[string]int, len(rules))
+	for i, r := range rules {
+		index[r.ID] = i
+	}
+
+	sorted := slices.Clone(findings)
+	sortFindings(sorted)
+	results := make([]sarifResult, 0, len(sorted))
+	for _, f := range sorted {
+		i, ok := index[f.Rule]
+		if !ok {
+			return fmt.Errorf("cog: sarif: finding of unknown rule %q", f.Rule)
+		}
+		results = append(results, sarifResult{
+			RuleID:    f.Rule,
+			RuleIndex: i,
+			Level:     sarifLevel(f.Severity),
+			Message:   sarifText{Text: f.Message},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifact(f.Pos.Filename),
+				Region: sarifRegion{
+					StartLine:   f.Pos.Line,
+					StartColumn: f.Pos.Column,
+					EndLine:     f.End.Line,
+					EndColumn:   f.End.Column,
+				},
+			}}},
+		})
+	}
+
+	log := sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           Analyzer.Name,
+				InformationURI: Analyzer.URL,
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(log); err != nil {
+		return fmt.Errorf("cog: sarif: %w", err)
+	}
+	return nil
+}
+
+// sarifRules describes every rule, including the unused-ignore pseudo-rule.
+func sarifRules() []sarifReportingDescr {
+	all := append(slices.Clone(Rules), unusedIgnoreRule)
+	rules := make([]sarifReportingDescr, 0, len(all))
+	for _, r := range all {
+		rules = append(rules, sarifReportingDescr{
+			ID:                   r.ID,
+			ShortDescription:     sarifText{Text: r.Doc},
+			Help:                 sarifText{Text: r.Doc},
+			HelpURI:              Analyzer.URL,
+			DefaultConfiguration: sarifConfiguration{Level: sarifLevel(r.defaultSeverity())},
+		})
+	}
+	return rules
+}
+
+// sarifLevel maps a severity to a SARIF result level.
+func sarifLevel(sev Severity) string {
+	switch sev {
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "note"
+	case SeverityOff:
+		return "none"
+	}
+	return "error"
+}
+
+// sarifArtifact locates filename relative to the working directory when it
+// lies beneath it.
+func sarifArtifact(filename string) sarifArtifactLocation {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return sarifArtifactLocation{URI: filepath.ToSlash(filename)}
+	}
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, abs); err == nil && filepath.IsLocal(rel) {
+			return sarifArtifactLocation{URI: filepath.ToSlash(rel), URIBaseID: "%SRCROOT%"}
+		}
+	}
+	path := filepath.ToSlash(abs)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path // a Windows drive letter: file:///C:/...
+	}
+	return sarifArtifactLocation{URI: "file://" + path}
+}
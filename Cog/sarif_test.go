@@ -0,0 +1,21 @@
+package cog
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteSARIFGolden(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteSARIF(&b, testFindings()); err != nil {
+		t.Fatalf("WriteSARIF: %v", err)
+	}
+	checkGolden(t, "findings.sarif", b.Bytes())
+}
+
+func TestWriteSARIFUnknownRule(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteSARIF(&b, []Finding{{Rule: "CogNoSuchRule"}}); err == nil {
+		t.Error("WriteSARIF of a finding of an unknown rule succeeded, want an error")
+	}
+}
@@ -0,0 +1,62 @@
+package cog
+
+import (
+	"flag"
+	"strconv"
+	"strings"
+)
+
+// ScaffoldConfig returns a commented .cog.yaml that lists every rule in
+// Rules at its default severity, with its summary, and every setting,
+// commented out, at its default value. Loading it gives the same
+// configuration as having no config file; `cog init` writes it.
+func ScaffoldConfig() []byte {
+	var b strings.Builder
+	b.WriteString("# Cog configuration, generated by `cog init`.\n" +
+		"#\n" +
+		"# Every rule is listed at its default severity. Set a rule to error,\n" +
+		"# warning, info or off; rules set to off do not run. Opt-in rules only\n" +
+		"# run once their <rule>.enable setting below is true, as noted.\n" +
+		"# See " + Analyzer.URL + "#configuration\n" +
+		"rules:\n")
+	for _, rule := range Rules {
+		b.WriteString("  # " + rule.Doc + "\n")
+		b.WriteString("  " + rule.ID + ": " + string(rule.defaultSeverity()))
+		if name, ok := enableSetting(rule); ok {
+			b.WriteString(" # opt-in: set " + name + ": true")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n# Settings give analyzer flags a value, as if set on the command line.\n" +
+		"# Uncomment one to change it from its default; a list may also be\n" +
+		"# written as a YAML sequence.\n" +
+		"settings:\n")
+	Analyzer.Flags.VisitAll(func(f *flag.Flag) {
+		if f.Name == "config" {
+			return
+		}
+		b.WriteString("  # " + f.Usage + "\n")
+		b.WriteString("  # " + f.Name + ": " + scaffoldValue(f) + "\n")
+	})
+	return []byte(b.String())
+}
+
+// scaffoldValue renders the default of f as YAML: booleans and integers as
+// they are, anything else as a quoted string.
+func scaffoldValue(f *flag.Flag) string {
+	if bf, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && bf.IsBoolFlag() {
+		return f.DefValue
+	}
+	if _, err := strconv.Atoi(f.DefValue); err == nil {
+		return f.DefValue
+	}
+	return strconv.Quote(f.DefValue)
+}
+
+// enableSetting returns the name of the setting that turns on the opt-in
+// rule r, such as typeerasure.enable for CogTypeErasure, or false when r
+// runs without one.
+func enableSetting(r *Rule) (string, bool) {
+	name := strings.ToLower(strings.TrimPrefix(r.ID, "Cog")) + ".enable"
+	return name, Analyzer.Flags.Lookup(name) != nil
+}
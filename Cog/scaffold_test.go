@@ -0,0 +1,81 @@
+package cog
+
+import (
+	"flag"
+	"maps"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// TestScaffoldConfigRoundTrip checks that the config `cog init` writes
+// loads as the default configuration.
+func TestScaffoldConfigRoundTrip(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.mod":    "module scaffold\n",
+		".cog.yaml": string(ScaffoldConfig()),
+	})
+	cfg, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig of the scaffold: %v", err)
+	}
+	if want := DefaultConfig(); !maps.Equal(cfg.Rules, want.Rules) {
+		t.Errorf("scaffold rules = %v, want the defaults %v", cfg.Rules, want.Rules)
+	}
+	if len(cfg.Settings) != 0 {
+		t.Errorf("scaffold settings = %v, want none", cfg.Settings)
+	}
+}
+
+// settingLine matches a commented-out setting of the scaffold:
+// "  # name: value".
+var settingLine = regexp.MustCompile(`^  # [a-z0-9.-]+: `)
+
+// TestScaffoldConfigSettings checks that every setting the scaffold lists,
+// once uncommented, is accepted and leaves its flag at its default.
+func TestScaffoldConfigSettings(t *testing.T) {
+	var b strings.Builder
+	for line := range strings.Lines(string(ScaffoldConfig())) {
+		if settingLine.MatchString(line) {
+			line = "  " + line[len("  # "):]
+		}
+		b.WriteString(line)
+	}
+	dir := writeModule(t, map[string]string{"go.mod": "module scaffold\n", ".cog.yaml": b.String()})
+	cfg, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig of the uncommented scaffold: %v\n%s", err, b.String())
+	}
+	n := 0
+	Analyzer.Flags.VisitAll(func(*flag.Flag) { n++ })
+	if len(cfg.Settings) != n-1 { // all but -config
+		t.Errorf("uncommented scaffold has %d settings, want %d", len(cfg.Settings), n-1)
+	}
+	if err := cfg.apply(&Analyzer.Flags, func(string) bool { return false }); err != nil {
+		t.Fatalf("applying the uncommented scaffold: %v", err)
+	}
+	Analyzer.Flags.VisitAll(func(f *flag.Flag) {
+		if f.Value.String() != f.DefValue {
+			t.Errorf("setting %s changed flag -%s from %q to %q", f.Name, f.Name, f.DefValue, f.Value.String())
+		}
+	})
+}
+
+// TestScaffoldConfigOptIn checks that the scaffold notes the setting each
+// opt-in rule needs, and only for those.
+func TestScaffoldConfigOptIn(t *testing.T) {
+	scaffold := string(ScaffoldConfig())
+	for _, rule := range Rules {
+		line := "\n  " + rule.ID + ": " + string(rule.defaultSeverity())
+		name, optIn := enableSetting(rule)
+		switch {
+		case optIn && !strings.Contains(scaffold, line+" # opt-in: set "+name+": true\n"):
+			t.Errorf("scaffold does not note that %s needs %s: true", rule.ID, name)
+		case !optIn && !strings.Contains(scaffold, line+"\n"):
+			t.Errorf("scaffold line of %s is not %q", rule.ID, line[1:])
+		}
+	}
+	if _, ok := enableSetting(typeErasureRule); !ok {
+		t.Error("CogTypeErasure is not opt-in")
+	}
+}
@@ -0,0 +1,143 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// selectContextRule reports a blocking select that ignores the context of
+// the function it runs in.
+//
+//	func worker(ctx context.Context, jobs <-chan Job) {
+//		for {
+//			select { // waits for a job even after ctx is cancelled
+//			case j := <-jobs:
+//				run(j)
+//			}
+//		}
+//	}
+//
+// A select with no default case blocks until one of its cases is ready.
+// When the enclosing function, or a function a closure is nested in, takes
+// a context.Context, cancelling it should end the wait too; without a
+// `case <-ctx.Done():` the goroutine outlives its caller. Receives from
+// Done of any context count, as do receives from a variable holding the
+// channel Done returned.
+var selectContextRule = &Rule{
+	ID:       "CogSelectContext",
+	Doc:      "report blocking selects with no ctx.Done case in functions that take a context",
+	Run:      runSelectContext,
+	Category: CategoryConcurrency,
+}
+
+func runSelectContext(p *Pass) {
+	for c := range p.Inspector.Root().Preorder((*ast.SelectStmt)(nil)) {
+		sel, ok := c.Node().(*ast.SelectStmt)
+		if !ok {
+			continue
+		}
+		ctx := contextInScope(p, c)
+		if ctx == "" {
+			continue
+		}
+		_, body := enclosingFunc(c)
+		if decl := enclosingDecl(c); decl != nil {
+			body = decl.Body // the Done channel may be taken outside a closure
+		}
+		if hasDefaultOrDone(p, sel, body) {
+			continue
+		}
+		p.Report(sel, "select blocks with no case for "+ctx+".Done(), so cancelling "+ctx+" does not end the wait; "+
+			"add `case <-"+ctx+".Done():` and return")
+	}
+}
+
+// contextInScope returns the name of the context.Context parameter of the
+// innermost function around c that has one, or "" when none does.
+func contextInScope(p *Pass, c inspector.Cursor) string {
+	for fc := range c.Enclosing((*ast.FuncDecl)(nil), (*ast.FuncLit)(nil)) {
+		var ft *ast.FuncType
+		switch fn := fc.Node().(type) {
+		case *ast.FuncDecl:
+			ft = fn.Type
+		case *ast.FuncLit:
+			ft = fn.Type
+		}
+		if field, _ := contextParam(p, ft); field != nil {
+			for _, name := range field.Names {
+				if name.Name != "_" {
+					return name.Name
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// hasDefaultOrDone reports whether sel has a default case or a case
+// receiving from a context's Done channel.
+func hasDefaultOrDone(p *Pass, sel *ast.SelectStmt, body *ast.BlockStmt) bool {
+	for _, stmt := range sel.Body.List {
+		clause, ok := stmt.(*ast.CommClause)
+		if !ok {
+			continue
+		}
+		var recv ast.Expr
+		switch comm := clause.Comm.(type) {
+		case nil:
+			return true // default
+		case *ast.ExprStmt:
+			recv = comm.X
+		case *ast.AssignStmt:
+			if len(comm.Rhs) == 1 {
+				recv = comm.Rhs[0]
+			}
+		}
+		arrow, ok := ast.Unparen(recv).(*ast.UnaryExpr)
+		if ok && arrow.Op == token.ARROW && isDoneChan(p, arrow.X, body) {
+			return true
+		}
+	}
+	return false
+}
+
+// isDoneChan reports whether e is a call of Done on a context.Context, or a
+// local variable assigned such a call in body.
+func isDoneChan(p *Pass, e ast.Expr, body *ast.BlockStmt) bool {
+	if isDoneCall(p, e) {
+		return true
+	}
+	v := localVar(p, ast.Unparen(e))
+	if v == nil || body == nil {
+		return false
+	}
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		assign, ok := n.(*ast.AssignStmt)
+		if found || !ok || len(assign.Lhs) != len(assign.Rhs) {
+			return !found
+		}
+		for i, lhs := range assign.Lhs {
+			if localVar(p, lhs) == v && isDoneCall(p, assign.Rhs[i]) {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// isDoneCall reports whether e is ctx.Done() for a context.Context ctx.
+func isDoneCall(p *Pass, e ast.Expr) bool {
+	call, ok := ast.Unparen(e).(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	fn, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok || fn.Sel.Name != "Done" {
+		return false
+	}
+	return isContextType(p.TypesInfo.TypeOf(fn.X))
+}
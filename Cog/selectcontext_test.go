@@ -0,0 +1,11 @@
+package cog
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestSelectContext(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), ruleAnalyzer(selectContextRule), "selectcontext")
+}
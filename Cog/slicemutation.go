@@ -0,0 +1,136 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+	"unicode"
+
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// sliceMutationRule reports an append to a slice parameter whose result
+// never leaves the function.
+//
+//	func addDefault(tags []string) {
+//		tags = append(tags, "default") // the caller's tags is unchanged
+//	}
+//
+// A slice is passed as a header (pointer, length, capacity) copied from the
+// caller's. Appending updates the local copy only, so the caller never sees
+// the new length; yet when the backing array has spare capacity, the new
+// elements are written into the caller's array, where a later append by
+// the caller overwrites them. The rule fires when the function assigns
+// append(p, ...) back to a parameter p, and p is never returned, stored,
+// sent, passed to a function or has its address taken. Functions whose doc
+// comment mentions p are taken to document the behavior. Maps are not
+// reported, since writes through a map parameter are visible to the caller.
+// The rule is a heuristic, so it is opt-in (-slicemutation.enable).
+var sliceMutationRule = &Rule{
+	ID:       "CogSliceMutation",
+	Doc:      "report appends to slice parameters that the caller never sees",
+	Run:      runSliceMutation,
+	Category: CategoryTypes,
+
+	Severity: SeverityWarning,
+}
+
+// sliceMutationEnable turns the rule on.
+var sliceMutationEnable bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&sliceMutationEnable, "slicemutation.enable", false,
+		"report appends to slice parameters whose result is not returned (heuristic)")
+}
+
+func runSliceMutation(p *Pass) {
+	if !sliceMutationEnable {
+		return
+	}
+	for c := range p.Inspector.Root().Preorder((*ast.FuncDecl)(nil)) {
+		decl, ok := c.Node().(*ast.FuncDecl)
+		if !ok || decl.Body == nil {
+			continue
+		}
+		for _, field := range decl.Type.Params.List {
+			for _, name := range field.Names {
+				v := identVar(p, name)
+				if v == nil || name.Name == "_" || docMentions(decl.Doc, name.Name) {
+					continue
+				}
+				if _, ok := v.Type().Underlying().(*types.Slice); !ok {
+					continue
+				}
+				call := lostAppend(p, decl.Body, v)
+				if call == nil {
+					continue
+				}
+				n := v.Name()
+				p.Report(call, "append to the parameter "+n+" is not seen by the caller: "+n+" is a copy of the "+
+					"caller's slice header, so the new length stays local, while the new elements may still be "+
+					"written into the caller's backing array; return "+n+", or take a *"+types.TypeString(v.Type(),
+					types.RelativeTo(p.Pkg))+" if the caller should see the change")
+			}
+		}
+	}
+}
+
+// docMentions reports whether the comment doc contains the word name.
+func docMentions(doc *ast.CommentGroup, name string) bool {
+	if doc == nil {
+		return false
+	}
+	words := strings.FieldsFunc(doc.Text(), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+	return slices.Contains(words, name)
+}
+
+// lostAppend returns the first append in `v = append(v, ...)` in body
+// when every other use of v in body keeps it inside the function, or nil.
+func lostAppend(p *Pass, body *ast.BlockStmt, v *types.Var) *ast.CallExpr {
+	bc, ok := p.Inspector.Root().FindNode(body)
+	if !ok {
+		return nil
+	}
+	var first *ast.CallExpr
+	for ic := range bc.Preorder((*ast.Ident)(nil)) {
+		id, ok := ic.Node().(*ast.Ident)
+		if !ok || p.TypesInfo.Uses[id] != v {
+			continue
+		}
+		switch parent := ic.Parent().Node().(type) {
+		case *ast.AssignStmt:
+			if !isLHS(parent, id) {
+				return nil // stored elsewhere
+			}
+		case *ast.CallExpr:
+			switch {
+			case isBuiltin(p, parent.Fun, "len"), isBuiltin(p, parent.Fun, "cap"):
+			case isBuiltin(p, parent.Fun, "append") && parent.Args[0] == id && selfAppend(p, ic.Parent(), v):
+				if first == nil {
+					first = parent
+				}
+			default:
+				return nil // handed to a function
+			}
+		case *ast.IndexExpr, *ast.RangeStmt, *ast.BinaryExpr:
+		default:
+			return nil // returned, sliced, sent, captured by address, ...
+		}
+	}
+	return first
+}
+
+// selfAppend reports whether the append call at c is the right-hand side
+// of `v = append(v, ...)`.
+func selfAppend(p *Pass, c inspector.Cursor, v *types.Var) bool {
+	assign, ok := c.Parent().Node().(*ast.AssignStmt)
+	if !ok || assign.Tok != token.ASSIGN || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+		return false
+	}
+	id, ok := assign.Lhs[0].(*ast.Ident)
+	return ok && p.TypesInfo.Uses[id] == v
+}
@@ -0,0 +1,14 @@
+package cog
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestSliceMutation(t *testing.T) {
+	saved := sliceMutationEnable
+	t.Cleanup(func() { sliceMutationEnable = saved })
+	sliceMutationEnable = true
+	analysistest.Run(t, analysistest.TestData(), ruleAnalyzer(sliceMutationRule), "slicemutation")
+}
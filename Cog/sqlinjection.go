@@ -0,0 +1,221 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"strconv"
+
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// sqlInjectionRule reports SQL queries built from non-constant values with
+// fmt.Sprintf or string concatenation.
+//
+//	rows, err := db.Query(fmt.Sprintf("SELECT * FROM users WHERE name = '%s'", name))
+//
+// A value spliced into the query text is parsed as SQL, so a name of
+// `x' OR '1'='1` changes what the query does. Placeholders (? or $1,
+// depending on the driver) with the values passed as arguments keep data
+// out of the SQL. The query argument, the first string parameter of a call
+// listed in -sqlinjection.funcs, is reported when it is a fmt.Sprintf,
+// Sprint or Sprintln call or a concatenation with a non-constant string
+// operand, or a local variable assigned one in the same function. Numbers,
+// booleans and strconv formatting of them cannot carry SQL and are
+// allowed, as is any query the type checker evaluates to a constant.
+var sqlInjectionRule = &Rule{
+	ID:       "CogSQLInjection",
+	Doc:      "report SQL queries built with fmt.Sprintf or concatenation of non-constant values",
+	Run:      runSQLInjection,
+	Category: CategorySecurity,
+}
+
+// sqlInjectionFuncs lists the calls that execute a query.
+var sqlInjectionFuncs = listFlag{
+	"(*database/sql.DB).Exec",
+	"(*database/sql.DB).ExecContext",
+	"(*database/sql.DB).Prepare",
+	"(*database/sql.DB).PrepareContext",
+	"(*database/sql.DB).Query",
+	"(*database/sql.DB).QueryContext",
+	"(*database/sql.DB).QueryRow",
+	"(*database/sql.DB).QueryRowContext",
+	"(*database/sql.Tx).Exec",
+	"(*database/sql.Tx).ExecContext",
+	"(*database/sql.Tx).Prepare",
+	"(*database/sql.Tx).PrepareContext",
+	"(*database/sql.Tx).Query",
+	"(*database/sql.Tx).QueryContext",
+	"(*database/sql.Tx).QueryRow",
+	"(*database/sql.Tx).QueryRowContext",
+	"(*database/sql.Conn).ExecContext",
+	"(*database/sql.Conn).PrepareContext",
+	"(*database/sql.Conn).QueryContext",
+	"(*database/sql.Conn).QueryRowContext",
+	"(*github.com/jmoiron/sqlx.DB).Get",
+	"(*github.com/jmoiron/sqlx.DB).Select",
+	"(*github.com/jmoiron/sqlx.DB).Queryx",
+	"(*github.com/jmoiron/sqlx.DB).QueryRowx",
+	"(*github.com/jackc/pgx/v5.Conn).Exec",
+	"(*github.com/jackc/pgx/v5.Conn).Query",
+	"(*github.com/jackc/pgx/v5.Conn).QueryRow",
+	"(*github.com/jackc/pgx/v5/pgxpool.Pool).Exec",
+	"(*github.com/jackc/pgx/v5/pgxpool.Pool).Query",
+	"(*github.com/jackc/pgx/v5/pgxpool.Pool).QueryRow",
+}
+
+func init() {
+	Analyzer.Flags.Var(&sqlInjectionFuncs, "sqlinjection.funcs",
+		"comma-separated calls whose first string argument is an SQL query, e.g. (*database/sql.DB).Query,example.com/db.Exec")
+}
+
+// safeStringFuncs format values that cannot carry SQL.
+var safeStringFuncs = []string{
+	"strconv.Itoa", "strconv.FormatInt", "strconv.FormatUint", "strconv.FormatFloat", "strconv.FormatBool",
+}
+
+func runSQLInjection(p *Pass) {
+	for c := range p.Inspector.Root().Preorder((*ast.CallExpr)(nil)) {
+		call, ok := c.Node().(*ast.CallExpr)
+		if !ok || !slices.Contains(sqlInjectionFuncs, calleeName(p.TypesInfo, call)) {
+			continue
+		}
+		query := queryArg(p, call)
+		if query == nil {
+			continue
+		}
+		how, ok := builtQuery(p, c, query)
+		if !ok {
+			continue
+		}
+		p.Report(query, "the query passed to "+types.ExprString(call.Fun)+" is "+how+
+			", so the values can inject SQL; use placeholders (? or $1) and pass the values as arguments")
+	}
+}
+
+// queryArg returns the argument of call for its first string parameter,
+// or nil.
+func queryArg(p *Pass, call *ast.CallExpr) ast.Expr {
+	fn, ok := typeutil.Callee(p.TypesInfo, call).(*types.Func)
+	if !ok {
+		return nil
+	}
+	sig, ok := fn.Type().(*types.Signature)
+	if !ok {
+		return nil
+	}
+	for i := range sig.Params().Len() {
+		if i >= len(call.Args) || (sig.Variadic() && i == sig.Params().Len()-1) {
+			break
+		}
+		if b, ok := sig.Params().At(i).Type().Underlying().(*types.Basic); ok && b.Info()&types.IsString != 0 {
+			return call.Args[i]
+		}
+	}
+	return nil
+}
+
+// builtQuery reports whether the query e at c is built from non-constant
+// values, describing how: "built with fmt.Sprintf from non-constant
+// values", "concatenated from non-constant strings", or, for a variable,
+// how it was built on which line.
+func builtQuery(p *Pass, c inspector.Cursor, e ast.Expr) (string, bool) {
+	if how, ok := splicedExpr(p, e); ok {
+		return how, true
+	}
+	v := localVar(p, ast.Unparen(e))
+	_, body := enclosingFunc(c)
+	if v == nil || body == nil {
+		return "", false
+	}
+	bc, ok := p.Inspector.Root().FindNode(body)
+	if !ok {
+		return "", false
+	}
+	for ac := range bc.Preorder((*ast.AssignStmt)(nil), (*ast.ValueSpec)(nil)) {
+		lhs, rhs := assignedValues(ac.Node())
+		if len(lhs) != len(rhs) {
+			continue
+		}
+		for i, l := range lhs {
+			id, ok := l.(*ast.Ident)
+			if !ok || p.TypesInfo.ObjectOf(id) != v {
+				continue
+			}
+			how, ok := splicedExpr(p, rhs[i])
+			if assign, isAssign := ac.Node().(*ast.AssignStmt); isAssign && assign.Tok == token.ADD_ASSIGN && !ok {
+				how, ok = "concatenated from non-constant strings", !safeSQLValue(p, rhs[i])
+			}
+			if ok {
+				return v.Name() + ", which is " + how + " on line " + strconv.Itoa(p.Fset.Position(rhs[i].Pos()).Line), true
+			}
+		}
+	}
+	return "", false
+}
+
+// assignedValues returns the left and right sides of an assignment or a
+// var declaration.
+func assignedValues(n ast.Node) ([]ast.Expr, []ast.Expr) {
+	switch n := n.(type) {
+	case *ast.AssignStmt:
+		return n.Lhs, n.Rhs
+	case *ast.ValueSpec:
+		lhs := make([]ast.Expr, len(n.Names))
+		for i, name := range n.Names {
+			lhs[i] = name
+		}
+		return lhs, n.Values
+	}
+	return nil, nil
+}
+
+// splicedExpr reports whether e splices non-constant values into a string
+// with fmt.Sprintf, Sprint or Sprintln or with +, and how.
+func splicedExpr(p *Pass, e ast.Expr) (string, bool) {
+	if p.TypesInfo.Types[e].Value != nil {
+		return "", false
+	}
+	switch e := ast.Unparen(e).(type) {
+	case *ast.CallExpr:
+		name := calleeName(p.TypesInfo, e)
+		if name != "fmt.Sprintf" && name != "fmt.Sprint" && name != "fmt.Sprintln" {
+			return "", false
+		}
+		for _, arg := range e.Args {
+			if !safeSQLValue(p, arg) {
+				return "built with " + name + " from non-constant values", true
+			}
+		}
+	case *ast.BinaryExpr:
+		if e.Op == token.ADD && !safeSQLValue(p, e) {
+			return "concatenated from non-constant strings", true
+		}
+	}
+	return "", false
+}
+
+// safeSQLValue reports whether e cannot carry SQL text: a constant, a
+// number or boolean, a strconv formatting of one, or a concatenation of
+// such values.
+func safeSQLValue(p *Pass, e ast.Expr) bool {
+	tv, ok := p.TypesInfo.Types[e]
+	if !ok {
+		return false
+	}
+	if tv.Value != nil {
+		return true
+	}
+	if b, ok := tv.Type.Underlying().(*types.Basic); ok && b.Info()&(types.IsNumeric|types.IsBoolean) != 0 {
+		return true
+	}
+	switch e := ast.Unparen(e).(type) {
+	case *ast.CallExpr:
+		return slices.Contains(safeStringFuncs, calleeName(p.TypesInfo, e))
+	case *ast.BinaryExpr:
+		return e.Op == token.ADD && safeSQLValue(p, e.X) && safeSQLValue(p, e.Y)
+	}
+	return false
+}
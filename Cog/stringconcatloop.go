@@ -0,0 +1,147 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// stringConcatLoopRule reports a string built up by concatenation inside a
+// loop. Each `s += x` copies all of s into a new string, so a loop of n
+// appends allocates O(n²) bytes.
+//
+//	var out string
+//	for _, line := range lines {
+//		out += line + "\n" // copies out on every iteration
+//	}
+//	return out
+//
+// The rule fires when s is declared outside the loop and read after it; a
+// strings.Builder collects the pieces instead. It reports each loop once,
+// at the first concatenation.
+var stringConcatLoopRule = &Rule{
+	ID:       "CogStringConcatLoop",
+	Doc:      "report strings built by concatenation inside loops",
+	Run:      runStringConcatLoop,
+	Category: CategoryPerformance,
+}
+
+func runStringConcatLoop(p *Pass) {
+	type key struct {
+		v    *types.Var
+		loop ast.Node
+	}
+	reported := make(map[key]bool)
+	var reads map[*types.Var][]token.Pos // built on first use
+
+	for c := range p.Inspector.Root().Preorder((*ast.AssignStmt)(nil)) {
+		assign, ok := c.Node().(*ast.AssignStmt)
+		if !ok || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+			continue
+		}
+		v := localVar(p, assign.Lhs[0])
+		if v == nil || !isString(v.Type()) || !concatenates(p, assign, v) {
+			continue
+		}
+		loop := outerLoop(c, v)
+		if loop == nil || reported[key{v, loop}] {
+			continue
+		}
+		if reads == nil {
+			reads = stringReads(p)
+		}
+		if !readAfter(reads[v], loop.End()) && !isNamedResult(p, c, v) {
+			continue
+		}
+		reported[key{v, loop}] = true
+		name := v.Name()
+		p.Report(assign, name+" is built by concatenation inside a loop, which copies the whole string on every "+
+			"iteration (O(n²) allocation); collect the pieces in a strings.Builder with b.WriteString "+
+			"and set "+name+" = b.String() after the loop")
+	}
+}
+
+// isString reports whether t is a string type.
+func isString(t types.Type) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Info()&types.IsString != 0
+}
+
+// concatenates reports whether assign is `v += x` or `v = v + x`.
+func concatenates(p *Pass, assign *ast.AssignStmt, v *types.Var) bool {
+	switch assign.Tok {
+	case token.ADD_ASSIGN:
+		return true
+	case token.ASSIGN:
+		e := ast.Unparen(assign.Rhs[0])
+		for {
+			bin, ok := e.(*ast.BinaryExpr)
+			if !ok || bin.Op != token.ADD {
+				break
+			}
+			e = ast.Unparen(bin.X)
+		}
+		id, ok := e.(*ast.Ident)
+		return ok && e != ast.Unparen(assign.Rhs[0]) && p.TypesInfo.Uses[id] == v
+	}
+	return false
+}
+
+// outerLoop returns the innermost for or range statement around c that v
+// is declared outside of, without leaving the enclosing function.
+func outerLoop(c inspector.Cursor, v *types.Var) ast.Node {
+	for lc := range c.Enclosing((*ast.ForStmt)(nil), (*ast.RangeStmt)(nil), (*ast.FuncLit)(nil), (*ast.FuncDecl)(nil)) {
+		switch loop := lc.Node().(type) {
+		case *ast.ForStmt, *ast.RangeStmt:
+			if v.Pos() < loop.Pos() {
+				return loop
+			}
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// stringReads indexes the positions at which string variables are referred
+// to, reads and writes alike.
+func stringReads(p *Pass) map[*types.Var][]token.Pos {
+	reads := make(map[*types.Var][]token.Pos)
+	for n := range p.Inspector.PreorderSeq((*ast.Ident)(nil)) {
+		id, ok := n.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		if v, ok := p.TypesInfo.Uses[id].(*types.Var); ok && isString(v.Type()) {
+			reads[v] = append(reads[v], id.Pos())
+		}
+	}
+	return reads
+}
+
+// readAfter reports whether any position in reads lies at or after pos.
+func readAfter(reads []token.Pos, pos token.Pos) bool {
+	for _, r := range reads {
+		if r >= pos {
+			return true
+		}
+	}
+	return false
+}
+
+// isNamedResult reports whether v is a named result of the function
+// declaration or literal containing c, which a bare return reads.
+func isNamedResult(p *Pass, c inspector.Cursor, v *types.Var) bool {
+	sig := enclosingSignature(p, c)
+	if sig == nil {
+		return false
+	}
+	for i := range sig.Results().Len() {
+		if sig.Results().At(i) == v {
+			return true
+		}
+	}
+	return false
+}
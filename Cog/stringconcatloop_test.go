@@ -0,0 +1,11 @@
+package cog
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestStringConcatLoop(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), ruleAnalyzer(stringConcatLoopRule), "stringconcatloop")
+}
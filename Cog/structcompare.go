@@ -0,0 +1,147 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// structCompareRule reports == and != between interface values that hold a
+// type that cannot be compared.
+//
+//	type Config struct {
+//		Name string
+//		Tags []string
+//	}
+//
+//	var a, b any = cfg, loadConfig()
+//	if a == b { // panics: comparing uncomparable type Config
+//
+// Comparing two structs with a slice, map or function field does not
+// compile, but once the structs are stored in interface values the
+// compiler no longer knows, and == panics at run time when both hold the
+// same uncomparable type. The rule works on SSA: it fires when one operand
+// of == or != of interface type was made from a struct or array that is not
+// comparable, and the other is not nil and not known to hold a different
+// type. Compare such values with reflect.DeepEqual or an Equal method.
+var structCompareRule = &Rule{
+	ID:       "CogStructCompare",
+	Doc:      "report == on interface values holding uncomparable structs",
+	Run:      runStructCompare,
+	Category: CategoryTypes,
+}
+
+func runStructCompare(p *Pass) {
+	comparisons := make(map[token.Pos]*ast.BinaryExpr)
+	for n := range p.Inspector.PreorderSeq((*ast.BinaryExpr)(nil)) {
+		bin, ok := n.(*ast.BinaryExpr)
+		if ok && (bin.Op == token.EQL || bin.Op == token.NEQ) {
+			comparisons[bin.OpPos] = bin
+		}
+	}
+
+	for _, fn := range p.SSA.SrcFuncs {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				cmp, ok := instr.(*ssa.BinOp)
+				if !ok || cmp.Op != token.EQL && cmp.Op != token.NEQ || !types.IsInterface(cmp.X.Type()) {
+					continue
+				}
+				bin := comparisons[cmp.Pos()]
+				if bin == nil {
+					continue // synthesized comparison, as of a switch case
+				}
+				t, ok := uncomparableOperand(cmp.X, cmp.Y)
+				if !ok {
+					t, ok = uncomparableOperand(cmp.Y, cmp.X)
+				}
+				if !ok {
+					continue
+				}
+				name := types.TypeString(t, types.RelativeTo(p.Pkg))
+				p.Report(bin, types.ExprString(bin)+" compares interface values that may both hold "+name+
+					", which is not comparable ("+uncomparablePart(t, p.Pkg)+"), so it panics at run time; "+
+					"use reflect.DeepEqual or give "+name+" an Equal method")
+			}
+		}
+	}
+}
+
+// uncomparableOperand returns the uncomparable type that x was made from
+// when y may hold the same type: y is neither nil nor known to hold only
+// other types.
+func uncomparableOperand(x, y ssa.Value) (types.Type, bool) {
+	xs, ok := dynamicTypes(x, make(map[ssa.Value]bool))
+	if !ok {
+		return nil, false
+	}
+	for _, t := range xs {
+		if types.Comparable(t) {
+			continue
+		}
+		if c, ok := y.(*ssa.Const); ok && c.IsNil() {
+			return nil, false
+		}
+		ys, known := dynamicTypes(y, make(map[ssa.Value]bool))
+		if !known || containsType(ys, t) {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// dynamicTypes returns the concrete types the interface value v may hold,
+// following phi nodes and interface conversions, and whether they are
+// all known.
+func dynamicTypes(v ssa.Value, seen map[ssa.Value]bool) ([]types.Type, bool) {
+	if seen[v] {
+		return nil, true
+	}
+	seen[v] = true
+
+	switch v := v.(type) {
+	case *ssa.MakeInterface:
+		return []types.Type{v.X.Type()}, true
+	case *ssa.ChangeInterface:
+		return dynamicTypes(v.X, seen)
+	case *ssa.Phi:
+		all := make([]types.Type, 0, len(v.Edges))
+		for _, e := range v.Edges {
+			ts, ok := dynamicTypes(e, seen)
+			if !ok {
+				return nil, false
+			}
+			all = append(all, ts...)
+		}
+		return all, true
+	}
+	return nil, false
+}
+
+// containsType reports whether ts has a type identical to t.
+func containsType(ts []types.Type, t types.Type) bool {
+	for _, u := range ts {
+		if types.Identical(u, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// uncomparablePart describes the part of the uncomparable type t that
+// makes it so, such as "field Tags is []string".
+func uncomparablePart(t types.Type, pkg *types.Package) string {
+	switch u := t.Underlying().(type) {
+	case *types.Struct:
+		for f := range u.Fields() {
+			if !types.Comparable(f.Type()) {
+				return "field " + f.Name() + " is " + types.TypeString(f.Type(), types.RelativeTo(pkg))
+			}
+		}
+	case *types.Array:
+		return "its elements are " + types.TypeString(u.Elem(), types.RelativeTo(pkg))
+	}
+	return "it is " + types.TypeString(t.Underlying(), types.RelativeTo(pkg))
+}
@@ -0,0 +1,12 @@
+package run
+
+import "os"
+
+func Clean() {
+	os.Remove("scratch")
+}
+
+func Split(s string) (head, tail string) {
+	head, tail = s[:1], s[1:]
+	return
+}
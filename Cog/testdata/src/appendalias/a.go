@@ -0,0 +1,35 @@
+package appendalias
+
+import "fmt"
+
+func remove(a []int, i int) {
+	b := append(a[:i], a[i+1:]...) // want `CogAppendAlias: append\(a\[:i\], a\[i\+1:\]\.\.\.\) shifts elements inside a's backing array, so a is modified too and b aliases it; use slices.Delete on a, or build b from a copy`
+	fmt.Println(a, b)
+}
+
+func extend(a []int) {
+	c := append(a, 1) // want `CogAppendAlias: c = append\(a, \.\.\.\) may share a's backing array, so a later append to either can overwrite the other's elements; append to slices.Clone\(a\) or assign the result back to a`
+	a = append(a, 2)
+	fmt.Println(c)
+}
+
+func reassigned(a []int) {
+	c := append(a, 1)
+	a = nil
+	fmt.Println(a, c)
+}
+
+func fullSlice(a []int) {
+	c := append(a[:len(a):len(a)], 1)
+	fmt.Println(a, c)
+}
+
+func sameVar(a []int) {
+	a = append(a, 1)
+	fmt.Println(a)
+}
+
+func sourceUnused(a []int) []int {
+	c := append(a, 1)
+	return c
+}
@@ -0,0 +1,42 @@
+package appendcap
+
+type user struct{ Name string }
+
+func names(users []user) []string {
+	names := make([]string, len(users)) // want `CogAppendCap: names is made with length len\(users\) but only appended to, so it starts with len\(users\) zero values; use make\(\[\]string, 0, len\(users\)\)`
+	for _, u := range users {
+		names = append(names, u.Name)
+	}
+	return names
+}
+
+func squares(n int) []int {
+	var out = make([]int, n) // want `CogAppendCap: out is made with length n but only appended to`
+	for i := range n {
+		out = append(out, i*i)
+	}
+	return out
+}
+
+func indexed(users []user) []string {
+	names := make([]string, len(users))
+	for i, u := range users {
+		names[i] = u.Name
+	}
+	return names
+}
+
+func copied(src []int) []int {
+	dst := make([]int, len(src))
+	copy(dst, src)
+	dst = append(dst, 0)
+	return dst
+}
+
+func capacity(users []user) []string {
+	names := make([]string, 0, len(users))
+	for _, u := range users {
+		names = append(names, u.Name)
+	}
+	return names
+}
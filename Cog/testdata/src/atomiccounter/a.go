@@ -0,0 +1,91 @@
+package atomiccounter
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+func run(job int) {}
+
+func loop(jobs []int) int {
+	var wg sync.WaitGroup
+	var processed int
+	for _, job := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			run(job)
+			processed++ // want `CogAtomicCounter: processed is updated by the goroutine started on line 15 and shared by every goroutine the loop starts, with no mutex or atomic; concurrent updates race and lose counts, so make it an atomic.Int64 and call processed.Add, or use atomic.AddInt64`
+		}()
+	}
+	wg.Wait()
+	return processed
+}
+
+func two() int {
+	var wg sync.WaitGroup
+	total := 0
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		total += 2 // want `CogAtomicCounter: total is updated by the goroutine started on line 29 and shared by 2 goroutines`
+	}()
+	go func() {
+		defer wg.Done()
+		total-- // want `CogAtomicCounter: total is updated by the goroutine started on line 33 and shared by 2 goroutines`
+	}()
+	wg.Wait()
+	return total
+}
+
+func single() int {
+	done := make(chan struct{})
+	n := 0
+	go func() {
+		n++
+		close(done)
+	}()
+	<-done
+	return n
+}
+
+func perIteration(jobs []int) {
+	for range jobs {
+		count := 0
+		go func() {
+			count++
+		}()
+	}
+}
+
+func atomicAdd(jobs []int) int64 {
+	var wg sync.WaitGroup
+	var processed int64
+	for range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			atomic.AddInt64(&processed, 1)
+			processed++
+		}()
+	}
+	wg.Wait()
+	return processed
+}
+
+func locked(jobs []int) int {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	processed := 0
+	for range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			mu.Lock()
+			processed++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return processed
+}
@@ -0,0 +1,54 @@
+package baregoroutine
+
+import (
+	"context"
+	"sync"
+)
+
+type request struct{ id int }
+
+type auditor struct{ n int }
+
+func (a *auditor) audit(req request) { a.n += req.id }
+
+func handle(a *auditor, req request) {
+	go a.audit(req) // want `CogBareGoroutine \(warning\): goroutine is started with no WaitGroup, errgroup, channel or context to wait for or stop it, so it leaks or is cut off on shutdown; track it with a sync.WaitGroup or pass it a context`
+
+	go func() { // want `CogBareGoroutine \(warning\): goroutine is started with no WaitGroup`
+		a.audit(req)
+	}()
+}
+
+type server struct {
+	wg sync.WaitGroup
+	n  int
+}
+
+func (s *server) work() { s.n++ }
+
+func governedByField(s *server) {
+	go s.work()
+}
+
+func governedByWaitGroup(a *auditor, req request) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		a.audit(req)
+	}()
+	wg.Wait()
+}
+
+func governedByContext(ctx context.Context, a *auditor) {
+	go func() {
+		<-ctx.Done()
+		a.n = 0
+	}()
+}
+
+func governedByChannel(done chan struct{}) {
+	go func() {
+		close(done)
+	}()
+}
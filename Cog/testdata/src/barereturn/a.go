@@ -0,0 +1,37 @@
+package barereturn
+
+import "errors"
+
+func calculate(x int) (result int, err error) {
+	if x < 0 {
+		err = errors.New("negative input")
+		return // want "CogBareReturn: bare return in a function with named results hides what is returned; write `return result, err`"
+	}
+	result = x * 2
+	return // want "CogBareReturn: bare return in a function with named results hides what is returned; write `return result, err`"
+}
+
+func blank(x int) (_ int, err error) {
+	err = errors.New("unused")
+	return // want `CogBareReturn: bare return in a function with named results hides what is returned; list the results explicitly`
+}
+
+func explicit(x int) (result int, err error) {
+	return x, nil
+}
+
+func unnamed(x int) (int, error) {
+	return x, nil
+}
+
+func noResults() {
+	return
+}
+
+func closure() (n int) {
+	f := func() {
+		return
+	}
+	f()
+	return n
+}
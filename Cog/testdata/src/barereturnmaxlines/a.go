@@ -0,0 +1,14 @@
+package barereturnmaxlines
+
+func short(x int) (n int) {
+	n = x
+	return
+}
+
+func long(x int) (n int) {
+	if x > 0 {
+		n = x
+	}
+	n *= 2
+	return // want `CogBareReturn: bare return in a function with named results hides what is returned`
+}
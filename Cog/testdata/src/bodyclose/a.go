@@ -0,0 +1,43 @@
+package bodyclose
+
+import (
+	"io"
+	"net/http"
+)
+
+func leak(url string) ([]byte, error) {
+	resp, err := http.Get(url) // want "CogBodyClose: body of response from http.Get is not closed on the path to line 13; add `defer resp.Body.Close\\(\\)` after checking the error"
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func discarded(url string) {
+	http.Get(url) // want `CogBodyClose: response from http.Get is discarded, so its body is never closed`
+}
+
+func deferred(url string) ([]byte, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+func returned(url string) (*http.Response, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func nilChecked(url string) error {
+	resp, _ := http.Get(url)
+	if resp == nil {
+		return nil
+	}
+	return resp.Body.Close()
+}
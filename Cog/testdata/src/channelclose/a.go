@@ -0,0 +1,65 @@
+package channelclose
+
+func run(int) {}
+
+func receiverCloses(n int) {
+	jobs := make(chan int)
+	go func() {
+		for job := range jobs {
+			run(job)
+		}
+		close(jobs) // want `CogChannelClose: close\(jobs\) runs in a goroutine that only receives from jobs, while jobs is sent on at line 14; only the sender should close a channel, since a send on a closed channel panics, so close jobs where the sends end and let receivers stop when it is drained`
+	}()
+	for i := range n {
+		jobs <- i
+	}
+}
+
+func twice(fail bool) {
+	done := make(chan struct{})
+	if fail {
+		close(done)
+	}
+	close(done) // want `CogChannelClose: channel done may be closed twice: this close can run after the close on line 21, and closing a closed channel panics; close done exactly once, in the goroutine that sends on it, or guard the close with a sync.Once`
+}
+
+func inLoop(n int) {
+	done := make(chan struct{})
+	for range n {
+		close(done) // want `CogChannelClose: channel done may be closed twice: this close can run after itself, through a loop`
+	}
+}
+
+func senderCloses(n int) {
+	jobs := make(chan int)
+	go func() {
+		defer close(jobs)
+		for i := range n {
+			jobs <- i
+		}
+	}()
+	for job := range jobs {
+		run(job)
+	}
+}
+
+func eitherBranch(fail bool) {
+	done := make(chan struct{})
+	if fail {
+		close(done)
+		return
+	}
+	close(done)
+}
+
+func escapes(n int) {
+	jobs := make(chan int)
+	go consume(jobs)
+	close(jobs)
+	close(jobs)
+}
+
+func consume(ch chan int) {
+	for range ch {
+	}
+}
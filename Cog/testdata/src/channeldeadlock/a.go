@@ -0,0 +1,45 @@
+package channeldeadlock
+
+import "fmt"
+
+func compute() int { return 42 }
+
+func sendFirst() {
+	results := make(chan int)
+	results <- compute() // want `CogChannelDeadlock: send on unbuffered channel results blocks forever: no other goroutine can receive from results yet, since it has not been passed to one; start the receiving goroutine before sending, or give the channel a buffer`
+	go func() { fmt.Println(<-results) }()
+}
+
+func zeroBuffer() {
+	var done = make(chan struct{}, 0)
+	done <- struct{}{} // want `CogChannelDeadlock: send on unbuffered channel done blocks forever`
+	close(done)
+}
+
+func receiverFirst() {
+	results := make(chan int)
+	go func() { fmt.Println(<-results) }()
+	results <- compute()
+}
+
+func buffered() {
+	results := make(chan int, 1)
+	results <- compute()
+	fmt.Println(<-results)
+}
+
+func passed() {
+	results := make(chan int)
+	go consume(results)
+	results <- compute()
+}
+
+func consume(ch chan int) { fmt.Println(<-ch) }
+
+func selected() {
+	results := make(chan int)
+	select {
+	case results <- compute():
+	default:
+	}
+}
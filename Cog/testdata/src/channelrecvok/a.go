@@ -0,0 +1,90 @@
+package channelrecvok
+
+type Job struct{ ID int }
+
+func run(Job) {}
+
+func worker(jobs <-chan Job) {
+	for {
+		job := <-jobs // want `CogChannelRecvOk: receive from jobs in an endless loop does not check whether jobs is closed; once it is, every receive returns the zero value and the loop spins; use for range jobs, or v, ok := <-jobs and stop when !ok`
+		run(job)
+	}
+}
+
+func later(jobs <-chan Job) {
+	var job Job
+	for {
+		run(job)
+		job = <-jobs // want `CogChannelRecvOk: receive from jobs in an endless loop`
+	}
+}
+
+func checked(jobs <-chan Job) {
+	for {
+		job, ok := <-jobs
+		if !ok {
+			return
+		}
+		run(job)
+	}
+}
+
+func sentinel(jobs <-chan Job) {
+	for {
+		job := <-jobs
+		if job.ID == 0 {
+			break
+		}
+		run(job)
+	}
+}
+
+func ranged(jobs <-chan Job) {
+	for job := range jobs {
+		run(job)
+	}
+}
+
+func ticks(tick <-chan int) {
+	for {
+		<-tick
+		run(Job{})
+	}
+}
+
+func blank(tick <-chan int) {
+	for {
+		_ = <-tick
+		run(Job{})
+	}
+}
+
+func signals(done <-chan struct{}) {
+	for {
+		v := <-done
+		_ = v
+		run(Job{})
+	}
+}
+
+type server struct {
+	requests chan Job
+}
+
+func (s *server) serve() {
+	for {
+		req := <-s.requests // want `CogChannelRecvOk: receive from s.requests in an endless loop`
+		run(req)
+	}
+}
+
+func (s *server) stop() {
+	close(s.requests)
+}
+
+func neverClosed(jobs chan Job) {
+	for {
+		job := <-jobs
+		run(job)
+	}
+}
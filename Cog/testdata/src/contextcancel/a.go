@@ -0,0 +1,53 @@
+package contextcancel
+
+import (
+	"context"
+	"time"
+)
+
+func ping(ctx context.Context) error { return ctx.Err() }
+
+func discarded(ctx context.Context) error {
+	ctx, _ = context.WithTimeout(ctx, time.Second) // want "CogContextCancel: the cancel function returned by context.WithTimeout is discarded, so the context leaks until its parent is done; assign it and `defer cancel\\(\\)`"
+	return ping(ctx)
+}
+
+func missing(ctx context.Context) error {
+	ctx, cancel := context.WithCancelCause(ctx) // want "CogContextCancel: the cancel function returned by context.WithCancelCause is not called on the path to line 18, so the context leaks until its parent is done; add `defer cancel\\(nil\\)` after the call"
+	_ = cancel == nil
+	return ping(ctx)
+}
+
+func late(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second) // want "CogContextCancel: the cancel function returned by context.WithTimeout is not called on the path to line 24, so the context leaks until its parent is done; move the `defer cancel\\(...\\)` on line 26 up to right after the call"
+	if err := ping(ctx); err != nil {
+		return err
+	}
+	defer cancel()
+	return nil
+}
+
+func deferred(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	return ping(ctx)
+}
+
+func returned(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	return ctx, cancel
+}
+
+type job struct {
+	cancel context.CancelFunc
+}
+
+func stored(ctx context.Context, j *job) context.Context {
+	ctx, j.cancel = context.WithCancel(ctx)
+	return ctx
+}
+
+func discardedDefine(ctx context.Context) error {
+	child, _ := context.WithCancel(ctx) // want `CogContextCancel: the cancel function returned by context.WithCancel is discarded`
+	return ping(child)
+}
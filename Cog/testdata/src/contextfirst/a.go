@@ -0,0 +1,28 @@
+package contextfirst
+
+import (
+	"context"
+	"net/http"
+)
+
+func fetch(url string, ctx context.Context) error { // want `CogContextFirst: ctx context.Context is parameter 2 of fetch; by convention the context comes first`
+	_ = url
+	return ctx.Err()
+}
+
+func run() error {
+	return fetch("https://example.com", context.Background())
+}
+
+func Exported(n int, ctx context.Context) { // want `CogContextFirst: ctx context.Context is parameter 2 of Exported; by convention the context comes first`
+	_, _ = n, ctx
+}
+
+func first(ctx context.Context, url string) error {
+	_ = url
+	return ctx.Err()
+}
+
+func blocking(url string) {
+	http.Get(url)
+}
@@ -0,0 +1,30 @@
+package contextfirstmissing
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+func fetch(url string) (*http.Response, error) { // want `CogContextFirst: fetch calls http.Get, which can block, but takes no context.Context; accept ctx as the first parameter and pass it on`
+	return http.Get(url)
+}
+
+func pause() { // want `CogContextFirst: pause calls time.Sleep, which can block, but takes no context.Context`
+	time.Sleep(time.Second)
+}
+
+func withContext(ctx context.Context) {
+	_ = ctx
+	time.Sleep(time.Second)
+}
+
+func handler(w http.ResponseWriter, r *http.Request) {
+	time.Sleep(time.Second)
+}
+
+func init() {
+	time.Sleep(time.Millisecond)
+}
+
+func quick(n int) int { return n * 2 }
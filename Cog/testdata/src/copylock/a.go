@@ -0,0 +1,66 @@
+package copylock
+
+import (
+	"sync"
+	"unsafe"
+)
+
+type Counter struct {
+	sync.Mutex
+	n int
+}
+
+func (c Counter) Value() int { // want `CogCopyLock: receiver c is passed by value, whose field Mutex is a sync.Mutex; use a pointer`
+	return c.n
+}
+
+func (c *Counter) Inc() {
+	c.Lock()
+	c.n++
+	c.Unlock()
+}
+
+type Registry struct {
+	mu    sync.RWMutex
+	items [4]Counter
+}
+
+func snapshot(r *Registry) Registry {
+	saved := *r  // want `CogCopyLock: assignment copies \*r, whose field mu is a sync.RWMutex; share it through a pointer`
+	return saved // want `CogCopyLock: return copies saved, whose field mu is a sync.RWMutex; share it through a pointer`
+}
+
+func use(Counter) {} // want `CogCopyLock: parameter Counter is passed by value, whose field Mutex is a sync.Mutex; use a pointer`
+
+func pass(c *Counter) {
+	use(*c) // want `CogCopyLock: call of use copies \*c, whose field Mutex is a sync.Mutex; share it through a pointer`
+}
+
+func total(r *Registry) int {
+	n := 0
+	for _, c := range r.items { // want `CogCopyLock: range value c copies each element, whose field Mutex is a sync.Mutex; range over indexes or pointers instead`
+		n += c.n
+	}
+	return n
+}
+
+func fresh() Counter {
+	return Counter{}
+}
+
+func shared(r *Registry) *Registry {
+	p := r
+	return p
+}
+
+func sizes(r *Registry) (int, int, uintptr, uintptr, uintptr) {
+	return len(r.items), cap(r.items), unsafe.Sizeof(*r), unsafe.Alignof(r.mu), unsafe.Offsetof(r.items)
+}
+
+func byIndex(r *Registry) int {
+	n := 0
+	for i := range r.items {
+		n += r.items[i].n
+	}
+	return n
+}
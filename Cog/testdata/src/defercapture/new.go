@@ -0,0 +1,12 @@
+//go:build go1.22
+
+package defercapture
+
+// perIteration targets Go 1.22, where each iteration has its own i.
+func perIteration(n int) {
+	for i := 0; i < n; i++ {
+		defer func() {
+			release(i)
+		}()
+	}
+}
@@ -0,0 +1,35 @@
+//go:build go1.21
+
+package defercapture
+
+func release(int) {}
+
+func releaseAll(n int) {
+	for i := 0; i < n; i++ {
+		defer func() { // want "CogDeferCapture: deferred closure reads loop variable i when the function returns, after the loop has ended; before Go 1.22 every deferred call sees the last value, so pass it as an argument: `defer func\\(i int\\) { ... }\\(i\\)`"
+			release(i)
+		}()
+	}
+}
+
+func pairs(m map[string]int) {
+	for k, v := range m {
+		defer func() { // want `CogDeferCapture: deferred closure reads loop variable k, v when the function returns`
+			println(k, v)
+		}()
+	}
+}
+
+func passed(n int) {
+	for i := 0; i < n; i++ {
+		defer func(i int) {
+			release(i)
+		}(i)
+	}
+}
+
+func direct(n int) {
+	for i := 0; i < n; i++ {
+		defer release(i)
+	}
+}
@@ -0,0 +1,54 @@
+package defercloseerror
+
+import "os"
+
+func write(path string, data []byte) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close() // want "CogDeferCloseError: defer f.Close\\(\\) drops the error of closing f, opened for writing by os.Create on line 6, so a failed write can go unnoticed; return it through a named error result: `defer func\\(\\) { if cerr := f.Close\\(\\); cerr != nil && err == nil { err = cerr } }\\(\\)`"
+	_, err = f.Write(data)
+	return err
+}
+
+func appendLog(path string, line string) (n int, err error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close() // want `CogDeferCloseError: defer f.Close\(\) drops the error of closing f, opened for writing by os.OpenFile on line 16`
+	return f.WriteString(line)
+}
+
+func noError(path string) {
+	f, err := os.Create(path)
+	if err != nil {
+		return
+	}
+	defer f.Close() // want `CogDeferCloseError: defer f.Close\(\) drops the error of closing f`
+	f.WriteString("x")
+}
+
+func read(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	buf := make([]byte, 10)
+	n, err := f.Read(buf)
+	return buf[:n], err
+}
+
+func checked(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.WriteString("x"); err != nil {
+		return err
+	}
+	return f.Close()
+}
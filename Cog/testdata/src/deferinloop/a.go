@@ -0,0 +1,62 @@
+package deferinloop
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+)
+
+func files(paths []string) error {
+	for _, p := range paths {
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close() // want `CogDeferInLoop: defer f.Close\(\) in a loop runs only when the function returns, so every iteration's resource is held until then; move the loop body into a helper function, or call f.Close\(\) at the end of each iteration`
+	}
+	return nil
+}
+
+func locks(mu *sync.Mutex, n int) {
+	for i := 0; i < n; i++ {
+		mu.Lock()
+		defer func() { // want `CogDeferInLoop: defer mu.Unlock\(\) in a loop`
+			mu.Unlock()
+		}()
+	}
+}
+
+func contexts(ctx context.Context, n int) {
+	for range n {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel() // want `CogDeferInLoop: defer cancel\(\) in a loop`
+		_ = ctx
+	}
+}
+
+func helper(p string) error {
+	f, err := os.Open(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return nil
+}
+
+func perIteration(paths []string) error {
+	for _, p := range paths {
+		if err := helper(p); err != nil {
+			return err
+		}
+		func() {
+			f, err := os.Open(p)
+			if err != nil {
+				return
+			}
+			defer f.Close()
+		}()
+		defer fmt.Println(p)
+	}
+	return nil
+}
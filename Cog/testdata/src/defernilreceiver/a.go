@@ -0,0 +1,83 @@
+package defernilreceiver
+
+import (
+	"io"
+	"net/http"
+	"os"
+)
+
+func open(name string) error {
+	f, err := os.Open(name)
+	defer f.Close() // want `CogDeferNilReceiver: defer f.Close\(\) runs on f before err from os.Open on line 10 is checked; when os.Open fails f is nil and the defer calls Close on a nil receiver; check err first, then defer`
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func get(url string) error {
+	resp, err := http.Get(url)
+	defer resp.Body.Close() // want `CogDeferNilReceiver: defer resp.Body.Close\(\) runs on resp before err from http.Get on line 19 is checked; when http.Get fails resp is nil and the deferred call panics; check err first, then defer`
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func reader(name string) (io.ReadCloser, error) {
+	return os.Open(name)
+}
+
+func openCloser(name string) error {
+	rc, err := reader(name)
+	defer rc.Close() // want `CogDeferNilReceiver: defer rc.Close\(\) runs on rc before err from reader on line 32 is checked; when reader fails rc is nil and the deferred call panics; check err first, then defer`
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+type conn struct{ id int }
+
+func (c conn) Release() {}
+
+func dial() (*conn, error) {
+	return &conn{}, nil
+}
+
+func valueMethod() error {
+	c, err := dial()
+	defer c.Release() // want `CogDeferNilReceiver: defer c.Release\(\) runs on c before err from dial on line 49 is checked; when dial fails c is nil and the deferred call panics; check err first, then defer`
+	return err
+}
+
+func checked(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return nil
+}
+
+func bareReturn(name string) {
+	f, err := os.Open(name)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+}
+
+func nilReturn(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	return nil
+}
+
+func noError() {
+	c := &conn{}
+	defer c.Release()
+}
@@ -0,0 +1,53 @@
+package errorsis
+
+import (
+	"io"
+)
+
+type notFound struct{}
+
+func (*notFound) Error() string { return "not found" }
+
+func isEOF(err error) bool {
+	return err == io.EOF // want `CogErrorsIs: err == io.EOF misses wrapped errors; use errors.Is`
+}
+
+func notEOF(err error) bool {
+	return io.EOF != err // want `CogErrorsIs: err != io.EOF misses wrapped errors; use errors.Is`
+}
+
+func assert(err error) bool {
+	_, ok := err.(*notFound) // want `CogErrorsIs: type assertion err.\(\*notFound\) misses wrapped errors; use errors.As\(err, &target\) with a target of type \*notFound`
+	return ok
+}
+
+func typeSwitch(err error) int {
+	switch err.(type) { // want `CogErrorsIs: type switch on err misses wrapped errors; use errors.As for each type`
+	case *notFound:
+		return 1
+	}
+	return 0
+}
+
+func tagSwitch(err error) int {
+	switch err {
+	case io.EOF: // want "CogErrorsIs: switch on err compares with ==, which misses wrapped errors; use `case errors.Is\\(err, io.EOF\\):` in a tagless switch"
+		return 1
+	}
+	return 0
+}
+
+func isNil(err error) bool {
+	return err == nil
+}
+
+type timeout struct{}
+
+func (timeout) Error() string { return "timeout" }
+
+func (timeout) Is(target error) bool { return target == io.ErrNoProgress }
+
+func toError(err error) error {
+	e, _ := err.(error)
+	return e
+}
@@ -0,0 +1,21 @@
+package errorstring
+
+import (
+	"errors"
+	"fmt"
+)
+
+var errBoth = errors.New("Invalid token.") // want `CogErrorString \(warning\): error string "Invalid token." starts with a capital letter and ends with punctuation; callers wrap error strings in longer messages, so keep them lowercase and unpunctuated`
+
+var errCapital = errors.New("Missing field") // want `CogErrorString \(warning\): error string "Missing field" starts with a capital letter`
+
+func parse(n int) error {
+	return fmt.Errorf("bad value %d!", n) // want `CogErrorString \(warning\): error string "bad value %d!" ends with punctuation`
+}
+
+var (
+	errAcronym = errors.New("HTTP request failed")
+	errIdent   = errors.New("ParseConfig failed")
+	errGood    = errors.New("invalid token")
+	errDots    = errors.New("...")
+)
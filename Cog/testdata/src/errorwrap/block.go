@@ -0,0 +1,33 @@
+package errorwrap
+
+import (
+	"errors"
+	"os"
+	"strconv"
+)
+
+var errEmpty = errors.New("empty")
+
+func ReadConfig(name string) ([]byte, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err // want `CogErrorWrap: err from os.ReadFile is returned without context`
+	}
+	if len(data) == 0 {
+		return nil, errEmpty
+	}
+	return data, nil
+}
+
+func Port(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, err // want `CogErrorWrap: err from strconv.Atoi is returned without context`
+	}
+	return n, nil
+}
+
+func Minted() error {
+	err := errors.New("minted")
+	return err
+}
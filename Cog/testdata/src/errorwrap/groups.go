@@ -0,0 +1,15 @@
+package errorwrap
+
+import (
+	"os"
+
+	"example.com/store"
+)
+
+func Open(name string) (*store.DB, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err // want `CogErrorWrap: err from os.Open is returned without context`
+	}
+	return store.New(f), nil
+}
@@ -0,0 +1,8 @@
+package errorwrap
+
+func load() error { return nil }
+
+func Load() error {
+	err := load()
+	return err // want `CogErrorWrap: err from load is returned without context`
+}
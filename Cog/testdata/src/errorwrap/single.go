@@ -0,0 +1,8 @@
+package errorwrap
+
+import "os"
+
+func Remove(name string) error {
+	err := os.Remove(name)
+	return err // want `CogErrorWrap: err from os.Remove is returned without context`
+}
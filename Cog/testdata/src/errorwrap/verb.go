@@ -0,0 +1,16 @@
+package errorwrap
+
+import (
+	"fmt"
+	"os"
+)
+
+func Chdir(dir string) error {
+	if err := os.Chdir(dir); err != nil {
+		return fmt.Errorf("chdir %s: %v", dir, err) // want `CogErrorWrap: fmt.Errorf formats error err with %v`
+	}
+	if err := os.Chdir(".."); err != nil {
+		return fmt.Errorf("chdir ..: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package errshadow
+
+import "strconv"
+
+func use(int) {}
+
+func stale(cond bool, s string) error {
+	var err error
+	if cond {
+		x, err := strconv.Atoi(s) // want `CogErrShadow: err declared here shadows the err declared on line 8; the read on line 15 sees the outer err, not this one; assign with = instead of :=`
+		if err == nil {
+			use(x)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func reassigned(cond bool, s string) error {
+	var err error
+	if cond {
+		x, err := strconv.Atoi(s)
+		if err != nil {
+			return err
+		}
+		use(x)
+	}
+	err = nil
+	return err
+}
+
+func ifInit(s string) error {
+	_, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	if v, err := strconv.Atoi(s); err != nil {
+		return err
+	} else {
+		use(v)
+	}
+	return nil
+}
+
+func closure(s string) error {
+	var err error
+	f := func() {
+		_, err := strconv.Atoi(s)
+		_ = err
+	}
+	f()
+	return err
+}
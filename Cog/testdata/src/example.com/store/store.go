@@ -0,0 +1,9 @@
+package store
+
+import "os"
+
+// A DB is a store backed by a file.
+type DB struct{ f *os.File }
+
+// New returns the store in f.
+func New(f *os.File) *DB { return &DB{f: f} }
@@ -0,0 +1,30 @@
+package floatequality
+
+import "math"
+
+type celsius float64
+
+func compare(a, b float64, c, d float32, t, u celsius, n int) {
+	_ = a == b   // want `CogFloatEquality: exact comparison of floating-point values a == b is sensitive to rounding`
+	_ = c != d   // want `CogFloatEquality: exact comparison of floating-point values c != d is sensitive to rounding`
+	_ = t == u   // want `CogFloatEquality: exact comparison of floating-point values t == u is sensitive to rounding`
+	_ = a == 0.3 // want `CogFloatEquality: exact comparison of floating-point values a == 0.3 is sensitive to rounding`
+
+	_ = a == 0 // zero is exempt by default
+	_ = 0.0 != c
+	_ = n == 3         // not floats
+	_ = 0.1+0.2 == 0.3 // constant, folded at compile time
+}
+
+func nan(a float64, c float32) {
+	_ = a != a                       // want `CogFloatEquality: a != a is a NaN test; write math.IsNaN\(a\)`
+	_ = a == a                       // want `CogFloatEquality: a == a is true unless a is NaN; write !math.IsNaN\(a\)`
+	_ = c != c                       // want `CogFloatEquality: c != c is a NaN test`
+	_ = a == math.NaN()              // want `CogFloatEquality: comparison with math.NaN\(\) is always false`
+	_ = math.NaN() != a              // want `CogFloatEquality: comparison with math.NaN\(\) is always true`
+	_ = math.Sqrt(a) != math.Sqrt(a) // want `CogFloatEquality: exact comparison of floating-point values`
+}
+
+func ignored(a, b float64) bool {
+	return a == b //cog:ignore CogFloatEquality -- exact by design
+}
@@ -0,0 +1,5 @@
+package floatequality
+
+func selfCompare(x float64) bool {
+	return x != x // want `CogFloatEquality: x != x is a NaN test`
+}
@@ -0,0 +1,7 @@
+package floatequalityzero
+
+func zero(a float64, c float32) {
+	_ = a == 0   // want `CogFloatEquality: exact comparison of floating-point values a == 0 is sensitive to rounding`
+	_ = 0.0 != c // want `CogFloatEquality: exact comparison of floating-point values 0.0 != c`
+	_ = a == 1   // want `CogFloatEquality: exact comparison of floating-point values a == 1`
+}
@@ -0,0 +1,29 @@
+package httpnotimeouts
+
+import (
+	"net/http"
+)
+
+func serve(mux *http.ServeMux) error {
+	return http.ListenAndServe(":8080", mux) // want `CogHTTPNoTimeouts: http.ListenAndServe serves with no timeouts, so slow clients can hold connections open forever; start an http.Server with ReadTimeout, WriteTimeout, IdleTimeout set and call its ListenAndServe method`
+}
+
+func serveTLS() error {
+	return http.ListenAndServeTLS(":8443", "cert.pem", "key.pem", nil) // want `CogHTTPNoTimeouts: http.ListenAndServeTLS serves with no timeouts`
+}
+
+func partial(mux *http.ServeMux) *http.Server {
+	return &http.Server{Addr: ":8080", Handler: mux, ReadTimeout: 1} // want `CogHTTPNoTimeouts: http.Server leaves WriteTimeout, IdleTimeout unset, so slow clients can hold connections open forever; set explicit timeouts`
+}
+
+func assignedLater(mux *http.ServeMux) *http.Server {
+	srv := &http.Server{Addr: ":8080", Handler: mux}
+	srv.ReadTimeout = 1
+	srv.WriteTimeout = 1
+	srv.IdleTimeout = 1
+	return srv
+}
+
+func complete(mux *http.ServeMux) *http.Server {
+	return &http.Server{Addr: ":8080", Handler: mux, ReadTimeout: 1, WriteTimeout: 1, IdleTimeout: 1}
+}
@@ -0,0 +1,63 @@
+package ignorederror
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+func remove(name string) error {
+	os.Remove(name) // want `CogIgnoredError: error returned by os.Remove is discarded; handle it with`
+	return nil
+}
+
+func decode(data []byte) (map[string]int, error) {
+	var m map[string]int
+	_ = json.Unmarshal(data, &m) // want `CogIgnoredError: error returned by json.Unmarshal is assigned to _; handle it with`
+	return m, nil
+}
+
+func read(name string) ([]byte, error) {
+	data, _ := os.ReadFile(name) // want `CogIgnoredError: error returned by os.ReadFile is assigned to _; bind it and check`
+	return data, nil
+}
+
+func cleanup(name string) {
+	os.Remove(name) // want `CogIgnoredError: error returned by os.Remove is discarded`
+}
+
+func declared(name string) []byte {
+	var data, _ = os.ReadFile(name) // want `CogIgnoredError: error returned by os.ReadFile is assigned to _`
+	return data
+}
+
+func allowed() {
+	fmt.Println("allowed")
+}
+
+func inMemory(n int) string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%d items\n", n)
+	var sb strings.Builder
+	fmt.Fprintln(&sb, buf.String())
+	return sb.String()
+}
+
+func toWriter(w io.Writer) error {
+	fmt.Fprintln(w, "not in memory") // want `CogIgnoredError: error returned by fmt.Fprintln is discarded`
+	return nil
+}
+
+func annotated(name string) {
+	os.Remove(name) // IGNORE: best effort, the file may not exist
+}
+
+func handled(name string) error {
+	if err := os.Remove(name); err != nil {
+		return fmt.Errorf("remove: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package importmerge
+
+import (
+	"errors"
+	"os"
+)
+
+var errEmpty = errors.New("empty")
+
+func isNaN(x float64) bool {
+	return x != x // want `CogFloatEquality: x != x is a NaN test`
+}
+
+func size(name string) (int64, error) {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return 0, err // want `CogErrorWrap: err from os.Stat is returned without context`
+	}
+	if fi.Size() == 0 {
+		return 0, errEmpty
+	}
+	return fi.Size(), nil
+}
@@ -0,0 +1,39 @@
+package indexbounds
+
+import (
+	"bytes"
+	"strings"
+)
+
+func port(addr string) string {
+	parts := strings.Split(addr, ":")
+	return parts[0] + parts[1] + parts[2] // want "CogIndexBounds: parts\\[1\\] assumes strings.Split returned at least 3 elements, and panics on input with fewer; check `if len\\(parts\\) < 3` first"
+}
+
+func direct(addr string) string {
+	return strings.Split(addr, ":")[1] // want `CogIndexBounds: strings.Split\(addr, ":"\)\[1\] panics when strings.Split returns fewer than 2 elements; assign the result and check its length first, or use strings.Cut for a single separator`
+}
+
+func fields(line []byte) []byte {
+	return bytes.Fields(line)[2] // want `CogIndexBounds: bytes.Fields\(line\)\[2\] panics when bytes.Fields returns fewer than 3 elements; assign the result and check its length first$`
+}
+
+func checked(addr string) string {
+	parts := strings.Split(addr, ":")
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[1]
+}
+
+func first(addr string) string {
+	return strings.Split(addr, ":")[0]
+}
+
+func reassigned(addr string) string {
+	parts := strings.Split(addr, ":")
+	if addr == "" {
+		parts = []string{"", ""}
+	}
+	return parts[1]
+}
@@ -0,0 +1,29 @@
+package intdivfloat
+
+import "time"
+
+func average(sum, count int) float64 {
+	return float64(sum / count) // want `CogIntDivFloat: float64\(sum / count\) converts the result of an integer division, which has already been truncated; convert the operands first: float64\(sum\) / float64\(count\)`
+}
+
+func half(n int) float64 {
+	return 1 + float64(n/2) // want `CogIntDivFloat: float64\(n / 2\) converts the result of an integer division`
+}
+
+func ratio(a, b int64) float32 {
+	return -float32(a / b) // want `CogIntDivFloat: float32\(a / b\) converts`
+}
+
+func operands(sum, count int) float64 {
+	return float64(sum) / float64(count)
+}
+
+func floats(x, y float64) float64 {
+	return float64(x / y)
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d / time.Millisecond)
+}
+
+const third = float64(10 / 3)
@@ -0,0 +1,38 @@
+package interfaceassert
+
+type Plugin interface{ Start() error }
+
+type Stopper interface{ Stop() }
+
+type Exporter struct{} // want `CogInterfaceAssert \(warning\): Exporter implements Plugin, which the package checks only at run time; add var _ Plugin = \(\*Exporter\)\(nil\) so that a drifting method fails to compile`
+
+func (e *Exporter) Start() error { return nil }
+
+type Importer struct{}
+
+var _ Plugin = (*Importer)(nil)
+
+func (i *Importer) Start() error { return nil }
+
+type Closer struct{} // want `CogInterfaceAssert \(warning\): Closer implements Stopper`
+
+func (Closer) Stop() {}
+
+type internal struct{}
+
+func (internal) Start() error { return nil }
+
+type Unrelated struct{}
+
+func (Unrelated) Run() {}
+
+func start(v any) error {
+	if p, ok := v.(Plugin); ok {
+		return p.Start()
+	}
+	switch s := v.(type) {
+	case Stopper:
+		s.Stop()
+	}
+	return nil
+}
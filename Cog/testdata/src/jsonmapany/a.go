@@ -0,0 +1,43 @@
+package jsonmapany
+
+import (
+	"encoding/json"
+	"io"
+)
+
+type config struct {
+	Port int `json:"port"`
+}
+
+func load(data []byte) (float64, error) {
+	var cfg map[string]any
+	if err := json.Unmarshal(data, &cfg); err != nil { // want `CogJSONMapAny \(warning\): JSON is decoded into cfg, a map\[string\]any, so every value must be type-asserted and a wrong key or type only fails at run time; decode into a struct with json tags for the expected fields, or suppress this with //cog:ignore if the shape is truly dynamic`
+		return 0, err
+	}
+	port, _ := cfg["port"].(float64)
+	return port, nil
+}
+
+func decode(r io.Reader) (map[string]interface{}, error) {
+	m := new(map[string]interface{})
+	err := json.NewDecoder(r).Decode(m) // want `CogJSONMapAny \(warning\): JSON is decoded into m, a map\[string\]any`
+	return *m, err
+}
+
+func typed(data []byte) (config, error) {
+	var cfg config
+	err := json.Unmarshal(data, &cfg)
+	return cfg, err
+}
+
+func strings(data []byte) (map[string]string, error) {
+	var m map[string]string
+	err := json.Unmarshal(data, &m)
+	return m, err
+}
+
+func dynamic(data []byte) (map[string]any, error) {
+	var m map[string]any
+	err := json.Unmarshal(data, &m) //cog:ignore CogJSONMapAny the payload is user-defined
+	return m, err
+}
@@ -0,0 +1,53 @@
+package jsontrailing
+
+import (
+	"encoding/json"
+	"io"
+)
+
+type request struct{ ID int }
+
+func inline(r io.Reader) (request, error) {
+	var req request
+	err := json.NewDecoder(r).Decode(&req) // want `CogJSONTrailing \(warning\): Decode reads one JSON value and silently ignores any data after it; keep the decoder in dec and check that dec.More\(\) is false or that dec.Token\(\) returns io.EOF afterwards, or json.Unmarshal the whole input`
+	return req, err
+}
+
+func configured(r io.Reader) (request, error) {
+	var req request
+	d := json.NewDecoder(r)
+	d.DisallowUnknownFields()
+	err := d.Decode(&req) // want `CogJSONTrailing \(warning\): Decode reads one JSON value and silently ignores any data after it; check that d.More\(\) is false`
+	return req, err
+}
+
+func checked(r io.Reader) (request, error) {
+	var req request
+	dec := json.NewDecoder(r)
+	if err := dec.Decode(&req); err != nil {
+		return req, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return req, err
+	}
+	return req, nil
+}
+
+func stream(r io.Reader) ([]request, error) {
+	reqs := make([]request, 0)
+	dec := json.NewDecoder(r)
+	for dec.More() {
+		var req request
+		if err := dec.Decode(&req); err != nil {
+			return nil, err
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs, nil
+}
+
+func handedOff(dec *json.Decoder) (request, error) {
+	var req request
+	err := dec.Decode(&req)
+	return req, err
+}
@@ -0,0 +1,32 @@
+package largevaluecopy
+
+type Report struct {
+	Title  string
+	Totals [32]float64
+}
+
+func (r Report) Summary() string { // want `CogLargeValueCopy \(warning\): receiver r of type Report is 272 bytes, copied on every call; use a pointer receiver, or list Report in -largevaluecopy.allow if it is meant to be copied`
+	return r.Title
+}
+
+func (r *Report) Title2() string {
+	return r.Title
+}
+
+func print(r Report) {} // want `CogLargeValueCopy \(warning\): parameter r of type Report is 272 bytes, copied on every call; pass a \*Report`
+
+var render = func(Report) {} // want `CogLargeValueCopy \(warning\): parameter of type Report is 272 bytes`
+
+type Point struct{ X, Y float64 }
+
+func (p Point) Len() float64 { return p.X + p.Y }
+
+func byPointer(r *Report) {}
+
+type Box[T any] struct {
+	Items [32]T
+}
+
+func (b Box[T]) First() T { return b.Items[0] }
+
+func totals(t [32]float64) float64 { return t[0] }
@@ -0,0 +1,16 @@
+package largevaluecopyallow
+
+// Report is listed in -largevaluecopy.allow.
+type Report struct {
+	Title  string
+	Totals [32]float64
+}
+
+func (r Report) Summary() string { return r.Title }
+
+type Matrix struct {
+	Cells [16]float64
+	Name  string
+}
+
+func scale(m Matrix) {} // want `CogLargeValueCopy \(warning\): parameter m of type Matrix is 144 bytes`
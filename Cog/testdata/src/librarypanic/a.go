@@ -0,0 +1,52 @@
+package librarypanic
+
+import (
+	"errors"
+	"os"
+	"regexp"
+)
+
+func Must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+var digits = regexp.MustCompile(`\d+`)
+
+var config = Must(os.ReadFile("config"))
+
+func Load(path string) []byte {
+	return Must(os.ReadFile(path)) // want `CogLibraryPanic: Must panics on error in library code; return the error instead, or keep it as a Result with cog.Try`
+}
+
+func Check(n int) {
+	if n < 0 {
+		panic(errors.New("negative")) // want `CogLibraryPanic: panic in library code crashes every caller instead of letting them handle the error; return an error, or mark a truly unreachable state with an // UNREACHABLE: comment`
+	}
+}
+
+func MustPositive(n int) int {
+	if n <= 0 {
+		panic("not positive")
+	}
+	return n
+}
+
+func kind(n int) string {
+	switch n % 2 {
+	case 0:
+		return "even"
+	case 1, -1:
+		return "odd"
+	}
+	panic("impossible") // UNREACHABLE: n % 2 is 0, 1 or -1
+}
+
+func load(path string) []byte {
+	go func() {
+		panic("in a goroutine") // want `CogLibraryPanic: panic in library code`
+	}()
+	return nil
+}
@@ -0,0 +1,9 @@
+package main
+
+import "os"
+
+func main() {
+	if len(os.Args) < 2 {
+		panic("usage: librarypanicmain file")
+	}
+}
@@ -0,0 +1,14 @@
+//go:build go1.22
+
+package loopcapture
+
+import "fmt"
+
+// perIteration targets Go 1.22, where each iteration has its own item.
+func perIteration(items []string) {
+	for _, item := range items {
+		go func() {
+			fmt.Println(item)
+		}()
+	}
+}
@@ -0,0 +1,46 @@
+//go:build go1.21
+
+package loopcapture
+
+import "fmt"
+
+func each(items []string) {
+	for _, item := range items {
+		go func() { // want `CogLoopCapture: goroutine captures loop variable item by reference; before Go 1.22 every iteration shares it, so pass it as an argument`
+			fmt.Println(item)
+		}()
+	}
+}
+
+func indexed(items []string, prefix string) {
+	for i, item := range items {
+		go func(p string) { // want `CogLoopCapture: goroutine captures loop variable i, item by reference`
+			fmt.Println(p, i, item)
+		}(prefix)
+	}
+}
+
+func counted(n int) {
+	for i := 0; i < n; i++ {
+		go func() { // want `CogLoopCapture: goroutine captures loop variable i by reference`
+			fmt.Println(i)
+		}()
+	}
+}
+
+func passed(items []string) {
+	for _, item := range items {
+		go func(item string) {
+			fmt.Println(item)
+		}(item)
+	}
+}
+
+func outside(items []string) {
+	last := items[len(items)-1]
+	for range items {
+		go func() {
+			fmt.Println(last)
+		}()
+	}
+}
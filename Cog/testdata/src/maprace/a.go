@@ -0,0 +1,75 @@
+package maprace
+
+import "sync"
+
+func tally(words []string) {
+	counts := make(map[string]int)
+	for _, w := range words {
+		go func() { counts[w]++ }() // want `CogMapRace: map counts is written by the goroutine started on line 8 and written by the goroutine started on line 10, with no mutex held`
+	}
+	go func() { counts["total"] = len(words) }()
+}
+
+func loop(words []string) {
+	counts := make(map[string]int)
+	for _, w := range words {
+		go func() { counts[w]++ }() // want `CogMapRace: map counts is written by the goroutine started on line 16 and written by the goroutines the same statement starts in the other iterations of the loop on line 15, with no mutex held; concurrent map access is a data race that can crash the program, so guard counts with a sync.Mutex or sync.RWMutex, or use a sync.Map`
+	}
+}
+
+func readAfter(words []string) int {
+	seen := make(map[string]bool)
+	go func() {
+		for _, w := range words {
+			seen[w] = true // want `CogMapRace: map seen is written by the goroutine started on line 22 and read by the function after starting it, on line 27`
+		}
+	}()
+	return len(seen)
+}
+
+func loopReads(words []string) {
+	local := make(map[string]int)
+	for _, w := range words {
+		go func() { _ = local[w] }()
+	}
+}
+
+func perIteration(words []string) {
+	for _, w := range words {
+		counts := make(map[string]int)
+		go func() { counts[w]++ }()
+	}
+}
+
+func waited(words []string) {
+	counts := make(map[string]int)
+	var wg sync.WaitGroup
+	for _, w := range words {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			counts[w]++
+		}()
+		wg.Wait()
+	}
+}
+
+func locked(words []string) {
+	var mu sync.Mutex
+	counts := make(map[string]int)
+	for _, w := range words {
+		go func() {
+			mu.Lock()
+			counts[w]++
+			mu.Unlock()
+		}()
+	}
+}
+
+func escapes(words []string) map[string]int {
+	counts := make(map[string]int)
+	for _, w := range words {
+		go func() { counts[w]++ }()
+	}
+	return counts
+}
@@ -0,0 +1,67 @@
+package maprangeorder
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+func names(users map[string]int) []string {
+	names := make([]string, 0, len(users))
+	for name := range users { // want `CogMapRangeOrder \(warning\): range over map users appends to names in random order, and names is returned unsorted on line 16; range over slices.Sorted\(maps.Keys\(users\)\) or sort names first`
+		names = append(names, name)
+	}
+	return names
+}
+
+func joined(set map[string]bool) string {
+	var keys []string
+	for k := range set { // want `CogMapRangeOrder \(warning\): range over map set appends to keys in random order, and keys is passed to strings.Join unsorted on line 24`
+		keys = append(keys, k)
+	}
+	return strings.Join(keys, ",")
+}
+
+func encoded(m map[string]int) ([]byte, error) {
+	var vals []int
+	for _, v := range m { // want `CogMapRangeOrder \(warning\): range over map m appends to vals in random order, and vals is passed to json.Marshal unsorted`
+		vals = append(vals, v)
+	}
+	return json.Marshal(vals)
+}
+
+func sorted(users map[string]int) []string {
+	names := make([]string, 0, len(users))
+	for name := range users {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedSlices(users map[string]int) string {
+	var names []string
+	for name := range users {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return fmt.Sprint(names)
+}
+
+func counted(users map[string]int) int {
+	var names []string
+	for name := range users {
+		names = append(names, name)
+	}
+	return len(names)
+}
+
+func fromSlice(list []string) []string {
+	var out []string
+	for _, s := range list {
+		out = append(out, s)
+	}
+	return out
+}
@@ -0,0 +1,38 @@
+package missingdoc
+
+func NewClient(addr string) (*Client, error) { return &Client{addr: addr}, nil } // want `CogMissingDoc \(warning\): exported function NewClient has no doc comment; document it with a comment starting with NewClient`
+
+// Client talks to a server.
+type Client struct{ addr string }
+
+func (c *Client) Addr() string { return c.addr } // want `CogMissingDoc \(warning\): exported method Addr has no doc comment`
+
+// Close releases the client.
+func (c *Client) Close() error { return nil }
+
+type Option func(*Client) // want `CogMissingDoc \(warning\): exported type Option has no doc comment`
+
+const MaxRetries = 3 // want `CogMissingDoc \(warning\): exported constant MaxRetries has no doc comment`
+
+// Timeouts, in seconds.
+const (
+	DialTimeout = 5
+	ReadTimeout = 30
+)
+
+var (
+	// DefaultAddr is used when none is given.
+	DefaultAddr = "localhost:80"
+
+	ErrClosed = errClosed{} // want `CogMissingDoc \(warning\): exported variable ErrClosed has no doc comment`
+)
+
+type errClosed struct{}
+
+func (errClosed) Error() string { return "closed" }
+
+type conn struct{}
+
+func (conn) Read() {}
+
+func helper() {}
@@ -0,0 +1,3 @@
+package missingdoc
+
+func Helper() {}
@@ -0,0 +1,3 @@
+package missingdocoff
+
+func Undocumented() {}
@@ -0,0 +1,13 @@
+package missingdocprefix
+
+// Dial connects to addr.
+func Dial(addr string) {}
+
+// A Server serves requests.
+type Server struct{}
+
+// Serves requests until stopped.
+func (s *Server) Serve() {} // want `CogMissingDoc \(warning\): doc comment of exported method Serve should start with Serve`
+
+// Listener accepts connections.
+type ListenerConfig struct{} // want `CogMissingDoc \(warning\): doc comment of exported type ListenerConfig should start with ListenerConfig`
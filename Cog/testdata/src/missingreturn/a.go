@@ -0,0 +1,62 @@
+package missingreturn
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+)
+
+type user struct{ Name string }
+
+func load(r *http.Request) (user, error) { return user{}, errors.New("no user") }
+
+func handler(w http.ResponseWriter, r *http.Request) {
+	u, err := load(r)
+	if err != nil {
+		http.Error(w, "not found", http.StatusNotFound) // want `CogMissingReturn: http.Error writes the error response, but the branch falls through and the handler goes on to write another; return after it`
+	}
+	json.NewEncoder(w).Encode(u)
+}
+
+func header(w http.ResponseWriter, r *http.Request) {
+	_, err := load(r)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError) // want `CogMissingReturn: w.WriteHeader writes the error response`
+		log.Print(err)
+	}
+	w.Write([]byte("ok"))
+}
+
+func status(w http.ResponseWriter, r *http.Request) int {
+	_, err := load(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest) // want `CogMissingReturn: http.Error writes the error response`
+	}
+	return http.StatusOK
+}
+
+func returns(w http.ResponseWriter, r *http.Request) {
+	u, err := load(r)
+	if err != nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(u)
+}
+
+func fatal(w http.ResponseWriter, r *http.Request) {
+	u, err := load(r)
+	if err != nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		log.Fatal(err)
+	}
+	json.NewEncoder(w).Encode(u)
+}
+
+func last(w http.ResponseWriter, r *http.Request) {
+	_, err := load(r)
+	if err != nil {
+		http.Error(w, "not found", http.StatusNotFound)
+	}
+}
@@ -0,0 +1,99 @@
+package nilmapwrite
+
+func count(keys []string) int {
+	var counts map[string]int
+	for _, k := range keys {
+		counts[k]++ // want `CogNilMapWrite: counts may be a nil map here, and writing to a nil map panics; initialize it with make\(map\[string\]int\)`
+	}
+	for k := range counts {
+		delete(counts, k)
+	}
+	return len(counts)
+}
+
+// returned may legitimately return a nil map, so initializing the
+// declaration is not offered.
+func returned(keys []string) map[string]int {
+	var m map[string]int
+	for _, k := range keys {
+		m[k]++ // want `CogNilMapWrite: m may be a nil map here`
+	}
+	return m
+}
+
+func branch(ok bool) {
+	var m map[string]bool
+	if ok {
+		m = make(map[string]bool)
+	}
+	m["x"] = true // want `CogNilMapWrite: m may be a nil map here`
+}
+
+func lazyInLoop(keys []string) map[string]int {
+	var m map[string]int
+	for _, k := range keys {
+		if m == nil {
+			m = make(map[string]int)
+		}
+		m[k]++
+	}
+	return m
+}
+
+func lazyNotEqual(keys []string) map[string]int {
+	var m map[string]int
+	for _, k := range keys {
+		if m != nil {
+			m[k]++
+			continue
+		}
+		m = map[string]int{k: 1}
+	}
+	return m
+}
+
+func lazyOnce(k string) map[string]int {
+	var m map[string]int
+	if m == nil {
+		m = make(map[string]int)
+	}
+	m[k] = 1
+	return m
+}
+
+func made() map[string]int {
+	m := make(map[string]int)
+	m["a"] = 1
+	lit := map[string]int{}
+	lit["b"] = 2
+	return m
+}
+
+func param(m map[string]int) {
+	m["a"] = 1 // the caller's map: assumed initialized
+}
+
+func deleteNil() {
+	var m map[string]int
+	delete(m, "a") // a no-op on a nil map
+}
+
+type registry struct {
+	byName map[string]int
+}
+
+func field() {
+	var r registry
+	r.byName["x"] = 1 // want `CogNilMapWrite: r.byName is never initialized in this function`
+}
+
+func fieldSet() {
+	var r registry
+	r.byName = map[string]int{}
+	r.byName["x"] = 1
+}
+
+func fieldFromConstructor(newRegistry func() registry) {
+	r := newRegistry()
+	r.byName["x"] = 1
+}
@@ -0,0 +1,46 @@
+package nilslicejson
+
+import "encoding/json"
+
+type Response struct {
+	Items []string `json:"items"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+func respond(found bool) ([]byte, error) {
+	var items []string
+	if found {
+		items = append(items, "item1")
+	}
+	return json.Marshal(Response{Items: items}) // want `CogNilSliceJSON: items may be a nil \[\]string when stored in JSON field Items, which encodes as null; initialize it with make\(\[\]string, 0\)`
+}
+
+func assigned(found bool) ([]byte, error) {
+	var r Response
+	var items []string
+	if found {
+		items = []string{"a"}
+	}
+	r.Items = items // want `CogNilSliceJSON: items may be a nil \[\]string when stored in JSON field Items`
+	return json.Marshal(r)
+}
+
+func omitted() ([]byte, error) {
+	var tags []string
+	return json.Marshal(Response{Items: make([]string, 0), Tags: tags})
+}
+
+func made() ([]byte, error) {
+	items := make([]string, 0)
+	return json.Marshal(Response{Items: items})
+}
+
+// Internal is never marshaled by the package.
+type Internal struct {
+	Items []string `json:"items"`
+}
+
+func internal() Internal {
+	var items []string
+	return Internal{Items: items}
+}
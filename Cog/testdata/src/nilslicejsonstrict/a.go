@@ -0,0 +1,16 @@
+package nilslicejsonstrict
+
+// Internal is never marshaled by the package, but -nilslicejson.strict
+// reports it anyway.
+type Internal struct {
+	Items []string `json:"items"`
+	Skip  []string `json:"-"`
+}
+
+func internal(found bool) Internal {
+	var items, skip []string
+	if found {
+		items = append(items, "a")
+	}
+	return Internal{Items: items, Skip: skip} // want `CogNilSliceJSON: items may be a nil \[\]string when stored in JSON field Items`
+}
@@ -0,0 +1,44 @@
+package osexit
+
+import (
+	"log"
+	"os"
+)
+
+func loadConfig(path string) []byte {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatal(err) // want `CogOsExit: log.Fatal outside main ends the program from a helper, which callers cannot handle and tests cannot survive; return an error up to main and exit there`
+	}
+	return data
+}
+
+func process(path string) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	if len(path) > 10 {
+		os.Exit(1) // want `CogOsExit: os.Exit skips the call deferred on line 21 \(f.Close\(\)\); return instead, passing the error up to main, so that deferred calls run`
+	}
+}
+
+func deferAfter(path string) {
+	if path == "" {
+		os.Exit(2) // want `CogOsExit: os.Exit outside main`
+	}
+	defer log.Println("done")
+}
+
+func logger(l *log.Logger) {
+	l.Fatalf("failed: %d", 1) // want `CogOsExit: l.Fatalf outside main`
+}
+
+func TestMain(m interface{ Run() int }) {
+	os.Exit(m.Run())
+}
+
+func init() {
+	log.Fatal("library init") // want `CogOsExit: log.Fatal outside main`
+}
@@ -0,0 +1,15 @@
+package osexitdeferonly
+
+import (
+	"log"
+	"os"
+)
+
+func fail(err error) {
+	log.Fatal(err)
+}
+
+func cleanup(f *os.File) {
+	defer f.Close()
+	os.Exit(1) // want `CogOsExit: os.Exit skips the call deferred on line 13`
+}
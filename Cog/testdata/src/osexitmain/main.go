@@ -0,0 +1,23 @@
+package main
+
+import (
+	"log"
+	"os"
+)
+
+func main() {
+	f, err := os.Open("input")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.Stat(); err != nil {
+		os.Exit(1) // want "CogOsExit: os.Exit skips the call deferred on line 13 \\(f.Close\\(\\)\\); move the body into a `run\\(\\) error` function and exit in main when it fails"
+	}
+}
+
+func init() {
+	if len(os.Args) > 5 {
+		os.Exit(2)
+	}
+}
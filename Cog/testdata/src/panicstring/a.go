@@ -0,0 +1,37 @@
+package panicstring
+
+import (
+	"fmt"
+)
+
+func load(err error) {
+	if err != nil {
+		panic("load config: " + err.Error()) // want `CogPanicString: panic with a string built by concatenation; a recover that checks for an error cannot match it, so panic with an error such as fmt.Errorf\(...\)`
+	}
+}
+
+func state(s int) {
+	panic(fmt.Sprintf("bad state %d", s)) // want `CogPanicString: panic with a string built by fmt.Sprintf`
+}
+
+func values(a, b any) {
+	panic(fmt.Sprint(a, b)) // want `CogPanicString: panic with a string built by fmt.Sprint`
+}
+
+func percent(name string) {
+	panic("100% " + name) // want `CogPanicString: panic with a string built by concatenation`
+}
+
+const prefix = "panicstring: "
+
+func constant() {
+	panic(prefix + "unreachable")
+}
+
+func plain(msg string) {
+	panic(msg)
+}
+
+func wrapped(err error) {
+	panic(fmt.Errorf("wrapped: %w", err))
+}
@@ -0,0 +1,11 @@
+//go:build go1.22
+
+package pointertoloopvar
+
+func collectNew(items []item) []*item {
+	ptrs := make([]*item, 0, len(items))
+	for _, it := range items {
+		ptrs = append(ptrs, &it)
+	}
+	return ptrs
+}
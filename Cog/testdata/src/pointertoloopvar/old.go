@@ -0,0 +1,46 @@
+//go:build go1.21
+
+package pointertoloopvar
+
+type item struct{ name string }
+
+type holder struct{ p *item }
+
+func collect(items []item) []*item {
+	ptrs := make([]*item, 0, len(items))
+	for _, it := range items {
+		ptrs = append(ptrs, &it) // want `CogPointerToLoopVar: &it is appended to ptrs, but before Go 1.22 it is one variable reused by every iteration, so every stored pointer ends up at the last element; copy it first with it := it`
+	}
+	return ptrs
+}
+
+func store(items []item, h *holder, out chan<- *holder) {
+	var last *item
+	for i, it := range items {
+		if i == 0 {
+			h.p = &it // want `CogPointerToLoopVar: &it is stored in h.p`
+		}
+		last = &it // want `CogPointerToLoopVar: &it is stored in last`
+
+		out <- &holder{p: &it} // want `CogPointerToLoopVar: &it is sent on out`
+	}
+	h.p = last
+}
+
+func find(items []item, name string) *item {
+	for _, it := range items {
+		if it.name == name {
+			return &it
+		}
+	}
+	return nil
+}
+
+func local(items []item) int {
+	n := 0
+	for _, it := range items {
+		p := &it
+		n += len(p.name)
+	}
+	return n
+}
@@ -0,0 +1,37 @@
+package printf
+
+import (
+	"errors"
+	"fmt"
+)
+
+type logger struct{}
+
+func (*logger) Infof(format string, args ...any) {}
+
+func failf(code int, format string, args ...any) {}
+
+type user struct {
+	ID   int
+	Name string
+}
+
+type level int
+
+func (l level) String() string { return fmt.Sprint(int(l)) }
+
+func calls(log *logger, u user, err error) {
+	log.Infof("user %d logged in from %s", u.Name) // want `CogPrintf: log.Infof format %d has arg u.Name of wrong type string` `CogPrintf: log.Infof format %s reads arg #2, but call has 1 arg`
+	log.Infof("user %s", u.Name, u.ID)             // want `CogPrintf: log.Infof call needs 1 arg but has 2 args`
+	log.Infof("progress %y", 1)                    // want `CogPrintf: log.Infof format %y has unknown verb y`
+	log.Infof("width %*d", "8", 1)                 // want `CogPrintf: log.Infof format %\*d uses non-int "8" as width or precision`
+	log.Infof("trailing %")                        // want `CogPrintf: log.Infof format "%" is missing a verb`
+	failf(2, "%t", u.ID)                           // want `CogPrintf: failf format %t has arg u.ID of wrong type int`
+
+	log.Infof("user %d is %s, %v, 100%%", u.ID, u.Name, u)
+	log.Infof("level %s, err %v, wrapped %w", level(1), err, errors.New("x"))
+	log.Infof("%[2]d %[1]s", u.Name, u.ID)
+	args := []any{u.ID}
+	log.Infof("%s", args...)
+	fmt.Printf("%d", u.Name)
+}
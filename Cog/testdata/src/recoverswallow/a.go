@@ -0,0 +1,71 @@
+package recoverswallow
+
+import (
+	"errors"
+	"fmt"
+	"log"
+)
+
+func swallow() {
+	defer func() {
+		recover() // want "CogRecoverSwallow: the value of recover\\(\\) is discarded, so the panic is swallowed and its cause lost"
+	}()
+}
+
+func blank() {
+	defer func() {
+		_ = recover() // want "CogRecoverSwallow: the value of recover\\(\\) is discarded"
+	}()
+}
+
+func nilOnly() (ok bool) {
+	defer func() {
+		if r := recover(); r != nil { // want "CogRecoverSwallow: the value of recover\\(\\) is discarded"
+			ok = false
+		}
+	}()
+	return true
+}
+
+func logged() {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("recovered: %v", r)
+		}
+	}()
+}
+
+func asError() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return nil
+}
+
+var errAborted = errors.New("aborted")
+
+func repanics() {
+	defer func() {
+		if r := recover(); r != nil {
+			panic(errAborted)
+		}
+	}()
+}
+
+func repanicsWithValue() {
+	defer func() {
+		if r := recover(); r != nil {
+			panic(r)
+		}
+	}()
+}
+
+func nestedPanic() {
+	defer func() {
+		recover() // want "CogRecoverSwallow: the value of recover\\(\\) is discarded"
+		report := func() { panic("unreachable") }
+		_ = report
+	}()
+}
@@ -0,0 +1,22 @@
+package regexpmust
+
+import (
+	"regexp"
+)
+
+const prefix = "^id-"
+
+var ids = regexp.MustCompile(prefix + `\d+`)
+
+func matcher(userPrefix string) (*regexp.Regexp, error) {
+	re := regexp.MustCompile("^" + userPrefix) // want `CogRegexpMust: regexp.MustCompile panics if the pattern built at run time is invalid; use regexp.Compile and handle its error`
+	return re, nil
+}
+
+func matches(pattern, s string) bool {
+	return regexp.MustCompilePOSIX(pattern).MatchString(s) // want `CogRegexpMust: regexp.MustCompilePOSIX panics if the pattern built at run time is invalid; use regexp.CompilePOSIX and handle its error`
+}
+
+func constant() *regexp.Regexp {
+	return regexp.MustCompile(`^[a-z]+$`)
+}
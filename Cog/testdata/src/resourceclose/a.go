@@ -0,0 +1,70 @@
+package resourceclose
+
+import (
+	"errors"
+	"os"
+)
+
+var errEmpty = errors.New("empty")
+
+func leak(path string) error {
+	f, err := os.Open(path) // want "CogResourceClose: f \\(\\*os.File\\) from os.Open is not closed on the path to line 17; add `defer f.Close\\(\\)` after checking the error"
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil || info.Size() == 0 {
+		return errEmpty
+	}
+	return f.Close()
+}
+
+func discarded(path string) {
+	os.Open(path) // want `CogResourceClose: closable result of os.Open is discarded, so it is never closed`
+}
+
+type conn struct{}
+
+func (*conn) Close() error { return nil }
+
+func (*conn) Ping() {}
+
+func dial() (*conn, error) { return &conn{}, nil }
+
+func closerLeak() error {
+	c, err := dial() // want "CogResourceClose: c \\(\\*conn\\) from dial is not closed on the path to line 40"
+	if err != nil {
+		return err
+	}
+	c.Ping()
+	return nil
+}
+
+func deferred(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Stat()
+	return err
+}
+
+func returned(path string) (*os.File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func closeFile(f *os.File) { f.Close() }
+
+func handedToCloser(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	closeFile(f)
+	return nil
+}
@@ -0,0 +1,61 @@
+package rowserr
+
+import (
+	"database/sql"
+)
+
+func names(db *sql.DB) ([]string, error) {
+	rows, err := db.Query("SELECT name FROM users")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() { // want "CogRowsErr: the rows.Next\\(\\) loop ending on line 20 is not followed by a rows.Err\\(\\) check; Next also returns false when fetching a row fails, so add `if err := rows.Err\\(\\); err != nil { ... }` after the loop"
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func count(rows *sql.Rows) {
+	n := 0
+	for rows.Next() { // want `CogRowsErr: the rows.Next\(\) loop ending on line 28 is not followed by a rows.Err\(\) check`
+		n++
+	}
+	rows.Err()
+}
+
+func checked(rows *sql.Rows) (int, error) {
+	n := 0
+	for rows.Next() {
+		n++
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+func deferred(rows *sql.Rows) (n int, err error) {
+	defer func() {
+		if rerr := rows.Err(); rerr != nil && err == nil {
+			err = rerr
+		}
+	}()
+	for rows.Next() {
+		n++
+	}
+	return n, nil
+}
+
+func finish(rows *sql.Rows) error { return rows.Err() }
+
+func handedOff(rows *sql.Rows) error {
+	for rows.Next() {
+	}
+	return finish(rows)
+}
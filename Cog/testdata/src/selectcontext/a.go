@@ -0,0 +1,57 @@
+package selectcontext
+
+import "context"
+
+func run(int) {}
+
+func worker(ctx context.Context, jobs <-chan int) {
+	for {
+		select { // want "CogSelectContext: select blocks with no case for ctx.Done\\(\\), so cancelling ctx does not end the wait; add `case <-ctx.Done\\(\\):` and return"
+		case j := <-jobs:
+			run(j)
+		}
+	}
+}
+
+func nested(ctx context.Context, jobs <-chan int) {
+	go func() {
+		select { // want `CogSelectContext: select blocks with no case for ctx.Done\(\)`
+		case j := <-jobs:
+			run(j)
+		}
+	}()
+}
+
+func withDone(ctx context.Context, jobs <-chan int) {
+	select {
+	case j := <-jobs:
+		run(j)
+	case <-ctx.Done():
+	}
+}
+
+func doneVar(ctx context.Context, jobs <-chan int) {
+	done := ctx.Done()
+	go func() {
+		select {
+		case j := <-jobs:
+			run(j)
+		case <-done:
+		}
+	}()
+}
+
+func nonBlocking(ctx context.Context, jobs <-chan int) {
+	select {
+	case j := <-jobs:
+		run(j)
+	default:
+	}
+}
+
+func noContext(jobs <-chan int) {
+	select {
+	case j := <-jobs:
+		run(j)
+	}
+}
@@ -0,0 +1,37 @@
+package slicemutation
+
+import "fmt"
+
+func addDefault(tags []string) {
+	tags = append(tags, "default") // want `CogSliceMutation \(warning\): append to the parameter tags is not seen by the caller: tags is a copy of the caller's slice header, so the new length stays local, while the new elements may still be written into the caller's backing array; return tags, or take a \*\[\]string if the caller should see the change`
+}
+
+type ids []int
+
+func extend(xs ids, n int) {
+	for i := range n {
+		xs = append(xs, i) // want `CogSliceMutation \(warning\): append to the parameter xs is not seen by the caller: .* take a \*ids if`
+	}
+	if len(xs) > 0 && xs[0] == 0 {
+		return
+	}
+}
+
+func returned(tags []string) []string {
+	tags = append(tags, "default")
+	return tags
+}
+
+func printed(tags []string) {
+	tags = append(tags, "default")
+	fmt.Println(tags)
+}
+
+func viaPointer(tags *[]string) {
+	*tags = append(*tags, "default")
+}
+
+// scratch appends to buf, which the caller keeps only for its capacity.
+func scratch(buf []byte) {
+	buf = append(buf, 'x')
+}
@@ -0,0 +1,40 @@
+package sqlinjection
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+)
+
+func byName(db *sql.DB, name string) (*sql.Rows, error) {
+	return db.Query(fmt.Sprintf("SELECT * FROM users WHERE name = '%s'", name)) // want `CogSQLInjection: the query passed to db.Query is built with fmt.Sprintf from non-constant values, so the values can inject SQL; use placeholders \(\? or \$1\) and pass the values as arguments`
+}
+
+func concat(ctx context.Context, tx *sql.Tx, table string) error {
+	_, err := tx.ExecContext(ctx, "DELETE FROM "+table) // want `CogSQLInjection: the query passed to tx.ExecContext is concatenated from non-constant strings`
+	return err
+}
+
+func viaVar(db *sql.DB, name string) *sql.Row {
+	query := "SELECT id FROM users WHERE name = '"
+	query += name + "'"
+	return db.QueryRow(query) // want `CogSQLInjection: the query passed to db.QueryRow is query, which is concatenated from non-constant strings on line 21`
+}
+
+func placeholder(db *sql.DB, name string) (*sql.Rows, error) {
+	return db.Query("SELECT * FROM users WHERE name = ?", name)
+}
+
+func numbers(db *sql.DB, limit int) (*sql.Rows, error) {
+	if limit < 0 {
+		return db.Query(fmt.Sprintf("SELECT * FROM users OFFSET %d", -limit))
+	}
+	return db.Query("SELECT * FROM users LIMIT " + strconv.Itoa(limit))
+}
+
+const table = "users"
+
+func constant(db *sql.DB) (*sql.Rows, error) {
+	return db.Query("SELECT * FROM " + table)
+}
@@ -0,0 +1,52 @@
+package stringconcatloop
+
+import "strings"
+
+func join(lines []string) string {
+	var out string
+	for _, line := range lines {
+		out += line // want `CogStringConcatLoop: out is built by concatenation inside a loop, which copies the whole string on every iteration \(O\(n²\) allocation\); collect the pieces in a strings.Builder with b.WriteString and set out = b.String\(\) after the loop`
+		out += "\n"
+	}
+	return out
+}
+
+func plus(n int) string {
+	s := ""
+	for i := 0; i < n; i++ {
+		s = s + "x" + "y" // want `CogStringConcatLoop: s is built by concatenation inside a loop`
+	}
+	return s
+}
+
+func named(parts []string) (s string) {
+	for _, p := range parts {
+		s += p // want `CogStringConcatLoop: s is built by concatenation inside a loop`
+	}
+	return
+}
+
+func builder(lines []string) string {
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+func perIteration(lines []string) int {
+	n := 0
+	for _, line := range lines {
+		s := "> "
+		s += line
+		n += len(s)
+	}
+	return n
+}
+
+func unread(lines []string) {
+	var out string
+	for _, line := range lines {
+		out += line
+	}
+}
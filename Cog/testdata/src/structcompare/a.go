@@ -0,0 +1,45 @@
+package structcompare
+
+type Config struct {
+	Name string
+	Tags []string
+}
+
+type Point struct{ X, Y int }
+
+type Grid [2][]int
+
+func load() any { return nil }
+
+func same(cfg Config) bool {
+	var a, b any = cfg, load()
+	return a == b // want `CogStructCompare: a == b compares interface values that may both hold Config, which is not comparable \(field Tags is \[\]string\), so it panics at run time; use reflect.DeepEqual or give Config an Equal method`
+}
+
+func grid(g Grid, other any) bool {
+	var a any = g
+	return other != a // want `CogStructCompare: other != a compares interface values that may both hold Grid, which is not comparable \(its elements are \[\]int\)`
+}
+
+func phi(cfg Config, p Point, useCfg bool) bool {
+	var a any = p
+	if useCfg {
+		a = cfg
+	}
+	return a == load() // want `CogStructCompare: a == load\(\) compares interface values that may both hold Config`
+}
+
+func comparable(p Point) bool {
+	var a any = p
+	return a == load()
+}
+
+func againstNil(cfg Config) bool {
+	var a any = cfg
+	return a != nil
+}
+
+func differentTypes(cfg Config, p Point) bool {
+	var a, b any = cfg, p
+	return a == b
+}
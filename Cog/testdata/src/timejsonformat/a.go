@@ -0,0 +1,25 @@
+package timejsonformat
+
+import "time"
+
+type Event struct {
+	ID        string     `json:"id"`
+	CreatedAt time.Time  `json:"created_at"` // want `CogTimeJSONFormat \(warning\): JSON field Event.CreatedAt uses time.Time's default RFC 3339 encoding, which consumers may not expect; give it a named type with MarshalJSON and UnmarshalJSON fixing the format, or exempt Event with -timejsonformat.exempt`
+	UpdatedAt time.Time  // want `CogTimeJSONFormat \(warning\): time field Event.UpdatedAt of a JSON-tagged struct has no json tag, so it is encoded as "UpdatedAt" in time.Time's default RFC 3339 format; tag it, and fix the format with a named type, or tag it .json:"-".`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"` // want `CogTimeJSONFormat \(warning\): JSON field Event.DeletedAt`
+	Internal  time.Time  `json:"-"`
+	seen      time.Time
+}
+
+type Audit struct {
+	At time.Time `json:"at"`
+}
+
+type untagged struct {
+	At time.Time
+}
+
+type embedded struct {
+	time.Time
+	Name string `json:"name"`
+}
@@ -0,0 +1,27 @@
+//go:build go1.23
+
+package timerleak
+
+import "time"
+
+// Go 1.23 collects unreferenced timers and tickers, so none of these
+// leaks.
+
+func consumeCollected(ch <-chan int) {
+	for {
+		select {
+		case <-ch:
+		case <-time.After(time.Minute):
+			return
+		}
+	}
+}
+
+func tickCollected() {
+	for range time.Tick(time.Second) {
+	}
+}
+
+func discardedCollected() {
+	time.NewTimer(time.Second)
+}
@@ -0,0 +1,53 @@
+//go:build go1.22
+
+package timerleak
+
+import "time"
+
+func consume(ch <-chan int) {
+	for {
+		select {
+		case <-ch:
+		case <-time.After(time.Minute): // want `CogTimerLeak: time.After in a select inside a loop allocates a new timer on every iteration; create one with time.NewTimer before the loop and Reset it each iteration`
+			return
+		}
+	}
+}
+
+func once(ch <-chan int) {
+	select {
+	case <-ch:
+	case <-time.After(time.Minute):
+	}
+}
+
+func tick() {
+	for range time.Tick(time.Second) { // want `CogTimerLeak: time.Tick creates a ticker that can never be stopped`
+	}
+}
+
+func discarded() {
+	time.NewTimer(time.Second) // want `CogTimerLeak: timer from time.NewTimer is discarded, so it can never be stopped`
+}
+
+func unstopped(done <-chan bool) {
+	t := time.NewTicker(time.Second) // want "CogTimerLeak: ticker t from time.NewTicker is not stopped on the path to line 39; add `defer t.Stop\\(\\)` after creating it"
+	for {
+		select {
+		case <-t.C:
+		case <-done:
+			return
+		}
+	}
+}
+
+func stopped(done <-chan bool) {
+	t := time.NewTicker(time.Second)
+	defer t.Stop()
+	<-done
+}
+
+func handedOff() *time.Timer {
+	t := time.NewTimer(time.Second)
+	return t
+}
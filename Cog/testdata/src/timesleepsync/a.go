@@ -0,0 +1,52 @@
+package timesleepsync
+
+import (
+	"sync"
+	"time"
+)
+
+type result struct{ n int }
+
+func flaky() bool {
+	done := false
+	go func() { done = true }()
+	time.Sleep(100 * time.Millisecond) // want `CogTimeSleepSync \(warning\): time.Sleep waits for the goroutine started on line 12 to write done, which it may not have done yet, and the read races with the write; wait for it with a channel or a sync.WaitGroup`
+	return done
+}
+
+func field(r *result) int {
+	go func() {
+		r.n++
+	}()
+	time.Sleep(time.Second) // want `CogTimeSleepSync \(warning\): time.Sleep waits for the goroutine started on line 18 to write r`
+	return r.n
+}
+
+func waited() bool {
+	var wg sync.WaitGroup
+	done := false
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		done = true
+	}()
+	wg.Wait()
+	time.Sleep(time.Millisecond)
+	return done
+}
+
+func unrelated() int {
+	n := 0
+	go func() {
+		m := 1
+		_ = m
+	}()
+	time.Sleep(time.Millisecond)
+	return n
+}
+
+func pacing(items []int) {
+	for range items {
+		time.Sleep(time.Millisecond)
+	}
+}
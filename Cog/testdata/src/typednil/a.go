@@ -0,0 +1,44 @@
+package typednil
+
+type MyError struct{ msg string }
+
+func (e *MyError) Error() string { return e.msg }
+
+func maybe(fail bool) error {
+	var err *MyError
+	if fail {
+		err = &MyError{msg: "failed"}
+	}
+	return err // want `CogTypedNil: err may be a nil \*MyError, which is a non-nil error; return a bare nil on the nil path`
+}
+
+func always() error {
+	var err *MyError
+	return err // want `CogTypedNil: err may be a nil \*MyError`
+}
+
+func checked(fail bool) error {
+	var err *MyError
+	if fail {
+		err = &MyError{msg: "failed"}
+	}
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func allocated() error {
+	return &MyError{msg: "failed"}
+}
+
+func param(err *MyError) error {
+	return err
+}
+
+func bare(fail bool) error {
+	if fail {
+		return &MyError{msg: "failed"}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package typeerasure
+
+import "fmt"
+
+func ProcessAny(data any) any { // want `CogTypeErasure \(warning\): data is typed any but only returned unchanged, so callers lose its type; introduce a type parameter for it, as ProcessTyped does`
+	return data
+}
+
+func ProcessTyped[T any](data T) T {
+	return data
+}
+
+func describe(v any) string { // want `CogTypeErasure \(warning\): v is typed any but every call passes a value of type int; take int, or a type parameter as ProcessTyped does`
+	return fmt.Sprint(v)
+}
+
+func count() any { // want `CogTypeErasure \(warning\): result 1 of count is typed any but every return yields a value of type int; return int instead`
+	return 42
+}
+
+func callers() {
+	_ = describe(1)
+	_ = describe(2)
+	_ = show(1)
+	_ = show("two")
+	_ = value(true)
+	logf("%d", 1)
+}
+
+func show(v any) string {
+	return fmt.Sprint(v)
+}
+
+func logf(format string, args ...any) {
+	fmt.Printf(format, args...)
+}
+
+func value(ok bool) any {
+	if ok {
+		return 1
+	}
+	return "none"
+}
+
+var handler func(any) string = used
+
+func used(v any) string {
+	return fmt.Sprint(v)
+}
+
+func init() {
+	_ = used(1)
+}
+
+type box struct{}
+
+func (box) Put(v any) any { return v }
@@ -0,0 +1,41 @@
+package uncheckedassert
+
+import (
+	"strings"
+)
+
+func size(v any) (int, error) {
+	s := v.(string) // want "CogUncheckedAssert: v.\\(string\\) panics when the dynamic type of v is not string; use the two-result form `v, ok := v.\\(string\\)` or a type switch"
+	return len(s), nil
+}
+
+func upper(v any) string {
+	s := v.(string) // want `CogUncheckedAssert: v.\(string\) panics when the dynamic type of v is not string`
+	return strings.ToUpper(s)
+}
+
+func inCall(v any) int {
+	return v.(int) + 1 // want `CogUncheckedAssert: v.\(int\) panics`
+}
+
+func checked(v any) int {
+	n, ok := v.(int)
+	if !ok {
+		return 0
+	}
+	return n
+}
+
+func switched(v any) int {
+	switch x := v.(type) {
+	case int:
+		return x
+	case string:
+		return len(v.(string))
+	}
+	return 0
+}
+
+func MustString(v any) string {
+	return v.(string)
+}
@@ -0,0 +1,21 @@
+package unkeyedstruct
+
+import (
+	"image"
+	"net"
+)
+
+type pair struct {
+	a, b int
+}
+
+func literals(ip net.IP, mask net.IPMask) {
+	_ = net.IPNet{ip, mask} // want `CogUnkeyedStruct: unkeyed literal of net.IPNet from another package breaks silently when the struct gains or reorders fields; name the fields`
+
+	_ = []*net.IPNet{{ip, mask}} // want `CogUnkeyedStruct: unkeyed literal of net.IPNet`
+
+	_ = net.IPNet{IP: ip, Mask: mask}
+	_ = net.IPNet{}
+	_ = image.Point{1, 2}
+	_ = pair{1, 2}
+}
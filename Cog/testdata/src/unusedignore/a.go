@@ -0,0 +1,23 @@
+package unusedignore
+
+func used() (n int) {
+	return //cog:ignore CogBareReturn short enough to read
+}
+
+func usedNextLine() (n int) {
+	//cog:ignore-next-line
+	return
+}
+
+func unused(x int) int {
+	return x //cog:ignore CogBareReturn // want `CogUnusedIgnore \(warning\): this //cog:ignore suppresses no CogBareReturn finding; remove it`
+}
+
+func unusedNextLine(x int) int {
+	//cog:ignore-next-line // want `CogUnusedIgnore \(warning\): this //cog:ignore suppresses no finding; remove it`
+	return x
+}
+
+func otherRule(x int) int {
+	return x //cog:ignore CogTypedNil
+}
@@ -0,0 +1,49 @@
+package waitgroupadd
+
+import "sync"
+
+func run(int) {}
+
+func inside(jobs []int) {
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		go func() {
+			wg.Add(1) // want `CogWaitGroupAdd: wg.Add inside the goroutine races with wg.Wait on line 16, which may return before Add runs; call wg.Add before the go statement`
+			defer wg.Done()
+			run(job)
+		}()
+	}
+	wg.Wait()
+}
+
+type pool struct {
+	wg sync.WaitGroup
+}
+
+func (p *pool) start(n int) {
+	go func() {
+		defer p.wg.Done()
+		p.wg.Add(n) // want `CogWaitGroupAdd: p.wg.Add inside the goroutine races with p.wg.Wait on line 28`
+	}()
+	p.wg.Wait()
+}
+
+func before(jobs []int) {
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			run(job)
+		}()
+	}
+	wg.Wait()
+}
+
+func noWait() {
+	var wg sync.WaitGroup
+	go func() {
+		wg.Add(1)
+		wg.Done()
+	}()
+}
@@ -0,0 +1,61 @@
+package waitgroupdone
+
+import (
+	"errors"
+	"os"
+	"sync"
+)
+
+func step() error { return errors.New("failed") }
+
+func early(wg *sync.WaitGroup) {
+	wg.Add(1)
+	go func() { // want "CogWaitGroupDone: goroutine can return on line 15 without calling wg.Done, so wg.Wait blocks forever; call defer wg.Done\\(\\) at the top of the goroutine"
+		if err := step(); err != nil {
+			return
+		}
+		wg.Done()
+	}()
+	wg.Wait()
+}
+
+func panics(wg *sync.WaitGroup, n int) {
+	wg.Add(1)
+	go func() { // want `CogWaitGroupDone: goroutine can panic on line 26 without calling wg.Done`
+		if n < 0 {
+			panic("negative")
+		}
+		wg.Done()
+	}()
+}
+
+func deferred(wg *sync.WaitGroup) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := step(); err != nil {
+			return
+		}
+	}()
+}
+
+func everyPath(wg *sync.WaitGroup) {
+	wg.Add(1)
+	go func() {
+		if err := step(); err != nil {
+			wg.Done()
+			return
+		}
+		wg.Done()
+	}()
+}
+
+func exits(wg *sync.WaitGroup) {
+	wg.Add(1)
+	go func() {
+		if err := step(); err != nil {
+			os.Exit(1)
+		}
+		wg.Done()
+	}()
+}
@@ -0,0 +1,33 @@
+package weakrandom
+
+import (
+	"math/rand"
+	"time"
+)
+
+func newSessionToken() []byte {
+	b := make([]byte, 16)
+	for i := range b {
+		b[i] = byte(rand.Intn(256)) // want `CogWeakRandom: math/rand.Intn is used for the function newSessionToken, whose name suggests a secret, but math/rand is predictable; use crypto/rand instead: rand.Int\(rand.Reader, max\) draws a secure number below max`
+	}
+	return b
+}
+
+type user struct {
+	apiKey int64
+}
+
+func register(u *user) {
+	u.apiKey = rand.Int63() // want `CogWeakRandom: math/rand.Int63 is used for the field apiKey`
+	salt := make([]byte, 8)
+	rand.Read(salt) // want `CogWeakRandom: math/rand.Read is used for the variable salt, whose name suggests a secret, but math/rand is predictable; use crypto/rand instead: rand.Read\(b\) fills b with secure random bytes`
+}
+
+func retryToken(base time.Duration) time.Duration {
+	jitter := time.Duration(rand.Int63n(int64(base)))
+	return base + jitter
+}
+
+func pick(items []string) string {
+	return items[rand.Intn(len(items))]
+}
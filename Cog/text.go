@@ -0,0 +1,223 @@
+package cog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ColorMode selects whether WriteText emits ANSI color codes.
+type ColorMode int
+
+const (
+	// ColorAuto colors output written to a terminal unless the NO_COLOR
+	// environment variable is set.
+	ColorAuto ColorMode = iota
+	// ColorAlways always colors output.
+	ColorAlways
+	// ColorNever never colors output.
+	ColorNever
+)
+
+// TextOptions configures WriteText. The zero value is ready to use.
+type TextOptions struct {
+	// Color selects whether to emit ANSI colors; ColorAuto when unset.
+	Color ColorMode
+
+	// ReadFile reads the source shown under each finding; nil means
+	// os.ReadFile. A file that cannot be read is shown without source.
+	ReadFile func(filename string) ([]byte, error)
+}
+
+// ANSI escape sequences used by WriteText.
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiRed    = "\x1b[1;31m"
+	ansiYellow = "\x1b[1;33m"
+	ansiCyan   = "\x1b[1;36m"
+	ansiBlue   = "\x1b[34m"
+)
+
+// WriteText writes findings to w for people reading a terminal, grouped by
+// file and sorted by position. Each finding shows its severity, rule ID and
+// message, its location, and the offending source line with carets under
+// the reported span:
+//
+//	examples/before.go (2 findings)
+//
+//	error[CogTypedNil]: err may be a nil *MyError, which is a non-nil error; ...
+//	  --> examples/before.go:55:9
+//	   |
+//	55 | 	return err
+//	   | 	       ^^^
+//
+// Paths under the working directory are shown relative to it.
+func WriteText(w io.Writer, findings []Finding, opts TextOptions) error {
+	readFile := opts.ReadFile
+	if readFile == nil {
+		readFile = os.ReadFile
+	}
+	tw := &textWriter{color: useColor(w, opts.Color)}
+
+	sorted := slices.Clone(findings)
+	sortFindings(sorted)
+	for i := 0; i < len(sorted); {
+		filename := sorted[i].Pos.Filename
+		j := i
+		for j < len(sorted) && sorted[j].Pos.Filename == filename {
+			j++
+		}
+		src, err := readFile(filename)
+		if err != nil {
+			src = nil // FALLBACK: findings are shown without source
+		}
+		if i > 0 {
+			tw.printf("\n")
+		}
+		noun := "findings"
+		if j-i == 1 {
+			noun = "finding"
+		}
+		tw.printf("%s (%d %s)\n", tw.paint(ansiBold, displayPath(filename)), j-i, noun)
+		for k := i; k < j; k++ {
+			tw.printf("\n")
+			tw.finding(&sorted[k], src)
+		}
+		i = j
+	}
+
+	if _, err := w.Write(tw.buf.Bytes()); err != nil {
+		return fmt.Errorf("cog: text: %w", err)
+	}
+	return nil
+}
+
+// textWriter renders findings for WriteText into a buffer, which is written
+// out in one piece.
+type textWriter struct {
+	buf   bytes.Buffer
+	color bool
+}
+
+func (tw *textWriter) printf(format string, args ...any) {
+	fmt.Fprintf(&tw.buf, format, args...)
+}
+
+// paint wraps s in the ANSI code when colors are on.
+func (tw *textWriter) paint(code, s string) string {
+	if !tw.color {
+		return s
+	}
+	return code + s + ansiReset
+}
+
+// finding renders one finding; src is the content of its file, or nil.
+func (tw *textWriter) finding(f *Finding, src []byte) {
+	sev := f.Severity
+	if sev == "" {
+		sev = SeverityError
+	}
+	code := severityColor(sev)
+	tw.printf("%s: %s\n", tw.paint(code, string(sev)+"["+f.Rule+"]"), tw.paint(ansiBold, f.Message))
+
+	line, ok := sourceLine(src, f.Pos.Offset, f.Pos.Column)
+	gutter := strings.Repeat(" ", len(strconv.Itoa(f.Pos.Line)))
+	tw.printf("%s%s %s:%d:%d\n", gutter, tw.paint(ansiBlue, "-->"), displayPath(f.Pos.Filename), f.Pos.Line, f.Pos.Column)
+	if !ok {
+		return
+	}
+
+	// The caret line repeats the tabs before the span so that it lines up
+	// under the source at any tab width.
+	before := line[:min(f.Pos.Column-1, len(line))]
+	pad := make([]byte, 0, len(before))
+	for _, r := range string(before) {
+		if r == '\t' {
+			pad = append(pad, '\t')
+			continue
+		}
+		pad = append(pad, ' ')
+	}
+	width := 1
+	if f.End.Line == f.Pos.Line && f.End.Column > f.Pos.Column {
+		width = utf8.RuneCount(line[len(before):min(f.End.Column-1, len(line))])
+	} else if rest := utf8.RuneCount(line[len(before):]); rest > 0 {
+		width = rest // the span continues on later lines
+	}
+
+	bar := tw.paint(ansiBlue, "|")
+	tw.printf("%s %s\n", gutter, bar)
+	tw.printf("%s %s %s\n", tw.paint(ansiBlue, strconv.Itoa(f.Pos.Line)), bar, line)
+	tw.printf("%s %s %s%s\n", gutter, bar, pad, tw.paint(code, strings.Repeat("^", max(width, 1))))
+	for _, fix := range f.Fixes {
+		tw.printf("%s %s %s\n", gutter, tw.paint(ansiBlue, "="), "fix: "+fix.Message)
+	}
+}
+
+// severityColor returns the ANSI code for a severity badge.
+func severityColor(sev Severity) string {
+	switch sev {
+	case SeverityWarning:
+		return ansiYellow
+	case SeverityInfo:
+		return ansiCyan
+	}
+	return ansiRed
+}
+
+// sourceLine returns the line of src containing the byte offset, which lies
+// at the 1-based column col, without its line terminator.
+func sourceLine(src []byte, offset, col int) ([]byte, bool) {
+	start := offset - (col - 1)
+	if src == nil || col < 1 || start < 0 || offset > len(src) {
+		return nil, false
+	}
+	line := src[start:]
+	if end := bytes.IndexByte(line, '\n'); end >= 0 {
+		line = line[:end]
+	}
+	return bytes.TrimSuffix(line, []byte("\r")), true
+}
+
+// useColor reports whether WriteText should color output written to w.
+func useColor(w io.Writer, mode ColorMode) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// displayPath returns filename relative to the working directory when it
+// lies beneath it.
+func displayPath(filename string) string {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return filename
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return filename
+	}
+	if rel, err := filepath.Rel(wd, abs); err == nil && filepath.IsLocal(rel) {
+		return rel
+	}
+	return filename
+}
@@ -0,0 +1,117 @@
+package cog
+
+import (
+	"go/ast"
+	"reflect"
+	"strconv"
+)
+
+// timeJSONFormatRule reports time.Time fields of JSON-tagged structs that
+// leave their wire format to time.Time's default.
+//
+//	type Event struct {
+//		ID        string    `json:"id"`
+//		CreatedAt time.Time `json:"created_at"` // RFC 3339, by accident
+//		UpdatedAt time.Time                      // "UpdatedAt", RFC 3339
+//	}
+//
+// time.Time encodes as an RFC 3339 string with nanoseconds. That is fine
+// inside a system but is rarely stated in the contract of a DTO exchanged
+// with others, which then breaks when a consumer expects Unix seconds or a
+// date, or when the field type changes. The rule is opt-in
+// (-timejsonformat.enable) and fires, for types declared in the package
+// whose struct has at least one json tag, on:
+//
+//   - a time.Time or *time.Time field with a json tag other than "-", for
+//     relying on the default format;
+//   - a time.Time or *time.Time field without a json tag, which is also
+//     encoded under its Go name.
+//
+// Use a named type whose MarshalJSON and UnmarshalJSON fix the format, or
+// tag the field `json:"-"` and encode it explicitly. Struct types listed in
+// -timejsonformat.exempt, as "importpath.Name", are not reported.
+var timeJSONFormatRule = &Rule{
+	ID:       "CogTimeJSONFormat",
+	Doc:      "report time.Time fields of JSON-tagged structs that rely on the default encoding",
+	Run:      runTimeJSONFormat,
+	Category: CategoryTypes,
+
+	Severity: SeverityWarning,
+}
+
+var (
+	// timeJSONFormatEnable turns the rule on.
+	timeJSONFormatEnable bool
+
+	// timeJSONFormatExempt lists struct types, as "importpath.Name", whose
+	// time fields are not reported.
+	timeJSONFormatExempt listFlag
+)
+
+func init() {
+	Analyzer.Flags.BoolVar(&timeJSONFormatEnable, "timejsonformat.enable", false,
+		"report time.Time fields of JSON-tagged structs that use the default RFC 3339 encoding")
+	Analyzer.Flags.Var(&timeJSONFormatExempt, "timejsonformat.exempt",
+		"comma-separated struct types (importpath.Name) whose time.Time fields CogTimeJSONFormat allows")
+}
+
+func runTimeJSONFormat(p *Pass) {
+	if !timeJSONFormatEnable {
+		return
+	}
+	for n := range p.Inspector.PreorderSeq((*ast.TypeSpec)(nil)) {
+		spec, ok := n.(*ast.TypeSpec)
+		if !ok {
+			continue
+		}
+		st, ok := spec.Type.(*ast.StructType)
+		if !ok || !hasJSONTag(st) || timeJSONFormatExempt.contains(p.Pkg.Path()+"."+spec.Name.Name) {
+			continue
+		}
+		for _, field := range st.Fields.List {
+			if len(field.Names) == 0 || !isNamed(derefType(p.TypesInfo.TypeOf(field.Type)), "time", "Time") {
+				continue // embedded fields are encoded through their own type
+			}
+			tag, tagged := reflect.StructTag(fieldTag(field)).Lookup("json")
+			if tag == "-" {
+				continue
+			}
+			for _, name := range field.Names {
+				if !name.IsExported() {
+					continue
+				}
+				if tagged {
+					p.Report(name, "JSON field "+spec.Name.Name+"."+name.Name+" uses time.Time's default RFC 3339 "+
+						"encoding, which consumers may not expect; give it a named type with MarshalJSON and "+
+						"UnmarshalJSON fixing the format, or exempt "+spec.Name.Name+" with -timejsonformat.exempt")
+					continue
+				}
+				p.Report(name, "time field "+spec.Name.Name+"."+name.Name+" of a JSON-tagged struct has no json "+
+					"tag, so it is encoded as \""+name.Name+"\" in time.Time's default RFC 3339 format; tag it, "+
+					"and fix the format with a named type, or tag it `json:\"-\"`")
+			}
+		}
+	}
+}
+
+// hasJSONTag reports whether some field of st has a json tag.
+func hasJSONTag(st *ast.StructType) bool {
+	for _, field := range st.Fields.List {
+		if _, ok := reflect.StructTag(fieldTag(field)).Lookup("json"); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldTag returns the unquoted tag of field, or "".
+func fieldTag(field *ast.Field) string {
+	if field.Tag == nil {
+		return ""
+	}
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return ""
+	}
+	return tag
+}
@@ -0,0 +1,15 @@
+package cog
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestTimeJSONFormat(t *testing.T) {
+	savedEnable, savedExempt := timeJSONFormatEnable, timeJSONFormatExempt
+	t.Cleanup(func() { timeJSONFormatEnable, timeJSONFormatExempt = savedEnable, savedExempt })
+	timeJSONFormatEnable = true
+	timeJSONFormatExempt = listFlag{"timejsonformat.Audit"}
+	analysistest.Run(t, analysistest.TestData(), ruleAnalyzer(timeJSONFormatRule), "timejsonformat")
+}
@@ -0,0 +1,174 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/cfg"
+)
+
+// timerLeakRule reports timers and tickers that are never released. It
+// covers three mistakes. time.After used as a select case inside a loop
+// allocates a new timer on each iteration, and before Go 1.23 none of
+// them could be collected until it fired, so a busy loop with a long
+// timeout piles up timers:
+//
+//	for {
+//		select {
+//		case msg := <-ch:
+//			handle(msg)
+//		case <-time.After(time.Minute): // a new timer per message
+//			return
+//		}
+//	}
+//
+// time.Tick returns only the channel of its ticker, which can therefore
+// never be stopped, and is reported wherever it is called. A ticker or
+// timer from time.NewTicker or time.NewTimer must be stopped on every path
+// out of the function:
+//
+//	t := time.NewTicker(time.Second)
+//	defer t.Stop()
+//
+// `defer t.Stop()`, a direct Stop, or handing t to other code — returning
+// it, storing it, passing it to a function — satisfies the rule. A
+// one-shot select on time.After outside a loop is fine and not reported.
+//
+// Go 1.23 made unreferenced timers and tickers collectable, stopped or not,
+// so none of the three leaks, and the rule only fires for files whose
+// language version is older.
+var timerLeakRule = &Rule{
+	ID:       "CogTimerLeak",
+	Doc:      "report time.After in loops, time.Tick, and tickers and timers that are not stopped",
+	Run:      runTimerLeak,
+	Category: CategoryPerformance,
+}
+
+func runTimerLeak(p *Pass) {
+	timeAfterInLoop(p)
+	cfgs := make(map[*ast.BlockStmt]*cfg.CFG)
+	for c := range p.Inspector.Root().Preorder((*ast.CallExpr)(nil), (*ast.AssignStmt)(nil), (*ast.ExprStmt)(nil)) {
+		if timersCollected(p, enclosingFile(c)) {
+			continue
+		}
+		if call, ok := c.Node().(*ast.CallExpr); ok {
+			if calleeName(p.TypesInfo, call) == "time.Tick" {
+				p.Report(call, "time.Tick creates a ticker that can never be stopped; use time.NewTicker "+
+					"and `defer t.Stop()`")
+			}
+			continue
+		}
+		unstoppedTimer(p, c, cfgs)
+	}
+}
+
+// timeAfterInLoop reports time.After select cases inside loops.
+func timeAfterInLoop(p *Pass) {
+	for c := range p.Inspector.Root().Preorder((*ast.CommClause)(nil)) {
+		clause, ok := c.Node().(*ast.CommClause)
+		if !ok || clause.Comm == nil || timersCollected(p, enclosingFile(c)) {
+			continue
+		}
+		var recv ast.Expr
+		switch comm := clause.Comm.(type) {
+		case *ast.ExprStmt:
+			recv = comm.X
+		case *ast.AssignStmt:
+			if len(comm.Rhs) == 1 {
+				recv = comm.Rhs[0]
+			}
+		}
+		arrow, ok := ast.Unparen(recv).(*ast.UnaryExpr)
+		if !ok || arrow.Op != token.ARROW {
+			continue
+		}
+		call, ok := ast.Unparen(arrow.X).(*ast.CallExpr)
+		if !ok || calleeName(p.TypesInfo, call) != "time.After" || !inLoopBody(c.Parent()) {
+			continue
+		}
+		p.Report(call, "time.After in a select inside a loop allocates a new timer on every iteration; "+
+			"create one with time.NewTimer before the loop and Reset it each iteration")
+	}
+}
+
+// unstoppedTimer reports the time.NewTicker or time.NewTimer call of the
+// statement at c when its result is discarded or not stopped on some path
+// out of the function. cfgs caches the function control flow graphs.
+func unstoppedTimer(p *Pass, c inspector.Cursor, cfgs map[*ast.BlockStmt]*cfg.CFG) {
+	acq, ok := acquisition(p, c, func(t types.Type) bool {
+		return isNamedPointer(t, "time", "Ticker") || isNamedPointer(t, "time", "Timer")
+	})
+	if !ok {
+		return
+	}
+	what := calleeName(p.TypesInfo, acq.call)
+	if what != "time.NewTicker" && what != "time.NewTimer" {
+		return
+	}
+	kind := "ticker"
+	if what == "time.NewTimer" {
+		kind = "timer"
+	}
+	if acq.resource == nil {
+		p.Report(acq.call, kind+" from "+what+" is discarded, so it can never be stopped")
+		return
+	}
+
+	_, body := enclosingFunc(c)
+	if body == nil {
+		return
+	}
+	g, ok := cfgs[body]
+	if !ok {
+		g = funcCFG(p, body)
+		cfgs[body] = g
+	}
+	t := acq.resource
+	exit, leaked := findLeak(p, g, body, acq.stmt, leakCheck{
+		released: func(n ast.Node) bool { return stopsTimer(p, n, t) },
+		unheld:   acq.unheld(p),
+	})
+	if !leaked {
+		return
+	}
+	p.Report(acq.call, kind+" "+t.Name()+" from "+what+" is not stopped on the path to line "+
+		strconv.Itoa(p.Fset.Position(exit).Line)+"; add `defer "+t.Name()+".Stop()` after creating it")
+}
+
+// stopsTimer reports whether n stops the ticker or timer t or hands it
+// off: `t.Stop()`, t returned, stored, assigned or passed to a function.
+// Receiving from t.C or calling t.Reset does neither.
+func stopsTimer(p *Pass, n ast.Node, t *types.Var) bool {
+	cur, ok := p.Inspector.Root().FindNode(n)
+	if !ok {
+		return false
+	}
+	for ic := range cur.Preorder((*ast.Ident)(nil)) {
+		id, ok := ic.Node().(*ast.Ident)
+		if !ok || p.TypesInfo.Uses[id] != t {
+			continue
+		}
+		parent := ic.Parent()
+		switch pn := parent.Node().(type) {
+		case *ast.SelectorExpr:
+			call, ok := parent.Parent().Node().(*ast.CallExpr)
+			if ok && call.Fun == pn && pn.Sel.Name == "Stop" {
+				return true
+			}
+		case *ast.CallExpr:
+			if pn.Fun != id {
+				return true
+			}
+		case *ast.ReturnStmt, *ast.CompositeLit, *ast.KeyValueExpr, *ast.UnaryExpr:
+			return true
+		case *ast.AssignStmt:
+			if !isLHS(pn, id) {
+				return true
+			}
+		}
+	}
+	return false
+}
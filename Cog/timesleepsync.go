@@ -0,0 +1,147 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+)
+
+// timeSleepSyncRule reports time.Sleep used to wait for a goroutine.
+//
+//	go func() { done = true }()
+//	time.Sleep(100 * time.Millisecond) // hope the goroutine has run by now
+//	if !done {
+//		t.Fatal("not done")
+//	}
+//
+// A sleep guarantees nothing about what another goroutine has done: on a
+// loaded machine or under the race detector it has not run yet, so the
+// code is flaky, and when it has run the read is still a data race. The
+// rule fires on a time.Sleep statement whose next statement reads a
+// variable that a function literal started earlier by a go statement in
+// the same function writes, unless the function waits for it (a Wait call,
+// a channel receive or a select) in between. The rule is a heuristic, so
+// it is opt-in (-timesleepsync.enable).
+var timeSleepSyncRule = &Rule{
+	ID:       "CogTimeSleepSync",
+	Doc:      "report time.Sleep used to wait for a goroutine's writes",
+	Run:      runTimeSleepSync,
+	Category: CategoryConcurrency,
+
+	Severity: SeverityWarning,
+}
+
+// timeSleepSyncEnable turns the rule on.
+var timeSleepSyncEnable bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&timeSleepSyncEnable, "timesleepsync.enable", false,
+		"report time.Sleep followed by a read of a variable a goroutine writes (heuristic)")
+}
+
+func runTimeSleepSync(p *Pass) {
+	if !timeSleepSyncEnable {
+		return
+	}
+	for c := range p.Inspector.Root().Preorder((*ast.CallExpr)(nil)) {
+		call, ok := c.Node().(*ast.CallExpr)
+		if !ok || calleeName(p.TypesInfo, call) != "time.Sleep" || inGoStmt(c) {
+			continue
+		}
+		if _, ok := c.Parent().Node().(*ast.ExprStmt); !ok {
+			continue
+		}
+		following := followingStmts(c.Parent())
+		_, body := enclosingFunc(c)
+		if len(following) == 0 || body == nil {
+			continue
+		}
+		next := following[0]
+		bc, ok := p.Inspector.Root().FindNode(body)
+		if !ok {
+			continue
+		}
+		waits := waitPoints(p, bc)
+		for gc := range bc.Preorder((*ast.GoStmt)(nil)) {
+			goStmt, ok := gc.Node().(*ast.GoStmt)
+			if !ok || goStmt.End() > call.Pos() || waitsBetween(waits, goStmt.End(), next.Pos()) {
+				continue
+			}
+			lit, ok := ast.Unparen(goStmt.Call.Fun).(*ast.FuncLit)
+			if !ok {
+				continue
+			}
+			v := readWritten(p, next, goroutineWrites(p, lit))
+			if v == nil {
+				continue
+			}
+			p.Report(call, "time.Sleep waits for the goroutine started on line "+
+				strconv.Itoa(p.Fset.Position(goStmt.Pos()).Line)+" to write "+v.Name()+", which it may not have "+
+				"done yet, and the read races with the write; wait for it with a channel or a sync.WaitGroup")
+			break
+		}
+	}
+}
+
+// goroutineWrites returns the variables declared outside lit that its body
+// assigns to or increments, directly or through a field, index or pointer.
+func goroutineWrites(p *Pass, lit *ast.FuncLit) map[*types.Var]bool {
+	writes := make(map[*types.Var]bool)
+	record := func(e ast.Expr) {
+		id := writtenIdent(e)
+		if id == nil {
+			return
+		}
+		if v, ok := p.TypesInfo.Uses[id].(*types.Var); ok && (v.Pos() < lit.Pos() || v.Pos() >= lit.End()) {
+			writes[v] = true
+		}
+	}
+	ast.Inspect(lit.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			if n.Tok != token.DEFINE {
+				for _, lhs := range n.Lhs {
+					record(lhs)
+				}
+			}
+		case *ast.IncDecStmt:
+			record(n.X)
+		}
+		return true
+	})
+	return writes
+}
+
+// writtenIdent returns the variable at the root of the assigned expression
+// e: v in v, v.f, v[i] and *v.
+func writtenIdent(e ast.Expr) *ast.Ident {
+	for {
+		switch x := ast.Unparen(e).(type) {
+		case *ast.Ident:
+			return x
+		case *ast.SelectorExpr:
+			e = x.X
+		case *ast.IndexExpr:
+			e = x.X
+		case *ast.StarExpr:
+			e = x.X
+		default:
+			return nil
+		}
+	}
+}
+
+// readWritten returns a variable of writes that stmt uses, or nil.
+func readWritten(p *Pass, stmt ast.Stmt, writes map[*types.Var]bool) *types.Var {
+	var found *types.Var
+	ast.Inspect(stmt, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok {
+			if v, ok := p.TypesInfo.Uses[id].(*types.Var); ok && writes[v] {
+				found = v
+			}
+		}
+		return found == nil
+	})
+	return found
+}
@@ -0,0 +1,14 @@
+package cog
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestTimeSleepSync(t *testing.T) {
+	saved := timeSleepSyncEnable
+	t.Cleanup(func() { timeSleepSyncEnable = saved })
+	timeSleepSyncEnable = true
+	analysistest.Run(t, analysistest.TestData(), ruleAnalyzer(timeSleepSyncRule), "timesleepsync")
+}
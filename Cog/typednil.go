@@ -0,0 +1,141 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// typedNilRule reports Mistake 4: a nil pointer returned through an
+// interface result, which produces a non-nil interface holding a nil value.
+//
+//	var err *MyError
+//	if fail {
+//		err = &MyError{msg: "failed"}
+//	}
+//	return err // err != nil for the caller even when fail is false
+//
+// The rule works on SSA so that a pointer declared locally is followed
+// through assignments and branches. It only fires when a nil constant can
+// actually reach the return, and stays quiet when a dominating nil check
+// (`if err != nil { return err }`) proves the value non-nil.
+var typedNilRule = &Rule{
+	ID:       "CogTypedNil",
+	Doc:      "report nil pointers returned as a non-nil interface value",
+	Run:      runTypedNil,
+	Category: CategoryTypes,
+}
+
+func runTypedNil(p *Pass) {
+	returns := make(map[token.Pos]*ast.ReturnStmt)
+	for n := range p.Inspector.PreorderSeq((*ast.ReturnStmt)(nil)) {
+		ret, ok := n.(*ast.ReturnStmt)
+		if ok {
+			returns[ret.Return] = ret
+		}
+	}
+
+	for _, fn := range p.SSA.SrcFuncs {
+		results := fn.Signature.Results()
+		for _, b := range fn.Blocks {
+			ret, ok := b.Instrs[len(b.Instrs)-1].(*ssa.Return)
+			if !ok {
+				continue
+			}
+			stmt := returns[ret.Pos()]
+			for i, v := range ret.Results {
+				if !types.IsInterface(results.At(i).Type()) {
+					continue
+				}
+				mi, ok := v.(*ssa.MakeInterface)
+				if !ok {
+					continue
+				}
+				if _, ok := mi.X.Type().Underlying().(*types.Pointer); !ok {
+					continue
+				}
+				if !mayBeNil(mi.X, make(map[ssa.Value]bool)) || provenNonNil(mi.X, b) {
+					continue
+				}
+
+				if stmt == nil {
+					continue // synthesized return without syntax
+				}
+				var rng ast.Node = stmt
+				name := "value"
+				if len(stmt.Results) == len(ret.Results) {
+					rng = stmt.Results[i]
+					name = types.ExprString(stmt.Results[i])
+				}
+				p.Report(rng, name+" may be a nil "+types.TypeString(mi.X.Type(), types.RelativeTo(p.Pkg))+
+					", which is a non-nil "+types.TypeString(results.At(i).Type(), types.RelativeTo(p.Pkg))+
+					"; return a bare nil on the nil path")
+			}
+		}
+	}
+}
+
+// mayBeNil reports whether a nil constant can flow into v through phi nodes.
+// Values produced any other way (allocations, calls, parameters) are assumed
+// non-nil so the rule only fires on nils introduced by local declarations.
+func mayBeNil(v ssa.Value, seen map[ssa.Value]bool) bool {
+	if seen[v] {
+		return false
+	}
+	seen[v] = true
+
+	switch v := v.(type) {
+	case *ssa.Const:
+		return v.IsNil()
+	case *ssa.Phi:
+		for _, e := range v.Edges {
+			if mayBeNil(e, seen) {
+				return true
+			}
+		}
+		return false
+	case *ssa.ChangeType:
+		return mayBeNil(v.X, seen)
+	default:
+		return false
+	}
+}
+
+// provenNonNil reports whether every path to block b passes through a
+// branch that compared v against nil and took the non-nil edge.
+func provenNonNil(v ssa.Value, b *ssa.BasicBlock) bool {
+	for dom := b.Idom(); dom != nil; dom = dom.Idom() {
+		ifInstr, ok := dom.Instrs[len(dom.Instrs)-1].(*ssa.If)
+		if !ok {
+			continue
+		}
+		cmp, ok := ifInstr.Cond.(*ssa.BinOp)
+		if !ok || (cmp.Op != token.EQL && cmp.Op != token.NEQ) {
+			continue
+		}
+		if !comparesWithNil(cmp, v) {
+			continue
+		}
+		// Succs[0] is the true edge. For `v != nil` that is the non-nil
+		// side; for `v == nil` it is Succs[1].
+		nonNil := dom.Succs[0]
+		if cmp.Op == token.EQL {
+			nonNil = dom.Succs[1]
+		}
+		if len(nonNil.Preds) == 1 && nonNil.Dominates(b) {
+			return true
+		}
+	}
+	return false
+}
+
+// comparesWithNil reports whether cmp has the form `v op nil` or `nil op v`.
+func comparesWithNil(cmp *ssa.BinOp, v ssa.Value) bool {
+	isNil := func(x ssa.Value) bool {
+		c, ok := x.(*ssa.Const)
+		return ok && c.IsNil()
+	}
+	return (cmp.X == v && isNil(cmp.Y)) || (cmp.Y == v && isNil(cmp.X))
+}
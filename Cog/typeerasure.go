@@ -0,0 +1,224 @@
+package cog
+
+import (
+	"go/ast"
+	"go/types"
+	"strconv"
+
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// typeErasureRule reports Mistake 1: an `any` parameter or result where the
+// code does not need one, so callers lose a type a type parameter or a
+// concrete type would have kept.
+//
+//	func ProcessAny(data any) any { return data } // callers get back an any
+//
+// Whether a type parameter "would work" is undecidable in general, so the
+// rule is opt-in (-typeerasure.enable) and fires only on these patterns:
+//
+//   - a parameter that is only ever returned unchanged, as in ProcessAny; a
+//     type parameter preserves its type, as in ProcessTyped;
+//   - a parameter of an unexported function, never used as a function
+//     value, to which every call in the package passes the same
+//     concrete type;
+//   - a result that every return statement fills with the same concrete
+//     type.
+//
+// Variadic `...any` parameters, such as those of fmt wrappers, and methods,
+// which cannot declare type parameters, are not reported.
+var typeErasureRule = &Rule{
+	ID:       "CogTypeErasure",
+	Doc:      "report any/interface{} parameters and results that a type parameter or concrete type could replace",
+	Run:      runTypeErasure,
+	Category: CategoryTypes,
+
+	Severity: SeverityWarning,
+}
+
+// typeErasureEnable turns the rule on.
+var typeErasureEnable bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&typeErasureEnable, "typeerasure.enable", false,
+		"report any parameters and results that a type parameter or concrete type could replace (heuristic)")
+}
+
+func runTypeErasure(p *Pass) {
+	if !typeErasureEnable {
+		return
+	}
+	args := callArgTypes(p)
+
+	for c := range p.Inspector.Root().Preorder((*ast.FuncDecl)(nil)) {
+		decl, ok := c.Node().(*ast.FuncDecl)
+		if !ok || decl.Recv != nil || decl.Body == nil {
+			continue
+		}
+		fn, ok := p.TypesInfo.Defs[decl.Name].(*types.Func)
+		if !ok {
+			continue
+		}
+		sig := fn.Signature()
+
+		index := 0
+		for _, field := range decl.Type.Params.List {
+			for _, name := range field.Names {
+				param, ok := p.TypesInfo.Defs[name].(*types.Var)
+				variadic := sig.Variadic() && index == sig.Params().Len()-1
+				i := index
+				index++
+				if !ok || name.Name == "_" || variadic || !isEmptyInterface(param.Type()) {
+					continue
+				}
+				if onlyReturned(p, c, param) {
+					p.Report(name, name.Name+" is typed any but only returned unchanged, so callers lose its type; "+
+						"introduce a type parameter for it, as ProcessTyped does")
+					continue
+				}
+				if fn.Exported() {
+					continue
+				}
+				if t := soleType(args[fn], i); t != nil {
+					typ := types.TypeString(t, types.RelativeTo(p.Pkg))
+					p.Report(name, name.Name+" is typed any but every call passes a value of type "+typ+
+						"; take "+typ+", or a type parameter as ProcessTyped does")
+				}
+			}
+			index += max(0, 1-len(field.Names)) // an unnamed parameter
+		}
+
+		if decl.Type.Results == nil {
+			continue
+		}
+		index = 0
+		for _, field := range decl.Type.Results.List {
+			n := max(len(field.Names), 1)
+			for i := index; i < index+n; i++ {
+				if !isEmptyInterface(sig.Results().At(i).Type()) {
+					continue
+				}
+				if t := returnedType(p, c, sig.Results().Len(), i); t != nil {
+					typ := types.TypeString(t, types.RelativeTo(p.Pkg))
+					p.Report(field.Type, "result "+strconv.Itoa(i+1)+" of "+decl.Name.Name+
+						" is typed any but every return yields a value of type "+typ+"; return "+typ+" instead")
+				}
+			}
+			index += n
+		}
+	}
+}
+
+// isEmptyInterface reports whether t is any or interface{}, not a named
+// type or a type parameter constrained by any.
+func isEmptyInterface(t types.Type) bool {
+	switch types.Unalias(t).(type) {
+	case *types.Named, *types.TypeParam:
+		return false
+	}
+	iface, ok := t.Underlying().(*types.Interface)
+	return ok && iface.Empty()
+}
+
+// onlyReturned reports whether every use of param in the function at c is
+// a result of a return statement.
+func onlyReturned(p *Pass, c inspector.Cursor, param *types.Var) bool {
+	used := false
+	for ic := range c.Preorder((*ast.Ident)(nil)) {
+		id, ok := ic.Node().(*ast.Ident)
+		if !ok || p.TypesInfo.Uses[id] != param {
+			continue
+		}
+		if _, ok := ic.Parent().Node().(*ast.ReturnStmt); !ok {
+			return false
+		}
+		used = true
+	}
+	return used
+}
+
+// callArgTypes records, for each function of the package, the argument
+// types of every direct call, one slice per call. A function used other
+// than by calling it maps to nil, since its signature may be required by a
+// function type.
+func callArgTypes(p *Pass) map[*types.Func][][]types.Type {
+	args := make(map[*types.Func][][]types.Type)
+	valueUse := make(map[*types.Func]bool)
+	for id, obj := range p.TypesInfo.Uses {
+		fn, ok := obj.(*types.Func)
+		if !ok || fn.Pkg() != p.Pkg {
+			continue
+		}
+		if _, ok := directCall(p, id); !ok {
+			valueUse[fn] = true
+		}
+	}
+	for n := range p.Inspector.PreorderSeq((*ast.CallExpr)(nil)) {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+		fn, ok := typeutil.Callee(p.TypesInfo, call).(*types.Func)
+		if !ok || fn.Pkg() != p.Pkg || valueUse[fn] {
+			continue
+		}
+		ts := make([]types.Type, 0, len(call.Args))
+		for _, arg := range call.Args {
+			t := p.TypesInfo.TypeOf(arg)
+			if _, ok := t.(*types.Tuple); ok {
+				t = nil // f(g()) spreads several results over the parameters
+			}
+			ts = append(ts, types.Default(t))
+		}
+		args[fn] = append(args[fn], ts)
+	}
+	return args
+}
+
+// soleType returns the concrete type every call passes as argument i, or
+// nil when there are no calls or they disagree.
+func soleType(calls [][]types.Type, i int) types.Type {
+	var sole types.Type
+	for _, ts := range calls {
+		if i >= len(ts) || ts[i] == nil || types.IsInterface(ts[i]) || isUntypedNil(ts[i]) {
+			return nil
+		}
+		if sole != nil && !types.Identical(sole, ts[i]) {
+			return nil
+		}
+		sole = ts[i]
+	}
+	return sole
+}
+
+// returnedType returns the concrete type every return statement of the
+// function at c yields as result i of n, or nil when they disagree or a
+// return does not list its results one by one.
+func returnedType(p *Pass, c inspector.Cursor, n, i int) types.Type {
+	var sole types.Type
+	for rc := range c.Preorder((*ast.ReturnStmt)(nil)) {
+		ret, ok := rc.Node().(*ast.ReturnStmt)
+		if !ok || enclosingFuncLit(rc) != nil {
+			continue
+		}
+		if len(ret.Results) != n {
+			return nil
+		}
+		t := types.Default(p.TypesInfo.TypeOf(ret.Results[i]))
+		if t == nil || types.IsInterface(t) || isUntypedNil(t) {
+			return nil
+		}
+		if sole != nil && !types.Identical(sole, t) {
+			return nil
+		}
+		sole = t
+	}
+	return sole
+}
+
+// isUntypedNil reports whether t is the type of the predeclared nil.
+func isUntypedNil(t types.Type) bool {
+	basic, ok := t.(*types.Basic)
+	return ok && basic.Kind() == types.UntypedNil
+}
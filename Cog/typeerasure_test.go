@@ -0,0 +1,14 @@
+package cog
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestTypeErasure(t *testing.T) {
+	saved := typeErasureEnable
+	t.Cleanup(func() { typeErasureEnable = saved })
+	typeErasureEnable = true
+	analysistest.Run(t, analysistest.TestData(), ruleAnalyzer(typeErasureRule), "typeerasure")
+}
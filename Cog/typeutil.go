@@ -0,0 +1,71 @@
+package cog
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// errorType is the predeclared error interface.
+var errorType = types.Universe.Lookup("error").Type()
+
+// isErrorType reports whether t is exactly the error interface.
+func isErrorType(t types.Type) bool {
+	return t != nil && types.Identical(t, errorType)
+}
+
+// resultTypes returns the result types of call, or nil when call is a type
+// conversion or a builtin without results.
+func resultTypes(info *types.Info, call *ast.CallExpr) []types.Type {
+	if tv, ok := info.Types[call.Fun]; ok && tv.IsType() {
+		return nil
+	}
+	t := info.TypeOf(call)
+	if t == nil {
+		return nil
+	}
+	tuple, ok := t.(*types.Tuple)
+	if !ok {
+		return []types.Type{t}
+	}
+	ts := make([]types.Type, 0, tuple.Len())
+	for i := range tuple.Len() {
+		ts = append(ts, tuple.At(i).Type())
+	}
+	return ts
+}
+
+// calleeName returns the qualified name of the function call invokes, such
+// as "fmt.Println" or "(*bytes.Buffer).Write", falling back to the source
+// text of the callee for dynamic calls.
+func calleeName(info *types.Info, call *ast.CallExpr) string {
+	if fn, ok := typeutil.Callee(info, call).(*types.Func); ok {
+		return fn.FullName()
+	}
+	return types.ExprString(call.Fun)
+}
+
+// typeExpr renders t as source text valid in file, naming packages by their
+// import name there. It reports false when t mentions a package that file
+// does not import, since the text would not compile without a new import.
+func typeExpr(p *Pass, file *ast.File, t types.Type) (string, bool) {
+	names := make(map[*types.Package]string)
+	for _, imp := range file.Imports {
+		if pn := p.TypesInfo.PkgNameOf(imp); pn != nil {
+			names[pn.Imported()] = pn.Name()
+		}
+	}
+	ok := true
+	s := types.TypeString(t, func(pkg *types.Package) string {
+		if pkg == p.Pkg {
+			return ""
+		}
+		name, found := names[pkg]
+		if !found || name == "_" || name == "." {
+			ok = false
+		}
+		return name
+	})
+	return s, ok
+}
@@ -0,0 +1,111 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// uncheckedAssertRule reports a single-result type assertion, which panics
+// when the dynamic type does not match.
+//
+//	n := v.(int) // panics if v holds a string
+//
+// The two-result form `n, ok := v.(int)` or a type switch handles the
+// mismatch instead. Assertions inside a `switch x.(type)` statement are not
+// reported. Where a panic is the intended outcome, as in Must-style
+// helpers, -uncheckedassert.exempt exempts functions by name.
+var uncheckedAssertRule = &Rule{
+	ID:       "CogUncheckedAssert",
+	Doc:      "report single-result type assertions that panic on a mismatch",
+	Run:      runUncheckedAssert,
+	Category: CategoryTypes,
+	Fixable:  true,
+}
+
+// uncheckedAssertExempt exempts functions by name ("Func" or "Recv.Method").
+var uncheckedAssertExempt regexpFlag
+
+func init() {
+	Analyzer.Flags.Var(&uncheckedAssertExempt, "uncheckedassert.exempt",
+		"regexp of function names (Func or Recv.Method) exempt from CogUncheckedAssert")
+}
+
+func runUncheckedAssert(p *Pass) {
+	for c := range p.Inspector.Root().Preorder((*ast.TypeAssertExpr)(nil)) {
+		ta, ok := c.Node().(*ast.TypeAssertExpr)
+		if !ok || ta.Type == nil || commaOK(c) || inTypeSwitch(c) {
+			continue
+		}
+		if decl := enclosingDecl(c); decl != nil && uncheckedAssertExempt.matches(funcDeclName(decl)) {
+			continue
+		}
+		x, typ := types.ExprString(ta.X), types.ExprString(ta.Type)
+		p.Report(ta, x+".("+typ+") panics when the dynamic type of "+x+" is not "+typ+
+			"; use the two-result form `v, ok := "+x+".("+typ+")` or a type switch", commaOKFix(p, c, ta)...)
+	}
+}
+
+// commaOK reports whether the assertion at c is the single value of a
+// two-result assignment or declaration.
+func commaOK(c inspector.Cursor) bool {
+	switch parent := c.Parent().Node().(type) {
+	case *ast.AssignStmt:
+		return len(parent.Lhs) == 2 && len(parent.Rhs) == 1
+	case *ast.ValueSpec:
+		return len(parent.Names) == 2 && len(parent.Values) == 1
+	}
+	return false
+}
+
+// inTypeSwitch reports whether c lies inside a type switch of the same
+// function.
+func inTypeSwitch(c inspector.Cursor) bool {
+	for ec := range c.Enclosing((*ast.TypeSwitchStmt)(nil), (*ast.FuncLit)(nil), (*ast.FuncDecl)(nil)) {
+		_, ok := ec.Node().(*ast.TypeSwitchStmt)
+		return ok
+	}
+	return false
+}
+
+// commaOKFix turns `v := x.(T)` into the comma-ok form followed by an
+// `if !ok` block. The block returns an error when the enclosing function
+// returns one and is otherwise left as a TODO.
+func commaOKFix(p *Pass, c inspector.Cursor, ta *ast.TypeAssertExpr) []analysis.SuggestedFix {
+	stmt, ok := c.Parent().Node().(*ast.AssignStmt)
+	if !ok || stmt.Tok != token.DEFINE || len(stmt.Lhs) != 1 || !inStatementList(c.Parent()) {
+		return nil
+	}
+	// `ok` must be new in this scope, or a bool that := can reuse.
+	scope := p.Pkg.Scope().Innermost(stmt.Pos())
+	if scope == nil {
+		return nil
+	}
+	if obj := scope.Lookup("ok"); obj != nil && !types.Identical(obj.Type(), types.Typ[types.Bool]) {
+		return nil
+	}
+
+	x, typ := types.ExprString(ta.X), types.ExprString(ta.Type)
+	body := "// TODO: handle the dynamic type of " + x + " not being " + typ
+	edits := make([]analysis.TextEdit, 0, 3)
+	file, sig := enclosingFile(c), enclosingSignature(p, c)
+	if _, isIdent := ast.Unparen(ta.X).(*ast.Ident); isIdent && file != nil && sig != nil {
+		fmtPkg, imports := importName(p, file, "fmt")
+		errExpr := fmtPkg + ".Errorf(" + strconv.Quote("unexpected type %T for "+x) + ", " + x + ")"
+		if ret, ok := returnZeros(p, file, sig, errExpr); ok {
+			body = "return " + ret
+			edits = append(edits, imports...)
+		}
+	}
+
+	indent, eol := lineIndent(p, stmt.Pos()), lineEnd(p, stmt.End())
+	edits = append(edits,
+		analysis.TextEdit{Pos: stmt.Lhs[0].End(), End: stmt.Lhs[0].End(), NewText: []byte(", ok")},
+		analysis.TextEdit{Pos: eol, End: eol, NewText: []byte("\n" + indent + "if !ok {\n" + indent + "\t" + body + "\n" + indent + "}")},
+	)
+	return []analysis.SuggestedFix{{Message: "Use the comma-ok form", TextEdits: edits}}
+}
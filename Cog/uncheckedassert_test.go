@@ -0,0 +1,16 @@
+package cog
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestUncheckedAssert(t *testing.T) {
+	saved := uncheckedAssertExempt
+	t.Cleanup(func() { uncheckedAssertExempt = saved })
+	if err := uncheckedAssertExempt.Set("^Must"); err != nil {
+		t.Fatal(err)
+	}
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), ruleAnalyzer(uncheckedAssertRule), "uncheckedassert")
+}
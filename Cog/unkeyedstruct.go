@@ -0,0 +1,91 @@
+package cog
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// unkeyedStructRule reports a composite literal of a struct type from
+// another package written without field names.
+//
+//	cfg := tls.Config{nil, time.Now, nil} // which fields are these?
+//
+// Positional fields compile only while the struct keeps exactly the fields
+// listed, in order; when the other package adds or reorders one, the
+// literal breaks or, worse, silently fills the wrong fields. Literals of the
+// package's own types are not reported, nor are the small value types
+// listed in -unkeyedstruct.exempt, whose layout is part of their contract.
+// The fix names each field in declaration order.
+var unkeyedStructRule = &Rule{
+	ID:       "CogUnkeyedStruct",
+	Doc:      "report unkeyed composite literals of struct types from other packages",
+	Run:      runUnkeyedStruct,
+	Category: CategoryStyle,
+	Fixable:  true,
+}
+
+// unkeyedStructExempt lists struct types, as "importpath.Name", whose
+// unkeyed literals are allowed.
+var unkeyedStructExempt = listFlag{
+	"image.Point",
+	"image.Rectangle",
+	"image/color.Alpha",
+	"image/color.Gray",
+	"image/color.NRGBA",
+	"image/color.RGBA",
+}
+
+func init() {
+	Analyzer.Flags.Var(&unkeyedStructExempt, "unkeyedstruct.exempt",
+		"comma-separated struct types (importpath.Name) whose unkeyed literals are allowed")
+}
+
+func runUnkeyedStruct(p *Pass) {
+	for n := range p.Inspector.PreorderSeq((*ast.CompositeLit)(nil)) {
+		lit, ok := n.(*ast.CompositeLit)
+		if !ok || len(lit.Elts) == 0 {
+			continue
+		}
+		if _, keyed := lit.Elts[0].(*ast.KeyValueExpr); keyed {
+			continue
+		}
+		t := types.Unalias(p.TypesInfo.TypeOf(lit))
+		if ptr, ok := t.(*types.Pointer); ok {
+			t = types.Unalias(ptr.Elem()) // an elided &T in a slice or map literal
+		}
+		named, ok := t.(*types.Named)
+		if !ok {
+			continue
+		}
+		st, ok := named.Underlying().(*types.Struct)
+		obj := named.Obj()
+		if !ok || obj.Pkg() == nil || obj.Pkg() == p.Pkg {
+			continue
+		}
+		name := obj.Pkg().Path() + "." + obj.Name()
+		if unkeyedStructExempt.contains(name) {
+			continue
+		}
+		p.Report(lit, "unkeyed literal of "+obj.Pkg().Name()+"."+obj.Name()+" from another package breaks "+
+			"silently when the struct gains or reorders fields; name the fields", keyFieldsFix(lit, st)...)
+	}
+}
+
+// keyFieldsFix prefixes each element of the unkeyed literal with the name
+// of the field it sets.
+func keyFieldsFix(lit *ast.CompositeLit, st *types.Struct) []analysis.SuggestedFix {
+	if st.NumFields() != len(lit.Elts) {
+		return nil
+	}
+	edits := make([]analysis.TextEdit, 0, len(lit.Elts))
+	for i, elt := range lit.Elts {
+		edits = append(edits, analysis.TextEdit{
+			Pos:     elt.Pos(),
+			End:     elt.Pos(),
+			NewText: []byte(st.Field(i).Name() + ": "),
+		})
+	}
+	return []analysis.SuggestedFix{{Message: "Name the fields", TextEdits: edits}}
+}
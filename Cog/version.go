@@ -0,0 +1,35 @@
+package cog
+
+import (
+	"go/ast"
+	"go/version"
+)
+
+// perIterationLoopVars reports whether file is compiled with Go 1.22
+// semantics, where each loop iteration declares fresh loop variables.
+func perIterationLoopVars(p *Pass, file *ast.File) bool {
+	return atLeastGo(p, file, "go1.22")
+}
+
+// timersCollected reports whether file is compiled for Go 1.23 or later,
+// where timers and tickers that are no longer referenced are garbage
+// collected, whether or not they were stopped or have fired.
+func timersCollected(p *Pass, file *ast.File) bool {
+	return atLeastGo(p, file, "go1.23")
+}
+
+// atLeastGo reports whether file is compiled for Go version v or later.
+//
+// The version comes from types.Info.FileVersions, which the driver derives
+// from the module's go directive, the -lang flag, and //go:build lines. When
+// no version is known the current toolchain semantics apply.
+func atLeastGo(p *Pass, file *ast.File, v string) bool {
+	fv := p.TypesInfo.FileVersions[file]
+	if fv == "" {
+		fv = p.Pkg.GoVersion()
+	}
+	if fv == "" {
+		return true
+	}
+	return version.Compare(fv, v) >= 0
+}
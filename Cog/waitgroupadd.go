@@ -0,0 +1,145 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// waitGroupAddRule reports sync.WaitGroup.Add called inside the goroutine it
+// accounts for, when the same WaitGroup is waited on elsewhere.
+//
+//	for _, job := range jobs {
+//		go func() {
+//			wg.Add(1) // races with wg.Wait below
+//			defer wg.Done()
+//			run(job)
+//		}()
+//	}
+//	wg.Wait() // may return before any goroutine has called Add
+//
+// Add must happen before the go statement, so that Wait cannot observe a
+// zero counter while goroutines are still starting. WaitGroups are matched
+// by variable (or struct field) identity. When Add is a statement of its
+// own with a constant argument, the fix moves it in front of the go
+// statement.
+var waitGroupAddRule = &Rule{
+	ID:       "CogWaitGroupAdd",
+	Doc:      "report WaitGroup.Add calls inside the goroutine they account for",
+	Run:      runWaitGroupAdd,
+	Category: CategoryConcurrency,
+	Fixable:  true,
+}
+
+func runWaitGroupAdd(p *Pass) {
+	waits := make(map[types.Object][]inspector.Cursor)
+	for c := range p.Inspector.Root().Preorder((*ast.CallExpr)(nil)) {
+		call, ok := c.Node().(*ast.CallExpr)
+		if !ok || calleeName(p.TypesInfo, call) != "(*sync.WaitGroup).Wait" {
+			continue
+		}
+		if obj := waitGroupOf(p, call); obj != nil {
+			waits[obj] = append(waits[obj], c)
+		}
+	}
+
+	for c := range p.Inspector.Root().Preorder((*ast.CallExpr)(nil)) {
+		call, ok := c.Node().(*ast.CallExpr)
+		if !ok || calleeName(p.TypesInfo, call) != "(*sync.WaitGroup).Add" {
+			continue
+		}
+		goStmt, lit := spawningGo(c)
+		if goStmt == nil {
+			continue
+		}
+		obj := waitGroupOf(p, call)
+		sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+		if obj == nil || !ok {
+			continue
+		}
+		var wait ast.Node
+		for _, w := range waits[obj] {
+			if n := w.Node(); n.Pos() < lit.Pos() || n.End() > lit.End() {
+				wait = n
+				break
+			}
+		}
+		if wait == nil {
+			continue
+		}
+		name := types.ExprString(sel.X)
+		p.Report(call, name+".Add inside the goroutine races with "+name+".Wait on line "+
+			strconv.Itoa(p.Fset.Position(wait.Pos()).Line)+", which may return before Add runs; call "+
+			name+".Add before the go statement", moveAddFix(p, c, goStmt, lit)...)
+	}
+}
+
+// waitGroupOf returns the variable or field whose WaitGroup the method call
+// operates on, or nil when the receiver is not a plain variable or field.
+func waitGroupOf(p *Pass, call *ast.CallExpr) types.Object {
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return nil
+	}
+	x := ast.Unparen(sel.X)
+	if u, ok := x.(*ast.UnaryExpr); ok && u.Op == token.AND {
+		x = ast.Unparen(u.X)
+	}
+	switch x := x.(type) {
+	case *ast.Ident:
+		return p.TypesInfo.Uses[x]
+	case *ast.SelectorExpr:
+		return p.TypesInfo.Uses[x.Sel]
+	}
+	return nil
+}
+
+// spawningGo returns the go statement and its function literal when the
+// literal is the innermost function containing c, or nils.
+func spawningGo(c inspector.Cursor) (*ast.GoStmt, *ast.FuncLit) {
+	lit := enclosingFuncLit(c)
+	if lit == nil {
+		return nil, nil
+	}
+	for gc := range c.Enclosing((*ast.GoStmt)(nil)) {
+		if g, ok := gc.Node().(*ast.GoStmt); ok && ast.Unparen(g.Call.Fun) == lit {
+			return g, lit
+		}
+		break
+	}
+	return nil, nil
+}
+
+// moveAddFix moves the statement `wg.Add(n)` at c, with n constant and
+// starting its line directly in the goroutine's body, in front of the go
+// statement.
+func moveAddFix(p *Pass, c inspector.Cursor, goStmt *ast.GoStmt, lit *ast.FuncLit) []analysis.SuggestedFix {
+	call, ok := c.Node().(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 || p.TypesInfo.Types[call.Args[0]].Value == nil {
+		return nil
+	}
+	stmt, ok := c.Parent().Node().(*ast.ExprStmt)
+	if !ok || c.Parent().Parent().Node() != lit.Body {
+		return nil
+	}
+	indent := lineIndent(p, stmt.Pos())
+	start, eol := stmt.Pos()-token.Pos(len(indent)), lineEnd(p, stmt.End())
+	if p.Fset.Position(start).Column != 1 {
+		return nil // shares its line with other code
+	}
+	text, ok := sourceRange(p, stmt.Pos(), eol) // with any trailing comment
+	if !ok {
+		return nil
+	}
+	return []analysis.SuggestedFix{{
+		Message: "Call Add before the go statement",
+		TextEdits: []analysis.TextEdit{
+			{Pos: goStmt.Pos(), End: goStmt.Pos(), NewText: []byte(text + "\n" + lineIndent(p, goStmt.Pos()))},
+			{Pos: start, End: eol + 1},
+		},
+	}}
+}
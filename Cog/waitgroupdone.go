@@ -0,0 +1,220 @@
+package cog
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/cfg"
+)
+
+// waitGroupDoneRule reports a goroutine that calls sync.WaitGroup.Done on
+// some paths but can return or panic without calling it on another.
+//
+//	wg.Add(1)
+//	go func() {
+//		if err := step(); err != nil {
+//			return // wg.Done is never called: wg.Wait blocks forever
+//		}
+//		wg.Done()
+//	}()
+//
+// Each Add must be matched by a Done, or Wait never returns. The rule
+// follows the control flow of the goroutine's function literal from its
+// start to each return, explicit panic and the end of its body; a path that
+// reaches one without a call to Done on the same WaitGroup is reported.
+// Calls that never return, such as os.Exit and log.Fatal, end the program
+// and are not paths. A `defer wg.Done()` anywhere in the literal covers
+// every path, and is what the fix inserts at its top, removing the other
+// Done calls.
+var waitGroupDoneRule = &Rule{
+	ID:       "CogWaitGroupDone",
+	Doc:      "report goroutines with a path that returns or panics without calling WaitGroup.Done",
+	Run:      runWaitGroupDone,
+	Category: CategoryConcurrency,
+	Fixable:  true,
+}
+
+func runWaitGroupDone(p *Pass) {
+	for c := range p.Inspector.Root().Preorder((*ast.GoStmt)(nil)) {
+		goStmt, ok := c.Node().(*ast.GoStmt)
+		if !ok {
+			continue
+		}
+		lit, ok := ast.Unparen(goStmt.Call.Fun).(*ast.FuncLit)
+		if !ok {
+			continue
+		}
+		dones := doneCalls(p, lit.Body)
+		for obj, calls := range dones {
+			if deferredDone(p, lit.Body, obj) {
+				continue
+			}
+			exit, kind, ok := uncoveredExit(p, funcCFG(p, lit.Body), lit.Body, obj)
+			if !ok {
+				continue
+			}
+			sel, ok := ast.Unparen(calls[0].Fun).(*ast.SelectorExpr)
+			if !ok {
+				continue
+			}
+			name := types.ExprString(sel.X)
+			p.Report(goStmt, "goroutine can "+kind+" on line "+strconv.Itoa(p.Fset.Position(exit).Line)+
+				" without calling "+name+".Done, so "+name+".Wait blocks forever; call defer "+name+
+				".Done() at the top of the goroutine", deferDoneFix(p, goStmt, lit, name, calls)...)
+		}
+	}
+}
+
+// doneCalls returns the calls to WaitGroup.Done in body, outside nested
+// function literals, by the variable or field of the WaitGroup.
+func doneCalls(p *Pass, body *ast.BlockStmt) map[types.Object][]*ast.CallExpr {
+	dones := make(map[types.Object][]*ast.CallExpr)
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.DeferStmt:
+			return false // a deferred Done covers every path; see deferredDone
+		case *ast.CallExpr:
+			if calleeName(p.TypesInfo, n) == "(*sync.WaitGroup).Done" {
+				if obj := waitGroupOf(p, n); obj != nil {
+					dones[obj] = append(dones[obj], n)
+				}
+			}
+		}
+		return true
+	})
+	return dones
+}
+
+// deferredDone reports whether body defers a call to Done on the
+// WaitGroup obj, directly or in a deferred function literal.
+func deferredDone(p *Pass, body *ast.BlockStmt, obj types.Object) bool {
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.DeferStmt:
+			ast.Inspect(n.Call, func(m ast.Node) bool {
+				if call, ok := m.(*ast.CallExpr); ok && calleeName(p.TypesInfo, call) == "(*sync.WaitGroup).Done" &&
+					waitGroupOf(p, call) == obj {
+					found = true
+				}
+				return !found
+			})
+		}
+		return !found
+	})
+	return found
+}
+
+// uncoveredExit searches g for a path from the start of body to a return,
+// a panic or the end of body that never calls Done on obj. It returns the
+// position of the exit and "return", "panic" or "finish".
+func uncoveredExit(p *Pass, g *cfg.CFG, body *ast.BlockStmt, obj types.Object) (token.Pos, string, bool) {
+	if len(g.Blocks) == 0 {
+		return token.NoPos, "", false
+	}
+	seen := make(map[*cfg.Block]bool)
+	var walk func(b *cfg.Block) (token.Pos, string, bool)
+	walk = func(b *cfg.Block) (token.Pos, string, bool) {
+		if seen[b] || !b.Live {
+			return token.NoPos, "", false
+		}
+		seen[b] = true
+		for _, n := range b.Nodes {
+			if callsDone(p, n, obj) {
+				return token.NoPos, "", false
+			}
+		}
+		if len(b.Succs) == 0 {
+			if len(b.Nodes) == 0 {
+				return body.Rbrace, "finish", true
+			}
+			switch last := b.Nodes[len(b.Nodes)-1].(type) {
+			case *ast.ReturnStmt:
+				return last.Pos(), "return", true
+			case *ast.ExprStmt:
+				if call, ok := last.X.(*ast.CallExpr); ok && isBuiltin(p, call.Fun, "panic") {
+					return last.Pos(), "panic", true
+				}
+				if call, ok := last.X.(*ast.CallExpr); ok && isNoReturn(p, call) {
+					return token.NoPos, "", false // os.Exit and the like end the program
+				}
+			}
+			return body.Rbrace, "finish", true
+		}
+		for _, succ := range b.Succs {
+			if pos, kind, ok := walk(succ); ok {
+				return pos, kind, true
+			}
+		}
+		return token.NoPos, "", false
+	}
+	return walk(g.Blocks[0])
+}
+
+// callsDone reports whether n calls Done on the WaitGroup obj outside
+// nested function literals.
+func callsDone(p *Pass, n ast.Node, obj types.Object) bool {
+	found := false
+	ast.Inspect(n, func(m ast.Node) bool {
+		switch m := m.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.CallExpr:
+			if calleeName(p.TypesInfo, m) == "(*sync.WaitGroup).Done" && waitGroupOf(p, m) == obj {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// deferDoneFix inserts `defer name.Done()` at the top of the goroutine's
+// body, below any comment after its brace, and deletes the Done calls,
+// when each is a statement on a line of its own.
+func deferDoneFix(p *Pass, goStmt *ast.GoStmt, lit *ast.FuncLit, name string, calls []*ast.CallExpr) []analysis.SuggestedFix {
+	edits := make([]analysis.TextEdit, 0, len(calls)+1)
+	for _, call := range calls {
+		stmt := statementOf(lit.Body, call)
+		if stmt == nil {
+			return nil
+		}
+		start, eol := stmt.Pos()-token.Pos(len(lineIndent(p, stmt.Pos()))), lineEnd(p, stmt.End())
+		rest, ok := sourceRange(p, stmt.End(), eol)
+		if !ok || p.Fset.Position(start).Column != 1 || lineEnd(p, start) != eol ||
+			(strings.TrimSpace(rest) != "" && !strings.HasPrefix(strings.TrimSpace(rest), "//")) {
+			return nil // shares its line with other code
+		}
+		edits = append(edits, analysis.TextEdit{Pos: start, End: eol + 1})
+	}
+	top := lit.Body.Lbrace + 1
+	if rest, ok := sourceRange(p, top, lineEnd(p, top)); ok && strings.HasPrefix(strings.TrimSpace(rest), "//") {
+		top = lineEnd(p, top)
+	}
+	indent := lineIndent(p, goStmt.Pos()) + "\t"
+	edits = append(edits, analysis.TextEdit{
+		Pos: top, End: top, NewText: []byte("\n" + indent + "defer " + name + ".Done()"),
+	})
+	return []analysis.SuggestedFix{{Message: "Defer " + name + ".Done() at the top of the goroutine", TextEdits: edits}}
+}
+
+// statementOf returns the expression statement in body whose expression is
+// call, or nil.
+func statementOf(body *ast.BlockStmt, call *ast.CallExpr) *ast.ExprStmt {
+	var stmt *ast.ExprStmt
+	ast.Inspect(body, func(n ast.Node) bool {
+		if s, ok := n.(*ast.ExprStmt); ok && s.X == call {
+			stmt = s
+		}
+		return stmt == nil
+	})
+	return stmt
+}
@@ -0,0 +1,173 @@
+package cog
+
+import (
+	"go/ast"
+	"go/types"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// weakRandomRule reports math/rand used to produce what looks like a
+// secret.
+//
+//	func newSessionToken() string {
+//		b := make([]byte, 16)
+//		for i := range b {
+//			b[i] = byte(rand.Intn(256)) // predictable: seeded, not a CSPRNG
+//		}
+//		return hex.EncodeToString(b)
+//	}
+//
+// math/rand and math/rand/v2 are not cryptographically secure: their
+// output can be predicted from a few samples, so tokens, keys and salts
+// drawn from them can be guessed. A call into either package is reported
+// when the enclosing function, a variable, field or key the result is
+// assigned to, or the buffer a Read fills, has a name with a word from
+// -weakrandom.words, such as token, secret or salt. Names with a word from
+// -weakrandom.allow, such as jitter or sample, mark randomness that need
+// not be secure, and silence the rule for the call. _test.go files are not
+// checked.
+var weakRandomRule = &Rule{
+	ID:       "CogWeakRandom",
+	Doc:      "report math/rand used to generate tokens, keys and other secrets",
+	Run:      runWeakRandom,
+	Category: CategorySecurity,
+}
+
+var (
+	// weakRandomWords are the name words that suggest a security use.
+	weakRandomWords = listFlag{
+		"token", "secret", "password", "passwd", "nonce", "key", "salt",
+		"otp", "csrf", "session", "credential", "apikey",
+	}
+
+	// weakRandomAllow are the name words that mark non-security randomness.
+	weakRandomAllow = listFlag{"jitter", "sample", "sampling", "shuffle", "backoff", "retry", "delay", "test"}
+)
+
+func init() {
+	Analyzer.Flags.Var(&weakRandomWords, "weakrandom.words",
+		"comma-separated name words (case-insensitive) that make CogWeakRandom treat math/rand use as security-sensitive")
+	Analyzer.Flags.Var(&weakRandomAllow, "weakrandom.allow",
+		"comma-separated name words (case-insensitive) that mark math/rand use as not security-sensitive")
+}
+
+func runWeakRandom(p *Pass) {
+	for c := range p.Inspector.Root().Preorder((*ast.CallExpr)(nil)) {
+		call, ok := c.Node().(*ast.CallExpr)
+		if !ok || strings.HasSuffix(p.Fset.Position(call.Pos()).Filename, "_test.go") {
+			continue
+		}
+		fn, ok := typeutil.Callee(p.TypesInfo, call).(*types.Func)
+		if !ok || fn.Pkg() == nil {
+			continue
+		}
+		if path := fn.Pkg().Path(); path != "math/rand" && path != "math/rand/v2" {
+			continue
+		}
+		names := make([]contextName, 0, 3)
+		if fn.Name() == "Read" && len(call.Args) == 1 {
+			names = appendTarget(names, call.Args[0]) // the buffer filled
+		}
+		names = append(names, randomContext(c)...)
+		if slices.ContainsFunc(names, func(n contextName) bool { return hasNameWord(n.name, weakRandomAllow) }) {
+			continue
+		}
+		i := slices.IndexFunc(names, func(n contextName) bool { return hasNameWord(n.name, weakRandomWords) })
+		if i < 0 {
+			continue
+		}
+		p.Report(call, fn.FullName()+" is used for the "+names[i].kind+" "+names[i].name+", whose name suggests "+
+			"a secret, but math/rand is predictable; use crypto/rand instead: "+cryptoAlternative(fn.Name()))
+	}
+}
+
+// A contextName is a name describing where a value ends up.
+type contextName struct {
+	kind string // "variable", "field" or "function"
+	name string
+}
+
+// randomContext returns the names describing the result of the call at c:
+// the variables, fields or keys it is assigned to, then the enclosing
+// function.
+func randomContext(c inspector.Cursor) []contextName {
+	names := make([]contextName, 0, 2)
+	cur := c.Parent()
+walk:
+	for ; ; cur = cur.Parent() {
+		switch n := cur.Node().(type) {
+		case *ast.AssignStmt:
+			for _, lhs := range n.Lhs {
+				names = appendTarget(names, lhs)
+			}
+			break walk
+		case *ast.ValueSpec:
+			for _, name := range n.Names {
+				names = append(names, contextName{"variable", name.Name})
+			}
+			break walk
+		case *ast.KeyValueExpr:
+			if key, ok := n.Key.(*ast.Ident); ok {
+				names = append(names, contextName{"field", key.Name})
+			}
+			break walk
+		case *ast.FuncLit:
+			break walk
+		case ast.Expr:
+		default:
+			break walk
+		}
+	}
+	if decl := enclosingDecl(c); decl != nil {
+		names = append(names, contextName{"function", decl.Name.Name})
+	}
+	return names
+}
+
+// appendTarget appends the name of the variable or field assigned by lhs,
+// looking through index expressions.
+func appendTarget(names []contextName, lhs ast.Expr) []contextName {
+	for {
+		switch e := lhs.(type) {
+		case *ast.Ident:
+			return append(names, contextName{"variable", e.Name})
+		case *ast.SelectorExpr:
+			return append(names, contextName{"field", e.Sel.Name})
+		case *ast.IndexExpr:
+			lhs = e.X
+		case *ast.ParenExpr:
+			lhs = e.X
+		default:
+			return names
+		}
+	}
+}
+
+// hasNameWord reports whether a word of the identifier name, or its plural,
+// is one of words, ignoring case.
+func hasNameWord(name string, words listFlag) bool {
+	for _, w := range identWords(name) {
+		for _, want := range words {
+			if strings.EqualFold(w, want) || strings.EqualFold(w, want+"s") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// cryptoAlternative names the crypto/rand replacement of the math/rand
+// function name.
+func cryptoAlternative(name string) string {
+	switch {
+	case name == "Read":
+		return "rand.Read(b) fills b with secure random bytes"
+	case strings.HasPrefix(name, "Int") || strings.HasPrefix(name, "Uint") || name == "N":
+		return "rand.Int(rand.Reader, max) draws a secure number below max"
+	}
+	return "rand.Read fills a byte slice with secure random bytes, and rand.Text returns a random token string"
+}